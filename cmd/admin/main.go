@@ -0,0 +1,287 @@
+// Command admin berisi perintah administrasi yang dijalankan di luar API server.
+//
+//	go run ./cmd/admin import-events -file events.csv -organizer 1 [-format csv] [-dry-run]
+//	go run ./cmd/admin reconcile [-report discrepancies.csv]
+//	go run ./cmd/admin ledger-backfill
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/joho/godotenv"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"gatherly-app/config"
+	"gatherly-app/models"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "import-events":
+		os.Exit(importEvents(os.Args[2:]))
+	case "reconcile":
+		os.Exit(reconcile(os.Args[2:]))
+	case "ledger-backfill":
+		os.Exit(ledgerBackfill(os.Args[2:]))
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: admin <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  import-events   bulk import events and tickets from CSV or JSON")
+	fmt.Fprintln(os.Stderr, "  reconcile       check transactions against Midtrans and repair statuses")
+	fmt.Fprintln(os.Stderr, "  ledger-backfill post settlement entries for settled transactions missing from the ledger")
+}
+
+func loadConfig() *config.Config {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Error yang terjadi :", err.Error())
+	}
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func openDatabase(cfg *config.Config) *gorm.DB {
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+	return db
+}
+
+func importEvents(args []string) int {
+	flags := flag.NewFlagSet("import-events", flag.ExitOnError)
+	file := flags.String("file", "", "path to the CSV or JSON file")
+	format := flags.String("format", "", "csv or json (default: from file extension)")
+	organizerID := flags.Int("organizer", 0, "user ID that will own the imported events")
+	dryRun := flags.Bool("dry-run", false, "validate and geocode without saving")
+	flags.Parse(args)
+
+	if *file == "" || *organizerID <= 0 {
+		flags.Usage()
+		return 2
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Printf("failed to read %s: %v", *file, err)
+		return 1
+	}
+
+	db := openDatabase(loadConfig())
+	importUC := usecase.NewEventImportUsecase(repositories.NewEventsRepository(db), repositories.NewEventImportRepository(db))
+
+	result, err := importUC.ImportNow(*organizerID, *format, data, *dryRun)
+	if err != nil {
+		log.Printf("import failed: %v", err)
+		return 1
+	}
+
+	for _, problem := range result.Errors {
+		if problem.Field != "" {
+			fmt.Printf("%s:%d: %s %s\n", *file, problem.Line, problem.Field, problem.Message)
+		} else {
+			fmt.Printf("%s:%d: %s\n", *file, problem.Line, problem.Message)
+		}
+	}
+	if len(result.Errors) > 0 {
+		fmt.Printf("%d error(s), nothing was saved\n", len(result.Errors))
+		return 1
+	}
+
+	summary, _ := json.MarshalIndent(result.Events, "", "  ")
+	fmt.Println(string(summary))
+	if *dryRun {
+		fmt.Printf("dry run: %d event(s) valid, nothing was saved\n", len(result.Events))
+	} else {
+		fmt.Printf("imported %d event(s)\n", len(result.Events))
+	}
+	return 0
+}
+
+func reconcile(args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	reportPath := flags.String("report", "", "write the discrepancy report to this CSV file")
+	flags.Parse(args)
+
+	cfg := loadConfig()
+	db := openDatabase(cfg)
+
+	// Efek samping perbaikan status sama dengan di server: notifikasi masuk outbox dan webhook masuk
+	// antrean delivery, lalu dikirim oleh job server.
+	pubsub := service.NewMemoryPubSub()
+	channels := []service.MessageChannel{service.NewInboxChannel(repositories.NewInboxRepository(db), pubsub)}
+	if cfg.SMTP.Host != "" {
+		channels = append(channels, service.NewSMTPChannel(cfg.SMTP))
+	}
+
+	userRepo := repositories.NewUserRepository(db)
+	eventRepo := repositories.NewEventsRepository(db)
+	attendeeRepo := repositories.MakeNewEventAttendeeRepository(db)
+	midtransService := service.NewMidtransService(resty.New(), cfg.MidtransServerKey)
+	webhookSender := service.NewWebhookSender(resty.New().
+		SetTimeout(cfg.WebhookTimeout).
+		SetRedirectPolicy(resty.NoRedirectPolicy()))
+
+	notificationUC := usecase.NewNotificationUsecase(repositories.NewNotificationRepository(db), userRepo, cfg.PaymentExpiry, channels...)
+	statsUC := usecase.NewEventStatsUsecase(attendeeRepo, eventRepo, pubsub)
+	webhookUC := usecase.NewWebhookUsecase(repositories.NewWebhookRepository(db), eventRepo, attendeeRepo, userRepo, webhookSender)
+	bankFormat, err := usecase.LoadBankFileFormat(cfg.Payout.BankFormatFile)
+	if err != nil {
+		log.Printf("failed to load payout bank format: %v", err)
+		return 1
+	}
+	ledgerRepo := repositories.NewLedgerRepository(db)
+	ledgerUC := usecase.NewLedgerUsecase(ledgerRepo, eventRepo)
+	defaultFee := models.FeeRule{PercentBps: cfg.Payout.DefaultFeePercentBps, FixedAmount: cfg.Payout.DefaultFeeFixed}
+	payoutUC := usecase.NewPayoutUsecase(repositories.NewPayoutRepository(db), ledgerRepo, ledgerUC, eventRepo, defaultFee, cfg.Payout.MinPayoutAmount, bankFormat)
+	invoiceUC := usecase.NewInvoiceUsecase(repositories.NewInvoiceRepository(db), eventRepo, userRepo)
+	transactionUC := usecase.NewTransactionUsecase(repositories.NewTransactionRepository(db), midtransService, notificationUC, pubsub, statsUC, webhookUC, ledgerUC, payoutUC, invoiceUC)
+	reconciliationUC := usecase.NewReconciliationUsecase(repositories.NewReconciliationRepository(db), midtransService, transactionUC, cfg.PaymentExpiry, cfg.ReconcileLookback)
+
+	run, err := reconciliationUC.Run(nil)
+	if err != nil {
+		log.Printf("reconciliation failed: %v", err)
+		return 1
+	}
+	fmt.Printf("run %d: checked %d transaction(s), %d discrepancy(ies), %d repaired\n",
+		run.ID, run.Checked, run.Discrepancies, run.Repaired)
+
+	if *reportPath != "" {
+		file, err := os.Create(*reportPath)
+		if err != nil {
+			log.Printf("failed to create %s: %v", *reportPath, err)
+			return 1
+		}
+		defer file.Close()
+
+		if err := reconciliationUC.WriteReport("admin", run.ID, utils.NewCSVTableWriter(file)); err != nil {
+			log.Printf("failed to write report: %v", err)
+			return 1
+		}
+		fmt.Printf("report written to %s\n", *reportPath)
+	}
+	return 0
+}
+
+// ledgerBackfill mencatat settlement untuk transaksi lunas yang terjadi sebelum ledger ada.
+// Settlement yang sudah tercatat dengan nominal berbeda tidak diubah, hanya dilaporkan.
+// Status transaksi Midtrans lama bisa berasal dari notifikasi yang belum diverifikasi, jadi
+// statusnya dicek ulang ke gateway sebelum dicatat.
+func ledgerBackfill(args []string) int {
+	flags := flag.NewFlagSet("ledger-backfill", flag.ExitOnError)
+	flags.Parse(args)
+
+	cfg := loadConfig()
+	db := openDatabase(cfg)
+	midtransService := service.NewMidtransService(resty.New(), cfg.MidtransServerKey)
+	ledgerRepo := repositories.NewLedgerRepository(db)
+	transactionRepo := repositories.NewTransactionRepository(db)
+	ledgerUC := usecase.NewLedgerUsecase(ledgerRepo, repositories.NewEventsRepository(db))
+
+	posted, failed := 0, 0
+	skipped := make(map[string]bool)
+	for {
+		mismatches, err := ledgerRepo.UnrecordedSettlements(100 + len(skipped))
+		if err != nil {
+			log.Printf("failed to find unrecorded settlements: %v", err)
+			return 1
+		}
+
+		progressed := false
+		for _, mismatch := range mismatches {
+			if skipped[mismatch.OrderID] {
+				continue
+			}
+			progressed = true
+
+			if mismatch.Recorded != 0 {
+				fmt.Printf("%s: ledger has %d, transaction is %d; post a correcting entry manually\n",
+					mismatch.OrderID, mismatch.Recorded, mismatch.Expected)
+				skipped[mismatch.OrderID] = true
+				failed++
+				continue
+			}
+
+			transaction, err := transactionRepo.FindByTransactionIdNoUser(mismatch.OrderID)
+			if err == nil {
+				err = verifySettlement(midtransService, transaction)
+			}
+			if err == nil {
+				err = ledgerUC.RecordSettlement(transaction)
+			}
+			if err != nil {
+				log.Printf("%s: %v", mismatch.OrderID, err)
+				skipped[mismatch.OrderID] = true
+				failed++
+				continue
+			}
+			posted++
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	fmt.Printf("posted %d settlement(s), %d failed\n", posted, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// verifySettlement memastikan transaksi Midtrans memang lunas di gateway dengan nominal yang sama.
+// Transaksi invoice ditandai lunas oleh organizer sendiri, jadi tidak dicek.
+func verifySettlement(midtransService service.MidtransService, transaction models.Transactions) error {
+	if transaction.Gateway != models.PaymentGatewayMidtrans {
+		return nil
+	}
+	status, err := midtransService.GetStatus(transaction.PaymentGatewayTransactionId)
+	if err != nil {
+		return fmt.Errorf("failed to check gateway status: %w", err)
+	}
+	settled := status.TransactionStatus == "settlement" ||
+		(status.TransactionStatus == "capture" && status.FraudStatus != "challenge")
+	if !settled {
+		return fmt.Errorf("gateway status is %q, not settled", status.TransactionStatus)
+	}
+	amount, err := strconv.ParseFloat(status.GrossAmount, 64)
+	if err != nil {
+		return fmt.Errorf("invalid gateway amount %q", status.GrossAmount)
+	}
+	gateway, err := models.MoneyFromMajor(amount, transaction.Money().Currency)
+	if err != nil || gateway.Amount != transaction.Amount {
+		return fmt.Errorf("gateway amount %s does not match %s", status.GrossAmount, transaction.Money())
+	}
+	return nil
+}
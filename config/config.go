@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func (c *Config) readConfig() error {
@@ -18,6 +22,11 @@ func (c *Config) readConfig() error {
 	c.APIConfig = APIConfig{
 		ApiPort: os.Getenv("API_PORT"),
 	}
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			c.TrustedProxies = append(c.TrustedProxies, proxy)
+		}
+	}
 	// Token Configuration
 	c.TokenConfig = TokenConfig{
 		ApplicationName:     os.Getenv("APP_NAME"),
@@ -30,6 +39,112 @@ func (c *Config) readConfig() error {
 
 	c.MidtransServerKey = os.Getenv("MIDTRANS_SERVER_KEY")
 
+	// Background job configuration
+	c.JobConfig = JobConfig{
+		TrendingRefreshInterval:   durationFromEnv("TRENDING_REFRESH_INTERVAL", 15*time.Minute),
+		TrendingWindow:            durationFromEnv("TRENDING_WINDOW", 7*24*time.Hour),
+		BroadcastDispatchInterval: durationFromEnv("BROADCAST_DISPATCH_INTERVAL", 30*time.Second),
+		NotificationDispatchInterval: durationFromEnv("NOTIFICATION_DISPATCH_INTERVAL", 30*time.Second),
+		NotificationScheduleInterval: durationFromEnv("NOTIFICATION_SCHEDULE_INTERVAL", 5*time.Minute),
+		// Midtrans Snap default expiry adalah 24 jam
+		PaymentExpiry: durationFromEnv("PAYMENT_EXPIRY", 24*time.Hour),
+		PaymentExpiryInterval: durationFromEnv("PAYMENT_EXPIRY_INTERVAL", 5*time.Minute),
+		WebhookDispatchInterval: durationFromEnv("WEBHOOK_DISPATCH_INTERVAL", 15*time.Second),
+		WebhookTimeout:          durationFromEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+		ImportDispatchInterval:  durationFromEnv("EVENT_IMPORT_INTERVAL", 30*time.Second),
+		ReconcileInterval:       durationFromEnv("RECONCILE_INTERVAL", time.Hour),
+		ReconcileLookback:       durationFromEnv("RECONCILE_LOOKBACK", 72*time.Hour),
+		LedgerCheckInterval:     durationFromEnv("LEDGER_CHECK_INTERVAL", time.Hour),
+		SeatHold:                durationFromEnv("SEAT_HOLD_DURATION", 10*time.Minute),
+	}
+
+	c.SMTP = SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if c.SMTP.Port == "" {
+		c.SMTP.Port = "587"
+	}
+
+	// Penyimpanan file upload (form registrasi)
+	c.StorageConfig = StorageConfig{
+		UploadDir:     os.Getenv("UPLOAD_DIR"),
+		MaxUploadSize: 5 << 20, // Default 5 MB
+	}
+	if c.UploadDir == "" {
+		c.UploadDir = "uploads"
+	}
+	if mb, err := strconv.Atoi(os.Getenv("MAX_UPLOAD_SIZE_MB")); err == nil && mb > 0 {
+		c.MaxUploadSize = int64(mb) << 20
+	}
+
+	// Fee platform dan payout organizer
+	c.Payout = PayoutConfig{
+		MinPayoutAmount: 10000 * 100, // Default Rp10.000
+		BankFormatFile:  os.Getenv("PAYOUT_BANK_FORMAT"),
+	}
+	if percent, err := strconv.ParseFloat(os.Getenv("PLATFORM_FEE_PERCENT"), 64); err == nil && percent >= 0 && percent <= 100 {
+		c.Payout.DefaultFeePercentBps = int(percent*100 + 0.5)
+	}
+	if fixed, err := strconv.ParseInt(os.Getenv("PLATFORM_FEE_FIXED"), 10, 64); err == nil && fixed >= 0 {
+		c.Payout.DefaultFeeFixed = fixed
+	}
+	if minimum, err := strconv.ParseInt(os.Getenv("PAYOUT_MIN_AMOUNT"), 10, 64); err == nil && minimum > 0 {
+		c.Payout.MinPayoutAmount = minimum
+	}
+
+	// Group booking dibayar lewat transfer bank terhadap invoice
+	c.GroupBooking = GroupBookingConfig{
+		MinTickets:  2,
+		MaxTickets:  500,
+		PaymentTerm: durationFromEnv("GROUP_BOOKING_PAYMENT_TERM", 14*24*time.Hour),
+	}
+	if minimum, err := strconv.Atoi(os.Getenv("GROUP_BOOKING_MIN_TICKETS")); err == nil && minimum > 0 {
+		c.GroupBooking.MinTickets = minimum
+	}
+	if maximum, err := strconv.Atoi(os.Getenv("GROUP_BOOKING_MAX_TICKETS")); err == nil && maximum > 0 {
+		c.GroupBooking.MaxTickets = maximum
+	}
+
+	// Rate limit registrasi/signup dan proteksi bot untuk on-sale ramai
+	c.PurchaseGuard = PurchaseGuardConfig{
+		RegisterPerUser:     RateLimit{Max: 10, Window: durationFromEnv("REGISTER_ATTEMPTS_WINDOW", time.Minute)},
+		RegisterPerIP:       RateLimit{Max: 30, Window: durationFromEnv("REGISTER_ATTEMPTS_WINDOW", time.Minute)},
+		SignupPerIP:         RateLimit{Max: 5, Window: durationFromEnv("SIGNUP_ATTEMPTS_WINDOW", time.Hour)},
+		SignupChallenge:     os.Getenv("SIGNUP_REQUIRE_CHALLENGE") == "true",
+		ChallengeProvider:   os.Getenv("CHALLENGE_PROVIDER"),
+		ChallengeSecret:     os.Getenv("CHALLENGE_SECRET"),
+		ChallengeDifficulty: 20,
+		ChallengeTTL:        durationFromEnv("CHALLENGE_TTL", 5*time.Minute),
+		QueueTokenSecret:    os.Getenv("QUEUE_TOKEN_SECRET"),
+		QueueAdmitInterval:  durationFromEnv("QUEUE_ADMIT_INTERVAL", 10*time.Second),
+		CleanupInterval:     durationFromEnv("RATE_LIMIT_CLEANUP_INTERVAL", time.Hour),
+	}
+	for env, limit := range map[string]*int{
+		"REGISTER_ATTEMPTS_PER_USER": &c.PurchaseGuard.RegisterPerUser.Max,
+		"REGISTER_ATTEMPTS_PER_IP":   &c.PurchaseGuard.RegisterPerIP.Max,
+		"SIGNUP_ATTEMPTS_PER_IP":     &c.PurchaseGuard.SignupPerIP.Max,
+	} {
+		if value, err := strconv.Atoi(os.Getenv(env)); err == nil && value >= 0 {
+			*limit = value
+		}
+	}
+	if bits, err := strconv.Atoi(os.Getenv("CHALLENGE_DIFFICULTY")); err == nil && bits > 0 && bits <= 32 {
+		c.PurchaseGuard.ChallengeDifficulty = bits
+	}
+	if c.PurchaseGuard.ChallengeSecret == "" {
+		c.PurchaseGuard.ChallengeSecret = c.JwtSignatureKey
+	}
+	if c.PurchaseGuard.QueueTokenSecret == "" {
+		c.PurchaseGuard.QueueTokenSecret = c.PurchaseGuard.ChallengeSecret
+	}
+
+	// Kurs untuk menampilkan perkiraan harga dalam mata uang lain
+	c.ExchangeRatesFile = os.Getenv("EXCHANGE_RATES_FILE")
+
 	// Validasi config wajib
 	
 	required := map[string]string{
@@ -57,6 +172,21 @@ func (c *Config) readConfig() error {
 	return nil
 }
 
+// durationFromEnv membaca durasi format Go (mis. "15m", "24h"), atau fallback jika kosong/tidak valid
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("invalid %s %q, using default %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
 func NewConfig() (*Config, error) {
 	cfg := &Config{}
 	if err := cfg.readConfig(); err != nil {
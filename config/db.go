@@ -1,5 +1,10 @@
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
 type DBConfig struct {
 	Host     string
 	Port     string
@@ -9,8 +14,14 @@ type DBConfig struct {
 	Driver   string
 }
 
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		c.Host, c.Port, c.Username, c.Password, c.Database)
+}
+
 type APIConfig struct {
-	ApiPort string
+	ApiPort        string
+	TrustedProxies []string // IP/CIDR reverse proxy yang boleh mengisi X-Forwarded-For; kosong = tidak ada
 }
 
 type TokenConfig struct {
@@ -20,10 +31,85 @@ type TokenConfig struct {
 	AccessTokenLifeTime int // dalam jam
 }
 
+type JobConfig struct {
+	TrendingRefreshInterval      time.Duration
+	TrendingWindow               time.Duration
+	BroadcastDispatchInterval    time.Duration
+	NotificationDispatchInterval time.Duration
+	NotificationScheduleInterval time.Duration
+	PaymentExpiry                time.Duration // masa berlaku transaksi pending di payment gateway
+	PaymentExpiryInterval        time.Duration
+	WebhookDispatchInterval      time.Duration
+	WebhookTimeout               time.Duration
+	ImportDispatchInterval       time.Duration // import event besar yang diantrekan
+	ReconcileInterval            time.Duration
+	ReconcileLookback            time.Duration // transaksi non-pending selama periode ini ikut dicek ulang
+	LedgerCheckInterval          time.Duration
+	SeatHold                     time.Duration // lama kursi ditahan selama user menyelesaikan registrasi
+}
+
+// SMTPConfig kosong (Host == "") berarti channel email tidak aktif
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type StorageConfig struct {
+	UploadDir     string
+	MaxUploadSize int64 // dalam byte
+}
+
+// PayoutConfig berisi fee platform default dan pengaturan payout organizer. Nominal dalam minor unit (1 IDR = 100).
+type PayoutConfig struct {
+	DefaultFeePercentBps int // 250 = 2.5%
+	DefaultFeeFixed      int64
+	MinPayoutAmount      int64
+	BankFormatFile       string // file JSON format transfer bank; kosong = CSV default
+}
+
+// GroupBookingConfig membatasi jumlah tiket per group booking dan lama waktu bayar invoice-nya
+type GroupBookingConfig struct {
+	MinTickets  int
+	MaxTickets  int
+	PaymentTerm time.Duration
+}
+
+// RateLimit membatasi Max percobaan per Window; Max 0 berarti tidak dibatasi
+type RateLimit struct {
+	Max    int
+	Window time.Duration
+}
+
+// PurchaseGuardConfig berisi batas percobaan registrasi/signup dan challenge anti-bot.
+// Batas tiket per event diatur organizer lewat EventPurchasePolicy.
+type PurchaseGuardConfig struct {
+	RegisterPerUser     RateLimit
+	RegisterPerIP       RateLimit
+	SignupPerIP         RateLimit
+	SignupChallenge     bool   // signup wajib menyelesaikan challenge
+	ChallengeProvider   string // "pow" (default) atau "none"
+	ChallengeSecret     string
+	ChallengeDifficulty int // jumlah bit nol di awal hash untuk proof-of-work
+	ChallengeTTL        time.Duration
+	QueueTokenSecret    string // kunci tanda tangan token antrean
+	QueueAdmitInterval  time.Duration
+	CleanupInterval     time.Duration
+}
+
 type Config struct {
 	DBConfig
 	APIConfig
 	TokenConfig
+	JobConfig
+	StorageConfig
+	SMTP              SMTPConfig
+	Payout            PayoutConfig
+	GroupBooking      GroupBookingConfig
+	PurchaseGuard     PurchaseGuardConfig
 	LocationIQAPIKey  string
 	MidtransServerKey string
+	ExchangeRatesFile string // file JSON kurs untuk tampilan harga; kosong = konversi mati
 }
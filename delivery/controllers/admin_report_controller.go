@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"fmt"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminReportController struct {
+	reconciliationUC usecase.ReconciliationUsecase
+	rg               *gin.RouterGroup
+}
+
+func NewAdminReportController(reconciliationUC usecase.ReconciliationUsecase, rg *gin.RouterGroup) *AdminReportController {
+	return &AdminReportController{reconciliationUC: reconciliationUC, rg: rg}
+}
+
+func (ac *AdminReportController) Route() {
+	ac.rg.GET("/admin/reports/transactions", ac.transactionReport)
+	ac.rg.POST("/admin/reconciliations", ac.startReconciliation)
+	ac.rg.GET("/admin/reconciliations", ac.listReconciliations)
+	ac.rg.GET("/admin/reconciliations/:id", ac.getReconciliation)
+	ac.rg.GET("/admin/reconciliations/:id/report", ac.downloadReconciliation)
+}
+
+// @Summary Platform transaction report
+// @Description Summarizes all transactions per status with settled and pending totals (admin only)
+// @Tags admin
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param from query string false "First day, YYYY-MM-DD (default: 29 days before to)"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today, UTC)"
+// @Success 200 {object} utils.Response{data=dto.TransactionReport}
+// @Failure 400 {object} utils.Response "Invalid date range"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/reports/transactions [get]
+// @Security BearerAuth
+func (ac *AdminReportController) transactionReport(ctx *gin.Context) {
+	report, err := ac.reconciliationUC.TransactionReport(currentUserRole(ctx), ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch transaction report", report, true))
+}
+
+// @Summary Start a payment reconciliation run
+// @Description Checks every pending transaction, plus recent settled ones, against the Midtrans status API in the background. Status differences are repaired; amount mismatches and stuck orders are only reported (admin only)
+// @Tags admin
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 202 {object} utils.Response{data=models.ReconciliationRun}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Failure 409 {object} utils.Response "A run is already in progress"
+// @Router /api/v1/admin/reconciliations [post]
+// @Security BearerAuth
+func (ac *AdminReportController) startReconciliation(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	run, err := ac.reconciliationUC.Start(userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, utils.APIResponse("Reconciliation started", run, true))
+}
+
+// @Summary List reconciliation runs
+// @Description Returns the 50 most recent runs, scheduled and manual (admin only)
+// @Tags admin
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=[]models.ReconciliationRun}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/reconciliations [get]
+// @Security BearerAuth
+func (ac *AdminReportController) listReconciliations(ctx *gin.Context) {
+	runs, err := ac.reconciliationUC.ListRuns(currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch reconciliation runs", runs, true))
+}
+
+// @Summary Get a reconciliation run
+// @Description Returns the run with every discrepancy found (admin only)
+// @Tags admin
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Run ID"
+// @Success 200 {object} utils.Response{data=dto.ReconciliationReport}
+// @Failure 400 {object} utils.Response "Invalid run ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Failure 404 {object} utils.Response "Run not found"
+// @Router /api/v1/admin/reconciliations/{id} [get]
+// @Security BearerAuth
+func (ac *AdminReportController) getReconciliation(ctx *gin.Context) {
+	runID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid run ID", nil, false))
+		return
+	}
+
+	report, err := ac.reconciliationUC.GetReport(currentUserRole(ctx), runID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch reconciliation run", report, true))
+}
+
+// @Summary Download a discrepancy report
+// @Description Downloads the discrepancies of a run as CSV or XLSX (admin only)
+// @Tags admin
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Run ID"
+// @Param format query string false "csv (default) or xlsx"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response "Invalid run ID or format"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Failure 404 {object} utils.Response "Run not found"
+// @Router /api/v1/admin/reconciliations/{id}/report [get]
+// @Security BearerAuth
+func (ac *AdminReportController) downloadReconciliation(ctx *gin.Context) {
+	runID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid run ID", nil, false))
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("format must be csv or xlsx", nil, false))
+		return
+	}
+
+	// Cek akses dan keberadaan run sebelum header file dikirim
+	if _, err := ac.reconciliationUC.GetReport(currentUserRole(ctx), runID); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	filename := fmt.Sprintf("reconciliation-%d.%s", runID, format)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	var writer utils.TableWriter
+	if format == "xlsx" {
+		ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		writer, err = utils.NewXLSXTableWriter(ctx.Writer, filename)
+	} else {
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		writer = utils.NewCSVTableWriter(ctx.Writer)
+	}
+	if err == nil {
+		err = ac.reconciliationUC.WriteReport(currentUserRole(ctx), runID, writer)
+	}
+	if err != nil {
+		ctx.Error(err)
+	}
+}
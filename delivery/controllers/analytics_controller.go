@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsController struct {
+	analyticsUC usecase.AnalyticsUsecase
+	rg          *gin.RouterGroup
+}
+
+func NewAnalyticsController(analyticsUC usecase.AnalyticsUsecase, rg *gin.RouterGroup) *AnalyticsController {
+	return &AnalyticsController{analyticsUC: analyticsUC, rg: rg}
+}
+
+func (ac *AnalyticsController) Route() {
+	ac.rg.GET("/analytics/events", ac.compareEvents)
+	ac.rg.GET("/analytics/events/:id", ac.eventAnalytics)
+}
+
+func analyticsQuery(ctx *gin.Context) dto.AnalyticsQuery {
+	return dto.AnalyticsQuery{
+		Bucket: ctx.Query("bucket"),
+		From:   ctx.Query("from"),
+		To:     ctx.Query("to"),
+		TZ:     ctx.Query("tz"),
+	}
+}
+
+// @Summary Get event analytics
+// @Description Returns the registration-to-payment funnel, cancellation and check-in rates, revenue by ticket type (settled transactions), attendee age groups and a day/week time series of registrations, payments, revenue, cancellations and check-ins (organizer only)
+// @Tags analytics
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param bucket query string false "Time series bucket: day (default) or week"
+// @Param from query string false "First day, YYYY-MM-DD (default: day the event was created)"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today)"
+// @Param tz query string false "IANA time zone for bucketing, e.g. Asia/Jakarta (default: UTC)"
+// @Success 200 {object} utils.Response{data=dto.EventAnalytics}
+// @Failure 400 {object} utils.Response "Invalid event ID or query"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/analytics/events/{id} [get]
+// @Security BearerAuth
+func (ac *AnalyticsController) eventAnalytics(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	analytics, err := ac.analyticsUC.EventAnalytics(ctx, eventID, userID, currentUserRole(ctx), analyticsQuery(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch event analytics", analytics, true))
+}
+
+// @Summary Compare events
+// @Description Returns the funnel, revenue and time series of several events side by side. Without ids the organizer's 20 most recent events are compared
+// @Tags analytics
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param ids query string false "Comma separated event IDs (max 20, required for admins)"
+// @Param bucket query string false "Time series bucket: day (default) or week"
+// @Param from query string false "First day, YYYY-MM-DD (default: day the oldest event was created)"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today)"
+// @Param tz query string false "IANA time zone for bucketing, e.g. Asia/Jakarta (default: UTC)"
+// @Success 200 {object} utils.Response{data=dto.EventComparison}
+// @Failure 400 {object} utils.Response "Invalid event IDs or query"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the organizer of every event"
+// @Router /api/v1/analytics/events [get]
+// @Security BearerAuth
+func (ac *AnalyticsController) compareEvents(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var eventIDs []int
+	if raw := ctx.Query("ids"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			eventID, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID: "+part, nil, false))
+				return
+			}
+			eventIDs = append(eventIDs, eventID)
+		}
+	}
+
+	comparison, err := ac.analyticsUC.CompareEvents(ctx, eventIDs, userID, currentUserRole(ctx), analyticsQuery(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success compare events", comparison, true))
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BroadcastController struct {
+	broadcastUC usecase.BroadcastUsecase
+	rg          *gin.RouterGroup
+}
+
+func NewBroadcastController(broadcastUC usecase.BroadcastUsecase, rg *gin.RouterGroup) *BroadcastController {
+	return &BroadcastController{broadcastUC: broadcastUC, rg: rg}
+}
+
+func (bc *BroadcastController) Route() {
+	bc.rg.POST("/event/:id/broadcasts", bc.createBroadcast)
+	bc.rg.GET("/event/:id/broadcasts", bc.listBroadcasts)
+	bc.rg.GET("/event/:id/broadcasts/:broadcastId/deliveries", bc.listDeliveries)
+}
+
+// @Summary Send a message to attendees
+// @Description Queues a message for the attendees matching the segment on the chosen channels (organizer only)
+// @Tags broadcasts
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param broadcast body dto.CreateBroadcastRequest true "Message, channels and segment"
+// @Success 202 {object} utils.Response{data=dto.BroadcastResponse}
+// @Failure 400 {object} utils.Response "Invalid request or no matching attendees"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/broadcasts [post]
+// @Security BearerAuth
+func (bc *BroadcastController) createBroadcast(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.CreateBroadcastRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	broadcast, err := bc.broadcastUC.CreateBroadcast(eventID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, utils.APIResponse("Broadcast queued", broadcast, true))
+}
+
+// @Summary List event broadcasts
+// @Description Retrieves sent messages with delivery counts (organizer only)
+// @Tags broadcasts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=[]dto.BroadcastResponse}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/broadcasts [get]
+// @Security BearerAuth
+func (bc *BroadcastController) listBroadcasts(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	broadcasts, err := bc.broadcastUC.ListBroadcasts(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch broadcasts", broadcasts, true))
+}
+
+// @Summary List broadcast deliveries
+// @Description Retrieves the delivery status of every recipient and channel (organizer only)
+// @Tags broadcasts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param broadcastId path int true "Broadcast ID"
+// @Success 200 {object} utils.Response{data=[]models.BroadcastDelivery}
+// @Failure 400 {object} utils.Response "Invalid ID or broadcast not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/broadcasts/{broadcastId}/deliveries [get]
+// @Security BearerAuth
+func (bc *BroadcastController) listDeliveries(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err1 := strconv.Atoi(ctx.Param("id"))
+	broadcastID, err2 := strconv.Atoi(ctx.Param("broadcastId"))
+	if err1 != nil || err2 != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid ID", nil, false))
+		return
+	}
+
+	deliveries, err := bc.broadcastUC.ListDeliveries(eventID, broadcastID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch deliveries", deliveries, true))
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"fmt"
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const icsContentType = "text/calendar; charset=utf-8"
+
+type CalendarController struct {
+	calendarUC usecase.CalendarUsecase
+	rg         *gin.RouterGroup
+}
+
+func NewCalendarController(calendarUC usecase.CalendarUsecase, rg *gin.RouterGroup) *CalendarController {
+	return &CalendarController{calendarUC: calendarUC, rg: rg}
+}
+
+func (cc *CalendarController) Route() {
+	cc.rg.GET("/event/:id/ics", cc.exportEvent)
+	cc.rg.GET("/calendar/feed-token", cc.getFeedToken)
+	cc.rg.POST("/calendar/feed-token/rotate", cc.rotateFeedToken)
+}
+
+// RegisterPublicRoutes mendaftarkan URL langganan yang dibuka oleh aplikasi kalender tanpa JWT.
+func (cc *CalendarController) RegisterPublicRoutes() {
+	cc.rg.GET("/calendar/feed/:token", cc.getFeed)
+}
+
+// @Summary Download event as iCalendar
+// @Description Downloads a single event as an .ics file
+// @Tags calendar
+// @Produce text/calendar
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param key query string false "Secret link key or invite code for non-public events"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Event not found"
+// @Router /api/v1/event/{id}/ics [get]
+// @Security BearerAuth
+func (cc *CalendarController) exportEvent(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	content, err := cc.calendarUC.ExportEvent(eventID, userID, ctx.Query("key"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%d.ics"`, eventID))
+	ctx.Data(http.StatusOK, icsContentType, content)
+}
+
+// @Summary Get calendar subscription URL
+// @Description Returns the secret iCalendar feed URL of the authenticated user, creating it on first use
+// @Tags calendar
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=dto.CalendarFeedResponse}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/calendar/feed-token [get]
+// @Security BearerAuth
+func (cc *CalendarController) getFeedToken(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	token, err := cc.calendarUC.GetFeedToken(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success get calendar feed", cc.feedResponse(ctx, token), true))
+}
+
+// @Summary Rotate calendar subscription URL
+// @Description Replaces the secret feed token, invalidating the previous subscription URL
+// @Tags calendar
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=dto.CalendarFeedResponse}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/calendar/feed-token/rotate [post]
+// @Security BearerAuth
+func (cc *CalendarController) rotateFeedToken(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	token, err := cc.calendarUC.RotateFeedToken(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success rotate calendar feed", cc.feedResponse(ctx, token), true))
+}
+
+// @Summary Calendar subscription feed
+// @Description Live iCalendar feed of the events the token owner registered for
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Secret feed token (optionally suffixed with .ics)"
+// @Success 200 {file} file
+// @Failure 404 {object} utils.Response "Feed not found"
+// @Router /api/v1/calendar/feed/{token} [get]
+func (cc *CalendarController) getFeed(ctx *gin.Context) {
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+
+	content, err := cc.calendarUC.GetFeed(ctx, token)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Data(http.StatusOK, icsContentType, content)
+}
+
+func (cc *CalendarController) feedResponse(ctx *gin.Context, token string) dto.CalendarFeedResponse {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	path := fmt.Sprintf("%s/api/v1/calendar/feed/%s.ics", ctx.Request.Host, token)
+
+	return dto.CalendarFeedResponse{
+		Token:           token,
+		SubscriptionURL: fmt.Sprintf("%s://%s", scheme, path),
+		WebcalURL:       "webcal://" + path,
+	}
+}
@@ -0,0 +1,456 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EligibilityController struct {
+	eligibilityUC usecase.EligibilityUsecase
+	rg            *gin.RouterGroup
+}
+
+func NewEligibilityController(eligibilityUC usecase.EligibilityUsecase, rg *gin.RouterGroup) *EligibilityController {
+	return &EligibilityController{eligibilityUC: eligibilityUC, rg: rg}
+}
+
+func (ec *EligibilityController) Route() {
+	ec.rg.GET("/event/:id/eligibility", ec.listRules)
+	ec.rg.PUT("/event/:id/eligibility", ec.saveRule)
+	ec.rg.DELETE("/event/:id/eligibility", ec.deleteRule)
+	ec.rg.GET("/event/:id/eligible-tickets", ec.eligibleTickets)
+
+	ec.rg.POST("/user-groups", ec.createGroup)
+	ec.rg.GET("/user-groups", ec.listGroups)
+	ec.rg.PUT("/user-groups/:id", ec.renameGroup)
+	ec.rg.DELETE("/user-groups/:id", ec.deleteGroup)
+	ec.rg.GET("/user-groups/:id/members", ec.listMembers)
+	ec.rg.POST("/user-groups/:id/members", ec.addMembers)
+	ec.rg.DELETE("/user-groups/:id/members/:userId", ec.removeMember)
+
+	ec.rg.PUT("/admin/users/:id/verification", ec.setVerified)
+}
+
+// @Summary List eligibility rules
+// @Description Lists the event-wide rule and the per ticket type rules of an event (organizer only)
+// @Tags eligibility
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=[]models.EligibilityRule}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/eligibility [get]
+// @Security BearerAuth
+func (ec *EligibilityController) listRules(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	rules, err := ec.eligibilityUC.ListRules(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch eligibility rules", rules, true))
+}
+
+// @Summary Create or replace an eligibility rule
+// @Description Sets who may register: minimum/maximum age, allowed email domains, membership of one of your user groups and/or a verified account. Applies to the whole event, or to one ticket type when ticket_type_id is set; both rules must be met (organizer only)
+// @Tags eligibility
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param rule body dto.SaveEligibilityRuleRequest true "Eligibility rule"
+// @Success 200 {object} utils.Response{data=models.EligibilityRule}
+// @Failure 400 {object} utils.Response "Invalid rule"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Failure 404 {object} utils.Response "User group not found"
+// @Router /api/v1/event/{id}/eligibility [put]
+// @Security BearerAuth
+func (ec *EligibilityController) saveRule(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.SaveEligibilityRuleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	rule, err := ec.eligibilityUC.SaveRule(eventID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Eligibility rule saved", rule, true))
+}
+
+// @Summary Delete an eligibility rule
+// @Tags eligibility
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param ticketTypeId query int false "Ticket type ID; omit for the event-wide rule"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid ID or rule not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/eligibility [delete]
+// @Security BearerAuth
+func (ec *EligibilityController) deleteRule(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+	ticketTypeID, err := optionalIntQuery(ctx, "ticketTypeId")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	if err := ec.eligibilityUC.DeleteRule(eventID, userID, currentUserRole(ctx), ticketTypeID); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Eligibility rule deleted", nil, true))
+}
+
+// @Summary List ticket types with eligibility
+// @Description Lists the event's ticket types and whether the current user meets their eligibility rules, with the reasons when not
+// @Tags eligibility
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=[]dto.EligibleTicket}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/event/{id}/eligible-tickets [get]
+// @Security BearerAuth
+func (ec *EligibilityController) eligibleTickets(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	tickets, err := ec.eligibilityUC.EligibleTickets(eventID, userID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch ticket eligibility", tickets, true))
+}
+
+// @Summary Create a user group
+// @Description Creates a group of users, e.g. community members or company staff, that can be required by eligibility rules
+// @Tags eligibility
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param group body dto.SaveUserGroupRequest true "Group"
+// @Success 201 {object} utils.Response{data=models.UserGroup}
+// @Failure 400 {object} utils.Response "Invalid request body"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/user-groups [post]
+// @Security BearerAuth
+func (ec *EligibilityController) createGroup(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var payload dto.SaveUserGroupRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	group, err := ec.eligibilityUC.CreateGroup(userID, payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.APIResponse("User group created", group, true))
+}
+
+// @Summary List my user groups
+// @Tags eligibility
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=[]models.UserGroup}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/user-groups [get]
+// @Security BearerAuth
+func (ec *EligibilityController) listGroups(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	groups, err := ec.eligibilityUC.ListGroups(userID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch user groups", groups, true))
+}
+
+// @Summary Rename a user group
+// @Tags eligibility
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Group ID"
+// @Param group body dto.SaveUserGroupRequest true "Group"
+// @Success 200 {object} utils.Response{data=models.UserGroup}
+// @Failure 400 {object} utils.Response "Invalid request body"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "User group not found"
+// @Router /api/v1/user-groups/{id} [put]
+// @Security BearerAuth
+func (ec *EligibilityController) renameGroup(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid group ID", nil, false))
+		return
+	}
+
+	var payload dto.SaveUserGroupRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	group, err := ec.eligibilityUC.RenameGroup(groupID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("User group updated", group, true))
+}
+
+// @Summary Delete a user group
+// @Description Deletes a group and its memberships. Groups still required by an eligibility rule cannot be deleted
+// @Tags eligibility
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Group ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid group ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "User group not found"
+// @Failure 409 {object} utils.Response "Group is used by an eligibility rule"
+// @Router /api/v1/user-groups/{id} [delete]
+// @Security BearerAuth
+func (ec *EligibilityController) deleteGroup(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid group ID", nil, false))
+		return
+	}
+
+	if err := ec.eligibilityUC.DeleteGroup(groupID, userID, currentUserRole(ctx)); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("User group deleted", nil, true))
+}
+
+// @Summary List user group members
+// @Tags eligibility
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Group ID"
+// @Success 200 {object} utils.Response{data=[]dto.UserResponse}
+// @Failure 400 {object} utils.Response "Invalid group ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "User group not found"
+// @Router /api/v1/user-groups/{id}/members [get]
+// @Security BearerAuth
+func (ec *EligibilityController) listMembers(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid group ID", nil, false))
+		return
+	}
+
+	members, err := ec.eligibilityUC.ListMembers(groupID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch group members", members, true))
+}
+
+// @Summary Add user group members
+// @Description Adds registered users by ID or account email. Existing members are kept; the request fails if any user is unknown
+// @Tags eligibility
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Group ID"
+// @Param members body dto.UserGroupMembersRequest true "Members"
+// @Success 200 {object} utils.Response{data=[]dto.UserResponse}
+// @Failure 400 {object} utils.Response "Invalid request body or unknown user"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "User group not found"
+// @Router /api/v1/user-groups/{id}/members [post]
+// @Security BearerAuth
+func (ec *EligibilityController) addMembers(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid group ID", nil, false))
+		return
+	}
+
+	var payload dto.UserGroupMembersRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	members, err := ec.eligibilityUC.AddMembers(groupID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Group members added", members, true))
+}
+
+// @Summary Remove a user group member
+// @Tags eligibility
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Group ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid ID or user is not a member"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "User group not found"
+// @Router /api/v1/user-groups/{id}/members/{userId} [delete]
+// @Security BearerAuth
+func (ec *EligibilityController) removeMember(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid group ID", nil, false))
+		return
+	}
+	memberID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid user ID", nil, false))
+		return
+	}
+
+	if err := ec.eligibilityUC.RemoveMember(groupID, userID, currentUserRole(ctx), memberID); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Group member removed", nil, true))
+}
+
+// @Summary Set account verification
+// @Description Marks a user's identity as verified or unverified, for events that require a verified account (admin only)
+// @Tags eligibility
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Param verification body dto.SetUserVerifiedRequest true "Verification flag"
+// @Success 200 {object} utils.Response{data=dto.UserResponse}
+// @Failure 400 {object} utils.Response "Invalid ID or user not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/users/{id}/verification [put]
+// @Security BearerAuth
+func (ec *EligibilityController) setVerified(ctx *gin.Context) {
+	targetID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid user ID", nil, false))
+		return
+	}
+
+	var payload dto.SetUserVerifiedRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	user, err := ec.eligibilityUC.SetVerified(targetID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Account verification updated", user, true))
+}
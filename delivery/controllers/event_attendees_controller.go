@@ -1,11 +1,14 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"gatherly-app/models/dto"
 	"gatherly-app/usecase"
 	"gatherly-app/utils"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,8 +31,9 @@ func (ec *EventAttendeeController) Route() {
 	ec.rg.GET("/attendee", ec.GetRegistration)
 	ec.rg.GET("/attendee/event/:eventId", ec.ListAttendeesByEvent)
 	ec.rg.GET("/attendee/user/:userId", ec.ListRegistrationsByUser)
-	ec.rg.PATCH("/attendee/confirm-payment", ec.ConfirmPayment)
 	ec.rg.PATCH("/attendee/rsvp", ec.UpdateRSVP)
+	ec.rg.PATCH("/attendee/check-in", ec.CheckIn)
+	ec.rg.GET("/attendee/event/:eventId/export", ec.ExportAttendees)
 }
 
 // @Summary Register for an event
@@ -42,6 +46,9 @@ func (ec *EventAttendeeController) Route() {
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} string "Invalid request body"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not eligible, challenge missing, not admitted from the queue or purchase limit reached"
+// @Failure 409 {object} utils.Response "Selected seat was taken"
+// @Failure 429 {object} utils.Response "Too many attempts"
 // @Failure 500 {object} string "Internal server error"
 // @Router /api/v1/attendee [post]
 // @Security BearerAuth
@@ -66,7 +73,13 @@ func (ec *EventAttendeeController) Register(ctx *gin.Context) {
 	}
 
 	// Use userID from token instead of payload.UserID
-	attendee, err := ec.eventAttendeeUseCase.Register(ctx, userID, payload.EventID, payload.TicketTypeID, payload.RSVPStatus) // Added payload.TicketTypeID
+	attendee, err := ec.eventAttendeeUseCase.Register(ctx, userID, payload.EventID, payload.TicketTypeID, payload.SeatID, payload.RSVPStatus, payload.AccessCode, payload.Answers, dto.ClientInfo{IP: ctx.ClientIP(), Challenge: payload.Challenge}) // Added payload.TicketTypeID
+	if errors.Is(err, usecase.ErrSeatUnavailable) || errors.Is(err, usecase.ErrRateLimited) ||
+		errors.Is(err, usecase.ErrChallengeRequired) || errors.Is(err, usecase.ErrNotAdmitted) ||
+		errors.Is(err, usecase.ErrPurchaseLimit) {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
 		return
@@ -194,60 +207,129 @@ func (ec *EventAttendeeController) ListRegistrationsByUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch user registrations", registrations, true))
 }
 
-// @Summary Confirm payment for an event
-// @Description Updates payment status for an event registration
+// @Summary Update RSVP status
+// @Description Updates an attendee's RSVP status for an event
 // @Tags event_attendees
 // @Accept json
 // @Produce json
 // @Param authorization header string true "Bearer token"
-// @Param payment body dto.AttendeePaymentRequest true "Payment confirmation data"
+// @Param rsvp body dto.AttendeeRSVPRequest true "RSVP Update Data"
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} string "Invalid request body"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
 // @Failure 500 {object} string "Internal server error"
-// @Router /api/v1/attendee/confirm-payment [patch]
+// @Router /api/v1/attendee/rsvp [patch]
 // @Security BearerAuth
-func (ec *EventAttendeeController) ConfirmPayment(ctx *gin.Context) {
-	var payload dto.AttendeePaymentRequest
+func (ec *EventAttendeeController) UpdateRSVP(ctx *gin.Context) {
+	var payload dto.AttendeeRSVPRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
 		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
 		return
 	}
 
-	attendee, err := ec.eventAttendeeUseCase.ConfirmPayment(ctx, payload.UserID, payload.EventID)
+	attendee, err := ec.eventAttendeeUseCase.UpdateRSVPStatus(ctx, payload.UserID, payload.EventID, payload.NewStatus)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, utils.APIResponse("Payment confirmed", attendee, true))
+	ctx.JSON(http.StatusOK, utils.APIResponse("RSVP status updated", attendee, true))
 }
 
-// @Summary Update RSVP status
-// @Description Updates an attendee's RSVP status for an event
+// @Summary Check in an attendee
+// @Description Marks an attendee as checked in by ticket code or user ID (organizer only)
 // @Tags event_attendees
 // @Accept json
 // @Produce json
 // @Param authorization header string true "Bearer token"
-// @Param rsvp body dto.AttendeeRSVPRequest true "RSVP Update Data"
+// @Param checkin body dto.AttendeeCheckInRequest true "Check-in Data"
 // @Success 200 {object} utils.Response
-// @Failure 400 {object} string "Invalid request body"
+// @Failure 400 {object} utils.Response "Invalid request or attendee cannot be checked in"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
-// @Failure 500 {object} string "Internal server error"
-// @Router /api/v1/attendee/rsvp [patch]
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/attendee/check-in [patch]
 // @Security BearerAuth
-func (ec *EventAttendeeController) UpdateRSVP(ctx *gin.Context) {
-	var payload dto.AttendeeRSVPRequest
+func (ec *EventAttendeeController) CheckIn(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var payload dto.AttendeeCheckInRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
 		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
 		return
 	}
 
-	attendee, err := ec.eventAttendeeUseCase.UpdateRSVPStatus(ctx, payload.UserID, payload.EventID, payload.NewStatus)
+	attendee, err := ec.eventAttendeeUseCase.CheckIn(ctx, userID, currentUserRole(ctx), payload)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, utils.APIResponse("RSVP status updated", attendee, true))
+	ctx.JSON(http.StatusOK, utils.APIResponse("Attendee checked in", attendee, true))
+}
+
+// @Summary Export attendee manifest
+// @Description Streams attendees joined with user, ticket, payment, check-in and form answers as CSV or XLSX (organizer only)
+// @Tags event_attendees
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param authorization header string true "Bearer token"
+// @Param eventId path int true "Event ID"
+// @Param format query string false "csv (default) or xlsx"
+// @Param columns query string false "Comma-separated columns: user_id,name,email,ticket_type,ticket_price,rsvp_status,rsvp_date,payment_status,ticket_code,seat,checked_in_at,answers,answer.<field>"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response "Invalid event ID, format or column"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/attendee/event/{eventId}/export [get]
+// @Security BearerAuth
+func (ec *EventAttendeeController) ExportAttendees(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("eventId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("format must be csv or xlsx", nil, false))
+		return
+	}
+
+	var columns []string
+	if raw := ctx.Query("columns"); raw != "" {
+		columns = strings.Split(raw, ",")
+	}
+
+	plan, err := ec.eventAttendeeUseCase.PrepareAttendeeExport(ctx, eventID, userID, currentUserRole(ctx), columns)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	filename := fmt.Sprintf("event-%d-attendees.%s", eventID, format)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	var writer utils.TableWriter
+	if format == "xlsx" {
+		ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		writer, err = utils.NewXLSXTableWriter(ctx.Writer, plan.EventName)
+	} else {
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		writer = utils.NewCSVTableWriter(ctx.Writer)
+	}
+	if err == nil {
+		// Response sudah mulai dikirim; error di tengah stream hanya bisa dicatat
+		err = ec.eventAttendeeUseCase.WriteAttendeeExport(ctx, plan, writer)
+	}
+	if err != nil {
+		ctx.Error(err)
+	}
 }
@@ -2,8 +2,11 @@ package controllers
 
 import (
 	"errors"
+	"gatherly-app/models"
 	"gatherly-app/models/dto"
+	"gatherly-app/service"
 	"gatherly-app/usecase"
+	"log"
 
 	"net/http"
 	"strconv"
@@ -13,6 +16,7 @@ import (
 
 type EventsController struct {
 	usecase usecase.EventsUsecase
+	rates   *service.ExchangeRates // nil jika konversi mata uang tidak dikonfigurasi
 	rg      *gin.RouterGroup
 }
 
@@ -20,9 +24,10 @@ type EventsController struct {
 // 	return &EventsController{usecase: usecase, rg: rg}
 // }
 
-func NewEventsController(uc usecase.EventsUsecase, rg *gin.RouterGroup) *EventsController {
+func NewEventsController(uc usecase.EventsUsecase, rates *service.ExchangeRates, rg *gin.RouterGroup) *EventsController {
 	return &EventsController{
 		usecase: uc, // Gunakan nama parameter yang berbeda
+		rates:   rates,
 		rg:      rg,
 	}
 }
@@ -34,6 +39,7 @@ func (e *EventsController) Route() {
 	e.rg.PUT("/event/:id", e.updateEvent)
 	e.rg.DELETE("/event/:id", e.deleteEvent)
 	e.rg.GET("/event/distance", e.getEventByDistance)
+	e.rg.GET("/events/recommendations", e.getRecommendations)
 }
 
 // @Summary Create an event
@@ -58,9 +64,14 @@ func (e *EventsController) createEvent(ctx *gin.Context) {
 		return
 	}
 
-	createEvent, err := e.usecase.CreateEvent(request)
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	createEvent, err := e.usecase.CreateEvent(userID, request)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		ctx.AbortWithStatusJSON(eventErrorStatus(err), dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -75,17 +86,28 @@ func (e *EventsController) createEvent(ctx *gin.Context) {
 // @Tags events
 // @Produce json
 // @Param authorization header string true "Bearer token"
+// @Param locale query string false "Price formatting: id or en (default: Accept-Language, then id)"
+// @Param display_currency query string false "Also show prices converted to this currency, e.g. USD"
 // @Success 200 {object} dto.GeneralResponse
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Router /api/v1/event [get]
 // @Security BearerAuth
 func (e *EventsController) getAllEvent(ctx *gin.Context) {
+	display, err := newMoneyDisplay(ctx, e.rates)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	events, err := e.usecase.GetAllEvent()
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
+	for i := range events {
+		display.ticket(events[i].Ticket)
+	}
 
 	ctx.JSON(http.StatusOK, dto.GeneralResponse{
 		Message: "successfully get all events",
@@ -99,6 +121,9 @@ func (e *EventsController) getAllEvent(ctx *gin.Context) {
 // @Produce json
 // @Param authorization header string true "Bearer token"
 // @Param id path int true "Event ID"
+// @Param key query string false "Secret link key or invite code for unlisted and invite-only events"
+// @Param locale query string false "Price formatting: id or en (default: Accept-Language, then id)"
+// @Param display_currency query string false "Also show prices converted to this currency, e.g. USD"
 // @Success 200 {object} dto.GeneralResponse
 // @Failure 400 {object} dto.ErrorResponse "Invalid event ID"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
@@ -112,11 +137,26 @@ func (e *EventsController) getEventByID(ctx *gin.Context) {
 		return
 	}
 
-	event, err := e.usecase.GetEventByID(id)
+	display, err := newMoneyDisplay(ctx, e.rates)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+	viewerID, _ := userID.(int)
+
+	event, err := e.usecase.GetEventByID(id, viewerID, ctx.Query("key"))
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
+	display.ticket(event.Ticket)
+
+	// Kegagalan mencatat view tidak boleh menggagalkan request
+	if err := e.usecase.RecordView(id, viewerID, ctx.ClientIP()); err != nil {
+		log.Printf("failed to record view for event %d: %v", id, err)
+	}
 
 	ctx.JSON(http.StatusOK, dto.GeneralResponse{
 		Message: "successfully get event by ID",
@@ -135,10 +175,16 @@ func (e *EventsController) getEventByID(ctx *gin.Context) {
 // @Success 200 {object} dto.GeneralResponse
 // @Failure 400 {object} dto.ErrorResponse "Invalid request body"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} dto.ErrorResponse "Not the event organizer"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Router /api/v1/event/{id} [put]
 // @Security BearerAuth
 func (e *EventsController) updateEvent(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
@@ -152,9 +198,9 @@ func (e *EventsController) updateEvent(ctx *gin.Context) {
 		return
 	}
 
-	event, err := e.usecase.UpdateEvent(id, request)
+	event, err := e.usecase.UpdateEvent(id, userID, currentUserRole(ctx), request)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		ctx.AbortWithStatusJSON(eventErrorStatus(err), dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -172,19 +218,29 @@ func (e *EventsController) updateEvent(ctx *gin.Context) {
 // @Success 204 "No Content"
 // @Failure 400 {object} dto.ErrorResponse "Invalid event ID"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} dto.ErrorResponse "Not the event organizer"
 // @Failure 404 {object} dto.ErrorResponse "Event not found"
 // @Router /api/v1/event/{id} [delete]
 // @Security BearerAuth
 func (e *EventsController) deleteEvent(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	err = e.usecase.DeleteEvent(id)
+	err = e.usecase.DeleteEvent(id, userID, currentUserRole(ctx))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		status := http.StatusNotFound
+		if errors.Is(err, usecase.ErrNotEventOrganizer) {
+			status = http.StatusForbidden
+		}
+		ctx.AbortWithStatusJSON(status, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -193,17 +249,19 @@ func (e *EventsController) deleteEvent(ctx *gin.Context) {
 	})
 }
 
-// @Summary Get recommended events
-// @Description Retrieves a list of recommended events for the authenticated user
+// @Summary Get nearby events
+// @Description Retrieves events within a radius of the location stored in the token
 // @Tags events
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Param limit query int false "Number of events to return (default: 10)"
-// @Success 200 {object} dto.GeneralResponse "Successfully retrieved recommended events"
-// @Failure 400 {object} dto.ErrorResponse "Invalid query parameter: limit must be an integer"
+// @Param radius query number false "Radius in kilometers (default: 20)"
+// @Param locale query string false "Price formatting: id or en (default: Accept-Language, then id)"
+// @Param display_currency query string false "Also show prices converted to this currency, e.g. USD"
+// @Success 200 {object} dto.GeneralResponse "Successfully retrieved nearby events"
+// @Failure 400 {object} dto.ErrorResponse "Invalid query parameter: radius must be a number"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Router /api/v1/events/recommendations [get]
+// @Router /api/v1/event/distance [get]
 // @Security BearerAuth
 func (e *EventsController) getEventByDistance(ctx *gin.Context) {
 	latitudeValue, latitudeExists := ctx.Get("userLat")
@@ -228,11 +286,20 @@ func (e *EventsController) getEventByDistance(ctx *gin.Context) {
 		return
 	}
 
+	display, err := newMoneyDisplay(ctx, e.rates)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	events, err := e.usecase.GetEventByDistance(userLatitude, userLongitude, radius)
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
+	for i := range events {
+		display.ticket(events[i].Ticket)
+	}
 
 	ctx.JSON(http.StatusOK, dto.GeneralResponse{
 		Message: "successfully get data by nearby location",
@@ -240,6 +307,79 @@ func (e *EventsController) getEventByDistance(ctx *gin.Context) {
 	})
 }
 
+// @Summary Get recommended events
+// @Description Ranks upcoming events for the authenticated user by favourite categories, distance, popularity and start date, excluding events already joined
+// @Tags events
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param limit query int false "Number of events to return (default: 10)"
+// @Param locale query string false "Price formatting: id or en (default: Accept-Language, then id)"
+// @Param display_currency query string false "Also show prices converted to this currency, e.g. USD"
+// @Success 200 {object} dto.GeneralResponse{data=[]dto.EventRecommendationDTO} "Successfully retrieved recommended events"
+// @Failure 400 {object} dto.ErrorResponse "Invalid query parameter: limit must be an integer"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/events/recommendations [get]
+// @Security BearerAuth
+func (e *EventsController) getRecommendations(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: "limit must be a positive integer"})
+		return
+	}
+
+	display, err := newMoneyDisplay(ctx, e.rates)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Lokasi bersifat opsional; tanpa lokasi skor jarak diabaikan
+	var userLatitude, userLongitude float64
+	latitudeValue, latitudeExists := ctx.Get("userLat")
+	longitudeValue, longitudeExists := ctx.Get("userLon")
+	if latitudeExists && longitudeExists {
+		userLatitude, userLongitude, err = convertCoordinates(latitudeValue, longitudeValue)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	events, err := e.usecase.GetRecommendations(ctx, userID, userLatitude, userLongitude, limit)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	for i := range events {
+		display.ticket(events[i].Ticket)
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get recommended events",
+		Data:    events,
+	})
+}
+
+// eventErrorStatus: mata uang yang tidak dikenal atau tidak bisa ditagihkan adalah kesalahan input
+func eventErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrUnknownCurrency), errors.Is(err, service.ErrCurrencyNotSupported):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrEventCurrencyLocked):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrNotEventOrganizer):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // Helper function untuk konversi koordinat
 func convertCoordinates(lat, lon interface{}) (float64, float64, error) {
 	var userLatitude, userLongitude float64
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize membatasi ukuran file import (10 MB)
+const maxImportSize = 10 << 20
+
+type EventImportController struct {
+	importUC usecase.EventImportUsecase
+	rg       *gin.RouterGroup
+}
+
+func NewEventImportController(importUC usecase.EventImportUsecase, rg *gin.RouterGroup) *EventImportController {
+	return &EventImportController{importUC: importUC, rg: rg}
+}
+
+func (ic *EventImportController) Route() {
+	ic.rg.POST("/event/import", ic.importEvents)
+	ic.rg.GET("/event/import/:id", ic.getImportJob)
+}
+
+// @Summary Bulk import events and tickets
+// @Description Imports events with nested ticket types from CSV (one row per ticket type, grouped by event_ref) or JSON (array of events). Every row is validated first, then geocoded; nothing is saved unless all rows are valid. Files that need many addresses geocoded are queued and return 202 with a job_id to poll.
+// @Tags events
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param file formData file false "CSV or JSON file (alternatively send the file as the raw request body)"
+// @Param format query string false "csv or json; detected from the file name or Content-Type when omitted"
+// @Param dryRun query bool false "Validate and geocode without saving"
+// @Success 200 {object} utils.Response{data=dto.ImportResult} "Dry run passed"
+// @Success 201 {object} utils.Response{data=dto.ImportResult} "Events imported"
+// @Success 202 {object} utils.Response{data=dto.ImportResult} "Import queued"
+// @Failure 400 {object} utils.Response "Unreadable or unsupported file"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 422 {object} utils.Response{data=dto.ImportResult} "Validation errors per line"
+// @Router /api/v1/event/import [post]
+// @Security BearerAuth
+func (ic *EventImportController) importEvents(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(ctx.Query("dryRun"))
+	format := strings.ToLower(ctx.Query("format"))
+
+	var data []byte
+	var err error
+	if file, fileErr := ctx.FormFile("file"); fileErr == nil {
+		if file.Size > maxImportSize {
+			ctx.JSON(http.StatusBadRequest, utils.APIResponse("import file is too large", nil, false))
+			return
+		}
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+		}
+		src, openErr := file.Open()
+		if openErr != nil {
+			ctx.JSON(http.StatusBadRequest, utils.APIResponse(openErr.Error(), nil, false))
+			return
+		}
+		defer src.Close()
+		data, err = io.ReadAll(src)
+	} else {
+		if format == "" {
+			format = importFormatFromContentType(ctx.GetHeader("Content-Type"))
+		}
+		data, err = io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize))
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("failed to read import file: "+err.Error(), nil, false))
+		return
+	}
+
+	result, err := ic.importUC.Import(userID, format, data, dryRun)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	switch {
+	case len(result.Errors) > 0:
+		ctx.JSON(http.StatusUnprocessableEntity, utils.APIResponse("Import has validation errors, nothing was saved", result, false))
+	case result.Queued:
+		ctx.JSON(http.StatusAccepted, utils.APIResponse("Import queued, check the job for the result", result, true))
+	case dryRun:
+		ctx.JSON(http.StatusOK, utils.APIResponse("Dry run passed, nothing was saved", result, true))
+	default:
+		ctx.JSON(http.StatusCreated, utils.APIResponse("Events imported", result, true))
+	}
+}
+
+// @Summary Get a queued event import
+// @Description Returns the status of an import that was queued for background processing, and its result once done
+// @Tags events
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Import job ID"
+// @Success 200 {object} utils.Response{data=dto.ImportJobResponse}
+// @Failure 400 {object} utils.Response "Invalid job ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Import job not found"
+// @Router /api/v1/event/import/{id} [get]
+// @Security BearerAuth
+func (ic *EventImportController) getImportJob(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+	jobID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("invalid job ID", nil, false))
+		return
+	}
+
+	job, err := ic.importUC.GetJob(userID, jobID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+	ctx.JSON(http.StatusOK, utils.APIResponse("Import job", job, true))
+}
+
+func importFormatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return usecase.ImportFormatCSV
+	case "application/json":
+		return usecase.ImportFormatJSON
+	}
+	return ""
+}
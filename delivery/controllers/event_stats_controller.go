@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"gatherly-app/service"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	liveStatsHeartbeatInterval = 25 * time.Second
+	liveStatsWriteTimeout      = 10 * time.Second
+)
+
+type EventStatsController struct {
+	statsUC usecase.EventStatsUsecase
+	rg      *gin.RouterGroup
+}
+
+func NewEventStatsController(statsUC usecase.EventStatsUsecase, rg *gin.RouterGroup) *EventStatsController {
+	return &EventStatsController{statsUC: statsUC, rg: rg}
+}
+
+func (sc *EventStatsController) Route() {
+	sc.rg.GET("/event/:id/stats", sc.getStats)
+}
+
+// RegisterStreamRoutes mendaftarkan endpoint WebSocket; rg harus memakai middleware RequireStreamToken
+func (sc *EventStatsController) RegisterStreamRoutes() {
+	sc.rg.GET("/event/:id/live", sc.live)
+}
+
+// @Summary Get live attendance and sales counters
+// @Description Returns current registration, payment, check-in and revenue totals per ticket type (organizer only)
+// @Tags event-stats
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=dto.EventCounters}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/stats [get]
+// @Security BearerAuth
+func (sc *EventStatsController) getStats(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	counters, err := sc.statsUC.Snapshot(ctx, eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch event stats", counters, true))
+}
+
+// @Summary Stream live attendance and sales counters
+// @Description WebSocket stream for organizer dashboards. The first message is a "snapshot" with the current totals; afterwards every attendee.registered, attendee.cancelled, payment.settled and checkin.created message carries the change plus the updated totals per ticket type. Browsers may pass the JWT as the access_token query parameter
+// @Tags event-stats
+// @Param authorization header string false "Bearer token"
+// @Param access_token query string false "JWT, alternative to the authorization header"
+// @Param id path int true "Event ID"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/live [get]
+// @Security BearerAuth
+func (sc *EventStatsController) live(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	// Subscribe sebelum snapshot agar perubahan di antaranya tidak terlewat
+	events, unsubscribe := sc.statsUC.Subscribe(eventID)
+	defer unsubscribe()
+
+	// Otorisasi dilakukan sebelum upgrade supaya error bisa dikirim sebagai response HTTP biasa
+	snapshot, err := sc.statsUC.Snapshot(ctx, eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	server := websocket.Server{
+		// Origin tidak dicek karena autentikasi memakai token, bukan cookie
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+
+			send := func(event service.StreamEvent) bool {
+				conn.SetWriteDeadline(time.Now().Add(liveStatsWriteTimeout))
+				return websocket.JSON.Send(conn, event) == nil
+			}
+
+			// Client tidak perlu mengirim apa pun; pembacaan hanya untuk mendeteksi koneksi ditutup
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				var discard string
+				for websocket.Message.Receive(conn, &discard) == nil {
+				}
+			}()
+
+			if !send(service.StreamEvent{Type: service.StreamEventSnapshot, Data: snapshot}) {
+				return
+			}
+
+			heartbeat := time.NewTicker(liveStatsHeartbeatInterval)
+			defer heartbeat.Stop()
+
+			for {
+				select {
+				case <-closed:
+					return
+				case event, open := <-events:
+					if !open || !send(event) {
+						return
+					}
+				case <-heartbeat.C:
+					if !send(service.StreamEvent{Type: "heartbeat"}) {
+						return
+					}
+				}
+			}
+		},
+	}
+	server.ServeHTTP(ctx.Writer, ctx.Request)
+}
@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupBookingController struct {
+	groupBookingUC usecase.GroupBookingUsecase
+	rg             *gin.RouterGroup
+}
+
+func NewGroupBookingController(groupBookingUC usecase.GroupBookingUsecase, rg *gin.RouterGroup) *GroupBookingController {
+	return &GroupBookingController{groupBookingUC: groupBookingUC, rg: rg}
+}
+
+func (gc *GroupBookingController) Route() {
+	gc.rg.POST("/event/:id/group-bookings", gc.create)
+	gc.rg.GET("/event/:id/group-bookings", gc.listForEvent)
+
+	gc.rg.GET("/group-bookings", gc.listMine)
+	gc.rg.GET("/group-bookings/:id", gc.get)
+	gc.rg.PUT("/group-bookings/:id/seats", gc.assignSeats)
+	gc.rg.POST("/group-bookings/:id/mark-paid", gc.markPaid)
+	gc.rg.POST("/group-bookings/:id/cancel", gc.cancel)
+}
+
+// @Summary Create a group booking
+// @Description Reserves several tickets of one ticket type for a company. The quota is held until the invoice due date; the booking is paid by bank transfer against the issued invoice and marked paid by the organizer
+// @Tags group_bookings
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param payload body dto.CreateGroupBookingRequest true "Ticket type, quantity and buyer company details"
+// @Success 201 {object} utils.Response{data=models.GroupBooking}
+// @Failure 400 {object} utils.Response "Invalid input, free event or not enough tickets left"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Challenge missing, not admitted from the queue or purchase limit reached"
+// @Failure 429 {object} utils.Response "Too many attempts"
+// @Router /api/v1/event/{id}/group-bookings [post]
+// @Security BearerAuth
+func (gc *GroupBookingController) create(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.CreateGroupBookingRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	booking, err := gc.groupBookingUC.Create(userID, eventID, payload, dto.ClientInfo{IP: ctx.ClientIP(), Challenge: payload.Challenge})
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.APIResponse("Group booking reserved", booking, true))
+}
+
+// @Summary List group bookings of an event
+// @Description Returns the 200 most recent group bookings of an event (organizer or admin only)
+// @Tags group_bookings
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param status query string false "reserved, paid, cancelled or expired"
+// @Success 200 {object} utils.Response{data=[]models.GroupBooking}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/group-bookings [get]
+// @Security BearerAuth
+func (gc *GroupBookingController) listForEvent(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	bookings, err := gc.groupBookingUC.ListForEvent(eventID, userID, currentUserRole(ctx), ctx.Query("status"))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch group bookings", bookings, true))
+}
+
+// @Summary List my group bookings
+// @Description Returns the current user's 200 most recent group bookings
+// @Tags group_bookings
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=[]models.GroupBooking}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/group-bookings [get]
+// @Security BearerAuth
+func (gc *GroupBookingController) listMine(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	bookings, err := gc.groupBookingUC.ListMine(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch group bookings", bookings, true))
+}
+
+// @Summary Get a group booking
+// @Description Returns a group booking with its seats. Visible to the purchaser, the event organizer and admins
+// @Tags group_bookings
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Group booking ID"
+// @Success 200 {object} utils.Response{data=models.GroupBooking}
+// @Failure 400 {object} utils.Response "Invalid group booking ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Group booking not found"
+// @Router /api/v1/group-bookings/{id} [get]
+// @Security BearerAuth
+func (gc *GroupBookingController) get(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	bookingID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid group booking ID", nil, false))
+		return
+	}
+
+	booking, err := gc.groupBookingUC.Get(bookingID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch group booking", booking, true))
+}
+
+// @Summary Assign attendees to seats
+// @Description Sets the attendee name and email of one or more seats (purchaser only). Seats can be reassigned until checked in. Attendees whose email belongs to an account get the ticket in their own registrations
+// @Tags group_bookings
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Group booking ID"
+// @Param payload body dto.AssignSeatsRequest true "Seat assignments"
+// @Success 200 {object} utils.Response{data=models.GroupBooking}
+// @Failure 400 {object} utils.Response "Invalid input, duplicate email or attendee already registered"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Group booking not found"
+// @Failure 409 {object} utils.Response "Group booking cancelled or expired"
+// @Router /api/v1/group-bookings/{id}/seats [put]
+// @Security BearerAuth
+func (gc *GroupBookingController) assignSeats(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	bookingID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid group booking ID", nil, false))
+		return
+	}
+
+	var payload dto.AssignSeatsRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	booking, err := gc.groupBookingUC.AssignSeats(bookingID, userID, payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Seats assigned", booking, true))
+}
+
+// @Summary Mark a group booking as paid
+// @Description Records the bank transfer for a reserved group booking (event organizer or admin only). Issues a ticket code for every seat and settles the booking's transaction
+// @Tags group_bookings
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Group booking ID"
+// @Param payload body dto.MarkGroupBookingPaidRequest true "Bank transfer reference"
+// @Success 200 {object} utils.Response{data=models.GroupBooking}
+// @Failure 400 {object} utils.Response "Invalid input"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Failure 404 {object} utils.Response "Group booking not found"
+// @Failure 409 {object} utils.Response "Group booking already paid, cancelled or expired"
+// @Router /api/v1/group-bookings/{id}/mark-paid [post]
+// @Security BearerAuth
+func (gc *GroupBookingController) markPaid(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	bookingID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid group booking ID", nil, false))
+		return
+	}
+
+	var payload dto.MarkGroupBookingPaidRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	booking, err := gc.groupBookingUC.MarkPaid(bookingID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Group booking marked as paid", booking, true))
+}
+
+// @Summary Cancel a group booking
+// @Description Cancels an unpaid group booking (purchaser, event organizer or admin) and releases its tickets
+// @Tags group_bookings
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Group booking ID"
+// @Success 200 {object} utils.Response{data=models.GroupBooking}
+// @Failure 400 {object} utils.Response "Invalid group booking ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Group booking not found"
+// @Failure 409 {object} utils.Response "Group booking already paid, cancelled or expired"
+// @Router /api/v1/group-bookings/{id}/cancel [post]
+// @Security BearerAuth
+func (gc *GroupBookingController) cancel(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	bookingID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid group booking ID", nil, false))
+		return
+	}
+
+	booking, err := gc.groupBookingUC.Cancel(bookingID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Group booking cancelled", booking, true))
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"errors"
+	"gatherly-app/service"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID membaca userID yang diset oleh AuthMiddleware.
+// Jika tidak ada, response error sudah ditulis dan ok bernilai false.
+func currentUserID(ctx *gin.Context) (int, bool) {
+	userIDFromToken, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, utils.APIResponse("User ID not found in token", nil, false))
+		return 0, false
+	}
+
+	userID, ok := userIDFromToken.(int)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse("User ID in token is of invalid type", nil, false))
+		return 0, false
+	}
+	return userID, true
+}
+
+// currentUserRole membaca role dari token; string kosong jika tidak ada.
+func currentUserRole(ctx *gin.Context) string {
+	role, _ := ctx.Get("userRole")
+	value, _ := role.(string)
+	return value
+}
+
+// usecaseErrorStatus memetakan error usecase yang dikenal ke HTTP status.
+func usecaseErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrNotEventOrganizer), errors.Is(err, usecase.ErrEventAccessDenied),
+		errors.Is(err, usecase.ErrAdminOnly), errors.Is(err, usecase.ErrChallengeRequired),
+		errors.Is(err, usecase.ErrNotAdmitted), errors.Is(err, usecase.ErrPurchaseLimit),
+		errors.Is(err, usecase.ErrCheckoutExpired), errors.Is(err, usecase.ErrNotEligible),
+		errors.Is(err, usecase.ErrUserAccessDenied), errors.Is(err, usecase.ErrUserFieldAdminOnly):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrQueueTokenInvalid):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrWebhookNotFound), errors.Is(err, usecase.ErrWebhookDeliveryNotFound),
+		errors.Is(err, usecase.ErrReconciliationNotFound), errors.Is(err, usecase.ErrPayoutNotFound),
+		errors.Is(err, usecase.ErrPayoutBatchNotFound), errors.Is(err, usecase.ErrInvoiceNotFound),
+		errors.Is(err, usecase.ErrGroupBookingNotFound), errors.Is(err, usecase.ErrSeatMapNotFound),
+		errors.Is(err, usecase.ErrQueueEntryNotFound), errors.Is(err, usecase.ErrUserGroupNotFound),
+		errors.Is(err, usecase.ErrImportJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrReconciliationRunning), errors.Is(err, usecase.ErrPayoutBatchClosed),
+		errors.Is(err, usecase.ErrGroupBookingClosed), errors.Is(err, usecase.ErrSeatUnavailable),
+		errors.Is(err, usecase.ErrSeatMapInUse), errors.Is(err, usecase.ErrUserGroupInUse):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrRateLimited):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadRequest
+	}
+}
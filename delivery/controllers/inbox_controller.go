@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inboxHeartbeatInterval menjaga koneksi SSE tetap hidup melewati proxy yang menutup koneksi idle
+const inboxHeartbeatInterval = 25 * time.Second
+
+type InboxController struct {
+	inboxUC usecase.InboxUsecase
+	rg      *gin.RouterGroup
+}
+
+func NewInboxController(inboxUC usecase.InboxUsecase, rg *gin.RouterGroup) *InboxController {
+	return &InboxController{inboxUC: inboxUC, rg: rg}
+}
+
+func (ic *InboxController) Route() {
+	ic.rg.GET("/inbox", ic.listMessages)
+	ic.rg.PATCH("/inbox/read-all", ic.markAllRead)
+	ic.rg.PATCH("/inbox/:id/read", ic.markRead)
+}
+
+// RegisterStreamRoutes mendaftarkan endpoint SSE; rg harus memakai middleware RequireStreamToken
+func (ic *InboxController) RegisterStreamRoutes() {
+	ic.rg.GET("/inbox/stream", ic.stream)
+}
+
+// @Summary List inbox messages
+// @Description Retrieves in-app messages of the authenticated user, newest first. Use next_before from the response as the before parameter to fetch the next page
+// @Tags inbox
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param limit query int false "Maximum number of messages (default 50, max 100)"
+// @Param before query int false "Only return messages with an ID lower than this cursor"
+// @Param unread query bool false "Only return unread messages"
+// @Success 200 {object} utils.Response{data=dto.InboxPage}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/inbox [get]
+// @Security BearerAuth
+func (ic *InboxController) listMessages(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	before, _ := strconv.Atoi(ctx.Query("before"))
+	unreadOnly, _ := strconv.ParseBool(ctx.Query("unread"))
+	page, err := ic.inboxUC.ListMessages(userID, before, limit, unreadOnly)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch inbox", page, true))
+}
+
+// @Summary Mark an inbox message as read
+// @Tags inbox
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Message ID"
+// @Success 200 {object} utils.Response{data=dto.InboxReadResponse}
+// @Failure 400 {object} utils.Response "Invalid message ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Message not found"
+// @Router /api/v1/inbox/{id}/read [patch]
+// @Security BearerAuth
+func (ic *InboxController) markRead(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	messageID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid message ID", nil, false))
+		return
+	}
+
+	response, err := ic.inboxUC.MarkRead(userID, messageID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Message marked as read", response, true))
+}
+
+// @Summary Mark all inbox messages as read
+// @Tags inbox
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=dto.InboxReadResponse}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/inbox/read-all [patch]
+// @Security BearerAuth
+func (ic *InboxController) markAllRead(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := ic.inboxUC.MarkAllRead(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("All messages marked as read", response, true))
+}
+
+// @Summary Stream inbox events
+// @Description Server-Sent Events stream of the authenticated user's events: inbox.message, inbox.read, payment.status and ticket.issued. A "ready" event with the unread count is sent first. Browsers that cannot set headers may pass the JWT as the access_token query parameter
+// @Tags inbox
+// @Produce text/event-stream
+// @Param authorization header string false "Bearer token"
+// @Param access_token query string false "JWT, alternative to the authorization header"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/inbox/stream [get]
+// @Security BearerAuth
+func (ic *InboxController) stream(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	// Subscribe sebelum menghitung unread agar tidak ada pesan yang terlewat di antaranya
+	events, unsubscribe := ic.inboxUC.Subscribe(userID)
+	defer unsubscribe()
+
+	unread, err := ic.inboxUC.UnreadCount(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.SSEvent("ready", gin.H{"unread_count": unread})
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(inboxHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case event, open := <-events:
+			if !open {
+				return false
+			}
+			ctx.SSEvent(event.Type, event.Data)
+		case <-heartbeat.C:
+			io.WriteString(w, ": ping\n\n")
+		}
+		return true
+	})
+}
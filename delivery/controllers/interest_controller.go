@@ -0,0 +1,326 @@
+package controllers
+
+import (
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InterestController struct {
+	interestUC usecase.InterestUsecase
+	rg         *gin.RouterGroup
+}
+
+func NewInterestController(interestUC usecase.InterestUsecase, rg *gin.RouterGroup) *InterestController {
+	return &InterestController{interestUC: interestUC, rg: rg}
+}
+
+func (ic *InterestController) Route() {
+	ic.rg.GET("/bookmarks", ic.listBookmarks)
+	ic.rg.POST("/bookmarks/:eventId", ic.addBookmark)
+	ic.rg.DELETE("/bookmarks/:eventId", ic.removeBookmark)
+	ic.rg.GET("/follows", ic.listFollows)
+	ic.rg.POST("/follows/organizers/:organizerId", ic.followOrganizer)
+	ic.rg.DELETE("/follows/organizers/:organizerId", ic.unfollowOrganizer)
+	ic.rg.POST("/follows/categories/:category", ic.followCategory)
+	ic.rg.DELETE("/follows/categories/:category", ic.unfollowCategory)
+	ic.rg.GET("/feed", ic.getFeed)
+	ic.rg.GET("/organizer/stats", ic.getOrganizerStats)
+}
+
+// @Summary List bookmarked events
+// @Description Retrieves the events the authenticated user saved for later
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/bookmarks [get]
+// @Security BearerAuth
+func (ic *InterestController) listBookmarks(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	bookmarks, err := ic.interestUC.ListBookmarks(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch bookmarks", bookmarks, true))
+}
+
+// @Summary Bookmark an event
+// @Description Saves an event for later without registering or consuming ticket quota
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param eventId path int true "Event ID"
+// @Param key query string false "Secret link key or invite code for non-public events"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Event not found"
+// @Router /api/v1/bookmarks/{eventId} [post]
+// @Security BearerAuth
+func (ic *InterestController) addBookmark(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("eventId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	if err := ic.interestUC.AddBookmark(userID, eventID, ctx.Query("key")); err != nil {
+		ctx.JSON(http.StatusNotFound, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Event bookmarked", nil, true))
+}
+
+// @Summary Remove a bookmark
+// @Description Removes an event from the authenticated user's bookmarks
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param eventId path int true "Event ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Bookmark not found"
+// @Router /api/v1/bookmarks/{eventId} [delete]
+// @Security BearerAuth
+func (ic *InterestController) removeBookmark(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("eventId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	if err := ic.interestUC.RemoveBookmark(userID, eventID); err != nil {
+		ctx.JSON(http.StatusNotFound, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Bookmark removed", nil, true))
+}
+
+// @Summary List follows
+// @Description Retrieves the organizers and categories the authenticated user follows
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=dto.FollowListResponse}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/follows [get]
+// @Security BearerAuth
+func (ic *InterestController) listFollows(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	follows, err := ic.interestUC.ListFollows(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch follows", follows, true))
+}
+
+// @Summary Follow an organizer
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param organizerId path int true "Organizer user ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid organizer ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/follows/organizers/{organizerId} [post]
+// @Security BearerAuth
+func (ic *InterestController) followOrganizer(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	organizerID, err := strconv.Atoi(ctx.Param("organizerId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid organizer ID", nil, false))
+		return
+	}
+
+	if err := ic.interestUC.FollowOrganizer(userID, organizerID); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Organizer followed", nil, true))
+}
+
+// @Summary Unfollow an organizer
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param organizerId path int true "Organizer user ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid organizer ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Not following"
+// @Router /api/v1/follows/organizers/{organizerId} [delete]
+// @Security BearerAuth
+func (ic *InterestController) unfollowOrganizer(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	organizerID, err := strconv.Atoi(ctx.Param("organizerId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid organizer ID", nil, false))
+		return
+	}
+
+	if err := ic.interestUC.UnfollowOrganizer(userID, organizerID); err != nil {
+		ctx.JSON(http.StatusNotFound, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Organizer unfollowed", nil, true))
+}
+
+// @Summary Follow a category
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param category path string true "Category name"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid category"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/follows/categories/{category} [post]
+// @Security BearerAuth
+func (ic *InterestController) followCategory(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ic.interestUC.FollowCategory(userID, ctx.Param("category")); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Category followed", nil, true))
+}
+
+// @Summary Unfollow a category
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param category path string true "Category name"
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Not following"
+// @Router /api/v1/follows/categories/{category} [delete]
+// @Security BearerAuth
+func (ic *InterestController) unfollowCategory(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ic.interestUC.UnfollowCategory(userID, ctx.Param("category")); err != nil {
+		ctx.JSON(http.StatusNotFound, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Category unfollowed", nil, true))
+}
+
+// @Summary Followed events feed
+// @Description Retrieves upcoming events from followed organizers and categories, newest first
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param since query string false "Only events created after this time (RFC3339)"
+// @Param limit query int false "Number of events to return (default: 20)"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid query parameter"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/feed [get]
+// @Security BearerAuth
+func (ic *InterestController) getFeed(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var since time.Time
+	var err error
+	if value := ctx.Query("since"); value != "" {
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.APIResponse("since must be an RFC3339 timestamp", nil, false))
+			return
+		}
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("limit must be an integer", nil, false))
+		return
+	}
+
+	events, err := ic.interestUC.GetFeed(userID, since, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch feed", events, true))
+}
+
+// @Summary Organizer follower and bookmark counts
+// @Description Retrieves follower count and per-event bookmark counts for events organized by the authenticated user
+// @Tags interests
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=dto.OrganizerStatsResponse}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/organizer/stats [get]
+// @Security BearerAuth
+func (ic *InterestController) getOrganizerStats(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := ic.interestUC.GetOrganizerStats(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch organizer stats", stats, true))
+}
@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationController struct {
+	invitationUC usecase.InvitationUsecase
+	rg           *gin.RouterGroup
+}
+
+func NewInvitationController(invitationUC usecase.InvitationUsecase, rg *gin.RouterGroup) *InvitationController {
+	return &InvitationController{invitationUC: invitationUC, rg: rg}
+}
+
+func (ic *InvitationController) Route() {
+	ic.rg.GET("/event/:id/access", ic.getEventAccess)
+	ic.rg.POST("/event/:id/invitations", ic.inviteUsers)
+	ic.rg.GET("/event/:id/invitations", ic.listInvitations)
+	ic.rg.DELETE("/event/:id/invitations/:invitationId", ic.revokeInvitation)
+	ic.rg.POST("/event/:id/invite-links", ic.createInviteLink)
+	ic.rg.GET("/event/:id/invite-links", ic.listInviteLinks)
+	ic.rg.DELETE("/event/:id/invite-links/:linkId", ic.revokeInviteLink)
+	ic.rg.GET("/invitations", ic.listMyInvitations)
+}
+
+// @Summary Get event share key
+// @Description Returns the visibility and secret link key of an event (organizer only)
+// @Tags invitations
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=dto.EventAccessResponse}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/access [get]
+// @Security BearerAuth
+func (ic *InvitationController) getEventAccess(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	access, err := ic.invitationUC.GetEventAccess(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch event access", access, true))
+}
+
+// @Summary Invite users to an event
+// @Description Invites users by email or username (organizer only)
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param invitations body dto.CreateInvitationsRequest true "Emails and usernames to invite"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid request body"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/invitations [post]
+// @Security BearerAuth
+func (ic *InvitationController) inviteUsers(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.CreateInvitationsRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	invitations, err := ic.invitationUC.InviteUsers(eventID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.APIResponse("Invitations sent", invitations, true))
+}
+
+// @Summary List event invitations
+// @Tags invitations
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/invitations [get]
+// @Security BearerAuth
+func (ic *InvitationController) listInvitations(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	invitations, err := ic.invitationUC.ListInvitations(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch invitations", invitations, true))
+}
+
+// @Summary Revoke an invitation
+// @Tags invitations
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param invitationId path int true "Invitation ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/invitations/{invitationId} [delete]
+// @Security BearerAuth
+func (ic *InvitationController) revokeInvitation(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err1 := strconv.Atoi(ctx.Param("id"))
+	invitationID, err2 := strconv.Atoi(ctx.Param("invitationId"))
+	if err1 != nil || err2 != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid ID", nil, false))
+		return
+	}
+
+	if err := ic.invitationUC.RevokeInvitation(eventID, invitationID, userID, currentUserRole(ctx)); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Invitation revoked", nil, true))
+}
+
+// @Summary Create an invite link
+// @Description Generates an access code with optional expiry and usage limit (organizer only)
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param link body dto.CreateInviteLinkRequest true "Invite link options"
+// @Success 201 {object} utils.Response{data=dto.InviteLinkResponse}
+// @Failure 400 {object} utils.Response "Invalid request body"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/invite-links [post]
+// @Security BearerAuth
+func (ic *InvitationController) createInviteLink(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.CreateInviteLinkRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	link, err := ic.invitationUC.CreateInviteLink(eventID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.APIResponse("Invite link created", link, true))
+}
+
+// @Summary List invite links
+// @Tags invitations
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=[]dto.InviteLinkResponse}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/invite-links [get]
+// @Security BearerAuth
+func (ic *InvitationController) listInviteLinks(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	links, err := ic.invitationUC.ListInviteLinks(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch invite links", links, true))
+}
+
+// @Summary Revoke an invite link
+// @Tags invitations
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param linkId path int true "Invite link ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/invite-links/{linkId} [delete]
+// @Security BearerAuth
+func (ic *InvitationController) revokeInviteLink(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err1 := strconv.Atoi(ctx.Param("id"))
+	linkID, err2 := strconv.Atoi(ctx.Param("linkId"))
+	if err1 != nil || err2 != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid ID", nil, false))
+		return
+	}
+
+	if err := ic.invitationUC.RevokeInviteLink(eventID, linkID, userID, currentUserRole(ctx)); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Invite link revoked", nil, true))
+}
+
+// @Summary List my invitations
+// @Description Retrieves invitations addressed to the authenticated user
+// @Tags invitations
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/invitations [get]
+// @Security BearerAuth
+func (ic *InvitationController) listMyInvitations(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	invitations, err := ic.invitationUC.ListMyInvitations(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch invitations", invitations, true))
+}
@@ -0,0 +1,329 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceController struct {
+	invoiceUC usecase.InvoiceUsecase
+	rg        *gin.RouterGroup
+}
+
+func NewInvoiceController(invoiceUC usecase.InvoiceUsecase, rg *gin.RouterGroup) *InvoiceController {
+	return &InvoiceController{invoiceUC: invoiceUC, rg: rg}
+}
+
+func (ic *InvoiceController) Route() {
+	ic.rg.GET("/event/:id/tax", ic.getEventTax)
+	ic.rg.PUT("/event/:id/tax", ic.setEventTax)
+	ic.rg.DELETE("/event/:id/tax", ic.deleteEventTax)
+	ic.rg.GET("/event/:id/invoices", ic.listEventInvoices)
+
+	ic.rg.GET("/organizer/legal-profile", ic.getLegalProfile)
+	ic.rg.PUT("/organizer/legal-profile", ic.setLegalProfile)
+
+	ic.rg.GET("/invoices", ic.listMyInvoices)
+	ic.rg.GET("/invoices/:id", ic.getInvoice)
+	ic.rg.PUT("/invoices/:id/buyer", ic.updateBuyer)
+}
+
+// @Summary Get event tax setting
+// @Description Returns the VAT/PPN configuration of an event (organizer or admin only)
+// @Tags invoices
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=models.EventTaxSetting}
+// @Failure 400 {object} utils.Response "Invalid event ID or no tax setting"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/tax [get]
+// @Security BearerAuth
+func (ic *InvoiceController) getEventTax(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	setting, err := ic.invoiceUC.GetEventTax(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch tax setting", setting, true))
+}
+
+// @Summary Set event tax setting
+// @Description Configures VAT/PPN for an event. Exclusive adds the tax on top of the ticket price; inclusive treats the ticket price as tax-inclusive. Tax is computed per order line and rounded to the currency's smallest unit in practice (whole rupiah for IDR). Applies to new orders only; issued invoices keep their tax
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param payload body dto.SetEventTaxRequest true "Mode, rate in percent, tax name and optional tax ID"
+// @Success 200 {object} utils.Response{data=models.EventTaxSetting}
+// @Failure 400 {object} utils.Response "Invalid input"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/tax [put]
+// @Security BearerAuth
+func (ic *InvoiceController) setEventTax(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.SetEventTaxRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	setting, err := ic.invoiceUC.SetEventTax(eventID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Tax setting saved", setting, true))
+}
+
+// @Summary Remove event tax setting
+// @Description New orders for the event are no longer taxed
+// @Tags invoices
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid event ID or no tax setting"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/tax [delete]
+// @Security BearerAuth
+func (ic *InvoiceController) deleteEventTax(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	if err := ic.invoiceUC.DeleteEventTax(eventID, userID, currentUserRole(ctx)); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Tax setting removed", nil, true))
+}
+
+// @Summary List event invoices
+// @Description Returns the 200 most recent invoices of an event (organizer or admin only)
+// @Tags invoices
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=[]models.Invoice}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/invoices [get]
+// @Security BearerAuth
+func (ic *InvoiceController) listEventInvoices(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	invoices, err := ic.invoiceUC.ListEventInvoices(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch invoices", invoices, true))
+}
+
+// @Summary Get my legal profile
+// @Description Returns the seller details printed on the current organizer's invoices
+// @Tags invoices
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=models.OrganizerLegalProfile}
+// @Failure 400 {object} utils.Response "Legal profile not set"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/organizer/legal-profile [get]
+// @Security BearerAuth
+func (ic *InvoiceController) getLegalProfile(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	profile, err := ic.invoiceUC.GetLegalProfile(userID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch legal profile", profile, true))
+}
+
+// @Summary Set my legal profile
+// @Description Sets the legal name, tax ID (NPWP) and address printed on invoices of the current organizer's events. Issued invoices are not changed
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param payload body dto.SetLegalProfileRequest true "Legal details"
+// @Success 200 {object} utils.Response{data=models.OrganizerLegalProfile}
+// @Failure 400 {object} utils.Response "Invalid input"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/organizer/legal-profile [put]
+// @Security BearerAuth
+func (ic *InvoiceController) setLegalProfile(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var payload dto.SetLegalProfileRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	profile, err := ic.invoiceUC.SetLegalProfile(userID, payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Legal profile saved", profile, true))
+}
+
+// @Summary List my invoices
+// @Description Returns the current user's 200 most recent invoices with their lines. Amounts are in minor units (1 IDR = 100)
+// @Tags invoices
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=[]models.Invoice}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/invoices [get]
+// @Security BearerAuth
+func (ic *InvoiceController) listMyInvoices(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	invoices, err := ic.invoiceUC.ListMyInvoices(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch invoices", invoices, true))
+}
+
+// @Summary Get an invoice
+// @Description Returns an invoice with its lines. Visible to the buyer, the event organizer and admins
+// @Tags invoices
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Invoice ID"
+// @Success 200 {object} utils.Response{data=models.Invoice}
+// @Failure 400 {object} utils.Response "Invalid invoice ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Invoice not found"
+// @Router /api/v1/invoices/{id} [get]
+// @Security BearerAuth
+func (ic *InvoiceController) getInvoice(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	invoiceID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid invoice ID", nil, false))
+		return
+	}
+
+	invoice, err := ic.invoiceUC.GetInvoice(invoiceID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch invoice", invoice, true))
+}
+
+// @Summary Set invoice buyer details
+// @Description Adds the buyer's company name, tax ID and address to an invoice. Only allowed while its transaction is still pending
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Invoice ID"
+// @Param payload body dto.InvoiceBuyerRequest true "Buyer company details"
+// @Success 200 {object} utils.Response{data=models.Invoice}
+// @Failure 400 {object} utils.Response "Invalid input or transaction no longer pending"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Invoice not found"
+// @Router /api/v1/invoices/{id}/buyer [put]
+// @Security BearerAuth
+func (ic *InvoiceController) updateBuyer(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	invoiceID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid invoice ID", nil, false))
+		return
+	}
+
+	var payload dto.InvoiceBuyerRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	invoice, err := ic.invoiceUC.UpdateBuyer(invoiceID, userID, payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Buyer details saved", invoice, true))
+}
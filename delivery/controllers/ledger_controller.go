@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerController struct {
+	ledgerUC usecase.LedgerUsecase
+	rg       *gin.RouterGroup
+}
+
+func NewLedgerController(ledgerUC usecase.LedgerUsecase, rg *gin.RouterGroup) *LedgerController {
+	return &LedgerController{ledgerUC: ledgerUC, rg: rg}
+}
+
+func (lc *LedgerController) Route() {
+	lc.rg.GET("/ledger/balance", lc.organizerBalance)
+	lc.rg.GET("/admin/ledger/accounts", lc.accountBalances)
+	lc.rg.GET("/admin/ledger/check", lc.check)
+}
+
+// @Summary Get my organizer balance
+// @Description Returns the amount the platform owes the current user as organizer: settlements minus platform fees, refunds and payouts. Amounts are in minor units (1 IDR = 100)
+// @Tags ledger
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=dto.LedgerBalance}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/ledger/balance [get]
+// @Security BearerAuth
+func (lc *LedgerController) organizerBalance(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	balance, err := lc.ledgerUC.OrganizerBalance(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch balance", balance, true))
+}
+
+// @Summary List ledger account balances
+// @Description Returns debits, credits and the normal-side balance of every ledger account, in minor units (admin only)
+// @Tags ledger
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=[]dto.LedgerBalance}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/ledger/accounts [get]
+// @Security BearerAuth
+func (lc *LedgerController) accountBalances(ctx *gin.Context) {
+	balances, err := lc.ledgerUC.AccountBalances(currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch ledger accounts", balances, true))
+}
+
+// @Summary Check ledger invariants
+// @Description Verifies that all postings sum to zero, every entry is balanced and every settled transaction has a matching settlement entry (admin only)
+// @Tags ledger
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=dto.LedgerCheckResult}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/ledger/check [get]
+// @Security BearerAuth
+func (lc *LedgerController) check(ctx *gin.Context) {
+	result, err := lc.ledgerUC.Check(currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success check ledger", result, true))
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// moneyDisplay menyesuaikan nominal di response dengan bahasa dan mata uang tampilan pilihan user.
+// Nominal asli tidak berubah; konversi hanya ditambahkan sebagai perkiraan.
+type moneyDisplay struct {
+	locale   string
+	currency string
+	rates    *service.ExchangeRates
+}
+
+// newMoneyDisplay membaca ?locale= (id atau en) atau header Accept-Language, dan ?display_currency=
+func newMoneyDisplay(ctx *gin.Context, rates *service.ExchangeRates) (moneyDisplay, error) {
+	display := moneyDisplay{locale: requestLocale(ctx), rates: rates}
+	if code := ctx.Query("display_currency"); code != "" {
+		currency, err := models.LookupCurrency(code)
+		if err != nil {
+			return moneyDisplay{}, err
+		}
+		display.currency = currency.Code
+	}
+	return display, nil
+}
+
+func requestLocale(ctx *gin.Context) string {
+	if locale := ctx.Query("locale"); locale == models.LocaleEnglish || locale == models.LocaleIndonesian {
+		return locale
+	}
+	// Accept-Language diurutkan dari yang paling disukai; bobot q diabaikan
+	for _, tag := range strings.Split(ctx.GetHeader("Accept-Language"), ",") {
+		language := strings.ToLower(strings.TrimSpace(strings.SplitN(strings.SplitN(tag, ";", 2)[0], "-", 2)[0]))
+		if language == models.LocaleEnglish || language == models.LocaleIndonesian {
+			return language
+		}
+	}
+	return models.LocaleIndonesian
+}
+
+func (d moneyDisplay) apply(view *dto.MoneyView) {
+	money := models.Money{Amount: view.Amount, Currency: view.Currency}
+	view.Formatted = money.Format(d.locale)
+	view.Converted = nil
+	if d.currency == "" || d.currency == view.Currency {
+		return
+	}
+	if converted, rate, ok := d.rates.Convert(money, d.currency); ok {
+		view.Converted = &dto.ConvertedMoney{
+			Amount:    converted.Amount,
+			Currency:  converted.Currency,
+			Formatted: converted.Format(d.locale),
+			Rate:      rate,
+			RatesDate: d.rates.Date,
+		}
+	}
+}
+
+func (d moneyDisplay) ticket(ticket *dto.TicketResponseDTO) {
+	if ticket != nil {
+		d.apply(&ticket.Price)
+	}
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationUC usecase.NotificationUsecase
+	rg             *gin.RouterGroup
+}
+
+func NewNotificationController(notificationUC usecase.NotificationUsecase, rg *gin.RouterGroup) *NotificationController {
+	return &NotificationController{notificationUC: notificationUC, rg: rg}
+}
+
+func (nc *NotificationController) Route() {
+	nc.rg.GET("/notifications/preferences", nc.getPreferences)
+	nc.rg.PUT("/notifications/preferences", nc.updatePreferences)
+}
+
+// @Summary Get notification preferences
+// @Description Returns the notification language and the enabled state of every notification type per channel
+// @Tags notifications
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=dto.NotificationPreferencesResponse}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/notifications/preferences [get]
+// @Security BearerAuth
+func (nc *NotificationController) getPreferences(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	preferences, err := nc.notificationUC.GetPreferences(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch notification preferences", preferences, true))
+}
+
+// @Summary Update notification preferences
+// @Description Updates the notification language (id or en) and enables or disables notification types per channel (email, inbox). Omitted combinations keep their current setting
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param preferences body dto.UpdateNotificationPreferencesRequest true "Notification preferences"
+// @Success 200 {object} utils.Response{data=dto.NotificationPreferencesResponse}
+// @Failure 400 {object} utils.Response "Invalid type, channel or locale"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/notifications/preferences [put]
+// @Security BearerAuth
+func (nc *NotificationController) updatePreferences(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var payload dto.UpdateNotificationPreferencesRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	preferences, err := nc.notificationUC.UpdatePreferences(userID, payload)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Notification preferences updated", preferences, true))
+}
@@ -0,0 +1,445 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PayoutController struct {
+	payoutUC usecase.PayoutUsecase
+	rg       *gin.RouterGroup
+}
+
+func NewPayoutController(payoutUC usecase.PayoutUsecase, rg *gin.RouterGroup) *PayoutController {
+	return &PayoutController{payoutUC: payoutUC, rg: rg}
+}
+
+func (pc *PayoutController) Route() {
+	pc.rg.GET("/payouts/statement", pc.statement)
+	pc.rg.POST("/payouts", pc.requestPayout)
+	pc.rg.GET("/payouts", pc.listPayouts)
+	pc.rg.DELETE("/payouts/:id", pc.cancelPayout)
+
+	pc.rg.GET("/admin/fee-rules", pc.listFeeRules)
+	pc.rg.PUT("/admin/fee-rules", pc.setFeeRule)
+	pc.rg.DELETE("/admin/fee-rules/:id", pc.deleteFeeRule)
+	pc.rg.GET("/admin/payouts", pc.listAllPayouts)
+	pc.rg.POST("/admin/payout-batches", pc.createBatch)
+	pc.rg.GET("/admin/payout-batches", pc.listBatches)
+	pc.rg.GET("/admin/payout-batches/:id", pc.getBatch)
+	pc.rg.POST("/admin/payout-batches/:id/approve", pc.approveBatch)
+	pc.rg.POST("/admin/payout-batches/:id/reject", pc.rejectBatch)
+	pc.rg.GET("/admin/payout-batches/:id/export", pc.exportBatch)
+}
+
+// @Summary Get my balance statement
+// @Description Lists ledger movements on the current organizer's account (settlements, platform fees, refunds, payouts) with opening, running and closing balances, plus the balance available for payout. Amounts are in minor units (1 IDR = 100)
+// @Tags payouts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param from query string false "First day, YYYY-MM-DD (default: first day of the month of to)"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today, UTC)"
+// @Success 200 {object} utils.Response{data=dto.OrganizerStatement}
+// @Failure 400 {object} utils.Response "Invalid date range"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/payouts/statement [get]
+// @Security BearerAuth
+func (pc *PayoutController) statement(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	statement, err := pc.payoutUC.Statement(userID, ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch statement", statement, true))
+}
+
+// @Summary Request a payout
+// @Description Requests a transfer of part of the available balance to a bank account. The amount is reserved until the payout is cancelled, rejected or approved
+// @Tags payouts
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param payload body dto.CreatePayoutRequest true "Amount in minor units and bank account"
+// @Success 201 {object} utils.Response{data=models.Payout}
+// @Failure 400 {object} utils.Response "Invalid input, below minimum or insufficient balance"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/payouts [post]
+// @Security BearerAuth
+func (pc *PayoutController) requestPayout(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var payload dto.CreatePayoutRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	payout, err := pc.payoutUC.RequestPayout(userID, payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.APIResponse("Payout requested", payout, true))
+}
+
+// @Summary List my payouts
+// @Description Returns the current organizer's 200 most recent payout requests
+// @Tags payouts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=[]models.Payout}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/payouts [get]
+// @Security BearerAuth
+func (pc *PayoutController) listPayouts(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	payouts, err := pc.payoutUC.ListPayouts(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch payouts", payouts, true))
+}
+
+// @Summary Cancel a payout request
+// @Description Cancels a payout that has not been added to a batch yet and releases the reserved amount
+// @Tags payouts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Payout ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Payout is already batched or closed"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Payout not found"
+// @Router /api/v1/payouts/{id} [delete]
+// @Security BearerAuth
+func (pc *PayoutController) cancelPayout(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	payoutID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid payout ID", nil, false))
+		return
+	}
+
+	if err := pc.payoutUC.CancelPayout(userID, payoutID); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Payout cancelled", nil, true))
+}
+
+// @Summary List platform fee rules
+// @Description Returns the default fee and all per-event and per-organizer overrides. An event rule takes precedence over its organizer's rule (admin only)
+// @Tags payouts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=dto.FeeRuleList}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/fee-rules [get]
+// @Security BearerAuth
+func (pc *PayoutController) listFeeRules(ctx *gin.Context) {
+	rules, err := pc.payoutUC.ListFeeRules(currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch fee rules", rules, true))
+}
+
+// @Summary Set a platform fee rule
+// @Description Creates or replaces the fee (percentage plus fixed amount in minor units) for one event or one organizer. Applies to settlements received afterwards (admin only)
+// @Tags payouts
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param payload body dto.SetFeeRuleRequest true "Exactly one of event_id or organizer_id"
+// @Success 200 {object} utils.Response{data=models.FeeRule}
+// @Failure 400 {object} utils.Response "Invalid input"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/fee-rules [put]
+// @Security BearerAuth
+func (pc *PayoutController) setFeeRule(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var payload dto.SetFeeRuleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	rule, err := pc.payoutUC.SetFeeRule(userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Fee rule saved", rule, true))
+}
+
+// @Summary Delete a platform fee rule
+// @Description Removes an override; the organizer rule or the default fee applies again (admin only)
+// @Tags payouts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Fee rule ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Fee rule not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/fee-rules/{id} [delete]
+// @Security BearerAuth
+func (pc *PayoutController) deleteFeeRule(ctx *gin.Context) {
+	ruleID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid fee rule ID", nil, false))
+		return
+	}
+
+	if err := pc.payoutUC.DeleteFeeRule(currentUserRole(ctx), ruleID); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Fee rule deleted", nil, true))
+}
+
+// @Summary List payout requests
+// @Description Returns the 200 most recent payouts of all organizers (admin only)
+// @Tags payouts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param status query string false "requested, batched, approved, rejected or cancelled"
+// @Success 200 {object} utils.Response{data=[]models.Payout}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/payouts [get]
+// @Security BearerAuth
+func (pc *PayoutController) listAllPayouts(ctx *gin.Context) {
+	payouts, err := pc.payoutUC.ListAllPayouts(currentUserRole(ctx), ctx.Query("status"))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch payouts", payouts, true))
+}
+
+// @Summary Create a payout batch
+// @Description Moves requested payouts (all, or only the given IDs) into a new batch awaiting approval (admin only)
+// @Tags payouts
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param payload body dto.CreatePayoutBatchRequest false "Payout IDs to include"
+// @Success 201 {object} utils.Response{data=models.PayoutBatch}
+// @Failure 400 {object} utils.Response "No requested payouts"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/payout-batches [post]
+// @Security BearerAuth
+func (pc *PayoutController) createBatch(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var payload dto.CreatePayoutBatchRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&payload); err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+			return
+		}
+	}
+
+	batch, err := pc.payoutUC.CreateBatch(userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.APIResponse("Payout batch created", batch, true))
+}
+
+// @Summary List payout batches
+// @Description Returns the 200 most recent payout batches (admin only)
+// @Tags payouts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=[]models.PayoutBatch}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Router /api/v1/admin/payout-batches [get]
+// @Security BearerAuth
+func (pc *PayoutController) listBatches(ctx *gin.Context) {
+	batches, err := pc.payoutUC.ListBatches(currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch payout batches", batches, true))
+}
+
+// @Summary Get a payout batch
+// @Description Returns a batch with its payouts (admin only)
+// @Tags payouts
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Batch ID"
+// @Success 200 {object} utils.Response{data=models.PayoutBatch}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Failure 404 {object} utils.Response "Batch not found"
+// @Router /api/v1/admin/payout-batches/{id} [get]
+// @Security BearerAuth
+func (pc *PayoutController) getBatch(ctx *gin.Context) {
+	batchID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid batch ID", nil, false))
+		return
+	}
+
+	batch, err := pc.payoutUC.GetBatch(currentUserRole(ctx), batchID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch payout batch", batch, true))
+}
+
+// @Summary Approve a payout batch
+// @Description Approves a pending batch and records each payout in the ledger. Fails if an organizer's balance no longer covers their payouts. Calling it again on an approved batch retries ledger entries that failed (admin only)
+// @Tags payouts
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Batch ID"
+// @Param payload body dto.ReviewPayoutBatchRequest false "Optional note"
+// @Success 200 {object} utils.Response{data=models.PayoutBatch}
+// @Failure 400 {object} utils.Response "Insufficient balance"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Failure 404 {object} utils.Response "Batch not found"
+// @Failure 409 {object} utils.Response "Batch was rejected"
+// @Router /api/v1/admin/payout-batches/{id}/approve [post]
+// @Security BearerAuth
+func (pc *PayoutController) approveBatch(ctx *gin.Context) {
+	pc.reviewBatch(ctx, pc.payoutUC.ApproveBatch, "Payout batch approved")
+}
+
+// @Summary Reject a payout batch
+// @Description Rejects a pending batch; its payouts are rejected and the reserved amounts released (admin only)
+// @Tags payouts
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Batch ID"
+// @Param payload body dto.ReviewPayoutBatchRequest false "Optional note"
+// @Success 200 {object} utils.Response{data=models.PayoutBatch}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Failure 404 {object} utils.Response "Batch not found"
+// @Failure 409 {object} utils.Response "Batch is no longer pending"
+// @Router /api/v1/admin/payout-batches/{id}/reject [post]
+// @Security BearerAuth
+func (pc *PayoutController) rejectBatch(ctx *gin.Context) {
+	pc.reviewBatch(ctx, pc.payoutUC.RejectBatch, "Payout batch rejected")
+}
+
+type reviewBatchFunc func(adminID int, role string, batchID int, note string) (*models.PayoutBatch, error)
+
+func (pc *PayoutController) reviewBatch(ctx *gin.Context, review reviewBatchFunc, message string) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	batchID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid batch ID", nil, false))
+		return
+	}
+
+	var payload dto.ReviewPayoutBatchRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&payload); err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+			return
+		}
+	}
+
+	batch, err := review(userID, currentUserRole(ctx), batchID, payload.Note)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse(message, batch, true))
+}
+
+// @Summary Export a payout batch as a bank transfer file
+// @Description Downloads an approved batch as a CSV file in the bank format configured with PAYOUT_BANK_FORMAT (admin only)
+// @Tags payouts
+// @Produce text/csv
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Batch ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response "Batch is not approved"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Admin only"
+// @Failure 404 {object} utils.Response "Batch not found"
+// @Router /api/v1/admin/payout-batches/{id}/export [get]
+// @Security BearerAuth
+func (pc *PayoutController) exportBatch(ctx *gin.Context) {
+	batchID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid batch ID", nil, false))
+		return
+	}
+
+	// Ditulis ke buffer dulu agar error tetap bisa dikirim sebagai JSON
+	var file bytes.Buffer
+	if err := pc.payoutUC.ExportBatch(currentUserRole(ctx), batchID, &file); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	filename := fmt.Sprintf("payout-batch-%d.csv", batchID)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", file.Bytes())
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PurchaseGuardController struct {
+	guardUC usecase.PurchaseGuardUsecase
+	rg      *gin.RouterGroup
+}
+
+func NewPurchaseGuardController(guardUC usecase.PurchaseGuardUsecase, rg *gin.RouterGroup) *PurchaseGuardController {
+	return &PurchaseGuardController{guardUC: guardUC, rg: rg}
+}
+
+func (pc *PurchaseGuardController) Route() {
+	pc.rg.GET("/event/:id/purchase-policy", pc.getPolicy)
+	pc.rg.PUT("/event/:id/purchase-policy", pc.setPolicy)
+	pc.rg.GET("/event/:id/challenge", pc.issueChallenge)
+}
+
+// @Summary Get event purchase policy
+// @Description Returns the per-account and per-IP ticket limits, bot protection and waiting queue settings of an event (organizer or admin only)
+// @Tags purchase_guard
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=models.EventPurchasePolicy}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/purchase-policy [get]
+// @Security BearerAuth
+func (pc *PurchaseGuardController) getPolicy(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	policy, err := pc.guardUC.GetPolicy(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch purchase policy", policy, true))
+}
+
+// @Summary Set event purchase policy
+// @Description Limits tickets per account (including group bookings) and per IP, requires an anti-bot challenge on registration, and enables the waiting queue that admits users to checkout in batches up to the remaining inventory. Limits of 0 mean unlimited
+// @Tags purchase_guard
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param payload body dto.SetPurchasePolicyRequest true "Purchase policy"
+// @Success 200 {object} utils.Response{data=models.EventPurchasePolicy}
+// @Failure 400 {object} utils.Response "Invalid input"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/purchase-policy [put]
+// @Security BearerAuth
+func (pc *PurchaseGuardController) setPolicy(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.SetPurchasePolicyRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	policy, err := pc.guardUC.SetPolicy(eventID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Purchase policy saved", policy, true))
+}
+
+// @Summary Get a registration challenge
+// @Description Returns an anti-bot challenge bound to the current user and event. For proof-of-work, find a solution such that sha256(token + ":" + solution) starts with at least difficulty zero bits, then send {token, solution} as "challenge" when registering. Each challenge can be used once
+// @Tags purchase_guard
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=service.Challenge}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/event/{id}/challenge [get]
+// @Security BearerAuth
+func (pc *PurchaseGuardController) issueChallenge(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	challenge, err := pc.guardUC.IssueChallenge(eventID, userID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Challenge issued", challenge, true))
+}
@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"fmt"
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RegistrationFormController struct {
+	formUC usecase.RegistrationFormUsecase
+	rg     *gin.RouterGroup
+}
+
+func NewRegistrationFormController(formUC usecase.RegistrationFormUsecase, rg *gin.RouterGroup) *RegistrationFormController {
+	return &RegistrationFormController{formUC: formUC, rg: rg}
+}
+
+func (fc *RegistrationFormController) Route() {
+	fc.rg.GET("/event/:id/form", fc.getForm)
+	fc.rg.PUT("/event/:id/form", fc.saveForm)
+	fc.rg.DELETE("/event/:id/form", fc.deleteForm)
+	fc.rg.GET("/event/:id/forms", fc.listForms)
+	fc.rg.POST("/event/:id/form/uploads", fc.uploadFile)
+	fc.rg.GET("/event/:id/form/answers", fc.listAnswers)
+	fc.rg.GET("/event/:id/form/answers/me", fc.getMyAnswers)
+}
+
+// optionalIntQuery membaca query integer opsional; nil jika tidak diisi
+func optionalIntQuery(ctx *gin.Context, key string) (*int, error) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", key)
+	}
+	return &value, nil
+}
+
+// @Summary Get registration form
+// @Description Retrieves the form that applies to a ticket type, falling back to the event-wide form
+// @Tags registration_forms
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param ticketTypeId query int false "Ticket type ID"
+// @Param key query string false "Access code for unlisted or invite-only events"
+// @Success 200 {object} utils.Response{data=models.RegistrationForm}
+// @Failure 400 {object} utils.Response "Invalid ID or form not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/event/{id}/form [get]
+// @Security BearerAuth
+func (fc *RegistrationFormController) getForm(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+	ticketTypeID, err := optionalIntQuery(ctx, "ticketTypeId")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	form, err := fc.formUC.GetForm(eventID, userID, ticketTypeID, ctx.Query("key"))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch registration form", form, true))
+}
+
+// @Summary Create or replace a registration form
+// @Description Saves the form schema for the event, or for one ticket type when ticket_type_id is set (organizer only)
+// @Tags registration_forms
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param form body dto.SaveRegistrationFormRequest true "Form schema"
+// @Success 200 {object} utils.Response{data=models.RegistrationForm}
+// @Failure 400 {object} utils.Response "Invalid form schema"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/form [put]
+// @Security BearerAuth
+func (fc *RegistrationFormController) saveForm(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.SaveRegistrationFormRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	form, err := fc.formUC.SaveForm(eventID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Registration form saved", form, true))
+}
+
+// @Summary Delete a registration form
+// @Tags registration_forms
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param ticketTypeId query int false "Ticket type ID; omit for the event-wide form"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid ID or form not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/form [delete]
+// @Security BearerAuth
+func (fc *RegistrationFormController) deleteForm(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+	ticketTypeID, err := optionalIntQuery(ctx, "ticketTypeId")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	if err := fc.formUC.DeleteForm(eventID, userID, currentUserRole(ctx), ticketTypeID); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Registration form deleted", nil, true))
+}
+
+// @Summary List registration forms of an event
+// @Description Retrieves the event-wide form and every ticket type form (organizer only)
+// @Tags registration_forms
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=[]models.RegistrationForm}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/forms [get]
+// @Security BearerAuth
+func (fc *RegistrationFormController) listForms(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	forms, err := fc.formUC.ListForms(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch registration forms", forms, true))
+}
+
+// @Summary Upload a file for a form field
+// @Description Stores a file for a "file" field; send the returned token as the answer when registering
+// @Tags registration_forms
+// @Accept multipart/form-data
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param field formData string true "Field key"
+// @Param file formData file true "File to upload"
+// @Param accessCode formData string false "Access code for unlisted or invite-only events"
+// @Success 201 {object} utils.Response{data=dto.FormUploadResponse}
+// @Failure 400 {object} utils.Response "Invalid upload"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/event/{id}/form/uploads [post]
+// @Security BearerAuth
+func (fc *RegistrationFormController) uploadFile(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("file is required", nil, false))
+		return
+	}
+
+	upload, err := fc.formUC.UploadFile(eventID, userID, ctx.PostForm("field"), ctx.PostForm("accessCode"), file)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.APIResponse("File uploaded", upload, true))
+}
+
+// @Summary Export registration form answers
+// @Description Retrieves every attendee's answers as JSON, or as a CSV file with format=csv (organizer only)
+// @Tags registration_forms
+// @Produce json
+// @Produce text/csv
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} utils.Response{data=[]models.RegistrationAnswer}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/event/{id}/form/answers [get]
+// @Security BearerAuth
+func (fc *RegistrationFormController) listAnswers(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	if ctx.Query("format") == "csv" {
+		// Cek akses dulu agar error masih bisa dikirim sebagai JSON sebelum header CSV ditulis
+		if _, err := fc.formUC.ListForms(eventID, userID, currentUserRole(ctx)); err != nil {
+			ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+			return
+		}
+
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%d-form-answers.csv"`, eventID))
+		ctx.Status(http.StatusOK)
+		if err := fc.formUC.ExportAnswersCSV(eventID, userID, currentUserRole(ctx), ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+		return
+	}
+
+	answers, err := fc.formUC.ListAnswers(eventID, userID, currentUserRole(ctx))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch form answers", answers, true))
+}
+
+// @Summary Get my form answers
+// @Description Retrieves the answers the authenticated user submitted when registering
+// @Tags registration_forms
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=models.RegistrationAnswer}
+// @Failure 400 {object} utils.Response "Invalid event ID or answers not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/event/{id}/form/answers/me [get]
+// @Security BearerAuth
+func (fc *RegistrationFormController) getMyAnswers(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	answer, err := fc.formUC.GetMyAnswers(eventID, userID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch form answers", answer, true))
+}
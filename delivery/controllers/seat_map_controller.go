@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SeatMapController struct {
+	seatMapUC usecase.SeatMapUsecase
+	rg        *gin.RouterGroup
+}
+
+func NewSeatMapController(seatMapUC usecase.SeatMapUsecase, rg *gin.RouterGroup) *SeatMapController {
+	return &SeatMapController{seatMapUC: seatMapUC, rg: rg}
+}
+
+func (sc *SeatMapController) Route() {
+	sc.rg.GET("/event/:id/seat-map", sc.getSeatMap)
+	sc.rg.PUT("/event/:id/seat-map", sc.setSeatMap)
+	sc.rg.POST("/event/:id/seat-holds", sc.holdSeat)
+	sc.rg.DELETE("/event/:id/seat-holds", sc.releaseHold)
+}
+
+// @Summary Get event seat map
+// @Description Returns the sections, rows and seats of an event with their pricing zone and live status (available, held, held_by_you, taken, blocked)
+// @Tags seat_maps
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=dto.SeatMapView}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Event has no seat map"
+// @Router /api/v1/event/{id}/seat-map [get]
+// @Security BearerAuth
+func (sc *SeatMapController) getSeatMap(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	seatMap, err := sc.seatMapUC.GetSeatMap(eventID, userID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch seat map", seatMap, true))
+}
+
+// @Summary Set event seat map
+// @Description Replaces the seat map of an event. Each pricing zone is tied to one of the event's ticket types; seats reference zones by name. Set copyFromEventId to reuse the layout of another event at the same venue. Not allowed once attendees hold seats (organizer or admin only)
+// @Tags seat_maps
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param payload body dto.SetSeatMapRequest true "Zones and seats"
+// @Success 200 {object} utils.Response{data=dto.SeatMapView}
+// @Failure 400 {object} utils.Response "Invalid input"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Failure 409 {object} utils.Response "Seats already registered"
+// @Router /api/v1/event/{id}/seat-map [put]
+// @Security BearerAuth
+func (sc *SeatMapController) setSeatMap(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.SetSeatMapRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	seatMap, err := sc.seatMapUC.SetSeatMap(eventID, userID, currentUserRole(ctx), payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Seat map saved", seatMap, true))
+}
+
+// @Summary Hold a seat
+// @Description Holds a seat for the current user for a limited time while they register. Any other seat the user holds for the event is released. Pass the seat ID as seatId when registering
+// @Tags seat_maps
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param payload body dto.HoldSeatRequest true "Seat to hold"
+// @Success 200 {object} utils.Response{data=dto.SeatHold}
+// @Failure 400 {object} utils.Response "Invalid input"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 409 {object} utils.Response "Seat is not available"
+// @Router /api/v1/event/{id}/seat-holds [post]
+// @Security BearerAuth
+func (sc *SeatMapController) holdSeat(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	var payload dto.HoldSeatRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	hold, err := sc.seatMapUC.HoldSeat(eventID, userID, payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Seat held", hold, true))
+}
+
+// @Summary Release seat hold
+// @Description Releases the seat the current user holds for the event
+// @Tags seat_maps
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/event/{id}/seat-holds [delete]
+// @Security BearerAuth
+func (sc *SeatMapController) releaseHold(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	if err := sc.seatMapUC.ReleaseHold(eventID, userID); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Seat hold released", nil, true))
+}
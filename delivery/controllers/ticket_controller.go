@@ -1,8 +1,8 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
-	"gatherly-app/models"
 	"gatherly-app/models/dto"
 	"gatherly-app/usecase"
 	"gatherly-app/utils"
@@ -27,29 +27,33 @@ func (tc *TicketController) Route() {
 }
 
 // @Summary Create tickets
-// @Description Creates a batch of tickets for an event
+// @Description Creates a batch of tickets for an event. price is in major units of the event currency (150000 = Rp150.000) and must be payable in that currency, e.g. whole rupiah for IDR; the response returns it in minor units
 // @Tags tickets
 // @Accept json
 // @Produce json
 // @Param authorization header string true "Bearer token"
-// @Param tickets body []model.Ticket true "List of tickets to create"
+// @Param tickets body []dto.CreateTicketRequest true "List of tickets to create"
 // @Success 200 {object} utils.Response
-// @Failure 400 {object} string "Invalid request body"
+// @Failure 400 {object} string "Invalid request body or price"
 // @Failure 401 {object} string "Unauthorized: Missing or invalid token"
 // @Failure 500 {object} string "Internal server error"
 // @Router /api/v1/ticket [post]
 // @Security BearerAuth
 func (tc *TicketController) createTicket(ctx *gin.Context) {
-	var payload []models.Ticket
+	var payload []dto.CreateTicketRequest
 
-	if err := ctx.ShouldBind(&payload); err != nil {
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
 		return
 	}
 
 	ticket, err := tc.ticketUseCase.CreateTicket(payload)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidTicket) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, utils.APIResponse(err.Error(), nil, false))
 		return
 	}
 
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"gatherly-app/models/dto"
 	"gatherly-app/usecase"
 	"gatherly-app/utils"
@@ -393,7 +394,9 @@ func (t *TransactionController) findTransactionByTicket(c *gin.Context) {
 // @Param notification body dto.MidtransNotification true "Midtrans Payment Notification"
 // @Success 200 {object} utils.Response "Success handle notification"
 // @Failure 400 {object} string "Invalid request payload"
+// @Failure 403 {object} string "Invalid signature"
 // @Failure 404 {object} string "No transactions found"
+// @Failure 409 {object} string "Amount or status does not match the transaction"
 // @Failure 500 {object} string "Internal server error"
 // @Router /api/v1/transaction/notification [post]
 func (t *TransactionController) handleNotification(c *gin.Context) {
@@ -405,7 +408,13 @@ func (t *TransactionController) handleNotification(c *gin.Context) {
 	}
 
 	err = t.transactionUsecase.HandleNotification(notification)
-	if err != nil && err.Error() == "record not found" {
+	if errors.Is(err, usecase.ErrInvalidSignature) {
+		c.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	} else if errors.Is(err, usecase.ErrAmountMismatch) || errors.Is(err, usecase.ErrInvalidStatusTransition) {
+		c.JSON(http.StatusConflict, gin.H{"err": err.Error()})
+		return
+	} else if err != nil && err.Error() == "record not found" {
 		c.JSON(http.StatusNotFound, gin.H{"err": "Transaction not found"})
 		return
 	} else if err != nil {
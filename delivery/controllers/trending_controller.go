@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrendingController struct {
+	trendingUC usecase.TrendingUsecase
+	rg         *gin.RouterGroup
+}
+
+func NewTrendingController(trendingUC usecase.TrendingUsecase, rg *gin.RouterGroup) *TrendingController {
+	return &TrendingController{trendingUC: trendingUC, rg: rg}
+}
+
+func (tc *TrendingController) Route() {
+	tc.rg.GET("/events/trending", tc.getTrending)
+}
+
+// @Summary Get trending events
+// @Description Retrieves events ranked by precomputed trending scores (registration velocity, paid conversion and views), optionally scoped by city or radius and category
+// @Tags events
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param category query string false "Event category"
+// @Param city query string false "City name; overrides the token location"
+// @Param radius query number false "Radius in kilometers around the city or token location"
+// @Param limit query int false "Number of events to return (default: 20)"
+// @Success 200 {object} dto.GeneralResponse{data=[]dto.TrendingEventDTO}
+// @Failure 400 {object} dto.ErrorResponse "Invalid query parameter"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /api/v1/events/trending [get]
+// @Security BearerAuth
+func (tc *TrendingController) getTrending(ctx *gin.Context) {
+	query := dto.TrendingEventQuery{
+		Category: ctx.Query("category"),
+		City:     ctx.Query("city"),
+	}
+
+	var err error
+	if radius := ctx.Query("radius"); radius != "" {
+		query.Radius, err = strconv.ParseFloat(radius, 64)
+		if err != nil || query.Radius < 0 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: "radius must be a positive number"})
+			return
+		}
+	}
+
+	if limit := ctx.Query("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{Error: "limit must be a positive integer"})
+			return
+		}
+	}
+
+	// Tanpa kota, radius dihitung dari lokasi di token
+	if query.City == "" && query.Radius > 0 {
+		latitudeValue, latitudeExists := ctx.Get("userLat")
+		longitudeValue, longitudeExists := ctx.Get("userLon")
+		if !latitudeExists || !longitudeExists {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User location not available in token"})
+			return
+		}
+
+		query.Latitude, query.Longitude, err = convertCoordinates(latitudeValue, longitudeValue)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	events, err := tc.trendingUC.GetTrending(query)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.GeneralResponse{
+		Message: "successfully get trending events",
+		Data:    events,
+	})
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"gatherly-app/models/dto"
 	"gatherly-app/usecase"
 	"net/http"
@@ -12,7 +13,9 @@ import (
 
 type UserController struct {
 	userUC   usecase.UserUsecase
+	guardUC  usecase.PurchaseGuardUsecase
 	validate *validator.Validate
+	rg       *gin.RouterGroup
 }
 
 // Getter untuk userUC
@@ -25,17 +28,27 @@ func (uc *UserController) GetValidate() *validator.Validate {
 	return uc.validate
 }
 
-func NewUserController(userUC usecase.UserUsecase, rg *gin.RouterGroup) {
-	ctrl := &UserController{
+func NewUserController(userUC usecase.UserUsecase, guardUC usecase.PurchaseGuardUsecase, rg *gin.RouterGroup) *UserController {
+	return &UserController{
 		userUC:   userUC,
+		guardUC:  guardUC,
 		validate: validator.New(),
+		rg:       rg,
 	}
+}
+
+// RegisterPublicRoutes: signup dan profil publik, tanpa token
+func (ctl *UserController) RegisterPublicRoutes() {
+	ctl.rg.GET("/signup-challenge", ctl.GetSignupChallenge)
+	ctl.rg.POST("/users", ctl.CreateUser)
+	ctl.rg.GET("/users/:id", ctl.GetUserByID)
+	ctl.rg.GET("/users", ctl.GetAllUsers)
+}
 
-	rg.POST("/users", ctrl.CreateUser)
-	rg.GET("/users/:id", ctrl.GetUserByID)
-	rg.GET("/users", ctrl.GetAllUsers)
-	rg.PUT("/users/:id", ctrl.UpdateUser)
-	rg.DELETE("/users/:id", ctrl.DeleteUser)
+// Route: ubah dan hapus akun, hanya pemilik akun atau admin
+func (ctl *UserController) Route() {
+	ctl.rg.PUT("/users/:id", ctl.UpdateUser)
+	ctl.rg.DELETE("/users/:id", ctl.DeleteUser)
 }
 
 // @Summary Create a new user
@@ -46,6 +59,8 @@ func NewUserController(userUC usecase.UserUsecase, rg *gin.RouterGroup) {
 // @Param user body dto.CreateUserRequest true "User Data"
 // @Success 201 {object} dto.UserResponse
 // @Failure 400 {object} string "Invalid request body"
+// @Failure 403 {object} string "Challenge missing or invalid"
+// @Failure 429 {object} string "Too many signups from this network"
 // @Router /api/v1/users [post]
 func (ctl *UserController) CreateUser(c *gin.Context) {
 	var input dto.CreateUserRequest
@@ -54,6 +69,11 @@ func (ctl *UserController) CreateUser(c *gin.Context) {
 		return
 	}
 
+	if err := ctl.guardUC.CheckSignup(c.ClientIP(), input.Challenge); err != nil {
+		c.JSON(usecaseErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := ctl.validate.Struct(input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -68,6 +88,23 @@ func (ctl *UserController) CreateUser(c *gin.Context) {
 	c.JSON(http.StatusCreated, user)
 }
 
+// @Summary Get a signup challenge
+// @Description Returns an anti-bot challenge bound to the caller's IP. Send the token and its solution as "challenge" when creating a user if signups require a challenge
+// @Tags users
+// @Produce json
+// @Success 200 {object} service.Challenge
+// @Failure 500 {object} string "Failed to issue challenge"
+// @Router /api/v1/signup-challenge [get]
+func (ctl *UserController) GetSignupChallenge(c *gin.Context) {
+	challenge, err := ctl.guardUC.IssueSignupChallenge(c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
 // @Summary Get user by ID
 // @Description Retrieves a specific user
 // @Tags users
@@ -112,14 +149,17 @@ func (ctl *UserController) GetAllUsers(c *gin.Context) {
 }
 
 // @Summary Update user by ID
-// @Description Modifies an existing user
+// @Description Modifies your own account, or any account as an admin. Role, age and email can only be changed by an admin
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Param user body dto.UpdateUserRequest true "Updated User Data"
 // @Success 200 {object} dto.UserResponse
 // @Failure 400 {object} string "Invalid request body"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Not your account, or field requires admin"
 // @Router /api/v1/users/{id} [put]
 func (ctl *UserController) UpdateUser(c *gin.Context) {
 	idParam := c.Param("id")
@@ -140,9 +180,14 @@ func (ctl *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := ctl.userUC.UpdateUser(id, input)
+	callerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := ctl.userUC.UpdateUser(id, callerID, currentUserRole(c), input)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(usecaseErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -150,11 +195,14 @@ func (ctl *UserController) UpdateUser(c *gin.Context) {
 }
 
 // @Summary Delete user by ID
-// @Description Removes a user from the database
+// @Description Removes your own account, or any account as an admin
 // @Tags users
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Success 200 {object} string "User deleted successfully"
 // @Failure 400 {object} string "Invalid user ID"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Not your account"
 // @Failure 500 {object} string "Internal server error"
 // @Router /api/v1/users/{id} [delete]
 func (ctl *UserController) DeleteUser(c *gin.Context) {
@@ -165,7 +213,16 @@ func (ctl *UserController) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = ctl.userUC.DeleteUser(id)
+	callerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	err = ctl.userUC.DeleteUser(id, callerID, currentUserRole(c))
+	if errors.Is(err, usecase.ErrUserAccessDenied) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
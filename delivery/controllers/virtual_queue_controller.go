@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// queueStatusPollInterval: status tetap dihitung ulang berkala karena posisi juga maju
+	// saat user lain keluar antrean, yang tidak dikirim lewat pubsub
+	queueStatusPollInterval = 5 * time.Second
+	queueHeartbeatInterval  = 25 * time.Second
+)
+
+type VirtualQueueController struct {
+	queueUC usecase.VirtualQueueUsecase
+	rg      *gin.RouterGroup
+}
+
+func NewVirtualQueueController(queueUC usecase.VirtualQueueUsecase, rg *gin.RouterGroup) *VirtualQueueController {
+	return &VirtualQueueController{queueUC: queueUC, rg: rg}
+}
+
+func (qc *VirtualQueueController) Route() {
+	qc.rg.POST("/event/:id/queue", qc.join)
+	qc.rg.GET("/event/:id/queue", qc.status)
+}
+
+// RegisterPublicRoutes mendaftarkan endpoint status antrean yang memakai token antrean,
+// bukan JWT, agar halaman antrean bisa polling tanpa membebani auth.
+func (qc *VirtualQueueController) RegisterPublicRoutes() {
+	qc.rg.GET("/event/:id/queue/status", qc.statusByToken)
+	qc.rg.GET("/event/:id/queue/stream", qc.stream)
+}
+
+// @Summary Join the waiting queue
+// @Description Joins the waiting queue of an event that has queue mode enabled. Joining again keeps the original position; a user whose checkout window expired goes to the back of the queue. The response contains a signed queue token for the public status endpoints. Once the status is "admitted" the user can register until expires_at
+// @Tags virtual_queue
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=dto.QueueStatus}
+// @Failure 400 {object} utils.Response "Invalid event ID or event does not use a queue"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Router /api/v1/event/{id}/queue [post]
+// @Security BearerAuth
+func (qc *VirtualQueueController) join(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	status, err := qc.queueUC.Join(eventID, userID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Joined the queue", status, true))
+}
+
+// @Summary Get waiting queue status
+// @Description Returns the current user's status and position in the event's waiting queue
+// @Tags virtual_queue
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=dto.QueueStatus}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Not in the queue"
+// @Router /api/v1/event/{id}/queue [get]
+// @Security BearerAuth
+func (qc *VirtualQueueController) status(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	status, err := qc.queueUC.Status(eventID, userID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch queue status", status, true))
+}
+
+// @Summary Get waiting queue status by token
+// @Description Returns position, estimated wait and checkout deadline for the queue token returned when joining. Does not require login
+// @Tags virtual_queue
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param token query string true "Queue token"
+// @Success 200 {object} utils.Response{data=dto.QueueStatus}
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} utils.Response "Invalid queue token"
+// @Failure 404 {object} utils.Response "Queue entry no longer exists"
+// @Router /api/v1/event/{id}/queue/status [get]
+func (qc *VirtualQueueController) statusByToken(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+
+	status, err := qc.queueUC.StatusByToken(eventID, ctx.Query("token"))
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch queue status", status, true))
+}
+
+// @Summary Stream waiting queue status
+// @Description Server-Sent Events stream of the queue status for a queue token. A "queue.status" event is sent on connect and whenever the status or position changes; the stream ends once the user has completed checkout
+// @Tags virtual_queue
+// @Produce text/event-stream
+// @Param id path int true "Event ID"
+// @Param token query string true "Queue token"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 400 {object} utils.Response "Invalid event ID"
+// @Failure 401 {object} utils.Response "Invalid queue token"
+// @Failure 404 {object} utils.Response "Queue entry no longer exists"
+// @Router /api/v1/event/{id}/queue/stream [get]
+func (qc *VirtualQueueController) stream(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid event ID", nil, false))
+		return
+	}
+	token := ctx.Query("token")
+
+	// Subscribe sebelum status awal agar admission di antaranya tidak terlewat
+	events, unsubscribe := qc.queueUC.Subscribe(eventID)
+	defer unsubscribe()
+
+	status, err := qc.queueUC.StatusByToken(eventID, token)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.SSEvent("queue.status", status)
+	ctx.Writer.Flush()
+
+	poll := time.NewTicker(queueStatusPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(queueHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	last := status
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case _, open := <-events:
+			if !open {
+				return false
+			}
+		case <-poll.C:
+		case <-heartbeat.C:
+			io.WriteString(w, ": ping\n\n")
+			return true
+		}
+
+		current, err := qc.queueUC.StatusByToken(eventID, token)
+		if err != nil {
+			ctx.SSEvent("error", gin.H{"message": err.Error()})
+			return false
+		}
+		if queueStatusChanged(last, current) {
+			ctx.SSEvent("queue.status", current)
+			last = current
+		}
+		return current.Status != models.QueueCompleted
+	})
+}
+
+func queueStatusChanged(previous, current *dto.QueueStatus) bool {
+	return previous.Status != current.Status || previous.Position != current.Position ||
+		previous.EstimatedWait != current.EstimatedWait
+}
@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"gatherly-app/models/dto"
+	"gatherly-app/usecase"
+	"gatherly-app/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	webhookUC usecase.WebhookUsecase
+	rg        *gin.RouterGroup
+}
+
+func NewWebhookController(webhookUC usecase.WebhookUsecase, rg *gin.RouterGroup) *WebhookController {
+	return &WebhookController{webhookUC: webhookUC, rg: rg}
+}
+
+func (wc *WebhookController) Route() {
+	wc.rg.POST("/webhooks", wc.createEndpoint)
+	wc.rg.GET("/webhooks", wc.listEndpoints)
+	wc.rg.PATCH("/webhooks/:id", wc.updateEndpoint)
+	wc.rg.DELETE("/webhooks/:id", wc.deleteEndpoint)
+	wc.rg.GET("/webhooks/:id/deliveries", wc.listDeliveries)
+	wc.rg.GET("/webhooks/:id/deliveries/:deliveryId/attempts", wc.listAttempts)
+	wc.rg.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", wc.redeliver)
+}
+
+// @Summary Register a webhook endpoint
+// @Description Registers a URL that receives HMAC-signed POST requests for the subscribed event types. Omit event_id to receive events of all your events. The signing secret is only returned in this response; verify the X-Gatherly-Signature header ("t=<unix>,v1=<hex>") as HMAC-SHA256 of "<t>.<body>". The URL must resolve to a public address; redirects are not followed
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param webhook body dto.CreateWebhookRequest true "Endpoint URL, scope and event types"
+// @Success 201 {object} utils.Response{data=dto.WebhookEndpointCreatedResponse}
+// @Failure 400 {object} utils.Response "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 403 {object} utils.Response "Not the event organizer"
+// @Router /api/v1/webhooks [post]
+// @Security BearerAuth
+func (wc *WebhookController) createEndpoint(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var payload dto.CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	endpoint, err := wc.webhookUC.CreateEndpoint(userID, payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.APIResponse("Webhook endpoint created", endpoint, true))
+}
+
+// @Summary List webhook endpoints
+// @Tags webhooks
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=[]models.WebhookEndpoint}
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api/v1/webhooks [get]
+// @Security BearerAuth
+func (wc *WebhookController) listEndpoints(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	endpoints, err := wc.webhookUC.ListEndpoints(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch webhook endpoints", endpoints, true))
+}
+
+// @Summary Update a webhook endpoint
+// @Description Changes the URL, event types or description, or pauses the endpoint with active=false
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Endpoint ID"
+// @Param webhook body dto.UpdateWebhookRequest true "Fields to update"
+// @Success 200 {object} utils.Response{data=models.WebhookEndpoint}
+// @Failure 400 {object} utils.Response "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Endpoint not found"
+// @Router /api/v1/webhooks/{id} [patch]
+// @Security BearerAuth
+func (wc *WebhookController) updateEndpoint(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	endpointID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid endpoint ID", nil, false))
+		return
+	}
+
+	var payload dto.UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	endpoint, err := wc.webhookUC.UpdateEndpoint(userID, endpointID, payload)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Webhook endpoint updated", endpoint, true))
+}
+
+// @Summary Delete a webhook endpoint
+// @Description Deletes the endpoint together with its delivery history
+// @Tags webhooks
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Endpoint ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response "Invalid endpoint ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Endpoint not found"
+// @Router /api/v1/webhooks/{id} [delete]
+// @Security BearerAuth
+func (wc *WebhookController) deleteEndpoint(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	endpointID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid endpoint ID", nil, false))
+		return
+	}
+
+	if err := wc.webhookUC.DeleteEndpoint(userID, endpointID); err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Webhook endpoint deleted", nil, true))
+}
+
+// @Summary List webhook deliveries
+// @Description Returns the latest 100 deliveries of the endpoint with their status
+// @Tags webhooks
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Endpoint ID"
+// @Success 200 {object} utils.Response{data=[]models.WebhookDelivery}
+// @Failure 400 {object} utils.Response "Invalid endpoint ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Endpoint not found"
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+// @Security BearerAuth
+func (wc *WebhookController) listDeliveries(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	endpointID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid endpoint ID", nil, false))
+		return
+	}
+
+	deliveries, err := wc.webhookUC.ListDeliveries(userID, endpointID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch webhook deliveries", deliveries, true))
+}
+
+// @Summary List delivery attempts
+// @Description Returns every HTTP attempt made for a delivery, including response status, body excerpt and duration
+// @Tags webhooks
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Endpoint ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 200 {object} utils.Response{data=[]models.WebhookAttempt}
+// @Failure 400 {object} utils.Response "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Endpoint or delivery not found"
+// @Router /api/v1/webhooks/{id}/deliveries/{deliveryId}/attempts [get]
+// @Security BearerAuth
+func (wc *WebhookController) listAttempts(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	endpointID, err1 := strconv.Atoi(ctx.Param("id"))
+	deliveryID, err2 := strconv.Atoi(ctx.Param("deliveryId"))
+	if err1 != nil || err2 != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid endpoint or delivery ID", nil, false))
+		return
+	}
+
+	attempts, err := wc.webhookUC.ListAttempts(userID, endpointID, deliveryID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.APIResponse("Success fetch delivery attempts", attempts, true))
+}
+
+// @Summary Redeliver a webhook
+// @Description Immediately sends the delivery again with the original body and returns the attempt result. A failed manual attempt does not change the automatic retry schedule
+// @Tags webhooks
+// @Produce json
+// @Param authorization header string true "Bearer token"
+// @Param id path int true "Endpoint ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 200 {object} utils.Response{data=dto.WebhookRedeliveryResponse}
+// @Failure 400 {object} utils.Response "Invalid ID or delivery in progress"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized: Missing or invalid token"
+// @Failure 404 {object} utils.Response "Endpoint or delivery not found"
+// @Router /api/v1/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+// @Security BearerAuth
+func (wc *WebhookController) redeliver(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	endpointID, err1 := strconv.Atoi(ctx.Param("id"))
+	deliveryID, err2 := strconv.Atoi(ctx.Param("deliveryId"))
+	if err1 != nil || err2 != nil {
+		ctx.JSON(http.StatusBadRequest, utils.APIResponse("Invalid endpoint or delivery ID", nil, false))
+		return
+	}
+
+	result, err := wc.webhookUC.Redeliver(ctx, userID, endpointID, deliveryID)
+	if err != nil {
+		ctx.JSON(usecaseErrorStatus(err), utils.APIResponse(err.Error(), nil, false))
+		return
+	}
+
+	message := "Webhook redelivered"
+	if result.Attempt.Error != "" {
+		message = "Webhook redelivery failed: " + result.Attempt.Error
+	}
+	ctx.JSON(http.StatusOK, utils.APIResponse(message, result, true))
+}
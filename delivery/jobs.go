@@ -0,0 +1,42 @@
+package delivery
+
+import (
+	"log"
+	"time"
+)
+
+// startJobs menjalankan background job periodik milik server.
+func (s *Server) startJobs() {
+	go runPeriodically("trending-refresh", s.cfg.TrendingRefreshInterval, s.trendingUC.RefreshScores)
+	go runPeriodically("broadcast-dispatch", s.cfg.BroadcastDispatchInterval, s.broadcastUC.DispatchPending)
+	go runPeriodically("notification-dispatch", s.cfg.NotificationDispatchInterval, s.notificationUC.DispatchOutbox)
+	go runPeriodically("notification-scheduler", s.cfg.NotificationScheduleInterval, s.notificationUC.ScheduleReminders)
+	go runPeriodically("webhook-dispatch", s.cfg.WebhookDispatchInterval, s.webhookUC.DispatchDue)
+	go runPeriodically("event-import", s.cfg.ImportDispatchInterval, s.importUC.ProcessQueued)
+	go runPeriodically("payment-reconciliation", s.cfg.ReconcileInterval, s.reconcileUC.RunScheduled)
+	go runPeriodically("payment-expiry", s.cfg.PaymentExpiryInterval, s.paymentExpiryUC.ExpireStale)
+	go runPeriodically("group-booking-expiry", s.cfg.PaymentExpiryInterval, s.groupBookingUC.ExpireOverdue)
+	go runPeriodically("ledger-invariants", s.cfg.LedgerCheckInterval, s.ledgerUC.CheckInvariants)
+	go runPeriodically("virtual-queue-admission", s.cfg.PurchaseGuard.QueueAdmitInterval, s.queueUC.AdmitBatches)
+	go runPeriodically("rate-limit-cleanup", s.cfg.PurchaseGuard.CleanupInterval, s.guardUC.CleanupCounters)
+}
+
+// runPeriodically menjalankan job sekali di awal lalu setiap interval.
+// Error hanya dicatat agar job tetap berjalan pada putaran berikutnya.
+func runPeriodically(name string, interval time.Duration, job func() error) {
+	run := func() {
+		start := time.Now()
+		if err := job(); err != nil {
+			log.Printf("job %s failed: %v", name, err)
+			return
+		}
+		log.Printf("job %s finished in %s", name, time.Since(start))
+	}
+
+	run()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		run()
+	}
+}
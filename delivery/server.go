@@ -27,14 +27,38 @@ import (
 
 type Server struct {
 	db              *gorm.DB
+	cfg             *config.Config
 	userUC          usecase.UserUsecase
 	ticketUC        usecase.TicketUseCase
 	eventAttendeeUC usecase.EventAttendeeUseCase
 	eventUC         usecase.EventsUsecase
 	transactionUC   usecase.TransactionUsecase
 	authUC          usecase.AuthenticationUseCase
+	calendarUC      usecase.CalendarUsecase
+	trendingUC      usecase.TrendingUsecase
+	interestUC      usecase.InterestUsecase
+	invitationUC    usecase.InvitationUsecase
+	formUC          usecase.RegistrationFormUsecase
+	importUC        usecase.EventImportUsecase
+	broadcastUC     usecase.BroadcastUsecase
+	inboxUC         usecase.InboxUsecase
+	notificationUC  usecase.NotificationUsecase
+	eventStatsUC    usecase.EventStatsUsecase
+	webhookUC       usecase.WebhookUsecase
+	analyticsUC     usecase.AnalyticsUsecase
+	reconcileUC     usecase.ReconciliationUsecase
+	paymentExpiryUC usecase.PaymentExpiryUsecase
+	ledgerUC        usecase.LedgerUsecase
+	payoutUC        usecase.PayoutUsecase
+	invoiceUC       usecase.InvoiceUsecase
+	groupBookingUC  usecase.GroupBookingUsecase
+	seatMapUC       usecase.SeatMapUsecase
+	guardUC         usecase.PurchaseGuardUsecase
+	queueUC         usecase.VirtualQueueUsecase
+	eligibilityUC   usecase.EligibilityUsecase
 	jwtService      service.JwtService
 	midtransService service.MidtransService
+	exchangeRates   *service.ExchangeRates
 	engine          *gin.Engine
 	host            string
 }
@@ -49,51 +73,181 @@ func (s *Server) initRoute() {
 	authMiddleware := middleware.NewAuthMiddleware(s.jwtService)
 
 	// Public routes
-	controllers.NewUserController(s.userUC, rgV1)
+	controllers.NewUserController(s.userUC, s.guardUC, rgV1).RegisterPublicRoutes()
 	controllers.NewTransactionController(s.transactionUC, rgV1).RegisterPublicRoutes()
+	controllers.NewCalendarController(s.calendarUC, rgV1).RegisterPublicRoutes()
+	controllers.NewVirtualQueueController(s.queueUC, rgV1).RegisterPublicRoutes()
 
 	// Authenticated routes
 	authGroup := rgV1.Group("")
 	authGroup.Use(authMiddleware.RequireToken())
 	{
+		controllers.NewUserController(s.userUC, s.guardUC, authGroup).Route()
 		controllers.NewTicketController(s.ticketUC, authGroup).Route()
 		controllers.NewEventAttendeeController(s.eventAttendeeUC, authGroup).Route()
-		controllers.NewEventsController(s.eventUC, authGroup).Route()
+		controllers.NewEventsController(s.eventUC, s.exchangeRates, authGroup).Route()
 		controllers.NewTransactionController(s.transactionUC, authGroup).Route()
+		controllers.NewCalendarController(s.calendarUC, authGroup).Route()
+		controllers.NewTrendingController(s.trendingUC, authGroup).Route()
+		controllers.NewInterestController(s.interestUC, authGroup).Route()
+		controllers.NewInvitationController(s.invitationUC, authGroup).Route()
+		controllers.NewRegistrationFormController(s.formUC, authGroup).Route()
+		controllers.NewEventImportController(s.importUC, authGroup).Route()
+		controllers.NewBroadcastController(s.broadcastUC, authGroup).Route()
+		controllers.NewInboxController(s.inboxUC, authGroup).Route()
+		controllers.NewNotificationController(s.notificationUC, authGroup).Route()
+		controllers.NewEventStatsController(s.eventStatsUC, authGroup).Route()
+		controllers.NewWebhookController(s.webhookUC, authGroup).Route()
+		controllers.NewAnalyticsController(s.analyticsUC, authGroup).Route()
+		controllers.NewAdminReportController(s.reconcileUC, authGroup).Route()
+		controllers.NewLedgerController(s.ledgerUC, authGroup).Route()
+		controllers.NewPayoutController(s.payoutUC, authGroup).Route()
+		controllers.NewInvoiceController(s.invoiceUC, authGroup).Route()
+		controllers.NewGroupBookingController(s.groupBookingUC, authGroup).Route()
+		controllers.NewSeatMapController(s.seatMapUC, authGroup).Route()
+		controllers.NewPurchaseGuardController(s.guardUC, authGroup).Route()
+		controllers.NewVirtualQueueController(s.queueUC, authGroup).Route()
+		controllers.NewEligibilityController(s.eligibilityUC, authGroup).Route()
+	}
+
+	// Streaming routes; token juga boleh lewat query karena EventSource tidak bisa mengirim header
+	streamGroup := rgV1.Group("")
+	streamGroup.Use(authMiddleware.RequireStreamToken())
+	{
+		controllers.NewInboxController(s.inboxUC, streamGroup).RegisterStreamRoutes()
+		controllers.NewEventStatsController(s.eventStatsUC, streamGroup).RegisterStreamRoutes()
 	}
 }
 
 func (s *Server) initMigration() {
+	if err := s.migrateMinorUnits(); err != nil {
+		log.Fatal("Failed to migrate: ", err)
+	}
+
 	err := s.db.AutoMigrate(
 		&models.Ticket{},
 		&models.Transactions{},
 		&models.User{},
 		&models.Event{},
+		&models.CalendarFeed{},
+		&models.EventView{},
+		&models.EventImportJob{},
+		&models.EventTrendingScore{},
+		&models.EventBookmark{},
+		&models.Follow{},
+		&models.EventInvitation{},
+		&models.EventInviteLink{},
+		&models.RegistrationForm{},
+		&models.RegistrationAnswer{},
+		&models.FormUpload{},
+		&models.Broadcast{},
+		&models.BroadcastDelivery{},
+		&models.InboxMessage{},
+		&models.NotificationPreference{},
+		&models.NotificationOutbox{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.WebhookAttempt{},
+		&models.RegistrationCancellation{},
+		&models.ReconciliationRun{},
+		&models.ReconciliationDiscrepancy{},
+		&models.LedgerAccount{},
+		&models.JournalEntry{},
+		&models.LedgerPosting{},
+		&models.FeeRule{},
+		&models.Payout{},
+		&models.PayoutBatch{},
+		&models.EventTaxSetting{},
+		&models.OrganizerLegalProfile{},
+		&models.Invoice{},
+		&models.InvoiceLine{},
+		&models.GroupBooking{},
+		&models.GroupBookingSeat{},
+		&models.SeatMap{},
+		&models.SeatZone{},
+		&models.Seat{},
+		&models.EventPurchasePolicy{},
+		&models.RateLimitCounter{},
+		&models.QueueEntry{},
+		&models.EligibilityRule{},
+		&models.UserGroup{},
+		&models.UserGroupMember{},
 	)
 
 	if err != nil {
 		log.Fatal("Failed to migrate: ", err)
 	}
 
+	// Ledger hanya boleh ditambah; perubahan ditolak oleh trigger di database
+	if err := s.db.Exec(repositories.LedgerAppendOnlySQL).Error; err != nil {
+		log.Fatal("Failed to migrate: ", err)
+	}
+
 	s.db.Migrator().CreateConstraint(&models.Transactions{}, "fk_transactions_users")
 	s.db.Migrator().CreateConstraint(&models.Transactions{}, "foreignKey")
 
+	// event_attendees dibuat dari DDL (tanpa primary key gorm), jadi kolom baru ditambahkan manual
+	for _, column := range []string{"CheckedInAt", "GroupBookingID", "PurchaserID", "SeatID", "SeatLabel"} {
+		if !s.db.Migrator().HasColumn(&models.EventAttendee{}, column) {
+			if err := s.db.Migrator().AddColumn(&models.EventAttendee{}, column); err != nil {
+				log.Fatal("Failed to migrate: ", err)
+			}
+		}
+	}
+	// Satu kursi hanya boleh dimiliki satu pendaftaran
+	if err := s.db.Exec(repositories.SeatAttendeeIndexSQL).Error; err != nil {
+		log.Fatal("Failed to migrate: ", err)
+	}
+	// Response body webhook tidak lagi disimpan; hapus isi lama yang bisa berasal dari layanan internal
+	if s.db.Migrator().HasColumn(&models.WebhookAttempt{}, "response_body") {
+		if err := s.db.Migrator().DropColumn(&models.WebhookAttempt{}, "response_body"); err != nil {
+			log.Fatal("Failed to migrate: ", err)
+		}
+	}
+
 	log.Println("Migrated Successfully")
 }
 
-func (s *Server) Run() {
-	s.engine.Use(func(c *gin.Context) {
-		// Middleware untuk mendapatkan IP asli (jika behind proxy)
-		if forwarded := c.GetHeader("X-Forwarded-For"); forwarded != "" {
-			c.Set("client_ip", forwarded)
-		} else {
-			c.Set("client_ip", c.ClientIP())
+// migrateMinorUnits mengubah nominal lama ke minor unit sebelum AutoMigrate menambah kolom currency:
+// amount transaksi dulu rupiah desimal dan harga tiket rupiah bulat. Kolom currency yang belum ada
+// menandakan data belum dikonversi, jadi langkah ini hanya berjalan sekali.
+func (s *Server) migrateMinorUnits() error {
+	migrator := s.db.Migrator()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if migrator.HasTable(&models.Event{}) && !migrator.HasColumn(&models.Event{}, "Currency") && migrator.HasTable(&models.Ticket{}) {
+			// Lebarkan kolom dulu: harga di atas ~21 juta rupiah melewati batas int4 setelah dikali 100
+			if err := tx.Exec("ALTER TABLE tickets ALTER COLUMN price TYPE bigint").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE tickets SET price = price * 100").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("ALTER TABLE events ADD COLUMN currency varchar(3) NOT NULL DEFAULT 'IDR'").Error; err != nil {
+				return err
+			}
 		}
-		c.Next()
+		if migrator.HasTable(&models.Transactions{}) && !migrator.HasColumn(&models.Transactions{}, "Currency") {
+			if err := tx.Exec("ALTER TABLE transactions ALTER COLUMN amount TYPE bigint USING ROUND(amount * 100)").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("ALTER TABLE transactions ADD COLUMN currency varchar(3) NOT NULL DEFAULT 'IDR'").Error; err != nil {
+				return err
+			}
+		}
+		return nil
 	})
+}
+
+func (s *Server) Run() {
+	// ClientIP hanya membaca X-Forwarded-For dari proxy yang dipercaya; tanpa TRUSTED_PROXIES
+	// yang dipakai adalah alamat koneksi langsung, supaya rate limit per IP tidak bisa dipalsukan
+	if err := s.engine.SetTrustedProxies(s.cfg.TrustedProxies); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	s.initRoute()     // Inisialisasi routing
 	s.initMigration() // Jalankan migrasi
+	s.startJobs()     // Jalankan background job
 
 	if err := s.engine.Run(s.host); err != nil {
 		log.Fatalf("server not running on host %s, because error %v", s.host, err)
@@ -111,18 +265,24 @@ func NewServer() *Server {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.Database)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("Database connection error: %v", err)
 	}
 
 	jwtService := service.NewJwtService(cfg.TokenConfig)
 
+	exchangeRates, err := service.LoadExchangeRates(cfg.ExchangeRatesFile)
+	if err != nil {
+		log.Fatalf("Failed to load exchange rates: %v", err)
+	}
+
 	client := resty.New()
 	midtransService := service.NewMidtransService(client, cfg.MidtransServerKey)
+	// Client terpisah untuk webhook: timeout pendek dan redirect tidak diikuti
+	webhookSender := service.NewWebhookSender(resty.New().
+		SetTimeout(cfg.WebhookTimeout).
+		SetRedirectPolicy(resty.NoRedirectPolicy()))
 
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
@@ -130,20 +290,89 @@ func NewServer() *Server {
 	ticketRepo := repositories.NewTicketRepository(db)
 	eventAttendeeRepo := repositories.MakeNewEventAttendeeRepository(db)
 	transactionRepo := repositories.NewTransactionRepository(db)
+	calendarFeedRepo := repositories.NewCalendarFeedRepository(db)
+	trendingRepo := repositories.NewTrendingRepository(db)
+	bookmarkRepo := repositories.NewBookmarkRepository(db)
+	followRepo := repositories.NewFollowRepository(db)
+	invitationRepo := repositories.NewInvitationRepository(db)
+	formRepo := repositories.NewRegistrationFormRepository(db)
+	broadcastRepo := repositories.NewBroadcastRepository(db)
+	inboxRepo := repositories.NewInboxRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
+	analyticsRepo := repositories.NewAnalyticsRepository(db)
+	reconciliationRepo := repositories.NewReconciliationRepository(db)
+	advisoryLocker := repositories.NewAdvisoryLocker(db)
+	ledgerRepo := repositories.NewLedgerRepository(db)
+	payoutRepo := repositories.NewPayoutRepository(db)
+	invoiceRepo := repositories.NewInvoiceRepository(db)
+	groupBookingRepo := repositories.NewGroupBookingRepository(db)
+	seatMapRepo := repositories.NewSeatMapRepository(db)
+	purchaseGuardRepo := repositories.NewPurchaseGuardRepository(db)
+	virtualQueueRepo := repositories.NewVirtualQueueRepository(db)
+	eligibilityRepo := repositories.NewEligibilityRepository(db)
+
+	// Pub/sub in-process untuk push real-time ke client yang terhubung
+	pubsub := service.NewMemoryPubSub()
+
+	// Message channels; email hanya aktif jika SMTP dikonfigurasi
+	messageChannels := []service.MessageChannel{service.NewInboxChannel(inboxRepo, pubsub)}
+	if cfg.SMTP.Host != "" {
+		messageChannels = append(messageChannels, service.NewSMTPChannel(cfg.SMTP))
+	} else {
+		log.Println("SMTP_HOST is not set, email channel disabled")
+	}
 
 	// Initialize use cases
 	userUsecase := usecase.NewUserUsecase(userRepo)
-	eventUsecase := usecase.NewEventUsecase(eventRepo, eventAttendeeRepo)
-	ticketUseCase := usecase.NewTicketUseCase(ticketRepo)
-	transactionUseCase := usecase.NewTransactionUsecase(transactionRepo, midtransService)
-	eventAttendeeUseCase := usecase.NewEventAttendeeUseCase(eventAttendeeRepo, eventRepo, ticketRepo, transactionUseCase)
+	notificationUseCase := usecase.NewNotificationUsecase(notificationRepo, userRepo, cfg.PaymentExpiry, messageChannels...)
+	eventStatsUseCase := usecase.NewEventStatsUsecase(eventAttendeeRepo, eventRepo, pubsub)
+	webhookUseCase := usecase.NewWebhookUsecase(webhookRepo, eventRepo, eventAttendeeRepo, userRepo, webhookSender)
+	analyticsUseCase := usecase.NewAnalyticsUsecase(analyticsRepo, eventRepo)
+	invitationUseCase := usecase.NewInvitationUsecase(invitationRepo, eventRepo, userRepo)
+	eventUsecase := usecase.NewEventUsecase(eventRepo, eventAttendeeRepo, invitationUseCase, notificationUseCase, midtransService)
+	ticketUseCase := usecase.NewTicketUseCase(ticketRepo, eventRepo)
+	ledgerUseCase := usecase.NewLedgerUsecase(ledgerRepo, eventRepo)
+	bankFormat, err := usecase.LoadBankFileFormat(cfg.Payout.BankFormatFile)
+	if err != nil {
+		log.Fatalf("Failed to load payout bank format: %v", err)
+	}
+	defaultFee := models.FeeRule{PercentBps: cfg.Payout.DefaultFeePercentBps, FixedAmount: cfg.Payout.DefaultFeeFixed}
+	payoutUseCase := usecase.NewPayoutUsecase(payoutRepo, ledgerRepo, ledgerUseCase, eventRepo, defaultFee, cfg.Payout.MinPayoutAmount, bankFormat)
+	invoiceUseCase := usecase.NewInvoiceUsecase(invoiceRepo, eventRepo, userRepo)
+	transactionUseCase := usecase.NewTransactionUsecase(transactionRepo, midtransService, notificationUseCase, pubsub, eventStatsUseCase, webhookUseCase, ledgerUseCase, payoutUseCase, invoiceUseCase)
+	reconciliationUseCase := usecase.NewReconciliationUsecase(reconciliationRepo, midtransService, transactionUseCase, cfg.PaymentExpiry, cfg.ReconcileLookback)
+	seatMapUseCase := usecase.NewSeatMapUsecase(seatMapRepo, eventRepo, ticketRepo, cfg.SeatHold)
+	guard := cfg.PurchaseGuard
+	challengeProvider, err := service.NewChallengeProvider(guard.ChallengeProvider, guard.ChallengeSecret, guard.ChallengeDifficulty, guard.ChallengeTTL)
+	if err != nil {
+		log.Fatalf("Failed to configure challenge provider: %v", err)
+	}
+	guardUseCase := usecase.NewPurchaseGuardUsecase(purchaseGuardRepo, eventRepo, challengeProvider, guard.ChallengeTTL,
+		usecase.AttemptLimit(guard.RegisterPerUser), usecase.AttemptLimit(guard.RegisterPerIP), usecase.AttemptLimit(guard.SignupPerIP), guard.SignupChallenge)
+	queueTokens := service.NewQueueTokenSigner(guard.QueueTokenSecret)
+	queueUseCase := usecase.NewVirtualQueueUsecase(virtualQueueRepo, purchaseGuardRepo, eventRepo, advisoryLocker, queueTokens, pubsub, guard.QueueAdmitInterval)
+	eligibilityUseCase := usecase.NewEligibilityUsecase(eligibilityRepo, eventRepo, ticketRepo, userRepo)
+	groupBookingUseCase := usecase.NewGroupBookingUsecase(groupBookingRepo, eventRepo, ticketRepo, userRepo, invitationUseCase, invoiceUseCase, transactionUseCase, seatMapUseCase, guardUseCase, queueUseCase, eligibilityUseCase, cfg.GroupBooking.MinTickets, cfg.GroupBooking.MaxTickets, cfg.GroupBooking.PaymentTerm)
+	paymentExpiryUseCase := usecase.NewPaymentExpiryUsecase(transactionRepo, advisoryLocker, midtransService, transactionUseCase, notificationUseCase, eventStatsUseCase, webhookUseCase, guardUseCase, pubsub, cfg.PaymentExpiry)
+	formUseCase := usecase.NewRegistrationFormUsecase(formRepo, eventRepo, ticketRepo, invitationUseCase, cfg.UploadDir, cfg.MaxUploadSize)
+	eventAttendeeUseCase := usecase.NewEventAttendeeUseCase(eventAttendeeRepo, eventRepo, ticketRepo, transactionUseCase, invitationUseCase, formUseCase, notificationUseCase, pubsub, eventStatsUseCase, webhookUseCase, seatMapUseCase, guardUseCase, queueUseCase, eligibilityUseCase)
 	authUseCase := usecase.NewAuthenticationUseCase(userRepo, jwtService)
+	calendarUseCase := usecase.NewCalendarUsecase(calendarFeedRepo, eventRepo, eventAttendeeUseCase, invitationUseCase)
+	trendingUseCase := usecase.NewTrendingUsecase(trendingRepo, cfg.TrendingWindow)
+	interestUseCase := usecase.NewInterestUsecase(bookmarkRepo, followRepo, eventRepo, userRepo, invitationUseCase)
+	importUseCase := usecase.NewEventImportUsecase(eventRepo, repositories.NewEventImportRepository(db))
+	broadcastUseCase := usecase.NewBroadcastUsecase(broadcastRepo, eventRepo, messageChannels...)
+	inboxUseCase := usecase.NewInboxUsecase(inboxRepo, pubsub)
 
-	engine := gin.Default()
+	// Tidak memakai gin.Default() karena logger bawaannya mencatat ?access_token= dari request stream
+	engine := gin.New()
+	engine.Use(middleware.Logger(), gin.Recovery())
 	host := fmt.Sprintf(":%s", cfg.ApiPort)
 
 	return &Server{
 		db:              db, // Simpan db ke struct Server
+		cfg:             cfg,
 		ticketUC:        ticketUseCase,
 		userUC:          userUsecase,
 		eventUC:         eventUsecase,
@@ -152,7 +381,30 @@ func NewServer() *Server {
 		engine:          engine,
 		host:            host,
 		authUC:          authUseCase,
+		calendarUC:      calendarUseCase,
+		trendingUC:      trendingUseCase,
+		interestUC:      interestUseCase,
+		invitationUC:    invitationUseCase,
+		formUC:          formUseCase,
+		importUC:        importUseCase,
+		broadcastUC:     broadcastUseCase,
+		inboxUC:         inboxUseCase,
+		notificationUC:  notificationUseCase,
+		eventStatsUC:    eventStatsUseCase,
+		webhookUC:       webhookUseCase,
+		analyticsUC:     analyticsUseCase,
+		reconcileUC:     reconciliationUseCase,
+		paymentExpiryUC: paymentExpiryUseCase,
+		ledgerUC:        ledgerUseCase,
+		payoutUC:        payoutUseCase,
+		invoiceUC:       invoiceUseCase,
+		groupBookingUC:  groupBookingUseCase,
+		seatMapUC:       seatMapUseCase,
+		guardUC:         guardUseCase,
+		queueUC:         queueUseCase,
+		eligibilityUC:   eligibilityUseCase,
 		jwtService:      jwtService,
 		midtransService: midtransService,
+		exchangeRates:   exchangeRates,
 	}
 }
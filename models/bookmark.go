@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// EventBookmark is a "save for later" marker; unlike a registration it does not consume ticket quota.
+type EventBookmark struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int       `json:"user_id" gorm:"not null;uniqueIndex:idx_bookmark_user_event"`
+	EventID   int       `json:"event_id" gorm:"not null;uniqueIndex:idx_bookmark_user_event;index"`
+	Event     Event     `json:"event" gorm:"foreignKey:EventID"`
+	CreatedAt time.Time `json:"created_at"`
+}
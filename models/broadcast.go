@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Channel names used by broadcasts and per-recipient deliveries.
+const (
+	ChannelEmail = "email"
+	ChannelInbox = "inbox"
+)
+
+const (
+	BroadcastStatusQueued  = "queued"
+	BroadcastStatusSent    = "sent"
+	BroadcastStatusPartial = "partial" // some deliveries failed permanently
+	BroadcastStatusFailed  = "failed"
+
+	DeliveryStatusPending = "pending"
+	DeliveryStatusSending = "sending"
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"
+)
+
+// BroadcastSegment selects attendees of the event; empty filters match everyone.
+type BroadcastSegment struct {
+	TicketTypeIDs   []int    `json:"ticket_type_ids,omitempty"`
+	PaymentStatuses []string `json:"payment_statuses,omitempty"`
+	RSVPStatuses    []string `json:"rsvp_statuses,omitempty"`
+	CheckedIn       *bool    `json:"checked_in,omitempty"`
+}
+
+type Broadcast struct {
+	ID             int              `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID        int              `json:"event_id" gorm:"not null;index"`
+	SenderID       int              `json:"sender_id" gorm:"not null"`
+	Subject        string           `json:"subject" gorm:"type:varchar(200);not null"`
+	Body           string           `json:"body" gorm:"type:text;not null"`
+	Channels       []string         `json:"channels" gorm:"type:jsonb;serializer:json"`
+	Segment        BroadcastSegment `json:"segment" gorm:"type:jsonb;serializer:json"`
+	Status         string           `json:"status" gorm:"type:varchar(20);not null"`
+	RecipientCount int              `json:"recipient_count"`
+	CreatedAt      time.Time        `json:"created_at"`
+	CompletedAt    *time.Time       `json:"completed_at"`
+}
+
+// BroadcastDelivery tracks one recipient on one channel.
+type BroadcastDelivery struct {
+	ID          int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	BroadcastID int        `json:"broadcast_id" gorm:"not null;index"`
+	UserID      int        `json:"user_id" gorm:"not null;index"`
+	Channel     string     `json:"channel" gorm:"type:varchar(20);not null"`
+	Address     string     `json:"address"` // email address for the email channel
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	SentAt      *time.Time `json:"sent_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// InboxTypeBroadcast marks inbox messages sent by organizers; other messages carry their notification type.
+const InboxTypeBroadcast = "broadcast"
+
+// InboxMessage is a message shown in the user's in-app inbox.
+type InboxMessage struct {
+	ID          int        `json:"id" gorm:"primaryKey;autoIncrement;index:idx_inbox_user_id,priority:2"`
+	UserID      int        `json:"user_id" gorm:"not null;index:idx_inbox_user_id,priority:1"`
+	EventID     *int       `json:"event_id"`
+	BroadcastID *int       `json:"broadcast_id"`
+	Type        string     `json:"type" gorm:"type:varchar(50);not null;default:'broadcast'"`
+	Subject     string     `json:"subject"`
+	Body        string     `json:"body" gorm:"type:text"`
+	ReadAt      *time.Time `json:"read_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// CalendarFeed stores the secret token behind a user's iCalendar subscription URL.
+type CalendarFeed struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int       `json:"user_id" gorm:"not null;uniqueIndex"`
+	Token     string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,111 @@
+package dto
+
+import "time"
+
+// AnalyticsQuery adalah parameter laporan dari query string. From/To berupa tanggal (YYYY-MM-DD)
+// inklusif di zona waktu TZ (nama IANA, default UTC); Bucket "day" (default) atau "week".
+type AnalyticsQuery struct {
+	Bucket string
+	From   string
+	To     string
+	TZ     string
+}
+
+// AnalyticsFunnel menghitung pendaftaran sepanjang umur event, termasuk yang sudah dibatalkan
+type AnalyticsFunnel struct {
+	Registrations    int64   `json:"registrations"`
+	Paid             int64   `json:"paid"`
+	Cancelled        int64   `json:"cancelled"`
+	CheckedIn        int64   `json:"checked_in"`
+	ConversionRate   float64 `json:"conversion_rate"`   // paid / registrations
+	CancellationRate float64 `json:"cancellation_rate"` // cancelled / registrations
+	CheckInRate      float64 `json:"check_in_rate"`     // checked_in / (registrations - cancelled)
+}
+
+// AnalyticsBucket adalah satu titik time series; BucketStart adalah hari atau Senin awal minggu
+type AnalyticsBucket struct {
+	BucketStart   string `json:"bucket_start"`
+	Registrations int64  `json:"registrations"`
+	Payments      int64  `json:"payments"`
+	Revenue       int64  `json:"revenue"` // minor unit mata uang event
+	Cancellations int64  `json:"cancellations"`
+	CheckIns      int64  `json:"check_ins"`
+}
+
+type TicketTypeRevenue struct {
+	TicketTypeID *int   `json:"ticket_type_id"` // nil jika transaksi tidak bisa dicocokkan ke pendaftaran
+	TicketType   string `json:"ticket_type"`
+	Transactions int64  `json:"transactions"`
+	Revenue      int64  `json:"revenue"`
+}
+
+type AgeGroupCount struct {
+	AgeGroup string `json:"age_group"`
+	Count    int64  `json:"count"`
+}
+
+type AttendeeDemographics struct {
+	AverageAge *float64        `json:"average_age"`
+	AgeGroups  []AgeGroupCount `json:"age_groups"`
+}
+
+type EventAnalytics struct {
+	EventID             int                  `json:"event_id"`
+	EventName           string               `json:"event_name"`
+	StartDate           time.Time            `json:"start_date"`
+	IsPaid              bool                 `json:"is_paid"`
+	Currency            string               `json:"currency"` // mata uang semua nominal revenue
+	Bucket              string               `json:"bucket"`
+	From                string               `json:"from"`
+	To                  string               `json:"to"`
+	TimeZone            string               `json:"time_zone"`
+	Funnel              AnalyticsFunnel      `json:"funnel"`
+	Revenue             int64                `json:"revenue"`
+	RevenueByTicketType []TicketTypeRevenue  `json:"revenue_by_ticket_type"`
+	TaxSummary          []TaxSummary         `json:"tax_summary"`
+	Demographics        AttendeeDemographics `json:"demographics"`
+	Series              []AnalyticsBucket    `json:"series"`
+}
+
+type EventComparisonItem struct {
+	EventID   int               `json:"event_id"`
+	EventName string            `json:"event_name"`
+	StartDate time.Time         `json:"start_date"`
+	IsPaid    bool              `json:"is_paid"`
+	Currency  string            `json:"currency"`
+	Funnel    AnalyticsFunnel   `json:"funnel"`
+	Revenue   int64             `json:"revenue"`
+	Series    []AnalyticsBucket `json:"series"`
+}
+
+type EventComparison struct {
+	Bucket   string                `json:"bucket"`
+	From     string                `json:"from"`
+	To       string                `json:"to"`
+	TimeZone string                `json:"time_zone"`
+	Events   []EventComparisonItem `json:"events"`
+}
+
+// Baris mentah hasil agregasi SQL
+
+type AnalyticsFunnelRow struct {
+	EventID       int
+	Registrations int64
+	Paid          int64
+	Cancelled     int64
+	CheckedIn     int64
+}
+
+type AnalyticsRevenueRow struct {
+	EventID      int
+	Transactions int64
+	Revenue      int64
+}
+
+type AnalyticsSeriesRow struct {
+	EventID     int
+	BucketStart string
+	Metric      string
+	Count       int64
+	Amount      int64
+}
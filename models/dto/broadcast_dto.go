@@ -0,0 +1,28 @@
+package dto
+
+import "gatherly-app/models"
+
+type CreateBroadcastRequest struct {
+	Subject  string                  `json:"subject" binding:"required,max=200"`
+	Body     string                  `json:"body" binding:"required"`
+	Channels []string                `json:"channels" binding:"required,min=1,dive,oneof=email inbox"`
+	Segment  models.BroadcastSegment `json:"segment"`
+}
+
+// BroadcastRecipient is an attendee matched by a broadcast segment.
+type BroadcastRecipient struct {
+	UserID int
+	Name   string
+	Email  string
+}
+
+type BroadcastDeliveryStats struct {
+	Pending int `json:"pending"`
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+}
+
+type BroadcastResponse struct {
+	models.Broadcast
+	Deliveries BroadcastDeliveryStats `json:"deliveries"`
+}
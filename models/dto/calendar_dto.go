@@ -0,0 +1,7 @@
+package dto
+
+type CalendarFeedResponse struct {
+	Token           string `json:"token"`
+	SubscriptionURL string `json:"subscription_url"`
+	WebcalURL       string `json:"webcal_url"`
+}
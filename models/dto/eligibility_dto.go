@@ -0,0 +1,34 @@
+package dto
+
+import "gatherly-app/models"
+
+// SaveEligibilityRuleRequest: nilai 0/kosong berarti syarat tidak dipakai
+type SaveEligibilityRuleRequest struct {
+	TicketTypeID    *int     `json:"ticket_type_id"` // kosong = rule untuk seluruh event
+	MinAge          int      `json:"min_age" binding:"min=0,max=150"`
+	MaxAge          int      `json:"max_age" binding:"min=0,max=150"`
+	EmailDomains    []string `json:"email_domains" binding:"max=50"` // selalu disertai syarat akun terverifikasi
+	UserGroupID     *int     `json:"user_group_id"`
+	RequireVerified bool     `json:"require_verified"`
+}
+
+// EligibleTicket menampilkan ticket type beserta alasan jika user tidak memenuhi syarat
+type EligibleTicket struct {
+	Ticket   models.Ticket `json:"ticket"`
+	Eligible bool          `json:"eligible"`
+	Reasons  []string      `json:"reasons,omitempty"`
+}
+
+type SaveUserGroupRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// UserGroupMembersRequest menambahkan anggota berdasarkan ID atau email akun
+type UserGroupMembersRequest struct {
+	UserIDs []int    `json:"user_ids" binding:"max=1000"`
+	Emails  []string `json:"emails" binding:"max=1000"`
+}
+
+type SetUserVerifiedRequest struct {
+	Verified bool `json:"verified"`
+}
@@ -1,9 +1,15 @@
 package dto
 
+import "time"
+
 type AttendeeRegisterRequest struct {
     EventID      int    `json:"eventId" binding:"required"`
     TicketTypeID int    `json:"ticketTypeId" binding:"required"` // <-- Make sure this line exists
     RSVPStatus   string `json:"rsvpStatus" binding:"required,oneof=pending attending not_attending maybe"`
+    AccessCode   string `json:"accessCode"` // secret link key or invite code for non-public events
+    Answers      map[string]any `json:"answers"` // custom registration form answers, keyed by field key
+    SeatID       *int   `json:"seatId"` // seat held via /event/:id/seat-holds, required for events with a seat map
+    Challenge    *ChallengeAnswer `json:"challenge"` // bot protection answer, required when the event enables it
 }
 
 type AttendeeCancelRequest struct {
@@ -11,13 +17,39 @@ type AttendeeCancelRequest struct {
 	EventID int `json:"eventId" binding:"required"`
 }
 
-type AttendeePaymentRequest struct {
-	UserID  int `json:"userId" binding:"required"`
-	EventID int `json:"eventId" binding:"required"`
-}
-
 type AttendeeRSVPRequest struct {
 	UserID    int    `json:"userId" binding:"required"`
 	EventID   int    `json:"eventId" binding:"required"`
 	NewStatus string `json:"newStatus" binding:"required,oneof=going interested not_going"`
 }
+
+// AttendeeCheckInRequest identifies the attendee by ticket code (paid events) or user ID (free events).
+type AttendeeCheckInRequest struct {
+	EventID    int    `json:"eventId" binding:"required"`
+	TicketCode string `json:"ticketCode"`
+	UserID     int    `json:"userId"`
+}
+
+// AttendeeManifestRow is one attendee joined with user, ticket type and form answers for export.
+type AttendeeManifestRow struct {
+	UserID        *int // nil untuk tamu group booking yang tidak punya akun
+	Name          string
+	Email         string
+	TicketType    string
+	TicketPrice   int64 // minor unit
+	Currency      string
+	RSVPStatus    string
+	RSVPDate      *time.Time
+	PaymentStatus string
+	TicketCode    *string
+	Seat          *string
+	CheckedInAt   *time.Time
+	Answers       map[string]any `gorm:"serializer:json"`
+}
+
+// AttendeeExportPlan is the resolved column set of an authorized attendee export.
+type AttendeeExportPlan struct {
+	EventID   int
+	EventName string
+	Columns   []string
+}
@@ -9,11 +9,13 @@ type CreateEventRequestDTO struct {
 	StartDate   string  `json:"start_date" binding:"required"`
 	EndDate     string  `json:"end_date" binding:"required"`
 	IsPaid      bool    `json:"is_paid"`
-	Price       float64 `json:"price"`
+	Currency    string  `json:"currency"` // kode ISO 4217, default IDR
+	Price       float64 `json:"price"`    // nominal desimal dalam mata uang event, mis. 150000 atau 9.99
 	Capacity    int     `json:"capacity" binding:"required"`
 	Address     string  `json:"address" binding:"required"`
 	PosterURL   string  `json:"poster_url"`
 	Status      string  `json:"status"`
+	Visibility  string  `json:"visibility" binding:"omitempty,oneof=public unlisted invite_only"`
 }
 
 type UpdateEventRequestDTO struct {
@@ -23,11 +25,13 @@ type UpdateEventRequestDTO struct {
 	StartDate   *string  `json:"start_date"`
 	EndDate     *string  `json:"end_date"`
 	IsPaid      *bool    `json:"is_paid"`
+	Currency    *string  `json:"currency"`
 	Price       *float64 `json:"price"`
 	Capacity    *int     `json:"capacity"`
 	Address     *string  `json:"address"`
 	PosterURL   *string  `json:"poster_url"`
 	Status      *string  `json:"status"`
+	Visibility  *string  `json:"visibility" binding:"omitempty,oneof=public unlisted invite_only"`
 }
 
 type EventResponseDTO struct {
@@ -37,6 +41,7 @@ type EventResponseDTO struct {
 	StartDate   string             `json:"start_date"`
 	EndDate     string             `json:"end_date"`
 	IsPaid      bool               `json:"is_paid"`
+	Currency    string             `json:"currency"`
 	Ticket      *TicketResponseDTO `json:"ticket"`
 	Capacity    int                `json:"capacity"`
 	Latitude    float64            `json:"latitude"`
@@ -53,6 +58,7 @@ type EventNearbyDistanceResponseDTO struct {
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time    `json:"end_date"`
 	IsPaid      bool      `json:"is_paid"`
+	Currency    string    `json:"currency"`
 	Ticket      *TicketResponseDTO `json:"ticket"`
 	Capacity    int       `json:"capacity"`
 	Latitude    float64   `json:"latitude"`
@@ -69,4 +75,50 @@ type GeneralResponse struct {
 
 type ErrorResponse struct {
 	Error string `json:"error"`
-}
\ No newline at end of file
+}
+type EventRecommendationDTO struct {
+	ID          int                `json:"id"`
+	Name        string             `json:"name"`
+	Category    string             `json:"category"`
+	Description string             `json:"description"`
+	StartDate   string             `json:"start_date"`
+	EndDate     string             `json:"end_date"`
+	IsPaid      bool               `json:"is_paid"`
+	Ticket      *TicketResponseDTO `json:"ticket"`
+	Capacity    int                `json:"capacity"`
+	Latitude    float64            `json:"latitude"`
+	Longitude   float64            `json:"longitude"`
+	Distance    *float64           `json:"distance,omitempty"`
+	PosterURL   string             `json:"poster_url"`
+	Score       float64            `json:"score"`
+	Reasons     []string           `json:"reasons"`
+}
+
+type TrendingEventQuery struct {
+	Category  string
+	City      string
+	Latitude  float64
+	Longitude float64
+	Radius    float64 // km, 0 berarti tanpa batas jarak
+	Limit     int
+}
+
+type TrendingEventDTO struct {
+	ID                   int       `json:"id"`
+	Name                 string    `json:"name"`
+	Category             string    `json:"category"`
+	StartDate            time.Time `json:"start_date"`
+	EndDate              time.Time `json:"end_date"`
+	IsPaid               bool      `json:"is_paid"`
+	Capacity             int       `json:"capacity"`
+	Latitude             float64   `json:"latitude"`
+	Longitude            float64   `json:"longitude"`
+	Distance             *float64  `json:"distance,omitempty"`
+	PosterURL            string    `json:"poster_url"`
+	Registrations        int       `json:"registrations"`
+	RegistrationVelocity float64   `json:"registration_velocity"`
+	PaidConversion       float64   `json:"paid_conversion"`
+	Views                int       `json:"views"`
+	Score                float64   `json:"score"`
+	ComputedAt           time.Time `json:"computed_at"`
+}
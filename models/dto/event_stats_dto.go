@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type TicketTypeCounter struct {
+	TicketTypeID   int    `json:"ticket_type_id"`
+	TicketType     string `json:"ticket_type"`
+	Price          int64  `json:"price"`
+	Currency       string `json:"currency"`
+	RemainingQuota int    `json:"remaining_quota"`
+	Registered     int64  `json:"registered"`
+	Paid           int64  `json:"paid"`
+	CheckedIn      int64  `json:"checked_in"`
+	Revenue        int64  `json:"revenue"` // minor unit
+}
+
+// EventCounters adalah total terkini satu event, dijumlahkan dari semua ticket type
+type EventCounters struct {
+	EventID     int                 `json:"event_id"`
+	Registered  int64               `json:"registered"`
+	Paid        int64               `json:"paid"`
+	CheckedIn   int64               `json:"checked_in"`
+	Revenue     int64               `json:"revenue"`
+	Currency    string              `json:"currency"`
+	TicketTypes []TicketTypeCounter `json:"ticket_types"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// EventStatsDelta dikirim ke dashboard setiap ada perubahan; tipe perubahan ada di StreamEvent.Type
+type EventStatsDelta struct {
+	EventID      int           `json:"event_id"`
+	UserID       int           `json:"user_id"`
+	TicketTypeID *int          `json:"ticket_type_id,omitempty"`
+	At           time.Time     `json:"at"`
+	Totals       EventCounters `json:"totals"`
+}
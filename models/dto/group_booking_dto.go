@@ -0,0 +1,25 @@
+package dto
+
+// CreateGroupBookingRequest memesan beberapa tiket sekaligus; Buyer dicetak di invoice
+type CreateGroupBookingRequest struct {
+	TicketTypeID int                 `json:"ticket_type_id" binding:"required"`
+	Quantity     int                 `json:"quantity" binding:"required,min=1"`
+	Buyer        InvoiceBuyerRequest `json:"buyer"`
+	AccessCode   string              `json:"access_code"` // untuk event invite-only/unlisted
+	Challenge    *ChallengeAnswer    `json:"challenge"`   // wajib jika event mengaktifkan proteksi bot
+}
+
+type SeatAssignment struct {
+	SeatID int    `json:"seat_id" binding:"required"`
+	Name   string `json:"name" binding:"required,max=100"`
+	Email  string `json:"email" binding:"required,email,max=100"`
+}
+
+type AssignSeatsRequest struct {
+	Seats []SeatAssignment `json:"seats" binding:"required,min=1,dive"`
+}
+
+// MarkGroupBookingPaidRequest: PaymentReference adalah nomor referensi transfer bank dari mutasi rekening
+type MarkGroupBookingPaidRequest struct {
+	PaymentReference string `json:"payment_reference" binding:"required,max=100"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import "time"
+
+type ImportTicketRow struct {
+	TicketType string  `json:"ticket_type"`
+	Price      float64 `json:"price"` // nominal desimal dalam mata uang event
+	Quota      int     `json:"quota"`
+	Status     string  `json:"status"`
+	Line       int     `json:"-"`
+}
+
+// ImportEventRow is one event of a bulk import. Latitude/Longitude skip geocoding when both are set.
+type ImportEventRow struct {
+	Name        string            `json:"name"`
+	Category    string            `json:"category"`
+	Description string            `json:"description"`
+	StartDate   string            `json:"start_date"`
+	EndDate     string            `json:"end_date"`
+	IsPaid      bool              `json:"is_paid"`
+	Currency    string            `json:"currency"` // default IDR
+	Capacity    int               `json:"capacity"`
+	Address     string            `json:"address"`
+	Latitude    *float64          `json:"latitude"`
+	Longitude   *float64          `json:"longitude"`
+	PosterURL   string            `json:"poster_url"`
+	Visibility  string            `json:"visibility"`
+	Tickets     []ImportTicketRow `json:"tickets"`
+	Line        int               `json:"-"`
+}
+
+type ImportError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportedEvent struct {
+	Line    int    `json:"line"`
+	ID      int    `json:"id,omitempty"`
+	Name    string `json:"name"`
+	Tickets int    `json:"tickets"`
+}
+
+// ImportResult: Queued berarti file diproses di background; hasilnya diambil lewat GET /event/import/{JobID}
+type ImportResult struct {
+	DryRun    bool            `json:"dry_run"`
+	Committed bool            `json:"committed"`
+	Queued    bool            `json:"queued"`
+	JobID     int             `json:"job_id,omitempty"`
+	Events    []ImportedEvent `json:"events"`
+	Errors    []ImportError   `json:"errors"`
+}
+
+// ImportJobResponse adalah status import background; Result terisi setelah status done
+type ImportJobResponse struct {
+	ID         int           `json:"id"`
+	Status     string        `json:"status"`
+	DryRun     bool          `json:"dry_run"`
+	Error      string        `json:"error,omitempty"`
+	Result     *ImportResult `json:"result,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	FinishedAt *time.Time    `json:"finished_at"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "gatherly-app/models"
+
+// InboxPage adalah satu halaman inbox; NextBefore diisi jika masih ada pesan yang lebih lama
+type InboxPage struct {
+	Messages    []models.InboxMessage `json:"messages"`
+	UnreadCount int64                 `json:"unread_count"`
+	NextBefore  *int                  `json:"next_before,omitempty"`
+}
+
+type InboxReadResponse struct {
+	Updated     int64 `json:"updated"`
+	UnreadCount int64 `json:"unread_count"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+type FollowListResponse struct {
+	Organizers []int    `json:"organizers"`
+	Categories []string `json:"categories"`
+}
+
+type EventBookmarkCount struct {
+	EventID   int    `json:"event_id"`
+	EventName string `json:"event_name"`
+	Bookmarks int    `json:"bookmarks"`
+}
+
+type OrganizerStatsResponse struct {
+	OrganizerID int                  `json:"organizer_id"`
+	Followers   int                  `json:"followers"`
+	Bookmarks   int                  `json:"bookmarks"`
+	Events      []EventBookmarkCount `json:"events"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+type CreateInvitationsRequest struct {
+	Emails    []string `json:"emails" binding:"omitempty,dive,email"`
+	Usernames []string `json:"usernames"`
+}
+
+type CreateInviteLinkRequest struct {
+	MaxUses        int `json:"max_uses" binding:"gte=0"`
+	ExpiresInHours int `json:"expires_in_hours" binding:"gte=0"`
+}
+
+type InviteLinkResponse struct {
+	ID        int    `json:"id"`
+	Code      string `json:"code"`
+	MaxUses   int    `json:"max_uses"`
+	UsedCount int    `json:"used_count"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Revoked   bool   `json:"revoked"`
+}
+
+type EventAccessResponse struct {
+	EventID    int    `json:"event_id"`
+	Visibility string `json:"visibility"`
+	AccessKey  string `json:"access_key"`
+}
@@ -0,0 +1,58 @@
+package dto
+
+import "gatherly-app/models"
+
+// SetEventTaxRequest mengatur pajak event; Rate dalam persen (11 berarti 11%)
+type SetEventTaxRequest struct {
+	Mode    string  `json:"mode" binding:"required,oneof=exclusive inclusive"`
+	Rate    float64 `json:"rate" binding:"gt=0,max=100"`
+	TaxName string  `json:"tax_name" binding:"max=20"` // default PPN
+	TaxID   string  `json:"tax_id" binding:"max=32"`   // kosong = NPWP di profil legal organizer
+}
+
+type SetLegalProfileRequest struct {
+	LegalName string `json:"legal_name" binding:"required,max=200"`
+	TaxID     string `json:"tax_id" binding:"max=32"`
+	Address   string `json:"address" binding:"max=500"`
+	Email     string `json:"email" binding:"omitempty,email,max=100"`
+	Phone     string `json:"phone" binding:"max=30"`
+}
+
+// InvoiceBuyerRequest melengkapi data perusahaan pembeli untuk faktur
+type InvoiceBuyerRequest struct {
+	Company string `json:"company" binding:"max=200"`
+	TaxID   string `json:"tax_id" binding:"max=32"`
+	Address string `json:"address" binding:"max=500"`
+}
+
+// OrderItem adalah satu baris pesanan sebelum pajak; UnitPrice dalam minor unit mata uang event
+type OrderItem struct {
+	Description string
+	Quantity    int
+	UnitPrice   int64
+}
+
+// OrderQuote adalah hasil perhitungan pajak satu pesanan. Total adalah nominal yang ditagihkan
+// dan disimpan sebagai amount transaksi.
+type OrderQuote struct {
+	Currency   string               `json:"currency"`
+	TaxMode    string               `json:"tax_mode,omitempty"`
+	TaxName    string               `json:"tax_name,omitempty"`
+	TaxRateBps int                  `json:"tax_rate_bps"`
+	TaxID      string               `json:"-"` // NPWP dari setting event, jika ada
+	Lines      []models.InvoiceLine `json:"lines"`
+	Subtotal   int64                `json:"subtotal"`
+	TaxTotal   int64                `json:"tax_total"`
+	Total      int64                `json:"total"`
+}
+
+// TaxSummary merangkum invoice yang transaksinya sudah lunas, per mata uang dan tarif
+type TaxSummary struct {
+	Currency   string `json:"currency"`
+	TaxName    string `json:"tax_name"`
+	TaxRateBps int    `json:"tax_rate_bps"`
+	Invoices   int64  `json:"invoices"`
+	Subtotal   int64  `json:"subtotal"`
+	TaxTotal   int64  `json:"tax_total"`
+	Total      int64  `json:"total"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// LedgerBalance memakai minor unit (1 IDR = 100). Balance mengikuti sisi normal akun:
+// debit - kredit untuk asset/expense, kredit - debit untuk liability/revenue.
+type LedgerBalance struct {
+	AccountID   int    `json:"account_id"`
+	Code        string `json:"code"`
+	Type        string `json:"type"`
+	OrganizerID *int   `json:"organizer_id,omitempty"`
+	Currency    string `json:"currency"`
+	Debits      int64  `json:"debits"`
+	Credits     int64  `json:"credits"`
+	Balance     int64  `json:"balance"`
+}
+
+type UnbalancedEntry struct {
+	EntryID   int    `json:"entry_id"`
+	Kind      string `json:"kind"`
+	Reference string `json:"reference"`
+	Total     int64  `json:"total"`
+	Postings  int64  `json:"postings"`
+}
+
+// SettlementMismatch adalah transaksi lunas yang nominal settlement-nya di ledger tidak sesuai
+type SettlementMismatch struct {
+	OrderID  string `json:"order_id"`
+	Expected int64  `json:"expected"`
+	Recorded int64  `json:"recorded"`
+}
+
+type LedgerCheckResult struct {
+	OK                   bool                 `json:"ok"`
+	CheckedAt            time.Time            `json:"checked_at"`
+	TrialBalance         int64                `json:"trial_balance"`
+	UnbalancedEntries    []UnbalancedEntry    `json:"unbalanced_entries"`
+	SettlementMismatches []SettlementMismatch `json:"settlement_mismatches"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "gatherly-app/models"
+
+// MoneyView adalah nominal di response: minor unit, teks sesuai bahasa user, dan opsional
+// perkiraan dalam mata uang lain.
+type MoneyView struct {
+	Amount    int64           `json:"amount"`
+	Currency  string          `json:"currency"`
+	Formatted string          `json:"formatted"`
+	Converted *ConvertedMoney `json:"converted,omitempty"`
+}
+
+// ConvertedMoney hanya untuk tampilan; pembayaran selalu dalam mata uang event
+type ConvertedMoney struct {
+	Amount    int64   `json:"amount"`
+	Currency  string  `json:"currency"`
+	Formatted string  `json:"formatted"`
+	Rate      float64 `json:"rate"`
+	RatesDate string  `json:"rates_date"`
+}
+
+func NewMoneyView(money models.Money) MoneyView {
+	return MoneyView{
+		Amount:    money.Amount,
+		Currency:  money.Currency,
+		Formatted: money.Format(models.LocaleIndonesian),
+	}
+}
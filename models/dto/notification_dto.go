@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+type NotificationPreferenceItem struct {
+	Type    string `json:"type" binding:"required"`
+	Channel string `json:"channel" binding:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+// UpdateNotificationPreferencesRequest mengubah bahasa notifikasi dan/atau preferensi per channel.
+// Kombinasi type/channel yang tidak dikirim tidak diubah.
+type UpdateNotificationPreferencesRequest struct {
+	Locale      string                       `json:"locale" binding:"omitempty,oneof=id en"`
+	Preferences []NotificationPreferenceItem `json:"preferences" binding:"dive"`
+}
+
+type NotificationPreferencesResponse struct {
+	Locale      string                       `json:"locale"`
+	Preferences []NotificationPreferenceItem `json:"preferences"`
+}
+
+// NotificationTarget adalah satu penerima notifikasi terjadwal beserta data event-nya
+type NotificationTarget struct {
+	UserID    int
+	EventID   int
+	EventName string
+	StartDate time.Time
+}
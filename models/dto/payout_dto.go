@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"gatherly-app/models"
+	"time"
+)
+
+// SetFeeRuleRequest membuat atau mengganti rule untuk satu event atau satu organizer (salah satu wajib diisi)
+type SetFeeRuleRequest struct {
+	EventID     *int    `json:"event_id"`
+	OrganizerID *int    `json:"organizer_id"`
+	Percent     float64 `json:"percent" binding:"min=0,max=100"` // 2.5 berarti 2.5%
+	FixedAmount int64   `json:"fixed_amount" binding:"min=0"`    // minor unit
+}
+
+// PlatformFee adalah fee default yang berlaku jika event dan organizer tidak punya rule
+type PlatformFee struct {
+	PercentBps  int   `json:"percent_bps"`
+	FixedAmount int64 `json:"fixed_amount"`
+}
+
+type FeeRuleList struct {
+	Default PlatformFee      `json:"default"`
+	Rules   []models.FeeRule `json:"rules"`
+}
+
+type CreatePayoutRequest struct {
+	Amount        int64  `json:"amount" binding:"required,min=1"` // minor unit
+	BankCode      string `json:"bank_code" binding:"max=20"`
+	BankName      string `json:"bank_name" binding:"required,max=100"`
+	AccountNumber string `json:"account_number" binding:"required,numeric,max=50"`
+	AccountHolder string `json:"account_holder" binding:"required,max=100"`
+	Note          string `json:"note" binding:"max=200"`
+}
+
+type CreatePayoutBatchRequest struct {
+	// Kosong berarti semua payout berstatus requested
+	PayoutIDs []int `json:"payout_ids"`
+}
+
+type ReviewPayoutBatchRequest struct {
+	Note string `json:"note" binding:"max=200"`
+}
+
+type StatementLine struct {
+	EntryID     int       `json:"entry_id"`
+	Kind        string    `json:"kind"`
+	Reference   string    `json:"reference"`
+	EventID     *int      `json:"event_id,omitempty"`
+	Description string    `json:"description"`
+	Amount      int64     `json:"amount"` // positif menambah saldo organizer
+	Balance     int64     `json:"balance"`
+	PostedAt    time.Time `json:"posted_at"`
+}
+
+// OrganizerStatement adalah mutasi akun organizer di ledger dalam satu periode, dalam minor unit.
+// Available adalah saldo akhir saat ini dikurangi payout yang belum selesai diproses.
+type OrganizerStatement struct {
+	OrganizerID    int             `json:"organizer_id"`
+	Currency       string          `json:"currency"`
+	From           string          `json:"from"`
+	To             string          `json:"to"`
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Settlements    int64           `json:"settlements"`
+	PlatformFees   int64           `json:"platform_fees"`
+	Refunds        int64           `json:"refunds"`
+	Payouts        int64           `json:"payouts"`
+	PendingPayouts int64           `json:"pending_payouts"`
+	Available      int64           `json:"available"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+// BankFileFormat mendeskripsikan file transfer massal yang diterima bank. Dibaca dari file JSON
+// (PAYOUT_BANK_FORMAT); tanpa file dipakai format CSV standar.
+type BankFileFormat struct {
+	Name      string           `json:"name"`
+	Delimiter string           `json:"delimiter"`
+	Header    bool             `json:"header"`
+	CRLF      bool             `json:"crlf"`
+	Columns   []BankFileColumn `json:"columns"`
+}
+
+// BankFileColumn mengambil nilai dari Field (lihat usecase.bankFileFields) atau konstanta Value.
+// Layout dipakai untuk field tanggal dengan format Go.
+type BankFileColumn struct {
+	Header string `json:"header"`
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	Layout string `json:"layout"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// ChallengeAnswer adalah jawaban challenge anti-bot yang diambil dari endpoint challenge
+type ChallengeAnswer struct {
+	Token    string `json:"token"`
+	Solution string `json:"solution"`
+}
+
+// ClientInfo adalah data request yang dipakai untuk rate limit dan proteksi bot pembelian
+type ClientInfo struct {
+	IP        string
+	Challenge *ChallengeAnswer
+}
+
+// SetPurchasePolicyRequest: batas 0 berarti tidak dibatasi
+type SetPurchasePolicyRequest struct {
+	MaxTicketsPerUser int  `json:"max_tickets_per_user" binding:"min=0"`
+	MaxTicketsPerIP   int  `json:"max_tickets_per_ip" binding:"min=0"`
+	RequireChallenge  bool `json:"require_challenge"`
+	QueueEnabled      bool `json:"queue_enabled"`
+	QueueBatchSize    int  `json:"queue_batch_size" binding:"min=0,max=10000"`  // 0 = default 50
+	QueueAdmitRate    int  `json:"queue_admit_rate" binding:"min=0,max=100000"` // user per menit, 0 = tanpa batas rate
+	CheckoutWindow    int  `json:"checkout_window" binding:"min=0,max=120"`     // menit, 0 = default 10
+}
+
+// QueueStatus: Position dan EstimatedWait hanya diisi selama status waiting (posisi 1 = berikutnya masuk).
+// Token hanya dikirim saat masuk antrean; simpan untuk cek status tanpa login.
+type QueueStatus struct {
+	EventID       int        `json:"event_id"`
+	Status        string     `json:"status"`
+	Position      int64      `json:"position,omitempty"`
+	EstimatedWait int64      `json:"estimated_wait_seconds,omitempty"` // hanya jika event memakai admission rate
+	Token         string     `json:"token,omitempty"`
+	JoinedAt      time.Time  `json:"joined_at"`
+	AdmittedAt    *time.Time `json:"admitted_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"` // batas akhir checkout selama status admitted
+}
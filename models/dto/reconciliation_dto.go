@@ -0,0 +1,25 @@
+package dto
+
+import "gatherly-app/models"
+
+type TransactionStatusSummary struct {
+	Status   string `json:"status"`
+	Currency string `json:"currency"`
+	Count    int64  `json:"count"`
+	Amount   int64  `json:"amount"` // minor unit
+}
+
+// TransactionReport merangkum transaksi seluruh platform dalam rentang tanggal (To eksklusif)
+type TransactionReport struct {
+	From          string                     `json:"from"`
+	To            string                     `json:"to"`
+	Statuses      []TransactionStatusSummary `json:"statuses"`
+	TotalCount    int64                      `json:"total_count"`
+	SettledAmount map[string]int64           `json:"settled_amount"` // per mata uang, minor unit
+	PendingAmount map[string]int64           `json:"pending_amount"`
+}
+
+type ReconciliationReport struct {
+	Run           models.ReconciliationRun           `json:"run"`
+	Discrepancies []models.ReconciliationDiscrepancy `json:"discrepancies"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "gatherly-app/models"
+
+type SaveRegistrationFormRequest struct {
+	TicketTypeID *int               `json:"ticket_type_id"`
+	Fields       []models.FormField `json:"fields" binding:"required"`
+}
+
+type FormUploadResponse struct {
+	Token    string `json:"token"`
+	FieldKey string `json:"field_key"`
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+}
@@ -0,0 +1,66 @@
+package dto
+
+import "time"
+
+type SeatZoneInput struct {
+	Name         string `json:"name" binding:"required,max=50"`
+	TicketTypeID int    `json:"ticket_type_id" binding:"required"`
+}
+
+// SeatInput adalah satu kursi; Zone merujuk ke nama di SetSeatMapRequest.Zones
+type SeatInput struct {
+	Section    string `json:"section" binding:"required,max=50"`
+	Row        string `json:"row" binding:"required,max=20"`
+	Number     string `json:"number" binding:"required,max=20"`
+	Zone       string `json:"zone" binding:"required"`
+	Accessible bool   `json:"accessible"`
+	Blocked    bool   `json:"blocked"`
+}
+
+// SetSeatMapRequest mengganti denah kursi event. Dengan CopyFromEventID, kursi disalin dari denah
+// event lain (venue yang sama) dan Seats diabaikan; nama zona harus ada di Zones.
+type SetSeatMapRequest struct {
+	Name            string          `json:"name" binding:"required,max=100"`
+	Zones           []SeatZoneInput `json:"zones" binding:"required,min=1,dive"`
+	Seats           []SeatInput     `json:"seats" binding:"dive"`
+	CopyFromEventID *int            `json:"copy_from_event_id"`
+}
+
+type HoldSeatRequest struct {
+	SeatID int `json:"seat_id" binding:"required"`
+}
+
+type SeatHold struct {
+	SeatID    int       `json:"seat_id"`
+	Seat      string    `json:"seat"`
+	HeldUntil time.Time `json:"held_until"`
+}
+
+type SeatZoneView struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	TicketTypeID int       `json:"ticket_type_id"`
+	TicketType   string    `json:"ticket_type"`
+	Amount       int64     `json:"-"`
+	Currency     string    `json:"-"`
+	Price        MoneyView `json:"price" gorm:"-"` // diisi dari Amount dan Currency
+}
+
+// SeatView: Status adalah salah satu models.Seat* dari sudut pandang user yang meminta
+type SeatView struct {
+	ID         int    `json:"id"`
+	ZoneID     int    `json:"zone_id"`
+	Section    string `json:"section"`
+	Row        string `json:"row"`
+	Number     string `json:"number"`
+	Accessible bool   `json:"accessible"`
+	Status     string `json:"status"`
+}
+
+type SeatMapView struct {
+	ID      int            `json:"id"`
+	EventID int            `json:"event_id"`
+	Name    string         `json:"name"`
+	Zones   []SeatZoneView `json:"zones"`
+	Seats   []SeatView     `json:"seats"`
+}
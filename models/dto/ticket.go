@@ -1,5 +1,15 @@
 package dto
 
+// CreateTicketRequest: Price dalam satuan utama mata uang event (150000 = Rp150.000),
+// disimpan sebagai minor unit
+type CreateTicketRequest struct {
+	TicketType string  `json:"ticketType" binding:"required"`
+	Price      float64 `json:"price" binding:"min=0"`
+	Quota      int     `json:"quota" binding:"min=0"`
+	Status     string  `json:"status"`
+	EventID    int     `json:"eventId" binding:"required"`
+}
+
 type PayloadTicket struct {
 	Ids []int `json:"ids" binding:"required"`
 }
@@ -7,7 +17,7 @@ type PayloadTicket struct {
 type TicketResponseDTO struct {
 	ID int `json:"id"`
 	TicketType string `json:"ticketType"`
-	Price int `json:"price"`
+	Price MoneyView `json:"price"`
 	Quota int `json:"quota"`
 	Status string `json:"status"`
 }
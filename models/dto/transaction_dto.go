@@ -1,14 +1,21 @@
 package dto
 
-import "time"
+import (
+	"gatherly-app/models"
+	"time"
+)
 
 type CreateTransaction struct {
 	UserId          int        `json:"user_id" binding:"required"`
 	EventId         int        `json:"event_id" binding:"required"`
 	TransactionDate *time.Time `json:"transaction_date" gorm:"not null"`
-	Amount          float64    `json:"amount" binding:"required"`
+	Amount          int64      `json:"amount" binding:"required"` // minor unit
+	Currency        string     `json:"currency"`
 	Items           string     `json:"items" binding:"required"`
 	Notes           string     `json:"notes"`
+	// Quote berisi perhitungan pajak pesanan; jika diisi, Amount dan Currency diambil dari Quote
+	// dan invoice diterbitkan bersama transaksi
+	Quote *OrderQuote `json:"-"`
 }
 
 type GetTransactionsByDate struct {
@@ -16,9 +23,11 @@ type GetTransactionsByDate struct {
 	EndDate   string `json:"end_date" binding:"required"`
 }
 
+// GetTransactionsByAmount memakai nominal desimal (mis. 150000 atau 9.99) dalam Currency, default IDR
 type GetTransactionsByAmount struct {
 	MinAmount float64 `json:"min_amount"`
 	MaxAmount float64 `json:"max_amount"`
+	Currency  string  `json:"currency"`
 }
 
 type MidtransSnapReq struct {
@@ -26,8 +35,17 @@ type MidtransSnapReq struct {
 		OrderID     string `json:"order_id"`
 		GrossAmount int    `json:"gross_amount"`
 	} `json:"transaction_details"`
-	Customer string `json:"customer"`
-	Items    string `json:"items_details"`
+	Customer    string               `json:"customer"`
+	Items       string               `json:"items_details"`
+	ItemDetails []MidtransItemDetail `json:"item_details,omitempty"`
+}
+
+// MidtransItemDetail adalah satu baris item_details; jumlah Price*Quantity harus sama dengan gross_amount
+type MidtransItemDetail struct {
+	ID       string `json:"id"`
+	Price    int    `json:"price"`
+	Quantity int    `json:"quantity"`
+	Name     string `json:"name"`
 }
 
 type MidtransSnapResp struct {
@@ -36,9 +54,37 @@ type MidtransSnapResp struct {
 	ErrorMessage string `json:"error_message"`
 }
 
+// MidtransNotification adalah body HTTP notification Midtrans; SignatureKey dihitung dari
+// order_id, status_code, gross_amount dan server key sehingga notifikasi palsu bisa ditolak
 type MidtransNotification struct {
 	StatusCode        string `json:"status_code"`
 	TransactionStatus string `json:"transaction_status"`
 	OrderID           string `json:"order_id"`
 	PaymentType       string `json:"payment_type"`
+	GrossAmount       string `json:"gross_amount"`
+	FraudStatus       string `json:"fraud_status"`
+	SignatureKey      string `json:"signature_key"`
+}
+
+// MidtransStatusResp adalah response GET /v2/{order_id}/status. Order yang tidak dikenal
+// dikembalikan dengan HTTP 200 dan StatusCode "404".
+type MidtransStatusResp struct {
+	StatusCode        string `json:"status_code"`
+	StatusMessage     string `json:"status_message"`
+	TransactionStatus string `json:"transaction_status"`
+	OrderID           string `json:"order_id"`
+	GrossAmount       string `json:"gross_amount"`
+	PaymentType       string `json:"payment_type"`
+	FraudStatus       string `json:"fraud_status"`
+	TransactionTime   string `json:"transaction_time"`
+	SettlementTime    string `json:"settlement_time"`
+}
+
+// StatusTransition adalah hasil TransitionStatus
+type StatusTransition struct {
+	Previous string // status transaksi sebelum diubah
+	Changed  bool   // false jika status sebelumnya tidak boleh berpindah ke status baru
+	// Issued adalah pendaftaran yang dikonfirmasi oleh pembayaran ini; TicketCode nil berarti
+	// kuota ticket type sudah habis (failed_no_quota)
+	Issued *models.EventAttendee
 }
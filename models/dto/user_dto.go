@@ -6,7 +6,8 @@ type CreateUserRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
 	Email    string `json:"email" validate:"required,email,max=100"`
 	Password string `json:"password" validate:"required,min=6"`
-	Role     string `json:"role" validate:"omitempty,oneof=user admin"`
+	// Challenge wajib jika SIGNUP_REQUIRE_CHALLENGE aktif; ambil dari GET /signup-challenge
+	Challenge *ChallengeAnswer `json:"challenge,omitempty"`
 }
 
 type UserResponse struct {
@@ -16,8 +17,10 @@ type UserResponse struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Role     string `json:"role"`
+	Verified bool   `json:"verified"`
 }
 
+// UpdateUserRequest: Age, Email dan Role hanya boleh diisi admin
 type UpdateUserRequest struct {
 	Name     string `json:"name" validate:"omitempty,min=3,max=100"`
 	Age      int    `json:"age" validate:"omitempty,gte=0,lte=150"`
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"gatherly-app/models"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=500"`
+	EventID     *int     `json:"event_id"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1,dive,oneof=attendee.registered attendee.cancelled payment.settled checkin.created"`
+	Description string   `json:"description" binding:"max=200"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,url,max=500"`
+	EventTypes  []string `json:"event_types" binding:"omitempty,min=1,dive,oneof=attendee.registered attendee.cancelled payment.settled checkin.created"`
+	Description *string  `json:"description" binding:"omitempty,max=200"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookEndpointCreatedResponse menampilkan secret satu kali saat endpoint dibuat
+type WebhookEndpointCreatedResponse struct {
+	models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+type WebhookRedeliveryResponse struct {
+	Delivery models.WebhookDelivery `json:"delivery"`
+	Attempt  models.WebhookAttempt  `json:"attempt"`
+}
+
+// WebhookPayload adalah body JSON yang dikirim ke endpoint organizer
+type WebhookPayload struct {
+	ID        string              `json:"id"`
+	Type      string              `json:"type"`
+	CreatedAt time.Time           `json:"created_at"`
+	Data      WebhookAttendeeData `json:"data"`
+}
+
+type WebhookAttendeeData struct {
+	EventID        int        `json:"event_id"`
+	EventName      string     `json:"event_name"`
+	UserID         int        `json:"user_id"` // 0 untuk tamu group booking tanpa akun
+	GroupBookingID *int       `json:"group_booking_id,omitempty"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	TicketTypeID   *int       `json:"ticket_type_id,omitempty"`
+	RSVPStatus     string     `json:"rsvp_status,omitempty"`
+	PaymentStatus  string     `json:"payment_status,omitempty"`
+	TicketCode     *string    `json:"ticket_code,omitempty"`
+	Seat           *string    `json:"seat,omitempty"`
+	CheckedInAt    *time.Time `json:"checked_in_at,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// EligibilityRule membatasi siapa yang boleh mendaftar. Rule event (TicketTypeID nil) berlaku untuk
+// semua ticket type, rule ticket type ditambahkan di atasnya; user harus memenuhi keduanya.
+// Nilai kosong/0 berarti syarat tersebut tidak dipakai.
+type EligibilityRule struct {
+	ID              int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID         int       `json:"event_id" gorm:"not null;index"`
+	TicketTypeID    *int      `json:"ticket_type_id" gorm:"index"`
+	MinAge          int       `json:"min_age" gorm:"not null;default:0"`
+	MaxAge          int       `json:"max_age" gorm:"not null;default:0"`
+	EmailDomains    []string  `json:"email_domains" gorm:"type:jsonb;serializer:json"` // subdomain ikut diterima
+	UserGroupID     *int      `json:"user_group_id"`
+	RequireVerified bool      `json:"require_verified" gorm:"not null;default:false"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// UserGroup adalah daftar user yang dikelola organizer, mis. anggota komunitas atau karyawan,
+// untuk dipakai di EligibilityRule.
+type UserGroup struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OwnerID   int       `json:"owner_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UserGroupMember struct {
+	GroupID   int       `json:"group_id" gorm:"primaryKey"`
+	UserID    int       `json:"user_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -2,6 +2,18 @@ package models
 
 import "time"
 
+// EventStatusCancelled marks an event that was called off by its organizer.
+// Other statuses are derived from the start and end dates.
+const EventStatusCancelled = "Cancelled"
+
+// Visibility modes. Unlisted events are hidden from listings and reachable through a
+// secret link; invite-only events additionally require an invitation to register.
+const (
+	EventVisibilityPublic     = "public"
+	EventVisibilityUnlisted   = "unlisted"
+	EventVisibilityInviteOnly = "invite_only"
+)
+
 type Event struct {
 	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name   		string    `json:"name"`
@@ -10,10 +22,17 @@ type Event struct {
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time `json:"end_date"`
 	IsPaid      bool      `json:"is_paid"`
+	Currency    string    `json:"currency" gorm:"type:varchar(3);not null;default:'IDR'"`
 	Tickets     []Ticket  `gorm:"foreignKey:EventID"`
 	Capacity    int       `json:"capacity"`
 	Latitude    float64   `json:"latitude"`
 	Longitude   float64   `json:"longitude"`
 	PosterURL   string    `json:"poster_url"`
 	Status      string    `json:"status"`
+	OrganizerID int       `json:"organizer_id" gorm:"index"`
+	Visibility  string    `json:"visibility" gorm:"type:varchar(20);not null;default:'public';index"`
+	AccessKey   string    `json:"-" gorm:"type:varchar(64)"` // secret for unlisted share links
+	Sequence    int       `json:"sequence" gorm:"not null;default:0"` // revision counter, bumped on every update
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
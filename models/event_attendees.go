@@ -13,4 +13,25 @@ type EventAttendee struct {
 	RSVPDate      *time.Time `json:"rsvp_date,omitempty"`
 	PaymentStatus string     `json:"payment_status"`
 	TicketCode    *string    `json:"ticket_code,omitempty"`
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`
+	// Diisi jika tiket dibeli orang lain lewat group booking
+	GroupBookingID *int `json:"group_booking_id,omitempty"`
+	PurchaserID    *int `json:"purchaser_id,omitempty"`
+	// Kursi untuk event dengan seat map; SeatLabel dicetak di tiket
+	SeatID    *int    `json:"seat_id,omitempty"`
+	SeatLabel *string `json:"seat,omitempty"`
+	// IP pembeli yang dihitung di batas tiket per IP; dilepas lagi saat pendaftaran dibatalkan
+	PurchaseIP *string `json:"-" gorm:"type:varchar(64)"`
+}
+
+// RegistrationCancellation menyimpan jejak pendaftaran yang dibatalkan. Baris EventAttendee
+// dihapus saat pembatalan, jadi laporan (tingkat pembatalan, konversi) membaca dari tabel ini.
+type RegistrationCancellation struct {
+	ID            int        `json:"id" gorm:"primaryKey"`
+	EventID       int        `json:"event_id" gorm:"not null;index"`
+	UserID        int        `json:"user_id" gorm:"not null"`
+	TicketTypeID  *int       `json:"ticket_type_id"`
+	PaymentStatus string     `json:"payment_status"`
+	RegisteredAt  *time.Time `json:"registered_at"`
+	CancelledAt   time.Time  `json:"cancelled_at" gorm:"not null"`
 }
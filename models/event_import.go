@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	ImportJobQueued  = "queued"
+	ImportJobRunning = "running"
+	ImportJobDone    = "done"
+	ImportJobFailed  = "failed"
+)
+
+// EventImportJob menyimpan file import besar yang diproses di background karena geocoding-nya
+// terlalu lama untuk satu request. Data dikosongkan setelah selesai; Result berisi dto.ImportResult.
+type EventImportJob struct {
+	ID          int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizerID int        `json:"organizer_id" gorm:"not null;index"`
+	Format      string     `json:"format" gorm:"type:varchar(10);not null"`
+	DryRun      bool       `json:"dry_run" gorm:"not null;default:false"`
+	Data        []byte     `json:"-" gorm:"type:bytea"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	Result      []byte     `json:"-" gorm:"type:jsonb"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// EventTrendingScore is a precomputed trending row per event, rebuilt by a background job.
+type EventTrendingScore struct {
+	EventID              int       `json:"event_id" gorm:"primaryKey;autoIncrement:false"`
+	Registrations        int       `json:"registrations"`         // pendaftaran dalam jendela waktu
+	RegistrationVelocity float64   `json:"registration_velocity"` // pendaftaran per hari
+	PaidConversion       float64   `json:"paid_conversion"`       // transaksi lunas / seluruh transaksi
+	Views                int       `json:"views"`
+	Score                float64   `json:"score" gorm:"index"`
+	ComputedAt           time.Time `json:"computed_at"`
+}
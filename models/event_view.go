@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// EventView records a single detail-page view, used as a trending signal. Repeat views by the
+// same user (or IP for anonymous viewers) within a short window are not recorded.
+type EventView struct {
+	ID       int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID  int       `json:"event_id" gorm:"not null;index:idx_event_views_event_time"`
+	UserID   int       `json:"user_id" gorm:"index"`
+	IP       string    `json:"-" gorm:"type:varchar(45)"`
+	ViewedAt time.Time `json:"viewed_at" gorm:"not null;index:idx_event_views_event_time"`
+}
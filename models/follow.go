@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+const (
+	FollowTargetOrganizer = "organizer"
+	FollowTargetCategory  = "category"
+)
+
+// Follow links a user to an organizer (TargetValue = organizer user ID)
+// or to a category (TargetValue = lower-cased category name).
+type Follow struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      int       `json:"user_id" gorm:"not null;uniqueIndex:idx_follow_user_target"`
+	TargetType  string    `json:"target_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_follow_user_target;index:idx_follow_target"`
+	TargetValue string    `json:"target_value" gorm:"type:varchar(100);not null;uniqueIndex:idx_follow_user_target;index:idx_follow_target"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Status group booking. Reserved menahan kuota ticket type sampai dibayar, dibatalkan atau lewat jatuh tempo.
+const (
+	GroupBookingReserved  = "reserved"
+	GroupBookingPaid      = "paid"
+	GroupBookingCancelled = "cancelled"
+	GroupBookingExpired   = "expired"
+)
+
+// GroupBooking adalah pembelian beberapa tiket sekaligus oleh satu pembeli (biasanya perusahaan)
+// yang dibayar lewat transfer bank terhadap invoice, bukan lewat Snap. Organizer menandai lunas manual.
+// PurchaseIP dihitung di batas tiket per IP sampai booking dibatalkan atau kedaluwarsa.
+type GroupBooking struct {
+	ID               int                `json:"id" gorm:"primaryKey"`
+	EventID          int                `json:"event_id" gorm:"not null;index"`
+	TicketTypeID     int                `json:"ticket_type_id" gorm:"not null"`
+	PurchaserID      int                `json:"purchaser_id" gorm:"not null;index"`
+	Quantity         int                `json:"quantity" gorm:"not null"`
+	Company          string             `json:"company" gorm:"type:varchar(200)"`
+	Status           string             `json:"status" gorm:"type:varchar(20);not null;index"`
+	TransactionID    uint               `json:"transaction_id" gorm:"not null"`
+	OrderID          string             `json:"order_id" gorm:"type:varchar(64);not null;uniqueIndex"`
+	InvoiceID        *int               `json:"invoice_id,omitempty"`
+	Amount           int64              `json:"amount" gorm:"not null"` // minor unit, termasuk pajak
+	Currency         string             `json:"currency" gorm:"type:varchar(3);not null"`
+	DueDate          time.Time          `json:"due_date" gorm:"not null"`
+	PaidAt           *time.Time         `json:"paid_at,omitempty"`
+	PaidBy           *int               `json:"paid_by,omitempty"`
+	PaymentReference string             `json:"payment_reference,omitempty" gorm:"type:varchar(100)"`
+	ClosedAt         *time.Time         `json:"closed_at,omitempty"` // dibatalkan atau kedaluwarsa
+	PurchaseIP       string             `json:"-" gorm:"type:varchar(64)"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	Seats            []GroupBookingSeat `json:"seats,omitempty" gorm:"foreignKey:GroupBookingID"`
+}
+
+// GroupBookingSeat adalah satu tiket dalam group booking. Nama dan email peserta diisi pembeli
+// kapan saja sebelum check-in. Jika email milik user terdaftar, kursi ditautkan ke EventAttendee
+// user tersebut (UserID) sehingga tiketnya juga muncul di akun peserta; check-in dicatat di sana.
+type GroupBookingSeat struct {
+	ID             int        `json:"id" gorm:"primaryKey"`
+	GroupBookingID int        `json:"group_booking_id" gorm:"not null;index"`
+	EventID        int        `json:"event_id" gorm:"not null;index"`
+	AttendeeName   string     `json:"attendee_name" gorm:"type:varchar(100)"`
+	AttendeeEmail  string     `json:"attendee_email" gorm:"type:varchar(100)"`
+	UserID         *int       `json:"user_id,omitempty"`
+	TicketCode     *string    `json:"ticket_code,omitempty" gorm:"type:varchar(64);uniqueIndex"` // diisi saat lunas
+	CheckedInAt    *time.Time `json:"checked_in_at,omitempty"`
+	AssignedAt     *time.Time `json:"assigned_at,omitempty"`
+}
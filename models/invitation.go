@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+)
+
+// EventInvitation grants a single user access to a non-public event. Invitations sent to
+// an email address without an account are matched by email once that user signs up.
+type EventInvitation struct {
+	ID         int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID    int        `json:"event_id" gorm:"not null;index"`
+	UserID     *int       `json:"user_id" gorm:"index"`
+	Email      string     `json:"email" gorm:"type:varchar(100);index"`
+	Status     string     `json:"status" gorm:"type:varchar(20);not null"`
+	InvitedBy  int        `json:"invited_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// EventInviteLink is a shareable access code with an optional expiry and usage limit.
+type EventInviteLink struct {
+	ID        int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID   int        `json:"event_id" gorm:"not null;index"`
+	Code      string     `json:"code" gorm:"type:varchar(64);not null;uniqueIndex"`
+	MaxUses   int        `json:"max_uses"` // 0 berarti tidak terbatas
+	UsedCount int        `json:"used_count" gorm:"not null;default:0"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy int        `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsUsable reports whether the link can still grant access at the given time.
+func (l *EventInviteLink) IsUsable(now time.Time) bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	if l.ExpiresAt != nil && now.After(*l.ExpiresAt) {
+		return false
+	}
+	return l.MaxUses == 0 || l.UsedCount < l.MaxUses
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Cara pajak dihitung dari harga tiket. Exclusive: pajak ditambahkan di atas harga;
+// inclusive: harga sudah termasuk pajak.
+const (
+	TaxExclusive = "exclusive"
+	TaxInclusive = "inclusive"
+)
+
+// EventTaxSetting adalah konfigurasi PPN/VAT satu event. Event tanpa setting tidak dikenai pajak.
+// RateBps dalam basis point (1100 = 11%). TaxID kosong berarti memakai NPWP di profil legal organizer.
+type EventTaxSetting struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	EventID   int       `json:"event_id" gorm:"not null;uniqueIndex"`
+	Mode      string    `json:"mode" gorm:"type:varchar(10);not null"`
+	RateBps   int       `json:"rate_bps" gorm:"not null"`
+	TaxName   string    `json:"tax_name" gorm:"type:varchar(20);not null;default:'PPN'"`
+	TaxID     string    `json:"tax_id" gorm:"type:varchar(32)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizerLegalProfile adalah identitas penjual yang dicetak di invoice
+type OrganizerLegalProfile struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	OrganizerID int       `json:"organizer_id" gorm:"not null;uniqueIndex"`
+	LegalName   string    `json:"legal_name" gorm:"type:varchar(200);not null"`
+	TaxID       string    `json:"tax_id" gorm:"type:varchar(32)"`
+	Address     string    `json:"address"`
+	Email       string    `json:"email" gorm:"type:varchar(100)"`
+	Phone       string    `json:"phone" gorm:"type:varchar(30)"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Invoice dibuat bersama transaksi dan tidak diubah lagi, kecuali data pembeli selama transaksi
+// masih pending. Data penjual dan pajak disalin agar invoice tetap sama walau setting berubah.
+// Semua nominal dalam minor unit Currency.
+type Invoice struct {
+	ID            int           `json:"id" gorm:"primaryKey"`
+	Number        string        `json:"number" gorm:"type:varchar(40);not null;uniqueIndex"` // INV-<tanggal>-<id transaksi>
+	TransactionID uint          `json:"transaction_id" gorm:"not null;uniqueIndex"`
+	OrderID       string        `json:"order_id" gorm:"type:varchar(64);not null"`
+	EventID       int           `json:"event_id" gorm:"not null;index"`
+	OrganizerID   int           `json:"organizer_id" gorm:"not null;index"`
+	UserID        int           `json:"user_id" gorm:"not null;index"`
+	Currency      string        `json:"currency" gorm:"type:varchar(3);not null"`
+	TaxMode       string        `json:"tax_mode,omitempty" gorm:"type:varchar(10)"`
+	TaxName       string        `json:"tax_name,omitempty" gorm:"type:varchar(20)"`
+	TaxRateBps    int           `json:"tax_rate_bps" gorm:"not null;default:0"`
+	Subtotal      int64         `json:"subtotal" gorm:"not null"` // tanpa pajak
+	TaxTotal      int64         `json:"tax_total" gorm:"not null"`
+	Total         int64         `json:"total" gorm:"not null"` // sama dengan amount transaksi
+	SellerName    string        `json:"seller_name"`
+	SellerTaxID   string        `json:"seller_tax_id"`
+	SellerAddress string        `json:"seller_address"`
+	BuyerName     string        `json:"buyer_name"`
+	BuyerEmail    string        `json:"buyer_email"`
+	BuyerCompany  string        `json:"buyer_company"`
+	BuyerTaxID    string        `json:"buyer_tax_id"`
+	BuyerAddress  string        `json:"buyer_address"`
+	IssuedAt      time.Time     `json:"issued_at" gorm:"not null"`
+	Lines         []InvoiceLine `json:"lines" gorm:"foreignKey:InvoiceID"`
+}
+
+// InvoiceLine: UnitPrice adalah harga tiket seperti yang diatur organizer (termasuk pajak jika
+// mode inclusive). Net + Tax = Total.
+type InvoiceLine struct {
+	ID          int    `json:"id" gorm:"primaryKey"`
+	InvoiceID   int    `json:"invoice_id" gorm:"not null;index"`
+	Description string `json:"description" gorm:"not null"`
+	Quantity    int    `json:"quantity" gorm:"not null"`
+	UnitPrice   int64  `json:"unit_price" gorm:"not null"`
+	NetAmount   int64  `json:"net_amount" gorm:"not null"`
+	TaxAmount   int64  `json:"tax_amount" gorm:"not null"`
+	TotalAmount int64  `json:"total_amount" gorm:"not null"`
+}
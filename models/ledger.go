@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tipe akun ledger. Saldo akun debit-normal (asset, expense) bertambah karena debit;
+// akun credit-normal (liability, revenue) bertambah karena kredit.
+const (
+	LedgerAsset     = "asset"
+	LedgerLiability = "liability"
+	LedgerRevenue   = "revenue"
+	LedgerExpense   = "expense"
+)
+
+// Akun sistem. Akun organizer dibuat otomatis dengan kode dari OrganizerAccountCode.
+const (
+	// Dana pembeli yang dipegang payment gateway
+	LedgerGatewayClearing = "gateway_clearing"
+	// Pendapatan platform dari fee
+	LedgerPlatformRevenue = "platform_revenue"
+	// Refund yang sudah dibebankan ke organizer tetapi belum dibayarkan gateway ke pembeli
+	LedgerRefunds = "refunds"
+)
+
+// Jenis journal entry
+const (
+	JournalSettlement  = "settlement"
+	JournalPlatformFee = "platform_fee"
+	JournalRefund      = "refund"
+	JournalRefundPaid  = "refund_paid"
+	JournalPayout      = "payout"
+)
+
+// LedgerCurrency adalah mata uang ledger; semua nominal dalam minor unit (1 IDR = 100)
+const LedgerCurrency = "IDR"
+
+// OrganizerAccountCode adalah kode akun liability "yang terutang ke organizer"
+func OrganizerAccountCode(organizerID int) string {
+	return fmt.Sprintf("organizer:%d", organizerID)
+}
+
+type LedgerAccount struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	Code        string    `json:"code" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Type        string    `json:"type" gorm:"type:varchar(20);not null"`
+	OrganizerID *int      `json:"organizer_id,omitempty" gorm:"index"`
+	Currency    string    `json:"currency" gorm:"type:varchar(3);not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// JournalEntry adalah satu kejadian keuangan. (Kind, Reference) unik sehingga kejadian yang
+// sama (mis. notifikasi Midtrans yang dikirim ulang) tidak tercatat dua kali.
+type JournalEntry struct {
+	ID          int             `json:"id" gorm:"primaryKey"`
+	Kind        string          `json:"kind" gorm:"type:varchar(30);not null;uniqueIndex:idx_journal_reference"`
+	Reference   string          `json:"reference" gorm:"type:varchar(100);not null;uniqueIndex:idx_journal_reference"`
+	EventID     *int            `json:"event_id,omitempty" gorm:"index"`
+	Description string          `json:"description"`
+	Postings    []LedgerPosting `json:"postings" gorm:"foreignKey:EntryID"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// LedgerPosting adalah satu baris entry. Amount bertanda: debit positif, kredit negatif,
+// sehingga setiap entry yang seimbang berjumlah nol.
+type LedgerPosting struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	EntryID   int       `json:"entry_id" gorm:"not null;index"`
+	AccountID int       `json:"account_id" gorm:"not null;index"`
+	Amount    int64     `json:"amount" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
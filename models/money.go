@@ -0,0 +1,148 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrUnknownCurrency dikembalikan untuk kode mata uang yang tidak ada di Currencies
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// ErrAmountOutOfRange dikembalikan untuk nominal yang tidak muat di int64 minor unit (atau NaN/Inf)
+var ErrAmountOutOfRange = errors.New("amount out of range")
+
+// DefaultCurrency dipakai untuk event yang tidak memilih mata uang dan untuk data sebelum multi-currency
+const DefaultCurrency = "IDR"
+
+// Currency adalah mata uang ISO 4217. Exponent adalah jumlah digit minor unit (IDR dan USD 2, JPY 0).
+type Currency struct {
+	Code     string `json:"code"`
+	Exponent int    `json:"exponent"`
+	Symbol   string `json:"symbol"`
+	// Rounding adalah nominal terkecil yang dipakai dalam praktik, dalam minor unit. Rupiah tidak
+	// memakai sen, jadi pajak IDR dibulatkan ke 100.
+	Rounding int64 `json:"rounding"`
+}
+
+// Currencies adalah mata uang yang boleh dipilih event. Bisa tidaknya dibayar tergantung payment provider.
+var Currencies = map[string]Currency{
+	"IDR": {Code: "IDR", Exponent: 2, Symbol: "Rp", Rounding: 100},
+	"USD": {Code: "USD", Exponent: 2, Symbol: "$", Rounding: 1},
+	"EUR": {Code: "EUR", Exponent: 2, Symbol: "€", Rounding: 1},
+	"GBP": {Code: "GBP", Exponent: 2, Symbol: "£", Rounding: 1},
+	"SGD": {Code: "SGD", Exponent: 2, Symbol: "S$", Rounding: 1},
+	"MYR": {Code: "MYR", Exponent: 2, Symbol: "RM", Rounding: 1},
+	"AUD": {Code: "AUD", Exponent: 2, Symbol: "A$", Rounding: 1},
+	"THB": {Code: "THB", Exponent: 2, Symbol: "฿", Rounding: 1},
+	"PHP": {Code: "PHP", Exponent: 2, Symbol: "₱", Rounding: 1},
+	"JPY": {Code: "JPY", Exponent: 0, Symbol: "¥", Rounding: 1},
+	"KRW": {Code: "KRW", Exponent: 0, Symbol: "₩", Rounding: 1},
+	"VND": {Code: "VND", Exponent: 0, Symbol: "₫", Rounding: 1},
+}
+
+// LookupCurrency menerima kode huruf kecil maupun besar
+func LookupCurrency(code string) (Currency, error) {
+	currency, ok := Currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+	return currency, nil
+}
+
+// Money adalah nominal dalam minor unit mata uangnya (Rp15.000 = {1500000, "IDR"}).
+// Integer dipakai agar penjumlahan dan perbandingan tidak terkena pembulatan float.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MoneyFromMajor mengubah nominal desimal (mis. 15000 atau 9.99) ke minor unit
+func MoneyFromMajor(value float64, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	minor := math.Round(value * math.Pow10(c.Exponent))
+	// 2^63 sendiri sudah di luar int64; float64 tidak bisa merepresentasikan MaxInt64 dengan tepat
+	if math.IsNaN(minor) || minor >= math.MaxInt64 || minor < math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %v %s", ErrAmountOutOfRange, value, c.Code)
+	}
+	return Money{Amount: int64(minor), Currency: c.Code}, nil
+}
+
+// Payable true jika nominal merupakan kelipatan pecahan terkecil yang dipakai mata uangnya
+// (rupiah bulat untuk IDR), sehingga bisa ditagihkan ke payment provider.
+func (m Money) Payable() bool {
+	c, err := LookupCurrency(m.Currency)
+	if err != nil {
+		return false
+	}
+	return c.Rounding <= 1 || m.Amount%c.Rounding == 0
+}
+
+// Major mengembalikan nominal desimal, hanya untuk tampilan dan API eksternal
+func (m Money) Major() float64 {
+	c, err := LookupCurrency(m.Currency)
+	if err != nil {
+		return float64(m.Amount)
+	}
+	return float64(m.Amount) / math.Pow10(c.Exponent)
+}
+
+// Decimal memformat nominal tanpa pemisah ribuan dengan titik desimal, mis. "15000.00"
+func (m Money) Decimal() string {
+	c, err := LookupCurrency(m.Currency)
+	if err != nil || c.Exponent == 0 {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+	scale := int64(math.Pow10(c.Exponent))
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, c.Exponent, amount%scale)
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Format memformat nominal sesuai bahasa user: "Rp150.000" atau "$9,99" (id), "IDR 150,000" atau
+// "USD 9.99" (en). Desimal nol tidak ditampilkan.
+func (m Money) Format(locale string) string {
+	c, err := LookupCurrency(m.Currency)
+	if err != nil {
+		return m.String()
+	}
+
+	group, decimal, prefix := ".", ",", c.Symbol
+	if locale == LocaleEnglish {
+		group, decimal, prefix = ",", ".", c.Code+" "
+	}
+
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	scale := int64(math.Pow10(c.Exponent))
+	digits := fmt.Sprintf("%d", amount/scale)
+
+	var b strings.Builder
+	b.WriteString(sign + prefix)
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(group)
+		}
+		b.WriteRune(r)
+	}
+	if fraction := amount % scale; fraction != 0 {
+		fmt.Fprintf(&b, "%s%0*d", decimal, c.Exponent, fraction)
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMoneyFromMajor(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{"rupiah", 15000, "IDR", Money{1500000, "IDR"}, nil},
+		{"lowercase code", 15000, "idr", Money{1500000, "IDR"}, nil},
+		{"two decimals", 9.99, "USD", Money{999, "USD"}, nil},
+		{"float representation rounds to nearest cent", 0.29, "USD", Money{29, "USD"}, nil},
+		{"zero decimal currency", 500, "JPY", Money{500, "JPY"}, nil},
+		{"zero decimal currency rounds fraction", 1.5, "JPY", Money{2, "JPY"}, nil},
+		{"zero", 0, "IDR", Money{0, "IDR"}, nil},
+		{"negative", -12.34, "USD", Money{-1234, "USD"}, nil},
+		{"negative zero decimal", -700, "KRW", Money{-700, "KRW"}, nil},
+		{"largest safe rupiah", 90_000_000_000_000, "IDR", Money{9_000_000_000_000_000, "IDR"}, nil},
+		{"overflow after scaling", 1e17, "IDR", Money{}, ErrAmountOutOfRange},
+		{"negative overflow", -1e19, "JPY", Money{}, ErrAmountOutOfRange},
+		{"infinity", math.Inf(1), "USD", Money{}, ErrAmountOutOfRange},
+		{"not a number", math.NaN(), "USD", Money{}, ErrAmountOutOfRange},
+		{"unknown currency", 10, "XXX", Money{}, ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MoneyFromMajor(tt.value, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MoneyFromMajor(%v, %s) = %+v, want %+v", tt.value, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyRoundTrip(t *testing.T) {
+	tests := []struct {
+		value    float64
+		currency string
+	}{
+		{15000, "IDR"},
+		{150000.5, "IDR"},
+		{9.99, "USD"},
+		{0.01, "EUR"},
+		{1234567.89, "SGD"},
+		{-42.5, "GBP"},
+		{500, "JPY"},
+		{25000, "VND"},
+	}
+	for _, tt := range tests {
+		money, err := MoneyFromMajor(tt.value, tt.currency)
+		if err != nil {
+			t.Fatalf("MoneyFromMajor(%v, %s): %v", tt.value, tt.currency, err)
+		}
+		if got := money.Major(); got != tt.value {
+			t.Errorf("round trip %v %s: Major() = %v", tt.value, tt.currency, got)
+		}
+		back, err := MoneyFromMajor(money.Major(), money.Currency)
+		if err != nil || back != money {
+			t.Errorf("round trip %v %s: got %+v (%v), want %+v", tt.value, tt.currency, back, err, money)
+		}
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{1500000, "IDR"}, "15000.00"},
+		{Money{999, "USD"}, "9.99"},
+		{Money{5, "USD"}, "0.05"},
+		{Money{-1234, "USD"}, "-12.34"},
+		{Money{-5, "EUR"}, "-0.05"},
+		{Money{500, "JPY"}, "500"},
+		{Money{0, "IDR"}, "0.00"},
+		{Money{42, "XXX"}, "42"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.want {
+			t.Errorf("%+v.Decimal() = %q, want %q", tt.money, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		money  Money
+		locale string
+		want   string
+	}{
+		{Money{15000000, "IDR"}, LocaleIndonesian, "Rp150.000"},
+		{Money{15000000, "IDR"}, LocaleEnglish, "IDR 150,000"},
+		{Money{15000050, "IDR"}, LocaleIndonesian, "Rp150.000,50"},
+		{Money{999, "USD"}, LocaleIndonesian, "$9,99"},
+		{Money{999, "USD"}, LocaleEnglish, "USD 9.99"},
+		{Money{123456789, "USD"}, LocaleEnglish, "USD 1,234,567.89"},
+		{Money{5, "USD"}, LocaleEnglish, "USD 0.05"},
+		{Money{0, "IDR"}, LocaleIndonesian, "Rp0"},
+		{Money{-250000, "IDR"}, LocaleIndonesian, "-Rp2.500"},
+		{Money{-1234, "USD"}, LocaleEnglish, "-USD 12.34"},
+		{Money{1000000, "JPY"}, LocaleIndonesian, "¥1.000.000"},
+		{Money{1000000, "JPY"}, LocaleEnglish, "JPY 1,000,000"},
+		{Money{100, "KRW"}, LocaleEnglish, "KRW 100"},
+		{Money{42, "XXX"}, LocaleEnglish, "42 XXX"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.Format(tt.locale); got != tt.want {
+			t.Errorf("%+v.Format(%s) = %q, want %q", tt.money, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyPayable(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  bool
+	}{
+		{Money{1500000, "IDR"}, true},
+		{Money{1500050, "IDR"}, false},
+		{Money{-100, "IDR"}, true},
+		{Money{999, "USD"}, true},
+		{Money{1, "JPY"}, true},
+		{Money{100, "XXX"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.money.Payable(); got != tt.want {
+			t.Errorf("%+v.Payable() = %v, want %v", tt.money, got, tt.want)
+		}
+	}
+}
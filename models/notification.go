@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Notification types sent by the platform. Each type has a template per locale.
+const (
+	NotificationRegistrationConfirmed = "registration_confirmed"
+	NotificationPaymentReceived       = "payment_received"
+	NotificationPaymentExpiring       = "payment_expiring"
+	NotificationPaymentExpired        = "payment_expired"
+	NotificationEventReminder24h      = "event_reminder_24h"
+	NotificationEventReminder1h       = "event_reminder_1h"
+	NotificationEventChanged          = "event_changed"
+	NotificationEventCancelled        = "event_cancelled"
+)
+
+// NotificationTypes lists every supported type, in the order shown to users.
+var NotificationTypes = []string{
+	NotificationRegistrationConfirmed,
+	NotificationPaymentReceived,
+	NotificationPaymentExpiring,
+	NotificationPaymentExpired,
+	NotificationEventReminder24h,
+	NotificationEventReminder1h,
+	NotificationEventChanged,
+	NotificationEventCancelled,
+}
+
+// Supported notification locales; LocaleIndonesian is the default.
+const (
+	LocaleIndonesian = "id"
+	LocaleEnglish    = "en"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSending = "sending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
+)
+
+// NotificationPreference stores a user's opt-out/opt-in for one type on one channel.
+// A missing row means the notification is enabled.
+type NotificationPreference struct {
+	ID        int       `json:"-" gorm:"primaryKey;autoIncrement"`
+	UserID    int       `json:"-" gorm:"not null;uniqueIndex:idx_notification_pref"`
+	Type      string    `json:"type" gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_pref"`
+	Channel   string    `json:"channel" gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_pref"`
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationOutbox is one notification waiting to be rendered and sent on one channel.
+// DedupKey prevents the same event from notifying a user twice.
+type NotificationOutbox struct {
+	ID          int            `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      int            `json:"user_id" gorm:"not null;index"`
+	Type        string         `json:"type" gorm:"type:varchar(50);not null"`
+	Channel     string         `json:"channel" gorm:"type:varchar(20);not null"`
+	Payload     map[string]any `json:"payload" gorm:"type:jsonb;serializer:json"`
+	DedupKey    string         `json:"-" gorm:"type:varchar(200);not null;uniqueIndex"`
+	Status      string         `json:"status" gorm:"type:varchar(20);not null;index:idx_outbox_status_available"`
+	Attempts    int            `json:"attempts" gorm:"not null;default:0"`
+	LastError   string         `json:"last_error,omitempty" gorm:"type:text"`
+	AvailableAt time.Time      `json:"available_at" gorm:"not null;index:idx_outbox_status_available"`
+	SentAt      *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Status payout organizer. Requested dan batched masih menahan saldo organizer.
+const (
+	PayoutRequested = "requested"
+	PayoutBatched   = "batched"
+	PayoutApproved  = "approved"
+	PayoutRejected  = "rejected"
+	PayoutCancelled = "cancelled"
+)
+
+// Status batch payout
+const (
+	PayoutBatchPending  = "pending"
+	PayoutBatchApproved = "approved"
+	PayoutBatchRejected = "rejected"
+)
+
+// FeeRule adalah fee platform yang dipotong dari setiap settlement: persen (basis point,
+// 250 = 2.5%) ditambah nominal tetap dalam minor unit. Rule event mengalahkan rule organizer;
+// tanpa keduanya dipakai default dari config.
+type FeeRule struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	EventID     *int      `json:"event_id,omitempty" gorm:"uniqueIndex"`
+	OrganizerID *int      `json:"organizer_id,omitempty" gorm:"uniqueIndex"`
+	PercentBps  int       `json:"percent_bps" gorm:"not null;default:0"`
+	FixedAmount int64     `json:"fixed_amount" gorm:"not null;default:0"`
+	CreatedBy   int       `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Payout adalah permintaan pencairan saldo organizer ke rekening bank. Amount dalam minor unit.
+type Payout struct {
+	ID            int        `json:"id" gorm:"primaryKey"`
+	OrganizerID   int        `json:"organizer_id" gorm:"not null;index"`
+	Amount        int64      `json:"amount" gorm:"not null"`
+	Currency      string     `json:"currency" gorm:"type:varchar(3);not null"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	BankCode      string     `json:"bank_code" gorm:"type:varchar(20)"`
+	BankName      string     `json:"bank_name" gorm:"type:varchar(100)"`
+	AccountNumber string     `json:"account_number" gorm:"type:varchar(50);not null"`
+	AccountHolder string     `json:"account_holder" gorm:"type:varchar(100);not null"`
+	Note          string     `json:"note,omitempty"`
+	BatchID       *int       `json:"batch_id,omitempty" gorm:"index"`
+	RequestedAt   time.Time  `json:"requested_at" gorm:"not null"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"` // approved, rejected atau cancelled
+}
+
+// PayoutBatch mengelompokkan payout yang disetujui admin sekaligus dan diekspor sebagai satu file transfer bank
+type PayoutBatch struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	Status     string     `json:"status" gorm:"type:varchar(20);not null"`
+	Count      int        `json:"count" gorm:"not null"`
+	Total      int64      `json:"total" gorm:"not null"`
+	Currency   string     `json:"currency" gorm:"type:varchar(3);not null"`
+	CreatedBy  int        `json:"created_by"`
+	ReviewedBy *int       `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Payouts    []Payout   `json:"payouts,omitempty" gorm:"foreignKey:BatchID"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// EventPurchasePolicy berisi batas pembelian dan proteksi bot satu event. Batas bernilai 0
+// berarti tidak dibatasi. Event tanpa policy hanya terkena rate limit global.
+type EventPurchasePolicy struct {
+	ID                int       `json:"id" gorm:"primaryKey"`
+	EventID           int       `json:"event_id" gorm:"not null;uniqueIndex"`
+	MaxTicketsPerUser int       `json:"max_tickets_per_user" gorm:"not null;default:0"` // termasuk tiket group booking
+	MaxTicketsPerIP   int       `json:"max_tickets_per_ip" gorm:"not null;default:0"`
+	RequireChallenge  bool      `json:"require_challenge" gorm:"not null;default:false"`
+	QueueEnabled      bool      `json:"queue_enabled" gorm:"not null;default:false"`
+	QueueBatchSize    int       `json:"queue_batch_size" gorm:"not null;default:50"` // maksimal user yang masuk checkout per putaran
+	QueueAdmitRate    int       `json:"queue_admit_rate" gorm:"not null;default:0"`  // user per menit; 0 = hanya dibatasi batch dan inventory
+	CheckoutWindow    int       `json:"checkout_window" gorm:"not null;default:10"`  // menit untuk menyelesaikan registrasi setelah masuk
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// RateLimitCounter adalah counter fixed-window yang dipakai bersama semua instance API,
+// untuk rate limit percobaan, jumlah tiket per IP dan penanda challenge yang sudah dipakai.
+type RateLimitCounter struct {
+	Key         string    `gorm:"primaryKey;type:varchar(200)"`
+	WindowStart time.Time `gorm:"primaryKey"`
+	Count       int       `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
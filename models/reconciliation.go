@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Status run rekonsiliasi
+const (
+	ReconciliationRunning   = "running"
+	ReconciliationCompleted = "completed"
+	ReconciliationFailed    = "failed"
+)
+
+// Jenis selisih antara tabel transactions dan payment gateway
+const (
+	DiscrepancyUnrecordedSettlement = "unrecorded_settlement" // gateway lunas, lokal belum
+	DiscrepancyStatusMismatch       = "status_mismatch"       // status lain berbeda (expire, cancel, deny, refund, ...)
+	DiscrepancyAmountMismatch       = "amount_mismatch"       // gross_amount gateway berbeda dengan amount lokal
+	DiscrepancyStuckPending         = "stuck_pending"         // masih pending di kedua sisi melewati masa berlaku pembayaran
+	DiscrepancyMissingAtGateway     = "missing_at_gateway"    // order tidak dikenal gateway
+	DiscrepancyGatewayError         = "gateway_error"         // status gateway tidak bisa diambil
+)
+
+// ReconciliationRun adalah satu putaran pengecekan transaksi terhadap payment gateway.
+// Partial unique index memastikan hanya ada satu run berjalan di seluruh instance.
+type ReconciliationRun struct {
+	ID            int        `json:"id" gorm:"primaryKey"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;uniqueIndex:idx_reconciliation_running,where:status = 'running'"`
+	TriggeredBy   *int       `json:"triggered_by"` // nil untuk job terjadwal
+	Checked       int        `json:"checked" gorm:"not null;default:0"`
+	Repaired      int        `json:"repaired" gorm:"not null;default:0"`
+	Discrepancies int        `json:"discrepancies" gorm:"not null;default:0"`
+	Error         string     `json:"error,omitempty"`
+	StartedAt     time.Time  `json:"started_at" gorm:"not null"`
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+type ReconciliationDiscrepancy struct {
+	ID            int       `json:"id" gorm:"primaryKey"`
+	RunID         int       `json:"run_id" gorm:"not null;index"`
+	TransactionID uint      `json:"transaction_id" gorm:"not null"`
+	OrderID       string    `json:"order_id"`
+	EventID       int       `json:"event_id"`
+	UserID        int       `json:"user_id"`
+	Kind          string    `json:"kind" gorm:"type:varchar(30);not null"`
+	LocalStatus   string    `json:"local_status"`
+	GatewayStatus string    `json:"gateway_status"`
+	LocalAmount   float64   `json:"local_amount"`
+	GatewayAmount *float64  `json:"gateway_amount"`
+	Repaired      bool      `json:"repaired" gorm:"not null;default:false"`
+	Note          string    `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+}
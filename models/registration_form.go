@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Supported registration form field types.
+const (
+	FormFieldText     = "text"
+	FormFieldSelect   = "select"
+	FormFieldCheckbox = "checkbox"
+	FormFieldNumber   = "number"
+	FormFieldDate     = "date"
+	FormFieldFile     = "file"
+)
+
+// FormFieldCondition makes a field visible only when another field has the given answer.
+type FormFieldCondition struct {
+	Field  string `json:"field"`
+	Equals any    `json:"equals"`
+}
+
+type FormField struct {
+	Key       string              `json:"key"`
+	Label     string              `json:"label"`
+	Type      string              `json:"type"`
+	Required  bool                `json:"required"`
+	Options   []string            `json:"options,omitempty"`    // select; checkbox dengan beberapa pilihan
+	Min       *float64            `json:"min,omitempty"`        // number
+	Max       *float64            `json:"max,omitempty"`        // number
+	MaxLength int                 `json:"max_length,omitempty"` // text
+	ShowIf    *FormFieldCondition `json:"show_if,omitempty"`
+}
+
+// RegistrationForm is an organizer-defined schema. A form bound to a ticket type
+// takes precedence over the event-wide form (TicketTypeID nil).
+type RegistrationForm struct {
+	ID           int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID      int         `json:"event_id" gorm:"not null;index"`
+	TicketTypeID *int        `json:"ticket_type_id" gorm:"index"`
+	Fields       []FormField `json:"fields" gorm:"type:jsonb;serializer:json"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// RegistrationAnswer stores one attendee's validated answers, keyed by field key.
+type RegistrationAnswer struct {
+	ID        int            `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID   int            `json:"event_id" gorm:"not null;uniqueIndex:idx_answer_event_user"`
+	UserID    int            `json:"user_id" gorm:"not null;uniqueIndex:idx_answer_event_user"`
+	FormID    int            `json:"form_id"`
+	Answers   map[string]any `json:"answers" gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// FormUpload is a file uploaded for a "file" field before registering; the answer stores its Token.
+type FormUpload struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Token       string    `json:"token" gorm:"type:varchar(64);not null;uniqueIndex"`
+	EventID     int       `json:"event_id" gorm:"not null;index"`
+	UserID      int       `json:"user_id" gorm:"not null;index"`
+	FieldKey    string    `json:"field_key"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Path        string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status kursi seperti yang dilihat user saat memilih; dihitung dari hold dan pendaftaran, tidak disimpan
+const (
+	SeatAvailable = "available"
+	SeatHeld      = "held"
+	SeatHeldByYou = "held_by_you"
+	SeatTaken     = "taken"
+	SeatBlocked   = "blocked"
+)
+
+// SeatMap adalah denah kursi satu event (tata letak venue). Event dengan seat map mewajibkan
+// pemilihan kursi saat registrasi; kuota ticket type tetap berlaku.
+type SeatMap struct {
+	ID        int        `json:"id" gorm:"primaryKey"`
+	EventID   int        `json:"event_id" gorm:"not null;uniqueIndex"`
+	Name      string     `json:"name" gorm:"type:varchar(100);not null"`
+	Zones     []SeatZone `json:"zones" gorm:"foreignKey:SeatMapID"`
+	Seats     []Seat     `json:"seats" gorm:"foreignKey:SeatMapID"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// SeatZone adalah zona harga; harga kursi mengikuti ticket type zona tersebut
+type SeatZone struct {
+	ID           int    `json:"id" gorm:"primaryKey"`
+	SeatMapID    int    `json:"seat_map_id" gorm:"not null;index"`
+	Name         string `json:"name" gorm:"type:varchar(50);not null"`
+	TicketTypeID int    `json:"ticket_type_id" gorm:"not null"`
+}
+
+// Seat: HeldBy/HeldUntil adalah hold sementara selama user menyelesaikan registrasi. Kursi yang
+// sudah terdaftar ditandai lewat event_attendees.seat_id, jadi otomatis lepas saat pendaftaran dihapus.
+type Seat struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	SeatMapID  int        `json:"seat_map_id" gorm:"not null;uniqueIndex:idx_seat_position"`
+	EventID    int        `json:"event_id" gorm:"not null;index"`
+	ZoneID     int        `json:"zone_id" gorm:"not null"`
+	Section    string     `json:"section" gorm:"type:varchar(50);not null;uniqueIndex:idx_seat_position"`
+	Row        string     `json:"row" gorm:"type:varchar(20);not null;uniqueIndex:idx_seat_position"`
+	Number     string     `json:"number" gorm:"type:varchar(20);not null;uniqueIndex:idx_seat_position"`
+	Accessible bool       `json:"accessible" gorm:"not null;default:false"` // akses kursi roda
+	Blocked    bool       `json:"blocked" gorm:"not null;default:false"`    // tidak dijual (kamera, pandangan terhalang)
+	HeldBy     *int       `json:"-"`
+	HeldUntil  *time.Time `json:"-" gorm:"index"`
+}
+
+// Label adalah posisi kursi seperti dicetak di tiket
+func (s Seat) Label() string {
+	return fmt.Sprintf("Section %s, Row %s, Seat %s", s.Section, s.Row, s.Number)
+}
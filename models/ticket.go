@@ -6,7 +6,7 @@ type Ticket struct {
 	Id         int        `json:"id" form:"id" gorm:"primaryKey"`
 	TikcetUuid string     `json:"ticketUuid" form:"ticketUuid" gorm:"size:255"`
 	TicketType string     `json:"ticketType"`
-	Price      int        `json:"price"`
+	Price      int64      `json:"price"` // minor unit mata uang event (Rp15.000 = 1500000)
 	Quota      int        `json:"quota"`
 	Status     string     `json:"status"`
 	CreatedAt  time.Time  `json:"createdAt"`
@@ -6,12 +6,20 @@ import (
 	"gorm.io/gorm"
 )
 
+// Cara transaksi dibayar. Transaksi invoice dibayar lewat transfer bank dan ditandai lunas manual
+// oleh organizer, jadi tidak dicek ke Midtrans oleh job expiry maupun rekonsiliasi.
+const (
+	PaymentGatewayMidtrans = "midtrans"
+	PaymentGatewayInvoice  = "invoice"
+)
+
 type Transactions struct {
 	gorm.Model
 	UserId                      int       `json:"user_id" gorm:"not null;index"`
 	EventId                     int       `json:"event_id" gorm:"not null;index"`
 	Event                       Event    `gorm:"foreignKey:EventId;references:ID"`
-	Amount                      float64   `json:"amount" gorm:"not null"`
+	Amount                      int64     `json:"amount" gorm:"not null"` // minor unit, lihat Money
+	Currency                    string    `json:"currency" gorm:"type:varchar(3);not null;default:'IDR'"`
 	TransactionDate             time.Time `json:"transaction_date" gorm:"not null"`
 	Status                      string    `json:"status" gorm:"not null"`
 	PaymentMethod               string    `json:"payment_method" gorm:"not null"`
@@ -19,5 +27,9 @@ type Transactions struct {
 	Items                       string    `json:"items" gorm:"not null"`
 	Notes                       string    `json:"notes"`
 	Url                         string    `json:"url"`
+	Gateway                     string    `json:"gateway" gorm:"type:varchar(20);not null;default:'midtrans'"`
 }
 
+func (t Transactions) Money() Money {
+	return Money{Amount: t.Amount, Currency: t.Currency}
+}
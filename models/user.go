@@ -14,6 +14,8 @@ type User struct {
 	Email    string `json:"email" gorm:"type:varchar(100);unique;not null"`
 	Password string `json:"-" gorm:"type:varchar(255);not null"`
 	Role     string `json:"role" gorm:"type:varchar(50);default:'user'"`
+	Locale   string `json:"locale" gorm:"type:varchar(5);not null;default:'id'"` // bahasa notifikasi: id atau en
+	Verified bool   `json:"verified" gorm:"not null;default:false"`              // identitas sudah diverifikasi admin
 }
 
 func (u *User) HashPassword(password string) error {
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Status antrean virtual. Waiting menunggu giliran; admitted boleh registrasi sampai ExpiresAt;
+// completed sudah mendapatkan tiket; expired melewatkan jendela checkout dan harus antre ulang.
+const (
+	QueueWaiting   = "waiting"
+	QueueAdmitted  = "admitted"
+	QueueCompleted = "completed"
+	QueueExpired   = "expired"
+)
+
+// QueueEntry adalah satu user di antrean virtual event. Urutan antrean mengikuti ID;
+// antre ulang memberi ID baru sehingga user kembali ke belakang.
+type QueueEntry struct {
+	ID         int64      `json:"id" gorm:"primaryKey;index:idx_queue_event_status,priority:3"`
+	EventID    int        `json:"event_id" gorm:"not null;uniqueIndex:idx_queue_event_user;index:idx_queue_event_status,priority:1;index:idx_queue_event_admitted,priority:1"`
+	UserID     int        `json:"user_id" gorm:"not null;uniqueIndex:idx_queue_event_user"`
+	Status     string     `json:"status" gorm:"type:varchar(20);not null;index:idx_queue_event_status,priority:2"`
+	JoinedAt   time.Time  `json:"joined_at" gorm:"not null"`
+	AdmittedAt *time.Time `json:"admitted_at,omitempty" gorm:"index:idx_queue_event_admitted,priority:2"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // akhir jendela checkout
+}
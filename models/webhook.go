@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Webhook event types organizers can subscribe to.
+const (
+	WebhookAttendeeRegistered = "attendee.registered"
+	WebhookAttendeeCancelled  = "attendee.cancelled"
+	WebhookPaymentSettled     = "payment.settled"
+	WebhookCheckInCreated     = "checkin.created"
+)
+
+var WebhookEventTypes = []string{
+	WebhookAttendeeRegistered,
+	WebhookAttendeeCancelled,
+	WebhookPaymentSettled,
+	WebhookCheckInCreated,
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySending   = "sending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEndpoint receives events of one organizer; EventID nil means all of the organizer's events.
+type WebhookEndpoint struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizerID int       `json:"organizer_id" gorm:"not null;index"`
+	EventID     *int      `json:"event_id" gorm:"index"`
+	URL         string    `json:"url" gorm:"type:varchar(500);not null"`
+	Secret      string    `json:"-" gorm:"type:varchar(100);not null"`
+	EventTypes  []string  `json:"event_types" gorm:"type:jsonb;serializer:json"`
+	Description string    `json:"description" gorm:"type:varchar(200)"`
+	Active      bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event to be sent to one endpoint. Body is stored verbatim so that
+// retries and manual redeliveries send (and sign) exactly the same bytes.
+type WebhookDelivery struct {
+	ID             int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	EndpointID     int        `json:"endpoint_id" gorm:"not null;index;uniqueIndex:idx_webhook_delivery_payload"`
+	PayloadID      string     `json:"payload_id" gorm:"type:varchar(150);uniqueIndex:idx_webhook_delivery_payload"` // id di body; satu delivery per endpoint
+	EventType      string     `json:"event_type" gorm:"type:varchar(50);not null"`
+	EventID        int        `json:"event_id" gorm:"not null"`
+	Body           string     `json:"body" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;index:idx_webhook_delivery_due"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"not null;index:idx_webhook_delivery_due"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookAttempt logs a single HTTP request made for a delivery.
+type WebhookAttempt struct {
+	ID         int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	DeliveryID int       `json:"delivery_id" gorm:"not null;index"`
+	Attempt    int       `json:"attempt" gorm:"not null"`
+	Manual     bool      `json:"manual" gorm:"not null;default:false"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" gorm:"type:text"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Kunci advisory lock Postgres untuk job yang hanya boleh berjalan di satu instance sekaligus
+const (
+	PaymentExpiryLockKey int64 = 7240001
+	VirtualQueueLockKey  int64 = 7240002
+)
+
+type AdvisoryLocker interface {
+	// TryWithLock menjalankan fn hanya jika lock didapat; false berarti instance lain sedang memegangnya
+	TryWithLock(ctx context.Context, key int64, fn func() error) (bool, error)
+}
+
+type advisoryLocker struct {
+	db *gorm.DB
+}
+
+func NewAdvisoryLocker(db *gorm.DB) *advisoryLocker {
+	return &advisoryLocker{db: db}
+}
+
+// TryWithLock memakai session-level lock pada satu koneksi khusus: lock dan unlock harus lewat
+// koneksi yang sama, dan lock otomatis lepas jika koneksi putus (mis. proses mati).
+func (l *advisoryLocker) TryWithLock(ctx context.Context, key int64, fn func() error) (bool, error) {
+	acquired := false
+	err := l.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+		// Context terpisah: koneksi kembali ke pool saat selesai, jadi unlock tidak boleh ikut batal
+		defer conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", key)
+		return fn()
+	})
+	return acquired, err
+}
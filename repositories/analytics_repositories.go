@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status pembayaran pendaftaran yang dianggap lunas; settlement dan capture tersisa dari data lama
+// sebelum pembayaran Midtrans langsung menerbitkan tiket
+var paidAttendeeStatuses = []string{"paid", "settlement", "capture"}
+
+// Status transaksi Midtrans yang dihitung sebagai pendapatan
+var settledTransactionStatuses = []string{"settlement", "capture"}
+
+type AnalyticsRepository interface {
+	OrganizerEvents(ctx context.Context, organizerID, limit int) ([]models.Event, error)
+	Funnel(ctx context.Context, eventIDs []int) ([]dto.AnalyticsFunnelRow, error)
+	Revenue(ctx context.Context, eventIDs []int) ([]dto.AnalyticsRevenueRow, error)
+	RevenueByTicketType(ctx context.Context, eventID int) ([]dto.TicketTypeRevenue, error)
+	TaxSummary(ctx context.Context, eventID int) ([]dto.TaxSummary, error)
+	Demographics(ctx context.Context, eventID int) (*dto.AttendeeDemographics, error)
+	Series(ctx context.Context, eventIDs []int, bucket, timeZone string, from, to time.Time) ([]dto.AnalyticsSeriesRow, error)
+}
+
+type analyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) *analyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+func (r *analyticsRepository) OrganizerEvents(ctx context.Context, organizerID, limit int) ([]models.Event, error) {
+	var events []models.Event
+	err := r.db.WithContext(ctx).
+		Where("organizer_id = ?", organizerID).
+		Order("start_date DESC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Funnel menggabungkan pendaftaran aktif dan yang sudah dibatalkan agar konversi dihitung dari semua pendaftaran
+func (r *analyticsRepository) Funnel(ctx context.Context, eventIDs []int) ([]dto.AnalyticsFunnelRow, error) {
+	var rows []dto.AnalyticsFunnelRow
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT event_id,
+			COUNT(*) AS registrations,
+			COUNT(*) FILTER (WHERE payment_status IN @paid) AS paid,
+			COUNT(*) FILTER (WHERE cancelled) AS cancelled,
+			COUNT(*) FILTER (WHERE checked_in_at IS NOT NULL) AS checked_in
+		FROM (
+			SELECT event_id, payment_status, false AS cancelled, checked_in_at
+			FROM event_attendees WHERE event_id IN @events
+			UNION ALL
+			SELECT event_id, payment_status, true, NULL
+			FROM registration_cancellations WHERE event_id IN @events
+		) r
+		GROUP BY event_id`,
+		map[string]any{"events": eventIDs, "paid": paidAttendeeStatuses},
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *analyticsRepository) Revenue(ctx context.Context, eventIDs []int) ([]dto.AnalyticsRevenueRow, error) {
+	var rows []dto.AnalyticsRevenueRow
+	err := r.db.WithContext(ctx).Model(&models.Transactions{}).
+		Select("event_id, COUNT(*) AS transactions, COALESCE(SUM(amount), 0)::bigint AS revenue").
+		Where("event_id IN ? AND status IN ?", eventIDs, settledTransactionStatuses).
+		Group("event_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// RevenueByTicketType mencocokkan transaksi ke pendaftarannya lewat (event, user, rsvp_date = transaction_date),
+// cara yang sama dipakai transactionRepository.UpdateStatus. Pendaftaran yang sudah dibatalkan tetap dihitung.
+func (r *analyticsRepository) RevenueByTicketType(ctx context.Context, eventID int) ([]dto.TicketTypeRevenue, error) {
+	var rows []dto.TicketTypeRevenue
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT reg.ticket_type_id, COALESCE(t.ticket_type, '') AS ticket_type,
+			COUNT(tx.id) AS transactions, COALESCE(SUM(tx.amount), 0)::bigint AS revenue
+		FROM transactions tx
+		LEFT JOIN (
+			SELECT event_id, user_id, ticket_type_id, rsvp_date AS registered_at FROM event_attendees WHERE event_id = @event
+			UNION ALL
+			SELECT event_id, user_id, ticket_type_id, registered_at FROM registration_cancellations WHERE event_id = @event
+		) reg ON reg.event_id = tx.event_id AND reg.user_id = tx.user_id AND reg.registered_at = tx.transaction_date
+		LEFT JOIN tickets t ON t.id = reg.ticket_type_id
+		WHERE tx.event_id = @event AND tx.status IN @settled AND tx.deleted_at IS NULL
+		GROUP BY reg.ticket_type_id, t.ticket_type
+		ORDER BY reg.ticket_type_id NULLS LAST`,
+		map[string]any{"event": eventID, "settled": settledTransactionStatuses},
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// TaxSummary menjumlahkan invoice yang transaksinya lunas, per mata uang dan tarif pajak
+func (r *analyticsRepository) TaxSummary(ctx context.Context, eventID int) ([]dto.TaxSummary, error) {
+	var rows []dto.TaxSummary
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT i.currency, COALESCE(i.tax_name, '') AS tax_name, i.tax_rate_bps,
+			COUNT(*) AS invoices,
+			SUM(i.subtotal)::bigint AS subtotal,
+			SUM(i.tax_total)::bigint AS tax_total,
+			SUM(i.total)::bigint AS total
+		FROM invoices i
+		JOIN transactions t ON t.id = i.transaction_id
+		WHERE i.event_id = ? AND t.status IN ? AND t.deleted_at IS NULL
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3`,
+		eventID, settledTransactionStatuses,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Demographics mengelompokkan umur peserta aktif; umur 0 dianggap tidak diisi
+func (r *analyticsRepository) Demographics(ctx context.Context, eventID int) (*dto.AttendeeDemographics, error) {
+	demographics := &dto.AttendeeDemographics{}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT CASE
+				WHEN u.age <= 0 THEN 'unknown'
+				WHEN u.age < 18 THEN 'under_18'
+				WHEN u.age < 25 THEN '18_24'
+				WHEN u.age < 35 THEN '25_34'
+				WHEN u.age < 45 THEN '35_44'
+				WHEN u.age < 55 THEN '45_54'
+				ELSE '55_plus'
+			END AS age_group,
+			COUNT(*) AS count
+		FROM event_attendees a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.event_id = ?
+		GROUP BY 1`, eventID,
+	).Scan(&demographics.AgeGroups).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var averageAge sql.NullFloat64
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT AVG(u.age)::float8
+		FROM event_attendees a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.event_id = ? AND u.age > 0`, eventID,
+	).Scan(&averageAge).Error
+	if err != nil {
+		return nil, err
+	}
+	if averageAge.Valid {
+		demographics.AverageAge = &averageAge.Float64
+	}
+	return demographics, nil
+}
+
+// Series menghitung metrik per event per bucket (hari atau minggu) di zona waktu timeZone.
+// Bucket kosong tidak dikembalikan; pengisian nol dilakukan di usecase.
+func (r *analyticsRepository) Series(ctx context.Context, eventIDs []int, bucket, timeZone string, from, to time.Time) ([]dto.AnalyticsSeriesRow, error) {
+	var rows []dto.AnalyticsSeriesRow
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT m.event_id,
+			to_char(date_trunc(@bucket, m.at AT TIME ZONE @tz), 'YYYY-MM-DD') AS bucket_start,
+			m.metric,
+			COUNT(*) AS count,
+			COALESCE(SUM(m.amount), 0)::bigint AS amount
+		FROM (
+			SELECT event_id, rsvp_date AS at, 'registrations' AS metric, 0::bigint AS amount
+			FROM event_attendees WHERE event_id IN @events
+			UNION ALL
+			SELECT event_id, registered_at, 'registrations', 0
+			FROM registration_cancellations WHERE event_id IN @events
+			UNION ALL
+			SELECT event_id, cancelled_at, 'cancellations', 0
+			FROM registration_cancellations WHERE event_id IN @events
+			UNION ALL
+			SELECT event_id, checked_in_at, 'check_ins', 0
+			FROM event_attendees WHERE event_id IN @events AND checked_in_at IS NOT NULL
+			UNION ALL
+			SELECT event_id, updated_at, 'payments', amount
+			FROM transactions WHERE event_id IN @events AND status IN @settled AND deleted_at IS NULL
+		) m
+		WHERE m.at >= @from AND m.at < @to
+		GROUP BY 1, 2, 3`,
+		map[string]any{
+			"bucket":  bucket,
+			"tz":      timeZone,
+			"events":  eventIDs,
+			"settled": settledTransactionStatuses,
+			"from":    from,
+			"to":      to,
+		},
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"errors"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookmarkRepository interface {
+	Create(bookmark *models.EventBookmark) error
+	Delete(userID, eventID int) error
+	ListByUserID(userID int) ([]models.EventBookmark, error)
+	CountByOrganizer(organizerID int) ([]dto.EventBookmarkCount, error)
+}
+
+type bookmarkRepository struct {
+	db *gorm.DB
+}
+
+func NewBookmarkRepository(db *gorm.DB) *bookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+// Create bersifat idempoten: menyimpan bookmark yang sama dua kali tidak menghasilkan error
+func (r *bookmarkRepository) Create(bookmark *models.EventBookmark) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Event").Create(bookmark).Error
+}
+
+func (r *bookmarkRepository) Delete(userID, eventID int) error {
+	result := r.db.Where("user_id = ? AND event_id = ?", userID, eventID).Delete(&models.EventBookmark{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *bookmarkRepository) ListByUserID(userID int) ([]models.EventBookmark, error) {
+	var bookmarks []models.EventBookmark
+	err := r.db.Preload("Event").Where("user_id = ?", userID).Order("created_at DESC").Find(&bookmarks).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return bookmarks, nil
+}
+
+func (r *bookmarkRepository) CountByOrganizer(organizerID int) ([]dto.EventBookmarkCount, error) {
+	var counts []dto.EventBookmarkCount
+	err := r.db.Table("events").
+		Select("events.id AS event_id, events.name AS event_name, COUNT(event_bookmarks.id) AS bookmarks").
+		Joins("LEFT JOIN event_bookmarks ON event_bookmarks.event_id = events.id").
+		Where("events.organizer_id = ?", organizerID).
+		Group("events.id, events.name").
+		Order("bookmarks DESC").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
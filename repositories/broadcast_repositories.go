@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BroadcastRepository interface {
+	FindRecipients(eventID int, segment models.BroadcastSegment) ([]dto.BroadcastRecipient, error)
+	CreateBroadcast(broadcast *models.Broadcast, deliveries []models.BroadcastDelivery) error
+	ListByEvent(eventID int) ([]models.Broadcast, error)
+	FindByID(eventID, broadcastID int) (*models.Broadcast, error)
+	FindByIDs(ids []int) ([]models.Broadcast, error)
+	ListDeliveries(broadcastID int) ([]models.BroadcastDelivery, error)
+	DeliveryStats(broadcastIDs []int) (map[int]dto.BroadcastDeliveryStats, error)
+
+	ClaimPendingDeliveries(limit int, updatedBefore time.Time) ([]models.BroadcastDelivery, error)
+	ReleaseStaleDeliveries(before time.Time) error
+	UpdateDelivery(delivery *models.BroadcastDelivery) error
+	CompleteBroadcasts(ids []int, now time.Time) error
+}
+
+type broadcastRepository struct {
+	db *gorm.DB
+}
+
+func NewBroadcastRepository(db *gorm.DB) *broadcastRepository {
+	return &broadcastRepository{db: db}
+}
+
+func (r *broadcastRepository) FindRecipients(eventID int, segment models.BroadcastSegment) ([]dto.BroadcastRecipient, error) {
+	var recipients []dto.BroadcastRecipient
+
+	db := r.db.Table("event_attendees a").
+		Select("a.user_id, u.name, u.email").
+		Joins("JOIN users u ON u.id = a.user_id AND u.deleted_at IS NULL").
+		Where("a.event_id = ?", eventID)
+
+	if len(segment.TicketTypeIDs) > 0 {
+		db = db.Where("a.ticket_type_id IN ?", segment.TicketTypeIDs)
+	}
+	if len(segment.PaymentStatuses) > 0 {
+		db = db.Where("a.payment_status IN ?", segment.PaymentStatuses)
+	}
+	if len(segment.RSVPStatuses) > 0 {
+		db = db.Where("a.rsvp_status IN ?", segment.RSVPStatuses)
+	}
+	if segment.CheckedIn != nil {
+		if *segment.CheckedIn {
+			db = db.Where("a.checked_in_at IS NOT NULL")
+		} else {
+			db = db.Where("a.checked_in_at IS NULL")
+		}
+	}
+
+	err := db.Order("a.user_id").Scan(&recipients).Error
+	if err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
+
+// CreateBroadcast menyimpan broadcast dan seluruh delivery-nya sekaligus
+func (r *broadcastRepository) CreateBroadcast(broadcast *models.Broadcast, deliveries []models.BroadcastDelivery) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(broadcast).Error; err != nil {
+			return err
+		}
+		for i := range deliveries {
+			deliveries[i].BroadcastID = broadcast.ID
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(deliveries, 500).Error
+	})
+}
+
+func (r *broadcastRepository) ListByEvent(eventID int) ([]models.Broadcast, error) {
+	var broadcasts []models.Broadcast
+	err := r.db.Where("event_id = ?", eventID).Order("created_at DESC").Find(&broadcasts).Error
+	if err != nil {
+		return nil, err
+	}
+	return broadcasts, nil
+}
+
+func (r *broadcastRepository) FindByID(eventID, broadcastID int) (*models.Broadcast, error) {
+	var broadcast models.Broadcast
+	err := r.db.Where("event_id = ? AND id = ?", eventID, broadcastID).First(&broadcast).Error
+	if err != nil {
+		return nil, err
+	}
+	return &broadcast, nil
+}
+
+func (r *broadcastRepository) FindByIDs(ids []int) ([]models.Broadcast, error) {
+	var broadcasts []models.Broadcast
+	if len(ids) == 0 {
+		return broadcasts, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&broadcasts).Error
+	if err != nil {
+		return nil, err
+	}
+	return broadcasts, nil
+}
+
+func (r *broadcastRepository) ListDeliveries(broadcastID int) ([]models.BroadcastDelivery, error) {
+	var deliveries []models.BroadcastDelivery
+	err := r.db.Where("broadcast_id = ?", broadcastID).Order("id").Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *broadcastRepository) DeliveryStats(broadcastIDs []int) (map[int]dto.BroadcastDeliveryStats, error) {
+	stats := make(map[int]dto.BroadcastDeliveryStats)
+	if len(broadcastIDs) == 0 {
+		return stats, nil
+	}
+
+	var rows []struct {
+		BroadcastID int
+		Status      string
+		Total       int
+	}
+	err := r.db.Model(&models.BroadcastDelivery{}).
+		Select("broadcast_id, status, COUNT(*) AS total").
+		Where("broadcast_id IN ?", broadcastIDs).
+		Group("broadcast_id, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		stat := stats[row.BroadcastID]
+		switch row.Status {
+		case models.DeliveryStatusSent:
+			stat.Sent += row.Total
+		case models.DeliveryStatusFailed:
+			stat.Failed += row.Total
+		default:
+			stat.Pending += row.Total
+		}
+		stats[row.BroadcastID] = stat
+	}
+	return stats, nil
+}
+
+// ClaimPendingDeliveries mengunci dan menandai delivery sebagai "sending" dalam satu statement.
+// SKIP LOCKED membuat beberapa worker (atau beberapa instance server) tidak mengirim pesan yang sama.
+// Hanya delivery yang terakhir diubah sebelum updatedBefore yang diambil, sehingga retry menunggu putaran berikutnya.
+func (r *broadcastRepository) ClaimPendingDeliveries(limit int, updatedBefore time.Time) ([]models.BroadcastDelivery, error) {
+	var deliveries []models.BroadcastDelivery
+	err := r.db.Raw(`
+		UPDATE broadcast_deliveries
+		SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM broadcast_deliveries
+			WHERE status = ? AND updated_at < ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.DeliveryStatusSending, time.Now(), models.DeliveryStatusPending, updatedBefore, limit,
+	).Scan(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ReleaseStaleDeliveries mengembalikan delivery yang tertahan di "sending" (mis. server mati saat mengirim)
+func (r *broadcastRepository) ReleaseStaleDeliveries(before time.Time) error {
+	return r.db.Model(&models.BroadcastDelivery{}).
+		Where("status = ? AND updated_at < ?", models.DeliveryStatusSending, before).
+		Update("status", models.DeliveryStatusPending).Error
+}
+
+func (r *broadcastRepository) UpdateDelivery(delivery *models.BroadcastDelivery) error {
+	return r.db.Model(delivery).Select("status", "attempts", "last_error", "sent_at", "updated_at").Updates(delivery).Error
+}
+
+// CompleteBroadcasts menetapkan status akhir broadcast yang semua delivery-nya sudah selesai
+func (r *broadcastRepository) CompleteBroadcasts(ids []int, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Exec(`
+		UPDATE broadcasts b
+		SET status = CASE
+				WHEN s.failed = 0 THEN ?
+				WHEN s.sent = 0 THEN ?
+				ELSE ?
+			END,
+			completed_at = ?
+		FROM (
+			SELECT broadcast_id,
+				COUNT(*) FILTER (WHERE status = ?) AS sent,
+				COUNT(*) FILTER (WHERE status = ?) AS failed,
+				COUNT(*) FILTER (WHERE status IN (?, ?)) AS open
+			FROM broadcast_deliveries
+			WHERE broadcast_id IN ?
+			GROUP BY broadcast_id
+		) s
+		WHERE b.id = s.broadcast_id AND s.open = 0 AND b.completed_at IS NULL`,
+		models.BroadcastStatusSent, models.BroadcastStatusFailed, models.BroadcastStatusPartial, now,
+		models.DeliveryStatusSent, models.DeliveryStatusFailed,
+		models.DeliveryStatusPending, models.DeliveryStatusSending,
+		ids,
+	).Error
+}
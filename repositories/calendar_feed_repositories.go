@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"errors"
+	"gatherly-app/models"
+
+	"gorm.io/gorm"
+)
+
+type CalendarFeedRepository interface {
+	FindByUserID(userID int) (*models.CalendarFeed, error)
+	FindByToken(token string) (*models.CalendarFeed, error)
+	Save(feed *models.CalendarFeed) error
+}
+
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) *calendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+func (r *calendarFeedRepository) FindByUserID(userID int) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.Where("user_id = ?", userID).First(&feed).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *calendarFeedRepository) FindByToken(token string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.Where("token = ?", token).First(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *calendarFeedRepository) Save(feed *models.CalendarFeed) error {
+	return r.db.Save(feed).Error
+}
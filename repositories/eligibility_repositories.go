@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"errors"
+	"gatherly-app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EligibilityRepository interface {
+	// FindRules mengembalikan rule event dan rule semua ticket type-nya
+	FindRules(eventID int) ([]models.EligibilityRule, error)
+	FindRule(eventID int, ticketTypeID *int) (*models.EligibilityRule, error)
+	SaveRule(rule *models.EligibilityRule) error
+	DeleteRule(eventID int, ticketTypeID *int) error
+
+	CreateGroup(group *models.UserGroup) error
+	SaveGroup(group *models.UserGroup) error
+	FindGroup(id int) (*models.UserGroup, error)
+	ListGroups(ownerID int) ([]models.UserGroup, error)
+	// DeleteGroup ikut menghapus anggota; rule yang memakai grup harus dihapus lebih dulu
+	DeleteGroup(id int) error
+	GroupInUse(id int) (bool, error)
+
+	AddMembers(groupID int, userIDs []int) error
+	RemoveMember(groupID, userID int) error
+	ListMembers(groupID int) ([]models.User, error)
+	IsMember(groupID, userID int) (bool, error)
+}
+
+type eligibilityRepository struct {
+	db *gorm.DB
+}
+
+func NewEligibilityRepository(db *gorm.DB) *eligibilityRepository {
+	return &eligibilityRepository{db: db}
+}
+
+func (r *eligibilityRepository) FindRules(eventID int) ([]models.EligibilityRule, error) {
+	var rules []models.EligibilityRule
+	if err := r.db.Where("event_id = ?", eventID).Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// FindRule mengembalikan (nil, nil) jika rule untuk kombinasi event/ticket type belum dibuat
+func (r *eligibilityRepository) FindRule(eventID int, ticketTypeID *int) (*models.EligibilityRule, error) {
+	var rule models.EligibilityRule
+	err := scopeTicketType(r.db.Where("event_id = ?", eventID), ticketTypeID).First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *eligibilityRepository) SaveRule(rule *models.EligibilityRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *eligibilityRepository) DeleteRule(eventID int, ticketTypeID *int) error {
+	result := scopeTicketType(r.db.Where("event_id = ?", eventID), ticketTypeID).Delete(&models.EligibilityRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *eligibilityRepository) CreateGroup(group *models.UserGroup) error {
+	return r.db.Create(group).Error
+}
+
+func (r *eligibilityRepository) SaveGroup(group *models.UserGroup) error {
+	return r.db.Save(group).Error
+}
+
+func (r *eligibilityRepository) FindGroup(id int) (*models.UserGroup, error) {
+	var group models.UserGroup
+	if err := r.db.First(&group, id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *eligibilityRepository) ListGroups(ownerID int) ([]models.UserGroup, error) {
+	var groups []models.UserGroup
+	if err := r.db.Where("owner_id = ?", ownerID).Order("name").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *eligibilityRepository) DeleteGroup(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&models.UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.UserGroup{}, id).Error
+	})
+}
+
+func (r *eligibilityRepository) GroupInUse(id int) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.EligibilityRule{}).Where("user_group_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// AddMembers mengabaikan user yang sudah menjadi anggota
+func (r *eligibilityRepository) AddMembers(groupID int, userIDs []int) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	members := make([]models.UserGroupMember, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, models.UserGroupMember{GroupID: groupID, UserID: userID})
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+func (r *eligibilityRepository) RemoveMember(groupID, userID int) error {
+	result := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&models.UserGroupMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *eligibilityRepository) ListMembers(groupID int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Joins("JOIN user_group_members m ON m.user_id = users.id").
+		Where("m.group_id = ?", groupID).
+		Order("users.name").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *eligibilityRepository) IsMember(groupID, userID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserGroupMember{}).Where("group_id = ? AND user_id = ?", groupID, userID).Count(&count).Error
+	return count > 0, err
+}
@@ -3,7 +3,9 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 	"gatherly-app/models"
+	"gatherly-app/models/dto"
 
 	"gorm.io/gorm"
 )
@@ -13,9 +15,24 @@ type EventAttendeeRepository interface {
 	FindByUserAndEvent(ctx context.Context, userID, eventID int) (*models.EventAttendee, error)
 	Update(ctx context.Context, attendee *models.EventAttendee) error
 	Delete(ctx context.Context, userID, eventID int) error
+	Cancel(ctx context.Context, attendee *models.EventAttendee, at time.Time) error
+	// CountCancellations menghitung pendaftaran user ke event ini yang pernah dibatalkan
+	CountCancellations(ctx context.Context, userID, eventID int) (int64, error)
 	ListByEventID(ctx context.Context, eventID int) ([]*models.EventAttendee, error)
 	ListByUserID(ctx context.Context, userID int) ([]*models.EventAttendee, error)
 	GetFavoriteCategory(userID int) (string, error)
+	GetFavoriteCategories(userID int, limit int) ([]string, error)
+	CountByEventIDs(ctx context.Context, eventIDs []int) (map[int]int, error)
+	FindByTicketCode(ctx context.Context, eventID int, ticketCode string) (*models.EventAttendee, error)
+	MarkCheckedIn(ctx context.Context, eventID, userID int, at time.Time) error
+	// FindGroupSeatByTicketCode mencari kursi group booking yang pesertanya tidak punya akun;
+	// hasilnya berupa EventAttendee tanpa UserID atas nama pembeli
+	FindGroupSeatByTicketCode(ctx context.Context, eventID int, ticketCode string) (*models.EventAttendee, error)
+	MarkGroupSeatCheckedIn(ctx context.Context, eventID int, ticketCode string, at time.Time) error
+	// FindGroupSeat mengembalikan kursi tamu apa adanya (nama dan email tamu)
+	FindGroupSeat(ctx context.Context, eventID int, ticketCode string) (*models.GroupBookingSeat, error)
+	StreamManifest(ctx context.Context, eventID int, fn func(row *dto.AttendeeManifestRow) error) error
+	TicketTypeCounters(ctx context.Context, eventID int) ([]dto.TicketTypeCounter, error)
 	// Optional methods like Exists or CountByEventID could be added here too
 }
 
@@ -73,6 +90,36 @@ func (r *eventAttendeeRepositoryImpl) Delete(ctx context.Context, userID, eventI
 	return nil
 }
 
+// Cancel menghapus pendaftaran dan mencatatnya di registration_cancellations dalam satu transaksi
+func (r *eventAttendeeRepositoryImpl) Cancel(ctx context.Context, attendee *models.EventAttendee, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND event_id = ?", attendee.UserID, attendee.EventID).Delete(&models.EventAttendee{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Create(&models.RegistrationCancellation{
+			EventID:       attendee.EventID,
+			UserID:        attendee.UserID,
+			TicketTypeID:  attendee.TicketTypeID,
+			PaymentStatus: attendee.PaymentStatus,
+			RegisteredAt:  attendee.RSVPDate,
+			CancelledAt:   at,
+		}).Error
+	})
+}
+
+func (r *eventAttendeeRepositoryImpl) CountCancellations(ctx context.Context, userID, eventID int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RegistrationCancellation{}).
+		Where("user_id = ? AND event_id = ?", userID, eventID).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *eventAttendeeRepositoryImpl) ListByEventID(ctx context.Context, eventID int) ([]*models.EventAttendee, error) {
 	var attendees []*models.EventAttendee
 	result := r.db.WithContext(ctx).Where("event_id = ?", eventID).Find(&attendees)
@@ -101,14 +148,173 @@ func (r *eventAttendeeRepositoryImpl) ListByUserID(ctx context.Context, userID i
 
 // Tambahkan method baru untuk cari kategori favorit user
 func (r *eventAttendeeRepositoryImpl) GetFavoriteCategory(userID int) (string, error) {
-	var category string
+	categories, err := r.GetFavoriteCategories(userID, 1)
+	if err != nil || len(categories) == 0 {
+		return "", err
+	}
+	return categories[0], nil
+}
+
+// GetFavoriteCategories mengembalikan kategori yang paling sering diikuti user, urut dari yang terbanyak
+func (r *eventAttendeeRepositoryImpl) GetFavoriteCategories(userID int, limit int) ([]string, error) {
+	var categories []string
 	err := r.db.Model(&models.EventAttendee{}).
 		Select("events.category").
 		Joins("JOIN events ON events.id = event_attendees.event_id").
 		Where("event_attendees.user_id = ?", userID).
 		Group("events.category").
 		Order("COUNT(*) DESC").
-		Limit(1).
-		Pluck("events.category", &category).Error
-	return category, err
+		Limit(limit).
+		Pluck("events.category", &categories).Error
+	return categories, err
+}
+
+// CountByEventIDs menghitung jumlah pendaftar untuk setiap event
+func (r *eventAttendeeRepositoryImpl) CountByEventIDs(ctx context.Context, eventIDs []int) (map[int]int, error) {
+	counts := make(map[int]int)
+	if len(eventIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		EventID int
+		Total   int
+	}
+	err := r.db.WithContext(ctx).Model(&models.EventAttendee{}).
+		Select("event_id, COUNT(*) AS total").
+		Where("event_id IN ?", eventIDs).
+		Group("event_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.EventID] = row.Total
+	}
+	return counts, nil
+}
+
+func (r *eventAttendeeRepositoryImpl) FindByTicketCode(ctx context.Context, eventID int, ticketCode string) (*models.EventAttendee, error) {
+	var attendee models.EventAttendee
+	err := r.db.WithContext(ctx).Where("event_id = ? AND ticket_code = ?", eventID, ticketCode).First(&attendee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
+
+// MarkCheckedIn hanya berhasil sekali; check-in ganda (mis. dua scanner bersamaan) menghasilkan ErrRecordNotFound
+func (r *eventAttendeeRepositoryImpl) MarkCheckedIn(ctx context.Context, eventID, userID int, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.EventAttendee{}).
+		Where("event_id = ? AND user_id = ? AND checked_in_at IS NULL", eventID, userID).
+		Update("checked_in_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *eventAttendeeRepositoryImpl) FindGroupSeatByTicketCode(ctx context.Context, eventID int, ticketCode string) (*models.EventAttendee, error) {
+	var attendee models.EventAttendee
+	err := r.db.WithContext(ctx).Table("group_booking_seats s").
+		Select(`s.event_id, b.ticket_type_id, b.id AS group_booking_id, b.purchaser_id, 'attending' AS rsvp_status,
+			s.assigned_at AS rsvp_date, 'paid' AS payment_status, s.ticket_code, s.checked_in_at`).
+		Joins("JOIN group_bookings b ON b.id = s.group_booking_id").
+		Where("s.event_id = ? AND s.ticket_code = ? AND s.user_id IS NULL", eventID, ticketCode).
+		Take(&attendee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
+
+func (r *eventAttendeeRepositoryImpl) FindGroupSeat(ctx context.Context, eventID int, ticketCode string) (*models.GroupBookingSeat, error) {
+	var seat models.GroupBookingSeat
+	err := r.db.WithContext(ctx).
+		Where("event_id = ? AND ticket_code = ? AND user_id IS NULL", eventID, ticketCode).
+		Take(&seat).Error
+	if err != nil {
+		return nil, err
+	}
+	return &seat, nil
+}
+
+// MarkGroupSeatCheckedIn sama seperti MarkCheckedIn: check-in ganda menghasilkan ErrRecordNotFound
+func (r *eventAttendeeRepositoryImpl) MarkGroupSeatCheckedIn(ctx context.Context, eventID int, ticketCode string, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.GroupBookingSeat{}).
+		Where("event_id = ? AND ticket_code = ? AND user_id IS NULL AND checked_in_at IS NULL", eventID, ticketCode).
+		Update("checked_in_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// StreamManifest membaca attendee beserta data user, ticket type dan jawaban form baris per baris
+// dari cursor database, sehingga event besar tidak dimuat sekaligus ke memori. Kursi group booking
+// yang pesertanya tidak punya akun ikut dimuat dari group_booking_seats tanpa user_id.
+func (r *eventAttendeeRepositoryImpl) StreamManifest(ctx context.Context, eventID int, fn func(row *dto.AttendeeManifestRow) error) error {
+	rows, err := r.db.WithContext(ctx).Raw(`SELECT * FROM (
+			SELECT a.user_id, u.name, u.email, t.ticket_type, COALESCE(t.price, 0) AS ticket_price, e.currency,
+				a.rsvp_status, a.rsvp_date, a.payment_status, a.ticket_code, a.seat_label AS seat, a.checked_in_at, ra.answers
+			FROM event_attendees a
+			JOIN users u ON u.id = a.user_id
+			JOIN events e ON e.id = a.event_id
+			LEFT JOIN tickets t ON t.id = a.ticket_type_id
+			LEFT JOIN registration_answers ra ON ra.event_id = a.event_id AND ra.user_id = a.user_id
+			WHERE a.event_id = ?
+			UNION ALL
+			SELECT NULL, s.attendee_name, s.attendee_email, t.ticket_type, COALESCE(t.price, 0), e.currency,
+				'attending', s.assigned_at, CASE WHEN b.status = ? THEN 'paid' ELSE 'pending' END, s.ticket_code, NULL, s.checked_in_at, NULL
+			FROM group_booking_seats s
+			JOIN group_bookings b ON b.id = s.group_booking_id
+			JOIN events e ON e.id = s.event_id
+			LEFT JOIN tickets t ON t.id = b.ticket_type_id
+			WHERE s.event_id = ? AND s.user_id IS NULL AND b.status IN ?
+		) manifest
+		ORDER BY rsvp_date NULLS LAST, user_id`,
+		eventID, models.GroupBookingPaid, eventID, []string{models.GroupBookingReserved, models.GroupBookingPaid}).
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row dto.AttendeeManifestRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// TicketTypeCounters menghitung pendaftar, pembayaran lunas, check-in dan pendapatan per ticket type event
+func (r *eventAttendeeRepositoryImpl) TicketTypeCounters(ctx context.Context, eventID int) ([]dto.TicketTypeCounter, error) {
+	var counters []dto.TicketTypeCounter
+	err := r.db.WithContext(ctx).Table("tickets t").
+		Select(`t.id AS ticket_type_id, t.ticket_type, t.price, e.currency, t.quota AS remaining_quota,
+			COUNT(a.user_id) FILTER (WHERE a.rsvp_status <> 'not_attending') AS registered,
+			COUNT(a.user_id) FILTER (WHERE a.payment_status IN ('paid', 'settlement', 'capture')) AS paid,
+			COUNT(a.user_id) FILTER (WHERE a.checked_in_at IS NOT NULL) AS checked_in`).
+		Joins("JOIN events e ON e.id = t.event_id").
+		Joins("LEFT JOIN event_attendees a ON a.ticket_type_id = t.id AND a.event_id = t.event_id").
+		Where("t.event_id = ?", eventID).
+		Group("t.id, t.ticket_type, t.price, e.currency, t.quota").
+		Order("t.id").
+		Scan(&counters).Error
+	if err != nil {
+		return nil, err
+	}
+	return counters, nil
 }
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EventImportRepository interface {
+	Create(job *models.EventImportJob) error
+	FindByID(id, organizerID int) (*models.EventImportJob, error)
+	ClaimQueued(limit int) ([]models.EventImportJob, error)
+	ReleaseStale(before time.Time) error
+	Finish(job *models.EventImportJob) error
+}
+
+type eventImportRepository struct {
+	db *gorm.DB
+}
+
+func NewEventImportRepository(db *gorm.DB) *eventImportRepository {
+	return &eventImportRepository{db: db}
+}
+
+func (r *eventImportRepository) Create(job *models.EventImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *eventImportRepository) FindByID(id, organizerID int) (*models.EventImportJob, error) {
+	var job models.EventImportJob
+	err := r.db.Omit("data").Where("id = ? AND organizer_id = ?", id, organizerID).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimQueued menandai job antrean sebagai running; SKIP LOCKED agar instance lain tidak mengambil job yang sama
+func (r *eventImportRepository) ClaimQueued(limit int) ([]models.EventImportJob, error) {
+	var jobs []models.EventImportJob
+	err := r.db.Raw(`
+		UPDATE event_import_jobs
+		SET status = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM event_import_jobs
+			WHERE status = ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.ImportJobRunning, time.Now(), models.ImportJobQueued, limit,
+	).Scan(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ReleaseStale mengembalikan job yang tertahan di "running" (mis. server mati saat import) ke antrean
+func (r *eventImportRepository) ReleaseStale(before time.Time) error {
+	return r.db.Model(&models.EventImportJob{}).
+		Where("status = ? AND updated_at < ?", models.ImportJobRunning, before).
+		Update("status", models.ImportJobQueued).Error
+}
+
+// Finish menyimpan hasil akhir dan membuang isi file
+func (r *eventImportRepository) Finish(job *models.EventImportJob) error {
+	return r.db.Model(job).
+		Updates(map[string]any{
+			"status":      job.Status,
+			"result":      job.Result,
+			"error":       job.Error,
+			"data":        nil,
+			"finished_at": job.FinishedAt,
+			"updated_at":  time.Now(),
+		}).Error
+}
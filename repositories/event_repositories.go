@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"errors"
+	"fmt"
 	"gatherly-app/models"
 	"gatherly-app/models/dto"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -19,6 +21,10 @@ type EventsRepository interface {
 	UpdateEvent(id int, updatedEvent *models.Event) (*models.Event, error)
 	DeleteEvent(id int) error
 	FindEventByDistance(latitude, longitude, radius float64) ([]dto.EventNearbyDistanceResponseDTO, error) 
+	FindEventsByIDs(ids []int) ([]models.Event, error)
+	FindUpcomingEvents(after time.Time) ([]models.Event, error)
+	RecordView(view models.EventView, since time.Time) error
+	CreateEventsInTransaction(events []models.Event) error
 }
 
 func NewEventsRepository(db *gorm.DB) *eventsRepository {
@@ -33,10 +39,22 @@ func (e *eventsRepository) CreateEvent(event *models.Event) (*models.Event, erro
 	return event, nil
 }
 
+// CreateEventsInTransaction menyimpan event beserta tiketnya; gagal satu berarti tidak ada yang tersimpan
+func (e *eventsRepository) CreateEventsInTransaction(events []models.Event) error {
+	return e.db.Transaction(func(tx *gorm.DB) error {
+		for i := range events {
+			if err := tx.Create(&events[i]).Error; err != nil {
+				return fmt.Errorf("event %q: %w", events[i].Name, err)
+			}
+		}
+		return nil
+	})
+}
+
 func (e *eventsRepository) FindEvent() ([]models.Event, error) {
 	var event []models.Event
 
-	err := e.db.Preload("Tickets").Find(&event).Error
+	err := e.db.Preload("Tickets").Where("visibility = ?", models.EventVisibilityPublic).Find(&event).Error
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +77,52 @@ func (e *eventsRepository) FindEventByID(id int) (*models.Event, error) {
 	return &event, nil
 }
 
+func (e *eventsRepository) FindEventsByIDs(ids []int) ([]models.Event, error) {
+	var events []models.Event
+	if len(ids) == 0 {
+		return events, nil
+	}
+
+	err := e.db.Where("id IN ?", ids).Order("start_date ASC").Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (e *eventsRepository) FindUpcomingEvents(after time.Time) ([]models.Event, error) {
+	var events []models.Event
+
+	err := e.db.Preload("Tickets").
+		Where("start_date > ? AND (status IS NULL OR status <> ?)", after, models.EventStatusCancelled).
+		Where("visibility = ?", models.EventVisibilityPublic).
+		Order("start_date ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// RecordView menyimpan view kecuali viewer yang sama sudah tercatat untuk event ini sejak since
+func (e *eventsRepository) RecordView(view models.EventView, since time.Time) error {
+	query := e.db.Model(&models.EventView{}).Where("event_id = ? AND viewed_at >= ?", view.EventID, since)
+	if view.UserID > 0 {
+		query = query.Where("user_id = ?", view.UserID)
+	} else {
+		query = query.Where("user_id = 0 AND ip = ?", view.IP)
+	}
+
+	var seen int64
+	if err := query.Limit(1).Count(&seen).Error; err != nil {
+		return err
+	}
+	if seen > 0 {
+		return nil
+	}
+	return e.db.Create(&view).Error
+}
+
 func (e *eventsRepository) UpdateEvent(id int, updatedEvent *models.Event) (*models.Event, error) {
 	var event models.Event
 
@@ -90,20 +154,20 @@ func (e *eventsRepository) FindEventByDistance(latitude, longitude, radius float
 	query := `
 	SELECT * FROM (
 		SELECT 
-			id, name, category, description, start_date, end_date, is_paid, price, capacity,
+			id, name, category, description, start_date, end_date, is_paid, currency, capacity,
 			latitude, longitude, poster_url, status,
 			CAST(6371 * acos(
 				cos(radians(?)) * cos(radians(latitude)) * cos(radians(longitude) - radians(?)) +
 				sin(radians(?)) * sin(radians(latitude))
 			) AS FLOAT) AS distance
 		FROM events
-		WHERE latitude IS NOT NULL AND longitude IS NOT NULL
+		WHERE latitude IS NOT NULL AND longitude IS NOT NULL AND visibility = ?
 	) AS events_with_distance
 	WHERE distance <= ?
 	ORDER BY distance ASC
 	`
 	
-	err := e.db.Raw(query, latitude, longitude, latitude, radius).Scan(&results).Error
+	err := e.db.Raw(query, latitude, longitude, latitude, models.EventVisibilityPublic, radius).Scan(&results).Error
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +184,7 @@ func (e *eventsRepository) FindEventByDistance(latitude, longitude, radius float
 			results[i].Ticket = &dto.TicketResponseDTO{
 				ID: ticket.Id,
 				TicketType: ticket.TicketType,
-				Price: ticket.Price,
+				Price: dto.NewMoneyView(models.Money{Amount: ticket.Price, Currency: results[i].Currency}),
 				Quota: ticket.Quota,
 				Status: status,
 			}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowRepository interface {
+	Create(follow *models.Follow) error
+	Delete(userID int, targetType, targetValue string) error
+	ListByUserID(userID int) ([]models.Follow, error)
+	CountFollowers(targetType, targetValue string) (int64, error)
+	FindFeedEvents(userID int, since time.Time, limit int) ([]models.Event, error)
+}
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) *followRepository {
+	return &followRepository{db: db}
+}
+
+func (r *followRepository) Create(follow *models.Follow) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(follow).Error
+}
+
+func (r *followRepository) Delete(userID int, targetType, targetValue string) error {
+	result := r.db.Where("user_id = ? AND target_type = ? AND target_value = ?", userID, targetType, targetValue).
+		Delete(&models.Follow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *followRepository) ListByUserID(userID int) ([]models.Follow, error) {
+	var follows []models.Follow
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&follows).Error
+	if err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
+
+func (r *followRepository) CountFollowers(targetType, targetValue string) (int64, error) {
+	var total int64
+	err := r.db.Model(&models.Follow{}).
+		Where("target_type = ? AND target_value = ?", targetType, targetValue).
+		Count(&total).Error
+	return total, err
+}
+
+// FindFeedEvents mengambil event mendatang dari organizer atau kategori yang diikuti user
+func (r *followRepository) FindFeedEvents(userID int, since time.Time, limit int) ([]models.Event, error) {
+	var events []models.Event
+
+	organizers := r.db.Model(&models.Follow{}).Select("CAST(target_value AS INTEGER)").
+		Where("user_id = ? AND target_type = ?", userID, models.FollowTargetOrganizer)
+	categories := r.db.Model(&models.Follow{}).Select("target_value").
+		Where("user_id = ? AND target_type = ?", userID, models.FollowTargetCategory)
+
+	query := r.db.Preload("Tickets").
+		Where("organizer_id IN (?) OR LOWER(category) IN (?)", organizers, categories).
+		Where("start_date > ? AND (status IS NULL OR status <> ?)", time.Now(), models.EventStatusCancelled).
+		Where("visibility = ?", models.EventVisibilityPublic)
+	if !since.IsZero() {
+		query = query.Where("created_at >= ?", since)
+	}
+
+	err := query.Order("created_at DESC NULLS LAST, start_date ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
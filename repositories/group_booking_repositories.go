@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"errors"
+	"gatherly-app/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrGroupBookingQuota dikembalikan saat kuota ticket type kurang dari jumlah tiket yang dipesan
+	ErrGroupBookingQuota = errors.New("not enough tickets left for this group booking")
+	// ErrGroupBookingNotReserved dikembalikan saat booking sudah dibayar, dibatalkan atau kedaluwarsa
+	ErrGroupBookingNotReserved = errors.New("group booking is no longer awaiting payment")
+)
+
+type GroupBookingRepository interface {
+	// Reserve mengurangi kuota lalu menyimpan transaksi invoice, booking dan kursinya dalam satu transaksi
+	Reserve(booking *models.GroupBooking, transaction *models.Transactions) error
+	SetInvoice(id, invoiceID int) error
+	FindByID(id int) (*models.GroupBooking, error)
+	ListByPurchaser(purchaserID, limit int) ([]models.GroupBooking, error)
+	ListByEvent(eventID int, status string, limit int) ([]models.GroupBooking, error)
+	// AssignSeat mengisi peserta satu kursi. attendee tidak nil jika email milik user terdaftar.
+	AssignSeat(bookingID, seatID int, name, email string, attendee *models.EventAttendee, at time.Time) (*models.GroupBookingSeat, error)
+	// MarkPaid menerbitkan ticket code untuk setiap kursi; codes dipanggil sekali per kursi
+	MarkPaid(id, paidBy int, reference string, at time.Time, codes func() string) error
+	// Close membatalkan atau mengakhiri booking yang belum dibayar dan mengembalikan kuotanya
+	Close(id int, status, transactionStatus string, at time.Time) error
+	FindOverdue(now time.Time, limit int) ([]models.GroupBooking, error)
+}
+
+type groupBookingRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupBookingRepository(db *gorm.DB) *groupBookingRepository {
+	return &groupBookingRepository{db: db}
+}
+
+func (r *groupBookingRepository) Reserve(booking *models.GroupBooking, transaction *models.Transactions) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Ticket{}).
+			Where("id = ? AND quota >= ?", booking.TicketTypeID, booking.Quantity).
+			UpdateColumn("quota", gorm.Expr("quota - ?", booking.Quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrGroupBookingQuota
+		}
+
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+		booking.TransactionID = transaction.ID
+		booking.Seats = make([]models.GroupBookingSeat, booking.Quantity)
+		for i := range booking.Seats {
+			booking.Seats[i].EventID = booking.EventID
+		}
+		return tx.Create(booking).Error
+	})
+}
+
+func (r *groupBookingRepository) SetInvoice(id, invoiceID int) error {
+	return r.db.Model(&models.GroupBooking{}).Where("id = ?", id).Update("invoice_id", invoiceID).Error
+}
+
+func (r *groupBookingRepository) FindByID(id int) (*models.GroupBooking, error) {
+	var booking models.GroupBooking
+	err := r.db.Preload("Seats", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&booking, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+func (r *groupBookingRepository) ListByPurchaser(purchaserID, limit int) ([]models.GroupBooking, error) {
+	var bookings []models.GroupBooking
+	err := r.db.Where("purchaser_id = ?", purchaserID).Order("id DESC").Limit(limit).Find(&bookings).Error
+	if err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (r *groupBookingRepository) ListByEvent(eventID int, status string, limit int) ([]models.GroupBooking, error) {
+	query := r.db.Where("event_id = ?", eventID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var bookings []models.GroupBooking
+	if err := query.Order("id DESC").Limit(limit).Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (r *groupBookingRepository) AssignSeat(bookingID, seatID int, name, email string, attendee *models.EventAttendee, at time.Time) (*models.GroupBookingSeat, error) {
+	var seat models.GroupBookingSeat
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Kunci booking agar status dan ticket code tidak berubah selama kursi diisi
+		var booking models.GroupBooking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, bookingID).Error; err != nil {
+			return err
+		}
+		if booking.Status != models.GroupBookingReserved && booking.Status != models.GroupBookingPaid {
+			return ErrGroupBookingNotReserved
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND group_booking_id = ?", seatID, bookingID).First(&seat).Error; err != nil {
+			return err
+		}
+		if seat.CheckedInAt != nil {
+			return errors.New("seat is already checked in")
+		}
+
+		// Lepas peserta lama; pendaftaran yang sudah check-in tidak boleh dipindahkan
+		if seat.UserID != nil {
+			result := tx.Where("user_id = ? AND event_id = ? AND group_booking_id = ? AND checked_in_at IS NULL",
+				*seat.UserID, seat.EventID, bookingID).Delete(&models.EventAttendee{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errors.New("seat is already checked in")
+			}
+		}
+
+		seat.AttendeeName = name
+		seat.AttendeeEmail = email
+		seat.UserID = nil
+		seat.AssignedAt = &at
+		if attendee != nil {
+			var existing int64
+			if err := tx.Model(&models.EventAttendee{}).
+				Where("user_id = ? AND event_id = ?", attendee.UserID, seat.EventID).
+				Count(&existing).Error; err != nil {
+				return err
+			}
+			if existing > 0 {
+				return errors.New("attendee is already registered for this event")
+			}
+
+			attendee.EventID = seat.EventID
+			attendee.TicketTypeID = &booking.TicketTypeID
+			attendee.GroupBookingID = &booking.ID
+			attendee.PurchaserID = &booking.PurchaserID
+			attendee.RSVPDate = &at
+			attendee.PaymentStatus = "pending"
+			if booking.Status == models.GroupBookingPaid {
+				attendee.PaymentStatus = "paid"
+				attendee.TicketCode = seat.TicketCode
+			}
+			if err := tx.Create(attendee).Error; err != nil {
+				return err
+			}
+			seat.UserID = &attendee.UserID
+		}
+
+		return tx.Model(&seat).Select("attendee_name", "attendee_email", "user_id", "assigned_at").Updates(&seat).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &seat, nil
+}
+
+func (r *groupBookingRepository) MarkPaid(id, paidBy int, reference string, at time.Time, codes func() string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.GroupBooking{}).
+			Where("id = ? AND status = ?", id, models.GroupBookingReserved).
+			Updates(map[string]any{
+				"status":            models.GroupBookingPaid,
+				"paid_at":           at,
+				"paid_by":           paidBy,
+				"payment_reference": reference,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrGroupBookingNotReserved
+		}
+
+		var seatIDs []int
+		if err := tx.Model(&models.GroupBookingSeat{}).Where("group_booking_id = ?", id).Pluck("id", &seatIDs).Error; err != nil {
+			return err
+		}
+		for _, seatID := range seatIDs {
+			if err := tx.Model(&models.GroupBookingSeat{}).Where("id = ?", seatID).Update("ticket_code", codes()).Error; err != nil {
+				return err
+			}
+		}
+
+		// Peserta yang sudah ditautkan ke akun ikut mendapat ticket code kursinya
+		return tx.Exec(`UPDATE event_attendees a SET payment_status = 'paid', ticket_code = s.ticket_code
+			FROM group_booking_seats s
+			WHERE s.group_booking_id = ? AND a.group_booking_id = s.group_booking_id
+				AND a.user_id = s.user_id AND a.event_id = s.event_id`, id).Error
+	})
+}
+
+func (r *groupBookingRepository) Close(id int, status, transactionStatus string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var booking models.GroupBooking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, id).Error; err != nil {
+			return err
+		}
+		if booking.Status != models.GroupBookingReserved {
+			return ErrGroupBookingNotReserved
+		}
+
+		if err := tx.Model(&booking).Updates(map[string]any{"status": status, "closed_at": at}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Ticket{}).Where("id = ?", booking.TicketTypeID).
+			UpdateColumn("quota", gorm.Expr("quota + ?", booking.Quantity)).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Transactions{}).Where("id = ? AND status = ?", booking.TransactionID, "pending").
+			Update("status", transactionStatus).Error; err != nil {
+			return err
+		}
+		return tx.Where("group_booking_id = ?", id).Delete(&models.EventAttendee{}).Error
+	})
+}
+
+func (r *groupBookingRepository) FindOverdue(now time.Time, limit int) ([]models.GroupBooking, error) {
+	var bookings []models.GroupBooking
+	err := r.db.Where("status = ? AND due_date < ?", models.GroupBookingReserved, now).
+		Order("id").Limit(limit).Find(&bookings).Error
+	if err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
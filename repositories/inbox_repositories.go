@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InboxRepository interface {
+	Create(message *models.InboxMessage) error
+	ListByUserID(userID, beforeID, limit int, unreadOnly bool) ([]models.InboxMessage, error)
+	CountUnread(userID int) (int64, error)
+	MarkRead(userID, messageID int, readAt time.Time) error
+	MarkAllRead(userID int, readAt time.Time) (int64, error)
+}
+
+type inboxRepository struct {
+	db *gorm.DB
+}
+
+func NewInboxRepository(db *gorm.DB) *inboxRepository {
+	return &inboxRepository{db: db}
+}
+
+func (r *inboxRepository) Create(message *models.InboxMessage) error {
+	return r.db.Create(message).Error
+}
+
+// ListByUserID mengembalikan pesan terbaru lebih dulu; beforeID > 0 dipakai sebagai cursor halaman berikutnya
+func (r *inboxRepository) ListByUserID(userID, beforeID, limit int, unreadOnly bool) ([]models.InboxMessage, error) {
+	var messages []models.InboxMessage
+	query := r.db.Where("user_id = ?", userID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *inboxRepository) CountUnread(userID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.InboxMessage{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead mengembalikan gorm.ErrRecordNotFound jika pesan tidak ada atau bukan milik user.
+// Pesan yang sudah dibaca tidak diubah waktu bacanya.
+func (r *inboxRepository) MarkRead(userID, messageID int, readAt time.Time) error {
+	var message models.InboxMessage
+	if err := r.db.Where("id = ? AND user_id = ?", messageID, userID).First(&message).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&models.InboxMessage{}).
+		Where("id = ? AND read_at IS NULL", messageID).
+		Update("read_at", readAt).Error
+}
+
+func (r *inboxRepository) MarkAllRead(userID int, readAt time.Time) (int64, error) {
+	result := r.db.Model(&models.InboxMessage{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt)
+	return result.RowsAffected, result.Error
+}
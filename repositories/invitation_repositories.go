@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"errors"
+	"gatherly-app/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	CreateInvitations(invitations []models.EventInvitation) error
+	ListInvitationsByEvent(eventID int) ([]models.EventInvitation, error)
+	ListInvitationsForUser(userID int, email string) ([]models.EventInvitation, error)
+	FindActiveInvitation(eventID, userID int, email string) (*models.EventInvitation, error)
+	UpdateInvitation(invitation *models.EventInvitation) error
+	RevokeInvitation(eventID, invitationID int) error
+
+	CreateLink(link *models.EventInviteLink) error
+	ListLinksByEvent(eventID int) ([]models.EventInviteLink, error)
+	FindLinkByCode(eventID int, code string) (*models.EventInviteLink, error)
+	RedeemLink(linkID int, now time.Time) error
+	RevokeLink(eventID, linkID int) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) *invitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) CreateInvitations(invitations []models.EventInvitation) error {
+	if len(invitations) == 0 {
+		return nil
+	}
+	return r.db.Create(&invitations).Error
+}
+
+func (r *invitationRepository) ListInvitationsByEvent(eventID int) ([]models.EventInvitation, error) {
+	var invitations []models.EventInvitation
+	err := r.db.Where("event_id = ?", eventID).Order("created_at DESC").Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *invitationRepository) ListInvitationsForUser(userID int, email string) ([]models.EventInvitation, error) {
+	var invitations []models.EventInvitation
+	err := r.db.Where("(user_id = ? OR LOWER(email) = ?) AND status <> ?", userID, strings.ToLower(email), models.InvitationStatusRevoked).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// FindActiveInvitation mencari undangan yang belum dicabut untuk user (berdasarkan ID atau email)
+func (r *invitationRepository) FindActiveInvitation(eventID, userID int, email string) (*models.EventInvitation, error) {
+	var invitation models.EventInvitation
+	err := r.db.Where("event_id = ? AND (user_id = ? OR LOWER(email) = ?) AND status <> ?",
+		eventID, userID, strings.ToLower(email), models.InvitationStatusRevoked).
+		First(&invitation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepository) UpdateInvitation(invitation *models.EventInvitation) error {
+	return r.db.Save(invitation).Error
+}
+
+func (r *invitationRepository) RevokeInvitation(eventID, invitationID int) error {
+	result := r.db.Model(&models.EventInvitation{}).
+		Where("id = ? AND event_id = ?", invitationID, eventID).
+		Update("status", models.InvitationStatusRevoked)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *invitationRepository) CreateLink(link *models.EventInviteLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *invitationRepository) ListLinksByEvent(eventID int) ([]models.EventInviteLink, error) {
+	var links []models.EventInviteLink
+	err := r.db.Where("event_id = ?", eventID).Order("created_at DESC").Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *invitationRepository) FindLinkByCode(eventID int, code string) (*models.EventInviteLink, error) {
+	var link models.EventInviteLink
+	err := r.db.Where("event_id = ? AND code = ?", eventID, code).First(&link).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// RedeemLink menambah used_count secara atomik; gagal jika link sudah habis, kedaluwarsa atau dicabut
+func (r *invitationRepository) RedeemLink(linkID int, now time.Time) error {
+	result := r.db.Model(&models.EventInviteLink{}).
+		Where("id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?) AND (max_uses = 0 OR used_count < max_uses)", linkID, now).
+		UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invite link is no longer valid")
+	}
+	return nil
+}
+
+func (r *invitationRepository) RevokeLink(eventID, linkID int) error {
+	result := r.db.Model(&models.EventInviteLink{}).
+		Where("id = ? AND event_id = ? AND revoked_at IS NULL", linkID, eventID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceRepository interface {
+	FindTaxSetting(eventID int) (*models.EventTaxSetting, error)
+	SaveTaxSetting(setting *models.EventTaxSetting) error
+	DeleteTaxSetting(eventID int) (bool, error)
+
+	FindLegalProfile(organizerID int) (*models.OrganizerLegalProfile, error)
+	SaveLegalProfile(profile *models.OrganizerLegalProfile) error
+
+	// Create menyimpan invoice beserta baris-barisnya
+	Create(invoice *models.Invoice) error
+	FindByID(id int) (*models.Invoice, error)
+	ListByUser(userID, limit int) ([]models.Invoice, error)
+	ListByEvent(eventID, limit int) ([]models.Invoice, error)
+	// UpdateBuyer hanya berhasil selama transaksi invoice masih pending
+	UpdateBuyer(id, userID int, buyer dto.InvoiceBuyerRequest) (bool, error)
+}
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) *invoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+func (r *invoiceRepository) FindTaxSetting(eventID int) (*models.EventTaxSetting, error) {
+	var settings []models.EventTaxSetting
+	if err := r.db.Where("event_id = ?", eventID).Limit(1).Find(&settings).Error; err != nil || len(settings) == 0 {
+		return nil, err
+	}
+	return &settings[0], nil
+}
+
+func (r *invoiceRepository) SaveTaxSetting(setting *models.EventTaxSetting) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"mode", "rate_bps", "tax_name", "tax_id", "updated_at"}),
+	}).Create(setting).Error
+}
+
+func (r *invoiceRepository) DeleteTaxSetting(eventID int) (bool, error) {
+	result := r.db.Where("event_id = ?", eventID).Delete(&models.EventTaxSetting{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *invoiceRepository) FindLegalProfile(organizerID int) (*models.OrganizerLegalProfile, error) {
+	var profiles []models.OrganizerLegalProfile
+	if err := r.db.Where("organizer_id = ?", organizerID).Limit(1).Find(&profiles).Error; err != nil || len(profiles) == 0 {
+		return nil, err
+	}
+	return &profiles[0], nil
+}
+
+func (r *invoiceRepository) SaveLegalProfile(profile *models.OrganizerLegalProfile) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organizer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"legal_name", "tax_id", "address", "email", "phone", "updated_at"}),
+	}).Create(profile).Error
+}
+
+func (r *invoiceRepository) Create(invoice *models.Invoice) error {
+	return r.db.Create(invoice).Error
+}
+
+func (r *invoiceRepository) FindByID(id int) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&invoice, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) ListByUser(userID, limit int) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Preload("Lines").Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+func (r *invoiceRepository) ListByEvent(eventID, limit int) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Preload("Lines").Where("event_id = ?", eventID).Order("id DESC").Limit(limit).Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+func (r *invoiceRepository) UpdateBuyer(id, userID int, buyer dto.InvoiceBuyerRequest) (bool, error) {
+	result := r.db.Model(&models.Invoice{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Where("EXISTS (SELECT 1 FROM transactions t WHERE t.id = invoices.transaction_id AND t.status = 'pending')").
+		Updates(map[string]any{
+			"buyer_company": buyer.Company,
+			"buyer_tax_id":  buyer.TaxID,
+			"buyer_address": buyer.Address,
+		})
+	return result.RowsAffected > 0, result.Error
+}
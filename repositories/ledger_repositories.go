@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LedgerAppendOnlySQL memasang trigger yang menolak UPDATE dan DELETE pada tabel ledger.
+// Koreksi dilakukan dengan entry pembalik, bukan dengan mengubah baris lama.
+const LedgerAppendOnlySQL = `
+CREATE OR REPLACE FUNCTION ledger_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'ledger table % is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS journal_entries_append_only ON journal_entries;
+CREATE TRIGGER journal_entries_append_only BEFORE UPDATE OR DELETE ON journal_entries
+	FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+DROP TRIGGER IF EXISTS ledger_postings_append_only ON ledger_postings;
+CREATE TRIGGER ledger_postings_append_only BEFORE UPDATE OR DELETE ON ledger_postings
+	FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+`
+
+type LedgerRepository interface {
+	EnsureAccount(account *models.LedgerAccount) error
+	// CreateEntry menyimpan entry beserta postings-nya; false jika (kind, reference) sudah pernah dicatat
+	CreateEntry(entry *models.JournalEntry) (bool, error)
+	Balances(organizerID *int) ([]dto.LedgerBalance, error)
+	UnbalancedEntries(limit int) ([]dto.UnbalancedEntry, error)
+	TrialBalance() (int64, error)
+	UnrecordedSettlements(limit int) ([]dto.SettlementMismatch, error)
+	// AccountActivity mengembalikan posting akun credit-normal dalam [from, to); Amount positif menambah saldo
+	AccountActivity(code string, from, to time.Time) ([]dto.StatementLine, error)
+	CreditBalanceBefore(code string, before time.Time) (int64, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) *ledgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// EnsureAccount membuat akun jika belum ada lalu mengisi account dengan baris di database
+func (r *ledgerRepository) EnsureAccount(account *models.LedgerAccount) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoNothing: true,
+	}).Create(account).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Where("code = ?", account.Code).First(account).Error
+}
+
+func (r *ledgerRepository) CreateEntry(entry *models.JournalEntry) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		postings := entry.Postings
+		entry.Postings = nil
+		defer func() { entry.Postings = postings }()
+
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "kind"}, {Name: "reference"}},
+			DoNothing: true,
+		}).Create(entry)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		for i := range postings {
+			postings[i].EntryID = entry.ID
+		}
+		if err := tx.Create(&postings).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// Balances menjumlahkan debit dan kredit per akun; organizerID nil berarti semua akun
+func (r *ledgerRepository) Balances(organizerID *int) ([]dto.LedgerBalance, error) {
+	var balances []dto.LedgerBalance
+	query := r.db.Table("ledger_accounts a").
+		Select(`a.id AS account_id, a.code, a.type, a.organizer_id, a.currency,
+			COALESCE(SUM(p.amount) FILTER (WHERE p.amount > 0), 0) AS debits,
+			COALESCE(-SUM(p.amount) FILTER (WHERE p.amount < 0), 0) AS credits`).
+		Joins("LEFT JOIN ledger_postings p ON p.account_id = a.id").
+		Group("a.id, a.code, a.type, a.organizer_id, a.currency").
+		Order("a.code")
+	if organizerID != nil {
+		query = query.Where("a.organizer_id = ?", *organizerID)
+	}
+	if err := query.Scan(&balances).Error; err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+func (r *ledgerRepository) UnbalancedEntries(limit int) ([]dto.UnbalancedEntry, error) {
+	var entries []dto.UnbalancedEntry
+	err := r.db.Table("journal_entries e").
+		Select("e.id AS entry_id, e.kind, e.reference, COALESCE(SUM(p.amount), 0) AS total, COUNT(p.id) AS postings").
+		Joins("LEFT JOIN ledger_postings p ON p.entry_id = e.id").
+		Group("e.id, e.kind, e.reference").
+		Having("COALESCE(SUM(p.amount), 0) <> 0 OR COUNT(p.id) < 2").
+		Order("e.id").
+		Limit(limit).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// TrialBalance adalah jumlah seluruh posting; selalu nol jika ledger konsisten
+func (r *ledgerRepository) TrialBalance() (int64, error) {
+	var total int64
+	err := r.db.Model(&models.LedgerPosting{}).Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	return total, err
+}
+
+// UnrecordedSettlements mencari transaksi lunas yang tidak punya entry settlement, atau yang
+// nominal di akun gateway_clearing berbeda dengan amount transaksi. Hanya transaksi dalam mata uang
+// ledger yang diperiksa.
+func (r *ledgerRepository) UnrecordedSettlements(limit int) ([]dto.SettlementMismatch, error) {
+	var mismatches []dto.SettlementMismatch
+	err := r.db.Raw(`
+		SELECT t.payment_gateway_transaction_id AS order_id,
+			t.amount AS expected,
+			COALESCE(SUM(p.amount), 0) AS recorded
+		FROM transactions t
+		LEFT JOIN journal_entries e ON e.kind = ? AND e.reference = t.payment_gateway_transaction_id
+		LEFT JOIN ledger_postings p ON p.entry_id = e.id
+			AND p.account_id = (SELECT id FROM ledger_accounts WHERE code = ?)
+		WHERE t.status IN ? AND t.currency = ? AND t.deleted_at IS NULL
+		GROUP BY t.id, t.payment_gateway_transaction_id, t.amount
+		HAVING COALESCE(SUM(p.amount), 0) <> t.amount
+		ORDER BY t.id
+		LIMIT ?`,
+		models.JournalSettlement, models.LedgerGatewayClearing, settledTransactionStatuses, models.LedgerCurrency, limit,
+	).Scan(&mismatches).Error
+	if err != nil {
+		return nil, err
+	}
+	return mismatches, nil
+}
+
+func (r *ledgerRepository) AccountActivity(code string, from, to time.Time) ([]dto.StatementLine, error) {
+	var lines []dto.StatementLine
+	err := r.db.Table("ledger_postings p").
+		Select("e.id AS entry_id, e.kind, e.reference, e.event_id, e.description, -p.amount AS amount, p.created_at AS posted_at").
+		Joins("JOIN journal_entries e ON e.id = p.entry_id").
+		Joins("JOIN ledger_accounts a ON a.id = p.account_id").
+		Where("a.code = ? AND p.created_at >= ? AND p.created_at < ?", code, from, to).
+		Order("p.id").
+		Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func (r *ledgerRepository) CreditBalanceBefore(code string, before time.Time) (int64, error) {
+	var balance int64
+	err := r.db.Table("ledger_postings p").
+		Select("COALESCE(-SUM(p.amount), 0)").
+		Joins("JOIN ledger_accounts a ON a.id = p.account_id").
+		Where("a.code = ? AND p.created_at < ?", code, before).
+		Scan(&balance).Error
+	return balance, err
+}
@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	ListPreferences(userID int) ([]models.NotificationPreference, error)
+	SavePreferences(preferences []models.NotificationPreference) error
+	DisabledChannels(userIDs []int, notificationType string) (map[int]map[string]bool, error)
+	Enqueue(messages []models.NotificationOutbox) error
+	ClaimOutbox(limit int, now time.Time) ([]models.NotificationOutbox, error)
+	ReleaseStaleOutbox(before time.Time) error
+	UpdateOutbox(message *models.NotificationOutbox) error
+	ReminderTargets(from, to time.Time) ([]dto.NotificationTarget, error)
+	ExpiringTransactions(from, to time.Time) ([]models.Transactions, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *notificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) ListPreferences(userID int) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Order("type, channel").Find(&preferences).Error
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (r *notificationRepository) SavePreferences(preferences []models.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&preferences).Error
+}
+
+// DisabledChannels mengembalikan channel yang dimatikan per user untuk satu tipe notifikasi
+func (r *notificationRepository) DisabledChannels(userIDs []int, notificationType string) (map[int]map[string]bool, error) {
+	disabled := make(map[int]map[string]bool)
+	if len(userIDs) == 0 {
+		return disabled, nil
+	}
+
+	var preferences []models.NotificationPreference
+	err := r.db.Where("user_id IN ? AND type = ? AND enabled = ?", userIDs, notificationType, false).
+		Find(&preferences).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, preference := range preferences {
+		if disabled[preference.UserID] == nil {
+			disabled[preference.UserID] = make(map[string]bool)
+		}
+		disabled[preference.UserID][preference.Channel] = true
+	}
+	return disabled, nil
+}
+
+// Enqueue menyimpan pesan ke outbox; pesan dengan dedup_key yang sudah ada diabaikan
+func (r *notificationRepository) Enqueue(messages []models.NotificationOutbox) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dedup_key"}},
+		DoNothing: true,
+	}).CreateInBatches(&messages, 500).Error
+}
+
+// ClaimOutbox mengambil pesan yang available_at-nya sudah lewat dan menandainya "sending".
+// attempts dinaikkan saat klaim, sehingga pesan yang membuat worker mati di tengah pengiriman
+// tetap terkena batas maxNotificationAttempts setelah dilepas ReleaseStaleOutbox.
+func (r *notificationRepository) ClaimOutbox(limit int, now time.Time) ([]models.NotificationOutbox, error) {
+	var messages []models.NotificationOutbox
+	err := r.db.Raw(`
+		UPDATE notification_outboxes
+		SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM notification_outboxes
+			WHERE status = ? AND available_at <= ?
+			ORDER BY available_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.OutboxStatusSending, now, models.OutboxStatusPending, now, limit,
+	).Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ReleaseStaleOutbox mengembalikan pesan yang tertahan di "sending" agar dikirim ulang
+func (r *notificationRepository) ReleaseStaleOutbox(before time.Time) error {
+	return r.db.Model(&models.NotificationOutbox{}).
+		Where("status = ? AND updated_at < ?", models.OutboxStatusSending, before).
+		Update("status", models.OutboxStatusPending).Error
+}
+
+func (r *notificationRepository) UpdateOutbox(message *models.NotificationOutbox) error {
+	return r.db.Model(message).
+		Select("status", "last_error", "available_at", "sent_at", "updated_at").
+		Updates(message).Error
+}
+
+// ReminderTargets mengembalikan peserta event yang mulai dalam rentang (from, to].
+// Peserta yang tidak hadir, belum membayar, atau event yang dibatalkan dilewati.
+func (r *notificationRepository) ReminderTargets(from, to time.Time) ([]dto.NotificationTarget, error) {
+	var targets []dto.NotificationTarget
+	err := r.db.Table("event_attendees ea").
+		Select("ea.user_id, e.id AS event_id, e.name AS event_name, e.start_date").
+		Joins("JOIN events e ON e.id = ea.event_id").
+		Where("e.start_date > ? AND e.start_date <= ?", from, to).
+		Where("e.status <> ?", models.EventStatusCancelled).
+		Where("ea.rsvp_status <> ?", "not_attending").
+		Where("e.is_paid = ? OR ea.payment_status IN ?", false, []string{"paid", "settlement", "capture"}).
+		Scan(&targets).Error
+	if err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// ExpiringTransactions mengembalikan transaksi pending yang dibuat dalam rentang [from, to)
+func (r *notificationRepository) ExpiringTransactions(from, to time.Time) ([]models.Transactions, error) {
+	var transactions []models.Transactions
+	err := r.db.Where("status = ? AND transaction_date >= ? AND transaction_date < ?", "pending", from, to).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
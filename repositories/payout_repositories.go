@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"errors"
+	"gatherly-app/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// openPayoutCondition memilih payout yang masih menahan saldo organizer: belum diproses, atau sudah
+// disetujui tetapi entry ledger-nya belum tercatat (lihat usecase ApproveBatch)
+const openPayoutCondition = `(status IN ('requested', 'batched') OR (status = 'approved' AND NOT EXISTS (
+	SELECT 1 FROM journal_entries e WHERE e.kind = 'payout' AND e.reference = 'payout:' || payouts.id)))`
+
+// errNothingToBatch membatalkan transaksi CreateBatch saat tidak ada payout yang bisa dimasukkan
+var errNothingToBatch = errors.New("nothing to batch")
+
+type PayoutRepository interface {
+	FindFeeRule(eventID, organizerID int) (*models.FeeRule, error)
+	ListFeeRules() ([]models.FeeRule, error)
+	SaveFeeRule(rule *models.FeeRule) error
+	DeleteFeeRule(id int) (bool, error)
+
+	// CreatePayout menyimpan payout jika saldo ledger organizer dikurangi payout yang masih
+	// terbuka mencukupi. Mengembalikan saldo tersedia sebelum payout ini.
+	CreatePayout(payout *models.Payout) (int64, bool, error)
+	PendingPayoutTotal(organizerID int) (int64, error)
+	FindPayout(id int) (*models.Payout, error)
+	ListPayouts(organizerID *int, status string, limit int) ([]models.Payout, error)
+	CancelPayout(id, organizerID int, at time.Time) (bool, error)
+
+	// CreateBatch memindahkan payout requested (semua, atau hanya ids) ke batch baru; nil jika tidak ada
+	CreateBatch(batch *models.PayoutBatch, ids []int) (*models.PayoutBatch, error)
+	FindBatch(id int) (*models.PayoutBatch, error)
+	ListBatches(limit int) ([]models.PayoutBatch, error)
+	// CloseBatch mengubah batch pending beserta payout-nya; false jika batch sudah tidak pending
+	CloseBatch(batch *models.PayoutBatch, payoutStatus string) (bool, error)
+}
+
+type payoutRepository struct {
+	db *gorm.DB
+}
+
+func NewPayoutRepository(db *gorm.DB) *payoutRepository {
+	return &payoutRepository{db: db}
+}
+
+// FindFeeRule mengembalikan rule event jika ada, lalu rule organizer; nil jika keduanya tidak ada
+func (r *payoutRepository) FindFeeRule(eventID, organizerID int) (*models.FeeRule, error) {
+	var rules []models.FeeRule
+	err := r.db.Where("event_id = ? OR organizer_id = ?", eventID, organizerID).
+		Order("event_id IS NULL").
+		Limit(1).
+		Find(&rules).Error
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	return &rules[0], nil
+}
+
+func (r *payoutRepository) ListFeeRules() ([]models.FeeRule, error) {
+	var rules []models.FeeRule
+	if err := r.db.Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// SaveFeeRule meng-upsert rule berdasarkan event_id atau organizer_id
+func (r *payoutRepository) SaveFeeRule(rule *models.FeeRule) error {
+	target := "organizer_id"
+	if rule.EventID != nil {
+		target = "event_id"
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: target}},
+		DoUpdates: clause.AssignmentColumns([]string{"percent_bps", "fixed_amount", "created_by", "updated_at"}),
+	}).Create(rule).Error
+}
+
+func (r *payoutRepository) DeleteFeeRule(id int) (bool, error) {
+	result := r.db.Delete(&models.FeeRule{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *payoutRepository) CreatePayout(payout *models.Payout) (int64, bool, error) {
+	var available int64
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock akun organizer agar dua permintaan bersamaan tidak memakai saldo yang sama
+		var account models.LedgerAccount
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", models.OrganizerAccountCode(payout.OrganizerID)).
+			First(&account).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Raw(`
+			SELECT
+				COALESCE((SELECT -SUM(amount) FROM ledger_postings WHERE account_id = ?), 0)
+				- COALESCE((SELECT SUM(amount) FROM payouts WHERE organizer_id = ? AND `+openPayoutCondition+`), 0)`,
+			account.ID, payout.OrganizerID,
+		).Scan(&available).Error
+		if err != nil {
+			return err
+		}
+		if available < payout.Amount {
+			return nil
+		}
+
+		if err := tx.Create(payout).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return available, created, err
+}
+
+func (r *payoutRepository) PendingPayoutTotal(organizerID int) (int64, error) {
+	var total int64
+	err := r.db.Model(&models.Payout{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("organizer_id = ? AND "+openPayoutCondition, organizerID).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *payoutRepository) FindPayout(id int) (*models.Payout, error) {
+	var payout models.Payout
+	if err := r.db.First(&payout, id).Error; err != nil {
+		return nil, err
+	}
+	return &payout, nil
+}
+
+func (r *payoutRepository) ListPayouts(organizerID *int, status string, limit int) ([]models.Payout, error) {
+	var payouts []models.Payout
+	query := r.db.Order("id DESC").Limit(limit)
+	if organizerID != nil {
+		query = query.Where("organizer_id = ?", *organizerID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&payouts).Error; err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
+
+// CancelPayout hanya berlaku untuk payout yang belum masuk batch
+func (r *payoutRepository) CancelPayout(id, organizerID int, at time.Time) (bool, error) {
+	result := r.db.Model(&models.Payout{}).
+		Where("id = ? AND organizer_id = ? AND status = ?", id, organizerID, models.PayoutRequested).
+		Updates(map[string]any{"status": models.PayoutCancelled, "closed_at": at})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *payoutRepository) CreateBatch(batch *models.PayoutBatch, ids []int) (*models.PayoutBatch, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+
+		query := tx.Model(&models.Payout{}).Where("status = ?", models.PayoutRequested)
+		if len(ids) > 0 {
+			query = query.Where("id IN ?", ids)
+		}
+		result := query.Updates(map[string]any{"status": models.PayoutBatched, "batch_id": batch.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNothingToBatch
+		}
+
+		var totals struct {
+			Count int
+			Total int64
+		}
+		err := tx.Model(&models.Payout{}).
+			Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS total").
+			Where("batch_id = ?", batch.ID).
+			Scan(&totals).Error
+		if err != nil {
+			return err
+		}
+		batch.Count = totals.Count
+		batch.Total = totals.Total
+		return tx.Model(batch).Updates(map[string]any{"count": batch.Count, "total": batch.Total}).Error
+	})
+	if errors.Is(err, errNothingToBatch) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (r *payoutRepository) FindBatch(id int) (*models.PayoutBatch, error) {
+	var batch models.PayoutBatch
+	err := r.db.Preload("Payouts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&batch, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *payoutRepository) ListBatches(limit int) ([]models.PayoutBatch, error) {
+	var batches []models.PayoutBatch
+	if err := r.db.Order("id DESC").Limit(limit).Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *payoutRepository) CloseBatch(batch *models.PayoutBatch, payoutStatus string) (bool, error) {
+	closed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PayoutBatch{}).
+			Where("id = ? AND status = ?", batch.ID, models.PayoutBatchPending).
+			Updates(map[string]any{
+				"status":      batch.Status,
+				"reviewed_by": batch.ReviewedBy,
+				"reviewed_at": batch.ReviewedAt,
+				"note":        batch.Note,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		err := tx.Model(&models.Payout{}).
+			Where("batch_id = ? AND status = ?", batch.ID, models.PayoutBatched).
+			Updates(map[string]any{"status": payoutStatus, "closed_at": batch.ReviewedAt}).Error
+		if err != nil {
+			return err
+		}
+		closed = true
+		return nil
+	})
+	return closed, err
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PurchaseGuardRepository interface {
+	FindPolicy(eventID int) (*models.EventPurchasePolicy, error)
+	SavePolicy(policy *models.EventPurchasePolicy) error
+
+	// Hit menambah counter dan mengembalikan nilai barunya; aman dipanggil bersamaan dari banyak instance
+	Hit(key string, windowStart time.Time, n int, expiresAt time.Time) (int, error)
+	// Reserve menambah counter sebesar n hanya jika hasilnya tidak melebihi max, dalam satu statement
+	// sehingga dua request bersamaan tidak bisa sama-sama lolos; ok false berarti batas terlampaui
+	Reserve(key string, windowStart time.Time, n, max int, expiresAt time.Time) (bool, error)
+	// Release mengurangi counter yang sebelumnya di-Reserve, tidak pernah di bawah 0
+	Release(key string, windowStart time.Time, n int) error
+	DeleteExpiredCounters(now time.Time) (int64, error)
+
+	// CountUserTickets menjumlahkan registrasi user sendiri dan tiket group booking aktif yang ia beli
+	CountUserTickets(eventID, userID int) (int, error)
+}
+
+type purchaseGuardRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseGuardRepository(db *gorm.DB) *purchaseGuardRepository {
+	return &purchaseGuardRepository{db: db}
+}
+
+func (r *purchaseGuardRepository) FindPolicy(eventID int) (*models.EventPurchasePolicy, error) {
+	var policies []models.EventPurchasePolicy
+	if err := r.db.Where("event_id = ?", eventID).Limit(1).Find(&policies).Error; err != nil || len(policies) == 0 {
+		return nil, err
+	}
+	return &policies[0], nil
+}
+
+func (r *purchaseGuardRepository) SavePolicy(policy *models.EventPurchasePolicy) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "event_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_tickets_per_user", "max_tickets_per_ip", "require_challenge",
+			"queue_enabled", "queue_batch_size", "queue_admit_rate", "checkout_window", "updated_at"}),
+	}).Create(policy).Error
+}
+
+func (r *purchaseGuardRepository) Hit(key string, windowStart time.Time, n int, expiresAt time.Time) (int, error) {
+	var count int
+	err := r.db.Raw(`INSERT INTO rate_limit_counters (key, window_start, count, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limit_counters.count + EXCLUDED.count
+		RETURNING count`, key, windowStart, n, expiresAt).Scan(&count).Error
+	return count, err
+}
+
+func (r *purchaseGuardRepository) Reserve(key string, windowStart time.Time, n, max int, expiresAt time.Time) (bool, error) {
+	var counts []int
+	err := r.db.Raw(`INSERT INTO rate_limit_counters (key, window_start, count, expires_at)
+		SELECT ?, ?, ?, ? WHERE ? <= ?
+		ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limit_counters.count + EXCLUDED.count
+			WHERE rate_limit_counters.count + EXCLUDED.count <= ?
+		RETURNING count`, key, windowStart, n, expiresAt, n, max, max).Scan(&counts).Error
+	return len(counts) > 0, err
+}
+
+func (r *purchaseGuardRepository) Release(key string, windowStart time.Time, n int) error {
+	return r.db.Model(&models.RateLimitCounter{}).
+		Where("key = ? AND window_start = ?", key, windowStart).
+		UpdateColumn("count", gorm.Expr("GREATEST(count - ?, 0)", n)).Error
+}
+
+func (r *purchaseGuardRepository) DeleteExpiredCounters(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&models.RateLimitCounter{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *purchaseGuardRepository) CountUserTickets(eventID, userID int) (int, error) {
+	var count int
+	err := r.db.Raw(`SELECT
+			(SELECT COUNT(*) FROM event_attendees WHERE event_id = ? AND user_id = ? AND group_booking_id IS NULL) +
+			(SELECT COALESCE(SUM(quantity), 0) FROM group_bookings WHERE event_id = ? AND purchaser_id = ? AND status IN ?)`,
+		eventID, userID, eventID, userID, []string{models.GroupBookingReserved, models.GroupBookingPaid}).
+		Scan(&count).Error
+	return count, err
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReconciliationRepository interface {
+	CreateRun(run *models.ReconciliationRun) error
+	FailStaleRuns(before time.Time) error
+	UpdateRun(run *models.ReconciliationRun) error
+	FindRun(id int) (*models.ReconciliationRun, error)
+	ListRuns(limit int) ([]models.ReconciliationRun, error)
+	CreateDiscrepancies(discrepancies []models.ReconciliationDiscrepancy) error
+	ListDiscrepancies(runID int) ([]models.ReconciliationDiscrepancy, error)
+	TransactionsToCheck(closedSince time.Time, afterID uint, limit int) ([]models.Transactions, error)
+	TransactionSummary(from, to time.Time) ([]dto.TransactionStatusSummary, error)
+}
+
+type reconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) *reconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+// CreateRun gagal dengan unique violation jika masih ada run lain berstatus running
+func (r *reconciliationRepository) CreateRun(run *models.ReconciliationRun) error {
+	return r.db.Create(run).Error
+}
+
+// FailStaleRuns menutup run yang tertinggal running, mis. karena server mati di tengah proses
+func (r *reconciliationRepository) FailStaleRuns(before time.Time) error {
+	return r.db.Model(&models.ReconciliationRun{}).
+		Where("status = ? AND started_at < ?", models.ReconciliationRunning, before).
+		Updates(map[string]any{
+			"status":      models.ReconciliationFailed,
+			"error":       "run did not finish",
+			"finished_at": time.Now(),
+		}).Error
+}
+
+func (r *reconciliationRepository) UpdateRun(run *models.ReconciliationRun) error {
+	return r.db.Save(run).Error
+}
+
+func (r *reconciliationRepository) FindRun(id int) (*models.ReconciliationRun, error) {
+	var run models.ReconciliationRun
+	if err := r.db.First(&run, id).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *reconciliationRepository) ListRuns(limit int) ([]models.ReconciliationRun, error) {
+	var runs []models.ReconciliationRun
+	err := r.db.Order("id DESC").Limit(limit).Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (r *reconciliationRepository) CreateDiscrepancies(discrepancies []models.ReconciliationDiscrepancy) error {
+	if len(discrepancies) == 0 {
+		return nil
+	}
+	return r.db.Create(&discrepancies).Error
+}
+
+func (r *reconciliationRepository) ListDiscrepancies(runID int) ([]models.ReconciliationDiscrepancy, error) {
+	var discrepancies []models.ReconciliationDiscrepancy
+	err := r.db.Where("run_id = ?", runID).Order("id").Find(&discrepancies).Error
+	if err != nil {
+		return nil, err
+	}
+	return discrepancies, nil
+}
+
+// TransactionsToCheck mengembalikan semua transaksi Midtrans pending (berapa pun umurnya) ditambah transaksi
+// berstatus lain yang dibuat sejak closedSince, berurutan per id untuk paginasi keyset.
+func (r *reconciliationRepository) TransactionsToCheck(closedSince time.Time, afterID uint, limit int) ([]models.Transactions, error) {
+	var transactions []models.Transactions
+	err := r.db.
+		Where("id > ?", afterID).
+		Where("payment_gateway_transaction_id <> ''").
+		Where("gateway = ?", models.PaymentGatewayMidtrans).
+		Where("status = ? OR transaction_date >= ?", "pending", closedSince).
+		Order("id").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func (r *reconciliationRepository) TransactionSummary(from, to time.Time) ([]dto.TransactionStatusSummary, error) {
+	var summary []dto.TransactionStatusSummary
+	err := r.db.Model(&models.Transactions{}).
+		Select("status, currency, COUNT(*) AS count, COALESCE(SUM(amount), 0)::bigint AS amount").
+		Where("transaction_date >= ? AND transaction_date < ?", from, to).
+		Group("status, currency").
+		Order("status, currency").
+		Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
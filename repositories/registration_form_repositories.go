@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"errors"
+	"gatherly-app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RegistrationFormRepository interface {
+	FindForm(eventID int, ticketTypeID *int) (*models.RegistrationForm, error)
+	SaveForm(form *models.RegistrationForm) error
+	DeleteForm(eventID int, ticketTypeID *int) error
+	ListFormsByEvent(eventID int) ([]models.RegistrationForm, error)
+
+	SaveAnswer(answer *models.RegistrationAnswer) error
+	FindAnswer(eventID, userID int) (*models.RegistrationAnswer, error)
+	ListAnswersByEvent(eventID int) ([]models.RegistrationAnswer, error)
+
+	CreateUpload(upload *models.FormUpload) error
+	FindUpload(token string) (*models.FormUpload, error)
+}
+
+type registrationFormRepository struct {
+	db *gorm.DB
+}
+
+func NewRegistrationFormRepository(db *gorm.DB) *registrationFormRepository {
+	return &registrationFormRepository{db: db}
+}
+
+func scopeTicketType(db *gorm.DB, ticketTypeID *int) *gorm.DB {
+	if ticketTypeID == nil {
+		return db.Where("ticket_type_id IS NULL")
+	}
+	return db.Where("ticket_type_id = ?", *ticketTypeID)
+}
+
+// FindForm mengembalikan (nil, nil) jika form untuk kombinasi event/ticket type belum dibuat
+func (r *registrationFormRepository) FindForm(eventID int, ticketTypeID *int) (*models.RegistrationForm, error) {
+	var form models.RegistrationForm
+	err := scopeTicketType(r.db.Where("event_id = ?", eventID), ticketTypeID).First(&form).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &form, nil
+}
+
+func (r *registrationFormRepository) SaveForm(form *models.RegistrationForm) error {
+	return r.db.Save(form).Error
+}
+
+func (r *registrationFormRepository) DeleteForm(eventID int, ticketTypeID *int) error {
+	result := scopeTicketType(r.db.Where("event_id = ?", eventID), ticketTypeID).Delete(&models.RegistrationForm{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *registrationFormRepository) ListFormsByEvent(eventID int) ([]models.RegistrationForm, error) {
+	var forms []models.RegistrationForm
+	err := r.db.Where("event_id = ?", eventID).Order("id").Find(&forms).Error
+	if err != nil {
+		return nil, err
+	}
+	return forms, nil
+}
+
+// SaveAnswer menimpa jawaban lama, misalnya saat user mendaftar ulang setelah membatalkan
+func (r *registrationFormRepository) SaveAnswer(answer *models.RegistrationAnswer) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"form_id", "answers", "updated_at"}),
+	}).Create(answer).Error
+}
+
+func (r *registrationFormRepository) FindAnswer(eventID, userID int) (*models.RegistrationAnswer, error) {
+	var answer models.RegistrationAnswer
+	err := r.db.Where("event_id = ? AND user_id = ?", eventID, userID).First(&answer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+func (r *registrationFormRepository) ListAnswersByEvent(eventID int) ([]models.RegistrationAnswer, error) {
+	var answers []models.RegistrationAnswer
+	err := r.db.Where("event_id = ?", eventID).Order("created_at").Find(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+func (r *registrationFormRepository) CreateUpload(upload *models.FormUpload) error {
+	return r.db.Create(upload).Error
+}
+
+func (r *registrationFormRepository) FindUpload(token string) (*models.FormUpload, error) {
+	var upload models.FormUpload
+	err := r.db.Where("token = ?", token).First(&upload).Error
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"errors"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrSeatUnavailable dikembalikan saat kursi diblokir, sudah terdaftar atau sedang ditahan user lain
+	ErrSeatUnavailable = errors.New("seat is not available")
+	// ErrSeatMapInUse dikembalikan saat denah diganti padahal sudah ada kursi yang terdaftar
+	ErrSeatMapInUse = errors.New("seat map already has registered seats")
+)
+
+// SeatAttendeeIndexSQL memastikan satu kursi hanya dimiliki satu pendaftaran, walau dua hold
+// sempat berebut kursi yang sama. event_attendees dibuat dari DDL, jadi index dibuat manual.
+const SeatAttendeeIndexSQL = `CREATE UNIQUE INDEX IF NOT EXISTS idx_event_attendees_seat
+	ON event_attendees (seat_id) WHERE seat_id IS NOT NULL`
+
+type SeatMapRepository interface {
+	// FindByEvent mengembalikan nil tanpa error jika event tidak punya seat map
+	FindByEvent(eventID int) (*models.SeatMap, error)
+	HasSeatMap(eventID int) (bool, error)
+	// Replace mengganti denah event. Seat.ZoneID berisi indeks zona di seatMap.Zones dan diganti
+	// dengan ID zona setelah zona disimpan.
+	Replace(seatMap *models.SeatMap) error
+	ZoneViews(seatMapID int) ([]dto.SeatZoneView, error)
+	SeatViews(seatMapID, userID int, now time.Time) ([]dto.SeatView, error)
+	ListSeats(seatMapID int) ([]models.Seat, []models.SeatZone, error)
+
+	// Hold menahan satu kursi untuk user sampai until dan melepas hold lain user di event yang sama
+	Hold(eventID, seatID, userID int, until, now time.Time) (*models.Seat, error)
+	ReleaseHolds(eventID, userID int) error
+	// FindHeldSeat mengembalikan kursi yang masih ditahan user beserta ticket type zonanya
+	FindHeldSeat(eventID, seatID, userID int, now time.Time) (*models.Seat, int, error)
+}
+
+type seatMapRepository struct {
+	db *gorm.DB
+}
+
+func NewSeatMapRepository(db *gorm.DB) *seatMapRepository {
+	return &seatMapRepository{db: db}
+}
+
+func (r *seatMapRepository) FindByEvent(eventID int) (*models.SeatMap, error) {
+	var seatMaps []models.SeatMap
+	if err := r.db.Where("event_id = ?", eventID).Limit(1).Find(&seatMaps).Error; err != nil || len(seatMaps) == 0 {
+		return nil, err
+	}
+	return &seatMaps[0], nil
+}
+
+func (r *seatMapRepository) HasSeatMap(eventID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.SeatMap{}).Where("event_id = ?", eventID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *seatMapRepository) Replace(seatMap *models.SeatMap) error {
+	zones, seats := seatMap.Zones, seatMap.Seats
+	seatMap.Zones, seatMap.Seats = nil, nil
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.SeatMap
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("event_id = ?", seatMap.EventID).Find(&existing).Error; err != nil {
+			return err
+		}
+		for _, old := range existing {
+			var registered int64
+			err := tx.Table("event_attendees a").
+				Joins("JOIN seats s ON s.id = a.seat_id").
+				Where("s.seat_map_id = ?", old.ID).
+				Count(&registered).Error
+			if err != nil {
+				return err
+			}
+			if registered > 0 {
+				return ErrSeatMapInUse
+			}
+			if err := tx.Where("seat_map_id = ?", old.ID).Delete(&models.Seat{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("seat_map_id = ?", old.ID).Delete(&models.SeatZone{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&old).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(seatMap).Error; err != nil {
+			return err
+		}
+		for i := range zones {
+			zones[i].SeatMapID = seatMap.ID
+		}
+		if err := tx.Create(&zones).Error; err != nil {
+			return err
+		}
+		for i := range seats {
+			seats[i].SeatMapID = seatMap.ID
+			seats[i].EventID = seatMap.EventID
+			seats[i].ZoneID = zones[seats[i].ZoneID].ID
+		}
+		if len(seats) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&seats, 500).Error
+	})
+	seatMap.Zones, seatMap.Seats = zones, seats
+	return err
+}
+
+func (r *seatMapRepository) ZoneViews(seatMapID int) ([]dto.SeatZoneView, error) {
+	var zones []dto.SeatZoneView
+	err := r.db.Table("seat_zones z").
+		Select("z.id, z.name, z.ticket_type_id, t.ticket_type, t.price AS amount, e.currency").
+		Joins("JOIN tickets t ON t.id = z.ticket_type_id").
+		Joins("JOIN events e ON e.id = t.event_id").
+		Where("z.seat_map_id = ?", seatMapID).
+		Order("z.id").
+		Scan(&zones).Error
+	if err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+func (r *seatMapRepository) SeatViews(seatMapID, userID int, now time.Time) ([]dto.SeatView, error) {
+	var seats []dto.SeatView
+	err := r.db.Table("seats s").
+		Select(`s.id, s.zone_id, s.section, s.row, s.number, s.accessible,
+			CASE WHEN s.blocked THEN ?
+				WHEN a.seat_id IS NOT NULL THEN ?
+				WHEN s.held_until > ? AND s.held_by = ? THEN ?
+				WHEN s.held_until > ? THEN ?
+				ELSE ? END AS status`,
+			models.SeatBlocked, models.SeatTaken, now, userID, models.SeatHeldByYou, now, models.SeatHeld, models.SeatAvailable).
+		Joins("LEFT JOIN event_attendees a ON a.seat_id = s.id").
+		Where("s.seat_map_id = ?", seatMapID).
+		Order("s.id").
+		Scan(&seats).Error
+	if err != nil {
+		return nil, err
+	}
+	return seats, nil
+}
+
+func (r *seatMapRepository) ListSeats(seatMapID int) ([]models.Seat, []models.SeatZone, error) {
+	var seats []models.Seat
+	if err := r.db.Where("seat_map_id = ?", seatMapID).Order("id").Find(&seats).Error; err != nil {
+		return nil, nil, err
+	}
+	var zones []models.SeatZone
+	if err := r.db.Where("seat_map_id = ?", seatMapID).Order("id").Find(&zones).Error; err != nil {
+		return nil, nil, err
+	}
+	return seats, zones, nil
+}
+
+func (r *seatMapRepository) Hold(eventID, seatID, userID int, until, now time.Time) (*models.Seat, error) {
+	var seat models.Seat
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Satu user hanya menahan satu kursi per event
+		err := tx.Model(&models.Seat{}).
+			Where("event_id = ? AND held_by = ? AND id <> ?", eventID, userID, seatID).
+			Updates(map[string]any{"held_by": nil, "held_until": nil}).Error
+		if err != nil {
+			return err
+		}
+
+		// Satu UPDATE bersyarat: baris terkunci selama update, jadi dua user tidak bisa menahan kursi yang sama
+		result := tx.Model(&models.Seat{}).
+			Where("id = ? AND event_id = ? AND NOT blocked", seatID, eventID).
+			Where("held_until IS NULL OR held_until <= ? OR held_by = ?", now, userID).
+			Where("NOT EXISTS (SELECT 1 FROM event_attendees a WHERE a.seat_id = seats.id)").
+			Updates(map[string]any{"held_by": userID, "held_until": until})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSeatUnavailable
+		}
+		return tx.First(&seat, seatID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &seat, nil
+}
+
+func (r *seatMapRepository) ReleaseHolds(eventID, userID int) error {
+	return r.db.Model(&models.Seat{}).
+		Where("event_id = ? AND held_by = ?", eventID, userID).
+		Updates(map[string]any{"held_by": nil, "held_until": nil}).Error
+}
+
+func (r *seatMapRepository) FindHeldSeat(eventID, seatID, userID int, now time.Time) (*models.Seat, int, error) {
+	var seat models.Seat
+	err := r.db.Where("id = ? AND event_id = ? AND held_by = ? AND held_until > ?", seatID, eventID, userID, now).
+		First(&seat).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	var zone models.SeatZone
+	if err := r.db.First(&zone, seat.ZoneID).Error; err != nil {
+		return nil, 0, err
+	}
+	return &seat, zone.TicketTypeID, nil
+}
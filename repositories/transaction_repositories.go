@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"gatherly-app/models"
 	"gatherly-app/models/dto"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository interface {
@@ -18,11 +20,13 @@ type TransactionRepository interface {
 	FindByTransactionIdNoUser(id string) (models.Transactions, error)
 	FindByTransactionId(id string, userId int) (models.Transactions, error)
 	FindByStatus(status string, userId int) ([]models.Transactions, error)
-	FindByAmountRange(input dto.GetTransactionsByAmount, userId int) ([]models.Transactions, error)
+	FindByAmountRange(minAmount, maxAmount models.Money, userId int) ([]models.Transactions, error)
 	FindByDateRange(input dto.GetTransactionsByDate, userId int) ([]models.Transactions, error)
 	FindByTicket(ticket string, userId int) ([]models.Transactions, error)
 	DeleteById(id uint, userId int) error
-	UpdateStatus(input dto.MidtransNotification)
+	TransitionStatus(orderID string, from []string, status, paymentMethod, ticketCode string) (*dto.StatusTransition, error)
+	FindExpiredPending(before time.Time, limit int) ([]models.Transactions, error)
+	ExpirePending(transaction models.Transactions, status string, at time.Time) (*models.EventAttendee, bool, error)
 }
 
 type transactionRepository struct {
@@ -120,10 +124,10 @@ func (t *transactionRepository) FindByStatus(status string, userId int) ([]model
 	return transactions, nil
 }
 
-func (t *transactionRepository) FindByAmountRange(input dto.GetTransactionsByAmount, userId int) ([]models.Transactions, error) {
+func (t *transactionRepository) FindByAmountRange(minAmount, maxAmount models.Money, userId int) ([]models.Transactions, error) {
 	var transactions []models.Transactions
 
-	err := t.db.Where("amount BETWEEN ? AND ? AND user_id = ?", input.MinAmount, input.MaxAmount, userId).Find(&transactions).Error
+	err := t.db.Where("amount BETWEEN ? AND ? AND currency = ? AND user_id = ?", minAmount.Amount, maxAmount.Amount, minAmount.Currency, userId).Find(&transactions).Error
 	if err != nil {
 		return nil, err
 	}
@@ -178,13 +182,153 @@ func (t *transactionRepository) DeleteById(id uint, userId int) error {
 	return nil
 }
 
-func (t *transactionRepository) UpdateStatus(input dto.MidtransNotification) {
-	t.db.Model(&models.Transactions{}).Where("payment_gateway_transaction_id = ?", input.OrderID).Updates(map[string]any{
-		"status":         input.TransactionStatus,
-		"payment_method": input.PaymentType,
+// TransitionStatus mengubah status transaksi hanya jika status saat ini termasuk from, lalu menyalin
+// status baru ke pendaftaran terkait. Baris transaksi dikunci sehingga notifikasi yang sama yang masuk
+// bersamaan hanya diterapkan sekali.
+// ticketCode diisi untuk status lunas: pendaftaran yang masih pending dikonfirmasi dengan mengurangi
+// kuota ticket type dan menerbitkan ticket code, atau ditandai failed_no_quota jika kuota sudah habis.
+// Pendaftaran group booking tidak ikut diubah karena kuotanya sudah dipotong saat reservasi.
+func (t *transactionRepository) TransitionStatus(orderID string, from []string, status, paymentMethod, ticketCode string) (*dto.StatusTransition, error) {
+	result := &dto.StatusTransition{}
+
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transactions
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("payment_gateway_transaction_id = ?", orderID).
+			First(&transaction).Error
+		if err != nil {
+			return err
+		}
+		result.Previous = transaction.Status
+		if !slices.Contains(from, transaction.Status) {
+			return nil
+		}
+
+		err = tx.Model(&transaction).Updates(map[string]any{
+			"status":         status,
+			"payment_method": paymentMethod,
+		}).Error
+		if err != nil {
+			return err
+		}
+		result.Changed = true
+
+		var attendees []models.EventAttendee
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("event_id = ? AND user_id = ? AND rsvp_date = ?", transaction.EventId, transaction.UserId, transaction.TransactionDate).
+			Where("group_booking_id IS NULL").
+			Find(&attendees).Error
+		if err != nil || len(attendees) == 0 {
+			return err
+		}
+		attendee := attendees[0]
+		scope := tx.Model(&models.EventAttendee{}).Where("event_id = ? AND user_id = ?", attendee.EventID, attendee.UserID)
+
+		if ticketCode == "" || attendee.PaymentStatus != "pending" || attendee.TicketTypeID == nil {
+			return scope.Update("payment_status", status).Error
+		}
+
+		quota := tx.Model(&models.Ticket{}).
+			Where("id = ? AND quota > 0", *attendee.TicketTypeID).
+			UpdateColumn("quota", gorm.Expr("quota - 1"))
+		if quota.Error != nil {
+			return quota.Error
+		}
+		if quota.RowsAffected == 0 {
+			// Pembayaran sudah diterima tetapi kuota habis; perlu refund manual
+			attendee.PaymentStatus = "failed_no_quota"
+		} else {
+			attendee.PaymentStatus = "paid"
+			attendee.TicketCode = &ticketCode
+		}
+		err = scope.Updates(map[string]any{
+			"payment_status": attendee.PaymentStatus,
+			"ticket_code":    attendee.TicketCode,
+		}).Error
+		if err != nil {
+			return err
+		}
+		result.Issued = &attendee
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FindExpiredPending mengembalikan transaksi Midtrans pending yang dibuat sebelum before, beserta event-nya
+func (t *transactionRepository) FindExpiredPending(before time.Time, limit int) ([]models.Transactions, error) {
+	var transactions []models.Transactions
+	err := t.db.Preload("Event").
+		Where("status = ? AND transaction_date < ?", "pending", before).
+		Where("gateway = ?", models.PaymentGatewayMidtrans).
+		Order("id").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
 
-	t.db.Model(&models.EventAttendee{}).
-		Where("(event_id, user_id, rsvp_date) IN (SELECT event_id, user_id, transaction_date FROM transactions WHERE payment_gateway_transaction_id = ?)", input.OrderID).
-		Update("payment_status", input.TransactionStatus)
+// ExpirePending menandai transaksi dengan status akhir dan melepas pendaftaran yang masih menunggu
+// pembayaran dalam satu transaksi database. Kuota tiket baru dikurangi saat pembayaran dikonfirmasi,
+// jadi menghapus pendaftaran sudah cukup untuk membebaskan tempatnya.
+// expired false berarti transaksi sudah tidak pending (mis. notifikasi Midtrans masuk duluan) atau
+// pendaftarannya sudah dikonfirmasi lunas lewat jalur lain; tidak ada yang diubah.
+func (t *transactionRepository) ExpirePending(transaction models.Transactions, status string, at time.Time) (*models.EventAttendee, bool, error) {
+	var released *models.EventAttendee
+	expired := false
+
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		var attendees []models.EventAttendee
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("event_id = ? AND user_id = ? AND rsvp_date = ?", transaction.EventId, transaction.UserId, transaction.TransactionDate).
+			Find(&attendees).Error
+		if err != nil {
+			return err
+		}
+		if len(attendees) > 0 && attendees[0].PaymentStatus != "pending" {
+			return nil
+		}
+
+		result := tx.Model(&models.Transactions{}).
+			Where("id = ? AND status = ?", transaction.ID, "pending").
+			Update("status", status)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		expired = true
+
+		if len(attendees) == 0 {
+			return nil
+		}
+		attendee := attendees[0]
+		err = tx.Where("event_id = ? AND user_id = ?", attendee.EventID, attendee.UserID).
+			Delete(&models.EventAttendee{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&models.RegistrationCancellation{
+			EventID:       attendee.EventID,
+			UserID:        attendee.UserID,
+			TicketTypeID:  attendee.TicketTypeID,
+			PaymentStatus: status,
+			RegisteredAt:  attendee.RSVPDate,
+			CancelledAt:   at,
+		}).Error
+		if err != nil {
+			return err
+		}
+		released = &attendee
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return released, expired, nil
 }
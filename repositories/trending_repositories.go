@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TrendingRepository interface {
+	RefreshScores(window time.Duration) error
+	FindTrending(query dto.TrendingEventQuery) ([]dto.TrendingEventDTO, error)
+}
+
+type trendingRepository struct {
+	db *gorm.DB
+}
+
+func NewTrendingRepository(db *gorm.DB) *trendingRepository {
+	return &trendingRepository{db: db}
+}
+
+// RefreshScores menghitung ulang seluruh tabel event_trending_scores dalam satu transaksi,
+// sehingga pembaca tidak pernah melihat tabel yang setengah terisi.
+func (r *trendingRepository) RefreshScores(window time.Duration) error {
+	now := time.Now()
+	since := now.Add(-window)
+	days := window.Hours() / 24
+
+	query := `
+	INSERT INTO event_trending_scores
+		(event_id, registrations, registration_velocity, paid_conversion, views, score, computed_at)
+	SELECT
+		e.id,
+		COALESCE(reg.total, 0),
+		COALESCE(reg.total, 0) / ?::float,
+		COALESCE(tx.paid::float / NULLIF(tx.total, 0), 0),
+		COALESCE(v.total, 0),
+		0.5 * ln(1 + COALESCE(reg.total, 0) / ?::float)
+			+ 0.2 * COALESCE(tx.paid::float / NULLIF(tx.total, 0), 0)
+			+ 0.3 * ln(1 + COALESCE(v.total, 0) / ?::float),
+		?
+	FROM events e
+	LEFT JOIN (
+		SELECT event_id, COUNT(*) AS total
+		FROM event_attendees
+		WHERE rsvp_date >= ?
+		GROUP BY event_id
+	) reg ON reg.event_id = e.id
+	LEFT JOIN (
+		SELECT event_id,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status IN ('settlement', 'capture')) AS paid
+		FROM transactions
+		WHERE transaction_date >= ? AND deleted_at IS NULL
+		GROUP BY event_id
+	) tx ON tx.event_id = e.id
+	LEFT JOIN (
+		SELECT event_id, COUNT(*) AS total
+		FROM event_views
+		WHERE viewed_at >= ?
+		GROUP BY event_id
+	) v ON v.event_id = e.id
+	WHERE e.end_date >= ? AND (e.status IS NULL OR e.status <> ?)
+	`
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM event_trending_scores").Error; err != nil {
+			return err
+		}
+		return tx.Exec(query, days, days, days, now, since, since, since, now, models.EventStatusCancelled).Error
+	})
+}
+
+func (r *trendingRepository) FindTrending(query dto.TrendingEventQuery) ([]dto.TrendingEventDTO, error) {
+	var results []dto.TrendingEventDTO
+
+	distance := `CAST(6371 * acos(LEAST(1,
+		cos(radians(?)) * cos(radians(e.latitude)) * cos(radians(e.longitude) - radians(?)) +
+		sin(radians(?)) * sin(radians(e.latitude))
+	)) AS FLOAT)`
+
+	db := r.db.Table("event_trending_scores s").
+		Joins("JOIN events e ON e.id = s.event_id").
+		Where("e.end_date >= ? AND (e.status IS NULL OR e.status <> ?)", time.Now(), models.EventStatusCancelled).
+		Where("e.visibility = ?", models.EventVisibilityPublic)
+
+	selectColumns := `e.id, e.name, e.category, e.start_date, e.end_date, e.is_paid, e.capacity,
+		e.latitude, e.longitude, e.poster_url,
+		s.registrations, s.registration_velocity, s.paid_conversion, s.views, s.score, s.computed_at`
+
+	if query.Radius > 0 {
+		db = db.Select(selectColumns+", "+distance+" AS distance",
+			query.Latitude, query.Longitude, query.Latitude).
+			Where(distance+" <= ?", query.Latitude, query.Longitude, query.Latitude, query.Radius)
+	} else {
+		db = db.Select(selectColumns)
+	}
+
+	if query.Category != "" {
+		db = db.Where("LOWER(e.category) = LOWER(?)", query.Category)
+	}
+
+	err := db.Order("s.score DESC").Limit(query.Limit).Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
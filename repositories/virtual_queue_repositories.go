@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"gatherly-app/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VirtualQueueRepository interface {
+	// Join memasukkan user ke antrean; jika sudah ada, entry lama dikembalikan (posisi tidak berubah)
+	Join(eventID, userID int, now time.Time) (*models.QueueEntry, error)
+	// Requeue memindahkan entry yang kedaluwarsa ke belakang antrean dengan ID baru
+	Requeue(entryID int64, now time.Time) (*models.QueueEntry, error)
+	FindEntry(eventID, userID int) (*models.QueueEntry, error)
+	FindEntryByID(id int64) (*models.QueueEntry, error)
+	Position(entry *models.QueueEntry) (int64, error)
+	Complete(eventID, userID int) error
+
+	// QueuedEvents mengembalikan event dengan mode antrean aktif yang masih punya user menunggu
+	QueuedEvents() ([]models.EventPurchasePolicy, error)
+	// AvailableInventory adalah sisa kuota dikurangi registrasi yang belum mengurangi kuota dan user yang sudah masuk checkout
+	AvailableInventory(eventID int) (int, error)
+	// AdmittedSince menghitung user yang masuk checkout sejak waktu tertentu, untuk admission rate
+	AdmittedSince(eventID int, since time.Time) (int, error)
+	AdmitNext(eventID, limit int, now, expiresAt time.Time) (int64, error)
+	// ExpireCheckouts menutup jendela checkout yang lewat sehingga inventory-nya bisa dipakai user berikutnya
+	ExpireCheckouts(now time.Time) (int64, error)
+}
+
+type virtualQueueRepository struct {
+	db *gorm.DB
+}
+
+func NewVirtualQueueRepository(db *gorm.DB) *virtualQueueRepository {
+	return &virtualQueueRepository{db: db}
+}
+
+func (r *virtualQueueRepository) Join(eventID, userID int, now time.Time) (*models.QueueEntry, error) {
+	entry := &models.QueueEntry{EventID: eventID, UserID: userID, Status: models.QueueWaiting, JoinedAt: now}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "user_id"}},
+		DoNothing: true,
+	}).Create(entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.FindEntry(eventID, userID)
+}
+
+func (r *virtualQueueRepository) Requeue(entryID int64, now time.Time) (*models.QueueEntry, error) {
+	var entries []models.QueueEntry
+	err := r.db.Raw(`
+		UPDATE queue_entries
+		SET id = nextval(pg_get_serial_sequence('queue_entries', 'id')), status = ?, joined_at = ?,
+			admitted_at = NULL, expires_at = NULL
+		WHERE id = ? AND status = ?
+		RETURNING *`,
+		models.QueueWaiting, now, entryID, models.QueueExpired,
+	).Scan(&entries).Error
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+func (r *virtualQueueRepository) FindEntry(eventID, userID int) (*models.QueueEntry, error) {
+	var entries []models.QueueEntry
+	if err := r.db.Where("event_id = ? AND user_id = ?", eventID, userID).Limit(1).Find(&entries).Error; err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+func (r *virtualQueueRepository) FindEntryByID(id int64) (*models.QueueEntry, error) {
+	var entries []models.QueueEntry
+	if err := r.db.Where("id = ?", id).Limit(1).Find(&entries).Error; err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+func (r *virtualQueueRepository) Position(entry *models.QueueEntry) (int64, error) {
+	var position int64
+	err := r.db.Model(&models.QueueEntry{}).
+		Where("event_id = ? AND status = ? AND id <= ?", entry.EventID, models.QueueWaiting, entry.ID).
+		Count(&position).Error
+	return position, err
+}
+
+func (r *virtualQueueRepository) Complete(eventID, userID int) error {
+	return r.db.Model(&models.QueueEntry{}).
+		Where("event_id = ? AND user_id = ? AND status = ?", eventID, userID, models.QueueAdmitted).
+		Update("status", models.QueueCompleted).Error
+}
+
+func (r *virtualQueueRepository) QueuedEvents() ([]models.EventPurchasePolicy, error) {
+	var policies []models.EventPurchasePolicy
+	err := r.db.Where("queue_enabled").
+		Where("EXISTS (SELECT 1 FROM queue_entries q WHERE q.event_id = event_purchase_policies.event_id AND q.status = ?)", models.QueueWaiting).
+		Find(&policies).Error
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// AvailableInventory: kuota tiket baru berkurang saat pembayaran lunas (TransitionStatus) atau
+// saat group booking dibuat. Registrasi event gratis ("unpaid") dan yang menunggu bayar ("pending")
+// belum mengurangi kuota, jadi dikurangi di sini; kursi group booking sudah dipotong saat reserve.
+func (r *virtualQueueRepository) AvailableInventory(eventID int) (int, error) {
+	var available int
+	err := r.db.Raw(`SELECT
+			(SELECT COALESCE(SUM(quota), 0) FROM tickets WHERE event_id = ? AND status = 'available') -
+			(SELECT COUNT(*) FROM event_attendees a
+				JOIN tickets t ON t.id = a.ticket_type_id AND t.status = 'available'
+				WHERE a.event_id = ? AND a.group_booking_id IS NULL AND a.payment_status IN ('unpaid', 'pending')) -
+			(SELECT COUNT(*) FROM queue_entries WHERE event_id = ? AND status = ?)`,
+		eventID, eventID, eventID, models.QueueAdmitted).Scan(&available).Error
+	return available, err
+}
+
+func (r *virtualQueueRepository) AdmittedSince(eventID int, since time.Time) (int, error) {
+	var count int64
+	err := r.db.Model(&models.QueueEntry{}).
+		Where("event_id = ? AND admitted_at > ?", eventID, since).
+		Count(&count).Error
+	return int(count), err
+}
+
+// AdmitNext memindahkan user terdepan ke status admitted. SKIP LOCKED membuat dua instance
+// yang berjalan bersamaan tidak mengambil user yang sama.
+func (r *virtualQueueRepository) AdmitNext(eventID, limit int, now, expiresAt time.Time) (int64, error) {
+	result := r.db.Exec(`
+		UPDATE queue_entries
+		SET status = ?, admitted_at = ?, expires_at = ?
+		WHERE id IN (
+			SELECT id FROM queue_entries
+			WHERE event_id = ? AND status = ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)`,
+		models.QueueAdmitted, now, expiresAt, eventID, models.QueueWaiting, limit)
+	return result.RowsAffected, result.Error
+}
+
+func (r *virtualQueueRepository) ExpireCheckouts(now time.Time) (int64, error) {
+	result := r.db.Model(&models.QueueEntry{}).
+		Where("status = ? AND expires_at <= ?", models.QueueAdmitted, now).
+		Update("status", models.QueueExpired)
+	return result.RowsAffected, result.Error
+}
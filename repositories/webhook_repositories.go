@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"encoding/json"
+	"gatherly-app/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(endpoint *models.WebhookEndpoint) error
+	UpdateEndpoint(endpoint *models.WebhookEndpoint) error
+	DeleteEndpoint(organizerID, endpointID int) error
+	FindEndpoint(organizerID, endpointID int) (*models.WebhookEndpoint, error)
+	FindEndpointsByIDs(ids []int) ([]models.WebhookEndpoint, error)
+	ListEndpoints(organizerID int) ([]models.WebhookEndpoint, error)
+	FindSubscribedEndpoints(organizerID, eventID int, eventType string) ([]models.WebhookEndpoint, error)
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+	ClaimDueDeliveries(limit int, now time.Time) ([]models.WebhookDelivery, error)
+	ReleaseStaleDeliveries(before time.Time) error
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	FindDelivery(endpointID, deliveryID int) (*models.WebhookDelivery, error)
+	ListDeliveries(endpointID, limit int) ([]models.WebhookDelivery, error)
+	CreateAttempt(attempt *models.WebhookAttempt) error
+	ListAttempts(deliveryID int) ([]models.WebhookAttempt, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *webhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Create(endpoint).Error
+}
+
+func (r *webhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Save(endpoint).Error
+}
+
+// DeleteEndpoint ikut menghapus riwayat delivery dan percobaannya
+func (r *webhookRepository) DeleteEndpoint(organizerID, endpointID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND organizer_id = ?", endpointID, organizerID).Delete(&models.WebhookEndpoint{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		deliveries := tx.Model(&models.WebhookDelivery{}).Select("id").Where("endpoint_id = ?", endpointID)
+		if err := tx.Where("delivery_id IN (?)", deliveries).Delete(&models.WebhookAttempt{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("endpoint_id = ?", endpointID).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+func (r *webhookRepository) FindEndpoint(organizerID, endpointID int) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	err := r.db.Where("id = ? AND organizer_id = ?", endpointID, organizerID).First(&endpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *webhookRepository) FindEndpointsByIDs(ids []int) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if len(ids) == 0 {
+		return endpoints, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&endpoints).Error
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (r *webhookRepository) ListEndpoints(organizerID int) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.db.Where("organizer_id = ?", organizerID).Order("id").Find(&endpoints).Error
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// FindSubscribedEndpoints mengembalikan endpoint aktif organizer untuk event tersebut (atau semua event)
+// yang berlangganan eventType
+func (r *webhookRepository) FindSubscribedEndpoints(organizerID, eventID int, eventType string) ([]models.WebhookEndpoint, error) {
+	eventTypes, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []models.WebhookEndpoint
+	err = r.db.
+		Where("organizer_id = ? AND active = ?", organizerID, true).
+		Where("event_id IS NULL OR event_id = ?", eventID).
+		Where("event_types @> ?::jsonb", string(eventTypes)).
+		Find(&endpoints).Error
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (r *webhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	// Payload yang sama (EmitOnce dengan key yang sama) tidak diantrekan dua kali ke endpoint yang sama
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// ClaimDueDeliveries mengambil delivery yang next_attempt_at-nya sudah lewat dan menandainya "sending".
+// Nomor percobaan ditentukan di sini; attempt mencatat WebhookAttempt dengan nomor tersebut.
+func (r *webhookRepository) ClaimDueDeliveries(limit int, now time.Time) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Raw(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.WebhookDeliverySending, now, models.WebhookDeliveryPending, now, limit,
+	).Scan(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ReleaseStaleDeliveries mengembalikan delivery yang tertahan di "sending" (mis. server mati saat mengirim)
+func (r *webhookRepository) ReleaseStaleDeliveries(before time.Time) error {
+	return r.db.Model(&models.WebhookDelivery{}).
+		Where("status = ? AND updated_at < ?", models.WebhookDeliverySending, before).
+		Update("status", models.WebhookDeliveryPending).Error
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_status_code", "last_error", "delivered_at", "updated_at").
+		Updates(delivery).Error
+}
+
+func (r *webhookRepository) FindDelivery(endpointID, deliveryID int) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.Where("id = ? AND endpoint_id = ?", deliveryID, endpointID).First(&delivery).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) ListDeliveries(endpointID, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Where("endpoint_id = ?", endpointID).Order("id DESC").Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) CreateAttempt(attempt *models.WebhookAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+func (r *webhookRepository) ListAttempts(deliveryID int) ([]models.WebhookAttempt, error) {
+	var attempts []models.WebhookAttempt
+	err := r.db.Where("delivery_id = ?", deliveryID).Order("id").Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
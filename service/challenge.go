@@ -0,0 +1,144 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// ErrChallengeInvalid dikembalikan saat jawaban challenge salah, kedaluwarsa atau milik subject lain.
+var ErrChallengeInvalid = errors.New("challenge answer is invalid or expired")
+
+// Challenge dikirim ke client sebelum registrasi pada event dengan proteksi bot.
+// Untuk proof-of-work, client mencari Solution sehingga sha256(Token + ":" + Solution)
+// diawali minimal Difficulty bit nol.
+type Challenge struct {
+	Provider   string    `json:"provider"`
+	Token      string    `json:"token,omitempty"`
+	Difficulty int       `json:"difficulty,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ChallengeProvider adalah hook anti-bot untuk on-sale ramai. Provider lain (mis. captcha pihak
+// ketiga) cukup memenuhi interface ini dan didaftarkan di NewChallengeProvider.
+type ChallengeProvider interface {
+	// Issue membuat challenge yang terikat ke subject (mis. user + event atau IP signup)
+	Issue(subject string) (*Challenge, error)
+	// Verify mengembalikan ID unik challenge agar pemakaian ulang bisa ditolak pemanggil
+	Verify(subject, token, solution string) (string, error)
+}
+
+// NewChallengeProvider memilih provider dari config; nama kosong berarti proof-of-work.
+func NewChallengeProvider(name, secret string, difficulty int, ttl time.Duration) (ChallengeProvider, error) {
+	switch name {
+	case "", "pow":
+		if secret == "" {
+			return nil, errors.New("proof-of-work challenge requires a secret")
+		}
+		return &proofOfWork{secret: []byte(secret), difficulty: difficulty, ttl: ttl}, nil
+	case "none":
+		return noChallenge{}, nil
+	default:
+		return nil, fmt.Errorf("unknown challenge provider %q", name)
+	}
+}
+
+// proofOfWork tidak menyimpan state: token berisi subject, nonce, difficulty dan kedaluwarsa yang
+// ditandatangani HMAC, sehingga semua instance bisa memverifikasinya.
+type proofOfWork struct {
+	secret     []byte
+	difficulty int
+	ttl        time.Duration
+}
+
+type powClaims struct {
+	Subject    string `json:"s"`
+	Nonce      string `json:"n"`
+	Difficulty int    `json:"d"`
+	ExpiresAt  int64  `json:"e"`
+}
+
+// signPayload menghasilkan tanda tangan HMAC-SHA256 base64url untuk token tanpa state
+func signPayload(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (p *proofOfWork) Issue(subject string) (*Challenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(p.ttl)
+	claims, err := json.Marshal(powClaims{
+		Subject:    subject,
+		Nonce:      hex.EncodeToString(nonce),
+		Difficulty: p.difficulty,
+		ExpiresAt:  expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(claims)
+	return &Challenge{
+		Provider:   "pow",
+		Token:      payload + "." + signPayload(p.secret, payload),
+		Difficulty: p.difficulty,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+func (p *proofOfWork) Verify(subject, token, solution string) (string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signPayload(p.secret, payload))) {
+		return "", ErrChallengeInvalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrChallengeInvalid
+	}
+	var claims powClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return "", ErrChallengeInvalid
+	}
+	if claims.Subject != subject || time.Now().Unix() > claims.ExpiresAt {
+		return "", ErrChallengeInvalid
+	}
+
+	sum := sha256.Sum256([]byte(token + ":" + solution))
+	if leadingZeroBits(sum[:]) < claims.Difficulty {
+		return "", ErrChallengeInvalid
+	}
+	return claims.Nonce, nil
+}
+
+func leadingZeroBits(hash []byte) int {
+	zeros := 0
+	for _, b := range hash {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+	return zeros
+}
+
+// noChallenge mematikan challenge, mis. untuk development atau saat proteksi dilakukan di depan API
+type noChallenge struct{}
+
+func (noChallenge) Issue(subject string) (*Challenge, error) {
+	return &Challenge{Provider: "none"}, nil
+}
+
+func (noChallenge) Verify(subject, token, solution string) (string, error) {
+	return "", nil
+}
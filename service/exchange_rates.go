@@ -0,0 +1,73 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"gatherly-app/models"
+	"math"
+	"os"
+	"strings"
+)
+
+// ExchangeRates adalah kurs dari file lokal, hanya untuk menampilkan perkiraan harga dalam mata uang
+// lain. Rates berisi nilai 1 unit Base dalam mata uang lain, mis. base IDR dan "USD": 0.000064.
+type ExchangeRates struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// LoadExchangeRates membaca file kurs. Path kosong berarti konversi dimatikan (nil, nil).
+func LoadExchangeRates(path string) (*ExchangeRates, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rates ExchangeRates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("invalid exchange rates file %s: %w", path, err)
+	}
+
+	base, err := models.LookupCurrency(rates.Base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange rates base: %w", err)
+	}
+	rates.Base = base.Code
+	normalized := make(map[string]float64, len(rates.Rates)+1)
+	for code, rate := range rates.Rates {
+		currency, err := models.LookupCurrency(code)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exchange rate: %w", err)
+		}
+		if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return nil, fmt.Errorf("exchange rate for %s must be positive", currency.Code)
+		}
+		normalized[currency.Code] = rate
+	}
+	normalized[base.Code] = 1
+	rates.Rates = normalized
+	return &rates, nil
+}
+
+// Convert mengubah nominal ke mata uang lain lewat kurs base. ok false jika konversi dimatikan
+// atau salah satu mata uang tidak punya kurs. rate adalah nilai 1 unit mata uang asal.
+func (r *ExchangeRates) Convert(amount models.Money, to string) (converted models.Money, rate float64, ok bool) {
+	if r == nil {
+		return models.Money{}, 0, false
+	}
+	fromRate, fromOK := r.Rates[strings.ToUpper(amount.Currency)]
+	toRate, toOK := r.Rates[strings.ToUpper(to)]
+	if !fromOK || !toOK {
+		return models.Money{}, 0, false
+	}
+
+	rate = toRate / fromRate
+	converted, err := models.MoneyFromMajor(amount.Major()*rate, to)
+	if err != nil {
+		return models.Money{}, 0, false
+	}
+	return converted, rate, true
+}
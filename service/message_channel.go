@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gatherly-app/models"
+	"gatherly-app/repositories"
+)
+
+// OutgoingMessage adalah satu pesan untuk satu penerima pada satu channel
+type OutgoingMessage struct {
+	UserID      int
+	Address     string // alamat tujuan khusus channel, mis. email
+	EventID     int
+	BroadcastID int
+	Type        string // jenis pesan: models.InboxTypeBroadcast atau tipe notifikasi
+	Subject     string
+	Body        string
+}
+
+// MessageChannel adalah titik ekstensi pengiriman pesan (email, inbox, dan channel lain di kemudian hari)
+type MessageChannel interface {
+	Name() string
+	Send(ctx context.Context, message OutgoingMessage) error
+}
+
+type inboxChannel struct {
+	repo   repositories.InboxRepository
+	pubsub PubSub
+}
+
+// NewInboxChannel mengirim pesan ke inbox in-app milik user dan meneruskannya ke client yang sedang terhubung
+func NewInboxChannel(repo repositories.InboxRepository, pubsub PubSub) MessageChannel {
+	return &inboxChannel{repo: repo, pubsub: pubsub}
+}
+
+func (c *inboxChannel) Name() string {
+	return models.ChannelInbox
+}
+
+func (c *inboxChannel) Send(ctx context.Context, message OutgoingMessage) error {
+	if message.UserID == 0 {
+		return errors.New("inbox message requires a user")
+	}
+
+	inbox := &models.InboxMessage{
+		UserID:  message.UserID,
+		Type:    message.Type,
+		Subject: message.Subject,
+		Body:    message.Body,
+	}
+	if inbox.Type == "" {
+		inbox.Type = models.InboxTypeBroadcast
+	}
+	if message.EventID != 0 {
+		inbox.EventID = &message.EventID
+	}
+	if message.BroadcastID != 0 {
+		inbox.BroadcastID = &message.BroadcastID
+	}
+	if err := c.repo.Create(inbox); err != nil {
+		return err
+	}
+
+	c.pubsub.Publish(UserTopic(message.UserID), StreamEvent{Type: StreamInboxMessage, Data: inbox})
+	return nil
+}
@@ -1,17 +1,34 @@
 package service
 
 import (
+	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"gatherly-app/models"
 	"gatherly-app/models/dto"
+	"net/http"
+	"strings"
 
 	"github.com/go-resty/resty/v2"
 )
 
+// ErrCurrencyNotSupported dikembalikan untuk mata uang yang tidak bisa diproses payment provider
+var ErrCurrencyNotSupported = errors.New("currency is not supported by the payment provider")
+
+// midtransCurrency adalah satu-satunya mata uang yang diterima Midtrans Snap
+const midtransCurrency = "IDR"
+
 type MidtransService interface {
 	Pay(payload dto.MidtransSnapReq) (dto.MidtransSnapResp, error)
 	CancelTransaction(orderID string) error
+	GetStatus(orderID string) (dto.MidtransStatusResp, error)
+	SupportsCurrency(currency string) bool
+	GrossAmount(amount models.Money) (int, error)
+	VerifySignature(notification dto.MidtransNotification) bool
 }
 
 type midtransService struct {
@@ -69,3 +86,65 @@ func (m *midtransService) CancelTransaction(orderID string) error {
 
 	return nil
 }
+
+func (m *midtransService) GetStatus(orderID string) (dto.MidtransStatusResp, error) {
+	encodedKey := base64.StdEncoding.EncodeToString([]byte(m.serverKey))
+
+	url := fmt.Sprintf("%s/%s/status", m.url, orderID)
+	resp, err := m.client.R().
+		SetHeader("Authorization", "Basic "+encodedKey).
+		SetHeader("Accept", "application/json").
+		Get(url)
+
+	if err != nil {
+		return dto.MidtransStatusResp{}, err
+	}
+	// Order yang tidak dikenal bisa dijawab dengan HTTP 404 maupun HTTP 200 + status_code "404"
+	if !resp.IsSuccess() && resp.StatusCode() != http.StatusNotFound {
+		return dto.MidtransStatusResp{}, fmt.Errorf("midtrans status API responded with %d", resp.StatusCode())
+	}
+
+	var statusResp dto.MidtransStatusResp
+	err = json.Unmarshal(resp.Body(), &statusResp)
+	if err != nil {
+		return dto.MidtransStatusResp{}, err
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		statusResp.StatusCode = "404"
+	}
+
+	return statusResp, nil
+}
+
+func (m *midtransService) SupportsCurrency(currency string) bool {
+	return strings.EqualFold(currency, midtransCurrency)
+}
+
+// GrossAmount mengubah nominal minor unit ke gross_amount Midtrans, yaitu rupiah bulat
+func (m *midtransService) GrossAmount(amount models.Money) (int, error) {
+	if !m.SupportsCurrency(amount.Currency) {
+		return 0, fmt.Errorf("%w: %s", ErrCurrencyNotSupported, amount.Currency)
+	}
+	currency, err := models.LookupCurrency(amount.Currency)
+	if err != nil {
+		return 0, err
+	}
+	scale := int64(1)
+	for i := 0; i < currency.Exponent; i++ {
+		scale *= 10
+	}
+	if amount.Amount%scale != 0 {
+		return 0, fmt.Errorf("midtrans only accepts whole rupiah amounts, got %s", amount.Decimal())
+	}
+	return int(amount.Amount / scale), nil
+}
+
+// VerifySignature memeriksa signature_key notifikasi, yaitu SHA512(order_id+status_code+gross_amount+server key)
+func (m *midtransService) VerifySignature(notification dto.MidtransNotification) bool {
+	if notification.SignatureKey == "" {
+		return false
+	}
+	sum := sha512.Sum512([]byte(notification.OrderID + notification.StatusCode + notification.GrossAmount + m.serverKey))
+	expected := hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(notification.SignatureKey))) == 1
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestGrossAmount(t *testing.T) {
+	midtrans := NewMidtransService(resty.New(), "test-key")
+	tests := []struct {
+		name    string
+		amount  models.Money
+		want    int
+		wantErr bool
+	}{
+		{"whole rupiah", models.Money{Amount: 1500000, Currency: "IDR"}, 15000, false},
+		{"lowercase currency", models.Money{Amount: 100, Currency: "idr"}, 1, false},
+		{"zero", models.Money{Amount: 0, Currency: "IDR"}, 0, false},
+		{"large amount", models.Money{Amount: 2_500_000_000_00, Currency: "IDR"}, 2_500_000_000, false},
+		{"sen is rejected", models.Money{Amount: 1500050, Currency: "IDR"}, 0, true},
+		{"other currency is rejected", models.Money{Amount: 999, Currency: "USD"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := midtrans.GrossAmount(tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GrossAmount(%+v) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := midtrans.GrossAmount(models.Money{Amount: 999, Currency: "USD"}); !errors.Is(err, ErrCurrencyNotSupported) {
+		t.Errorf("USD error = %v, want ErrCurrencyNotSupported", err)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	midtrans := NewMidtransService(resty.New(), "test-key")
+	// SHA512("order-1" + "200" + "150000.00" + "test-key")
+	const signature = "39ab69213e00a1876b00381072bdbaee0a9668eb3a1a84e823c913cc7cf15d07ade55b2caa532c32a70a7fda5d637bc558204f22aa405593f3cb085226bebfaa"
+	valid := dto.MidtransNotification{OrderID: "order-1", StatusCode: "200", GrossAmount: "150000.00", SignatureKey: signature}
+
+	tests := []struct {
+		name   string
+		modify func(n *dto.MidtransNotification)
+		want   bool
+	}{
+		{"valid", func(n *dto.MidtransNotification) {}, true},
+		{"uppercase signature", func(n *dto.MidtransNotification) { n.SignatureKey = strings.ToUpper(n.SignatureKey) }, true},
+		{"missing signature", func(n *dto.MidtransNotification) { n.SignatureKey = "" }, false},
+		{"different amount", func(n *dto.MidtransNotification) { n.GrossAmount = "1.00" }, false},
+		{"different status code", func(n *dto.MidtransNotification) { n.StatusCode = "201" }, false},
+		{"different order", func(n *dto.MidtransNotification) { n.OrderID = "order-2" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := valid
+			tt.modify(&notification)
+			if got := midtrans.VerifySignature(notification); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if NewMidtransService(resty.New(), "other-key").VerifySignature(valid) {
+		t.Error("signature made with another server key was accepted")
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// StreamEvent adalah satu pesan real-time; Data harus bisa di-encode ke JSON
+// agar implementasi lain (mis. Postgres LISTEN/NOTIFY) bisa mengirimnya antar instance.
+type StreamEvent struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// Tipe event yang dikirim ke topic user
+const (
+	StreamInboxMessage  = "inbox.message"
+	StreamInboxRead     = "inbox.read"
+	StreamPaymentStatus = "payment.status"
+	StreamTicketIssued  = "ticket.issued"
+)
+
+// Tipe event yang dikirim ke topic event (dashboard organizer)
+const (
+	StreamEventSnapshot      = "snapshot"
+	StreamAttendeeRegistered = "attendee.registered"
+	StreamAttendeeCancelled  = "attendee.cancelled"
+	StreamPaymentSettled     = "payment.settled"
+	StreamCheckInCreated     = "checkin.created"
+)
+
+// StreamQueueAdvanced dikirim ke topic antrean setiap ada user yang masuk checkout
+const StreamQueueAdvanced = "queue.advanced"
+
+// PubSub menyebarkan StreamEvent ke subscriber per topic.
+type PubSub interface {
+	Publish(topic string, event StreamEvent)
+	// Subscribe mengembalikan channel event dan fungsi untuk berhenti berlangganan
+	Subscribe(topic string) (<-chan StreamEvent, func())
+	// HasSubscribers memberi tahu publisher apakah event perlu disiapkan sama sekali
+	HasSubscribers(topic string) bool
+}
+
+// UserTopic adalah topic untuk event milik satu user (inbox, status pembayaran, tiket)
+func UserTopic(userID int) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// EventTopic adalah topic untuk perubahan peserta dan penjualan satu event
+func EventTopic(eventID int) string {
+	return fmt.Sprintf("event:%d", eventID)
+}
+
+// QueueTopic adalah topic antrean virtual satu event
+func QueueTopic(eventID int) string {
+	return fmt.Sprintf("queue:%d", eventID)
+}
+
+const subscriberBuffer = 32
+
+type memoryPubSub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan StreamEvent]struct{}
+}
+
+// NewMemoryPubSub membuat pub/sub in-process; hanya menjangkau client yang terhubung ke instance yang sama
+func NewMemoryPubSub() PubSub {
+	return &memoryPubSub{subscribers: make(map[string]map[chan StreamEvent]struct{})}
+}
+
+// Publish tidak pernah memblokir: subscriber yang buffer-nya penuh dilewati
+func (p *memoryPubSub) Publish(topic string, event StreamEvent) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for ch := range p.subscribers[topic] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("pubsub: dropping %s event for slow subscriber on %s", event.Type, topic)
+		}
+	}
+}
+
+func (p *memoryPubSub) HasSubscribers(topic string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.subscribers[topic]) > 0
+}
+
+func (p *memoryPubSub) Subscribe(topic string) (<-chan StreamEvent, func()) {
+	ch := make(chan StreamEvent, subscriberBuffer)
+
+	p.mu.Lock()
+	if p.subscribers[topic] == nil {
+		p.subscribers[topic] = make(map[chan StreamEvent]struct{})
+	}
+	p.subscribers[topic][ch] = struct{}{}
+	p.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			p.mu.Lock()
+			delete(p.subscribers[topic], ch)
+			if len(p.subscribers[topic]) == 0 {
+				delete(p.subscribers, topic)
+			}
+			p.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrQueueTokenInvalid dikembalikan saat token antrean rusak atau tanda tangannya salah
+var ErrQueueTokenInvalid = errors.New("invalid queue token")
+
+// QueueTokenClaims mengikat token antrean ke satu entry. EntryID menentukan urutan di antrean,
+// sehingga token yang sama tetap berlaku sampai user keluar atau masuk ulang ke antrean.
+type QueueTokenClaims struct {
+	EventID  int   `json:"e"`
+	UserID   int   `json:"u"`
+	EntryID  int64 `json:"q"`
+	IssuedAt int64 `json:"t"`
+}
+
+// QueueTokenSigner membuat dan memverifikasi token antrean tanpa state, sehingga status antrean
+// bisa dicek dari instance mana pun tanpa JWT.
+type QueueTokenSigner struct {
+	secret []byte
+}
+
+// queueTokenDomain dipisahkan dari token challenge agar token satu jenis tidak bisa dipakai sebagai jenis lain
+const queueTokenDomain = "queue:"
+
+func NewQueueTokenSigner(secret string) *QueueTokenSigner {
+	return &QueueTokenSigner{secret: []byte(secret)}
+}
+
+func (s *QueueTokenSigner) Sign(eventID, userID int, entryID int64) (string, error) {
+	claims, err := json.Marshal(QueueTokenClaims{
+		EventID:  eventID,
+		UserID:   userID,
+		EntryID:  entryID,
+		IssuedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(claims)
+	return payload + "." + signPayload(s.secret, queueTokenDomain+payload), nil
+}
+
+func (s *QueueTokenSigner) Parse(token string) (*QueueTokenClaims, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signPayload(s.secret, queueTokenDomain+payload))) {
+		return nil, ErrQueueTokenInvalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrQueueTokenInvalid
+	}
+	var claims QueueTokenClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, ErrQueueTokenInvalid
+	}
+	return &claims, nil
+}
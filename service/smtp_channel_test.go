@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"gatherly-app/config"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer adalah server SMTP minimal di 127.0.0.1 yang mencatat satu sesi
+type fakeSMTPServer struct {
+	listener net.Listener
+	// rejectRcpt membuat RCPT TO dijawab 550
+	rejectRcpt bool
+	// auth diiklankan lewat EHLO dan AUTH PLAIN dijawab 235
+	auth bool
+
+	done     chan struct{}
+	from     string
+	rcpt     []string
+	authLine string
+	data     string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return &fakeSMTPServer{listener: listener, done: make(chan struct{})}
+}
+
+func (s *fakeSMTPServer) config() config.SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return config.SMTPConfig{Host: host, Port: port, From: "Gatherly <noreply@gatherly.test>"}
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake.test ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			if s.auth {
+				tp.PrintfLine("250-fake.test")
+				tp.PrintfLine("250 AUTH PLAIN")
+			} else {
+				tp.PrintfLine("250 fake.test")
+			}
+		case "AUTH":
+			s.authLine = line
+			tp.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = line
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			if s.rejectRcpt {
+				tp.PrintfLine("550 mailbox unavailable")
+				continue
+			}
+			s.rcpt = append(s.rcpt, line)
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			tp.PrintfLine("250 OK queued")
+		case "RSET", "NOOP":
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP session did not finish")
+	}
+}
+
+func TestSMTPChannelSend(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	go server.serve()
+
+	channel := NewSMTPChannel(server.config())
+	err := channel.Send(context.Background(), OutgoingMessage{
+		Address: "Budi <budi@example.com>",
+		Subject: "Tiket Konser Akbar ✓",
+		Body:    "Halo Budi,\nTiket kamu sudah terbit. Sampai jumpa di acara!",
+	})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	server.wait(t)
+
+	if server.from != "MAIL FROM:<noreply@gatherly.test>" {
+		t.Errorf("MAIL FROM = %q", server.from)
+	}
+	if len(server.rcpt) != 1 || server.rcpt[0] != "RCPT TO:<budi@example.com>" {
+		t.Errorf("RCPT TO = %q", server.rcpt)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v\n%s", err, server.data)
+	}
+	if got := message.Header.Get("From"); got != `"Gatherly" <noreply@gatherly.test>` {
+		t.Errorf("From = %q", got)
+	}
+	if got := message.Header.Get("To"); got != `"Budi" <budi@example.com>` {
+		t.Errorf("To = %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != "Tiket Konser Akbar ✓" {
+		t.Errorf("Subject = %q (err %v)", subject, err)
+	}
+	if !strings.HasSuffix(message.Header.Get("Message-ID"), "@gatherly.test>") {
+		t.Errorf("Message-ID = %q", message.Header.Get("Message-ID"))
+	}
+	if got := message.Header.Get("Content-Transfer-Encoding"); got != "quoted-printable" {
+		t.Errorf("Content-Transfer-Encoding = %q", got)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(message.Body))
+	if err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	// DATA selalu diakhiri CRLF sebelum titik penutup
+	got := strings.TrimSuffix(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
+	if got != "Halo Budi,\nTiket kamu sudah terbit. Sampai jumpa di acara!" {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestSMTPChannelSendWithAuth(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.auth = true
+	go server.serve()
+
+	cfg := server.config()
+	cfg.Username = "mailer"
+	cfg.Password = "rahasia"
+	if err := NewSMTPChannel(cfg).Send(context.Background(), OutgoingMessage{Address: "budi@example.com", Subject: "Hi", Body: "Hi"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	server.wait(t)
+
+	want := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00mailer\x00rahasia"))
+	if server.authLine != want {
+		t.Errorf("AUTH = %q, want %q", server.authLine, want)
+	}
+}
+
+func TestSMTPChannelSendRejectedRecipient(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.rejectRcpt = true
+	go server.serve()
+
+	err := NewSMTPChannel(server.config()).Send(context.Background(), OutgoingMessage{Address: "nobody@example.com", Subject: "Hi", Body: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Fatalf("Send error = %v, want 550 rejection", err)
+	}
+	server.wait(t)
+	if server.data != "" {
+		t.Errorf("message data sent despite rejected recipient: %q", server.data)
+	}
+}
+
+func TestSMTPChannelSendInvalidAddress(t *testing.T) {
+	channel := NewSMTPChannel(config.SMTPConfig{Host: "127.0.0.1", Port: "1", From: "noreply@gatherly.test"})
+	if err := channel.Send(context.Background(), OutgoingMessage{Subject: "Hi", Body: "Hi"}); err == nil {
+		t.Error("expected error for empty address")
+	}
+	if err := channel.Send(context.Background(), OutgoingMessage{Address: "not an address", Subject: "Hi", Body: "Hi"}); err == nil {
+		t.Error("expected error for invalid address")
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Header yang dikirim bersama setiap webhook
+const (
+	WebhookSignatureHeader = "X-Gatherly-Signature"
+	WebhookEventHeader     = "X-Gatherly-Event"
+	WebhookDeliveryHeader  = "X-Gatherly-Delivery"
+)
+
+// ErrWebhookAddressNotAllowed dikembalikan untuk URL webhook yang mengarah ke jaringan internal
+var ErrWebhookAddressNotAllowed = errors.New("webhook URL must point to a public address")
+
+// Rentang yang tidak tercakup method net.IP (IsPrivate, IsLoopback, dst.) tapi tetap bukan internet publik
+var blockedWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64 bisa meneruskan ke alamat IPv4 internal
+}
+
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range blockedWebhookPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateWebhookURL memeriksa URL saat endpoint disimpan. Hostname baru bisa dipastikan saat
+// koneksi dibuat, karena DNS bisa berubah; pengecekan utama ada di dialer webhookSender.
+func ValidateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: invalid URL", ErrWebhookAddressNotAllowed)
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".internal") {
+		return ErrWebhookAddressNotAllowed
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddress(addr) {
+		return ErrWebhookAddressNotAllowed
+	}
+	return nil
+}
+
+// publicOnlyControl menolak koneksi setelah DNS di-resolve, sehingga hostname yang
+// mengarah (atau di-rebind) ke alamat internal tetap terblokir.
+func publicOnlyControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil || !publicAddress(addrPort.Addr()) {
+		return ErrWebhookAddressNotAllowed
+	}
+	return nil
+}
+
+// WebhookRequest adalah satu pengiriman webhook yang sudah siap ditandatangani
+type WebhookRequest struct {
+	URL        string
+	Secret     string
+	DeliveryID int
+	EventType  string
+	Body       string
+}
+
+// WebhookResult mencatat hasil satu percobaan; StatusCode 0 berarti request tidak sampai ke server tujuan.
+// Response body tidak disimpan agar webhook tidak bisa dipakai membaca isi layanan lain.
+type WebhookResult struct {
+	StatusCode int
+	Duration   time.Duration
+}
+
+type WebhookSender interface {
+	Send(ctx context.Context, request WebhookRequest) (WebhookResult, error)
+}
+
+type webhookSender struct {
+	client *resty.Client
+}
+
+// NewWebhookSender memasang transport yang hanya bisa terhubung ke alamat publik, tanpa proxy
+// dan tanpa mengikuti redirect.
+func NewWebhookSender(client *resty.Client) WebhookSender {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: publicOnlyControl}
+	client.SetTransport(&http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	})
+	client.SetRedirectPolicy(resty.NoRedirectPolicy())
+	return &webhookSender{client: client}
+}
+
+// SignWebhook menghasilkan header signature "t=<unix>,v1=<hex>" dengan HMAC-SHA256 atas "<unix>.<body>".
+// Penerima menghitung ulang HMAC dengan secret endpoint dan menolak timestamp yang terlalu lama.
+func SignWebhook(secret string, timestamp time.Time, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", timestamp.Unix(), body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp.Unix(), hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Send mengembalikan error untuk kegagalan jaringan maupun status non-2xx
+func (s *webhookSender) Send(ctx context.Context, request WebhookRequest) (WebhookResult, error) {
+	start := time.Now()
+	if err := ValidateWebhookURL(request.URL); err != nil {
+		return WebhookResult{}, err
+	}
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("User-Agent", "Gatherly-Webhooks/1.0").
+		SetHeader(WebhookSignatureHeader, SignWebhook(request.Secret, start, request.Body)).
+		SetHeader(WebhookEventHeader, request.EventType).
+		SetHeader(WebhookDeliveryHeader, fmt.Sprint(request.DeliveryID)).
+		SetBody(request.Body).
+		SetDoNotParseResponse(true).
+		Post(request.URL)
+
+	result := WebhookResult{Duration: time.Since(start)}
+	if err != nil {
+		return result, err
+	}
+	resp.RawBody().Close()
+
+	result.StatusCode = resp.StatusCode()
+	if !resp.IsSuccess() {
+		return result, fmt.Errorf("endpoint responded with status %d", resp.StatusCode())
+	}
+	return result, nil
+}
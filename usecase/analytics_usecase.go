@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"math"
+	"time"
+)
+
+const (
+	analyticsDateLayout = "2006-01-02"
+	// Batas jumlah bucket agar satu permintaan tidak menghasilkan time series raksasa
+	maxAnalyticsBuckets = 400
+	// Batas jumlah event dalam satu perbandingan
+	maxComparedEvents = 20
+)
+
+// Urutan kelompok umur di response, sama dengan CASE di AnalyticsRepository.Demographics
+var ageGroups = []string{"under_18", "18_24", "25_34", "35_44", "45_54", "55_plus", "unknown"}
+
+type AnalyticsUsecase interface {
+	// EventAnalytics mengembalikan funnel, pendapatan, demografi dan time series satu event (organizer atau admin)
+	EventAnalytics(ctx context.Context, eventID, organizerID int, role string, query dto.AnalyticsQuery) (*dto.EventAnalytics, error)
+	// CompareEvents membandingkan beberapa event; tanpa eventIDs dipakai event terbaru milik organizer
+	CompareEvents(ctx context.Context, eventIDs []int, organizerID int, role string, query dto.AnalyticsQuery) (*dto.EventComparison, error)
+}
+
+type analyticsUsecase struct {
+	repo      repositories.AnalyticsRepository
+	eventRepo repositories.EventsRepository
+}
+
+func NewAnalyticsUsecase(repo repositories.AnalyticsRepository, eventRepo repositories.EventsRepository) AnalyticsUsecase {
+	return &analyticsUsecase{repo: repo, eventRepo: eventRepo}
+}
+
+// analyticsPeriod adalah AnalyticsQuery yang sudah divalidasi; to eksklusif (awal hari setelah tanggal To)
+type analyticsPeriod struct {
+	bucket   string
+	location *time.Location
+	from     time.Time
+	to       time.Time
+}
+
+func (uc *analyticsUsecase) EventAnalytics(ctx context.Context, eventID, organizerID int, role string, query dto.AnalyticsQuery) (*dto.EventAnalytics, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, organizerID, role); err != nil {
+		return nil, err
+	}
+
+	period, err := parseAnalyticsPeriod(query, []models.Event{*event})
+	if err != nil {
+		return nil, err
+	}
+
+	eventIDs := []int{eventID}
+	funnels, revenues, series, err := uc.aggregate(ctx, eventIDs, period)
+	if err != nil {
+		return nil, err
+	}
+
+	byTicketType, err := uc.repo.RevenueByTicketType(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate revenue by ticket type: %w", err)
+	}
+	taxSummary, err := uc.repo.TaxSummary(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate tax: %w", err)
+	}
+	demographics, err := uc.repo.Demographics(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate attendee demographics: %w", err)
+	}
+
+	return &dto.EventAnalytics{
+		EventID:             event.ID,
+		EventName:           event.Name,
+		StartDate:           event.StartDate,
+		IsPaid:              event.IsPaid,
+		Currency:            event.Currency,
+		Bucket:              period.bucket,
+		From:                period.from.Format(analyticsDateLayout),
+		To:                  period.to.AddDate(0, 0, -1).Format(analyticsDateLayout),
+		TimeZone:            period.location.String(),
+		Funnel:              funnels[eventID],
+		Revenue:             revenues[eventID],
+		RevenueByTicketType: byTicketType,
+		TaxSummary:          taxSummary,
+		Demographics:        orderAgeGroups(*demographics),
+		Series:              series[eventID],
+	}, nil
+}
+
+func (uc *analyticsUsecase) CompareEvents(ctx context.Context, eventIDs []int, organizerID int, role string, query dto.AnalyticsQuery) (*dto.EventComparison, error) {
+	eventIDs = uniqueInts(eventIDs)
+	if len(eventIDs) > maxComparedEvents {
+		return nil, fmt.Errorf("at most %d events can be compared at once", maxComparedEvents)
+	}
+
+	var events []models.Event
+	var err error
+	if len(eventIDs) == 0 {
+		if role == "admin" {
+			return nil, errors.New("event ids are required")
+		}
+		events, err = uc.repo.OrganizerEvents(ctx, organizerID, maxComparedEvents)
+	} else {
+		events, err = uc.eventRepo.FindEventsByIDs(eventIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(eventIDs) > 0 && len(events) != len(eventIDs) {
+		return nil, errors.New("one or more events not found")
+	}
+	for i := range events {
+		if err := authorizeOrganizer(&events[i], organizerID, role); err != nil {
+			return nil, err
+		}
+	}
+
+	period, err := parseAnalyticsPeriod(query, events)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &dto.EventComparison{
+		Bucket:   period.bucket,
+		From:     period.from.Format(analyticsDateLayout),
+		To:       period.to.AddDate(0, 0, -1).Format(analyticsDateLayout),
+		TimeZone: period.location.String(),
+		Events:   []dto.EventComparisonItem{},
+	}
+	if len(events) == 0 {
+		return comparison, nil
+	}
+
+	ids := make([]int, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	funnels, revenues, series, err := uc.aggregate(ctx, ids, period)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		comparison.Events = append(comparison.Events, dto.EventComparisonItem{
+			EventID:   event.ID,
+			EventName: event.Name,
+			StartDate: event.StartDate,
+			IsPaid:    event.IsPaid,
+			Currency:  event.Currency,
+			Funnel:    funnels[event.ID],
+			Revenue:   revenues[event.ID],
+			Series:    series[event.ID],
+		})
+	}
+	return comparison, nil
+}
+
+// aggregate menjalankan query yang dipakai bersama laporan satu event dan perbandingan
+func (uc *analyticsUsecase) aggregate(ctx context.Context, eventIDs []int, period analyticsPeriod) (map[int]dto.AnalyticsFunnel, map[int]int64, map[int][]dto.AnalyticsBucket, error) {
+	funnelRows, err := uc.repo.Funnel(ctx, eventIDs)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to aggregate registrations: %w", err)
+	}
+	revenueRows, err := uc.repo.Revenue(ctx, eventIDs)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to aggregate revenue: %w", err)
+	}
+	seriesRows, err := uc.repo.Series(ctx, eventIDs, period.bucket, period.location.String(), period.from, period.to)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to aggregate time series: %w", err)
+	}
+
+	funnels := make(map[int]dto.AnalyticsFunnel, len(eventIDs))
+	for _, row := range funnelRows {
+		funnels[row.EventID] = buildFunnel(row)
+	}
+
+	revenues := make(map[int]int64, len(eventIDs))
+	for _, row := range revenueRows {
+		revenues[row.EventID] = row.Revenue
+	}
+
+	series := make(map[int][]dto.AnalyticsBucket, len(eventIDs))
+	positions := make(map[string]int)
+	for i, start := range period.bucketStarts() {
+		positions[start] = i
+	}
+	for _, eventID := range eventIDs {
+		buckets := make([]dto.AnalyticsBucket, len(positions))
+		for start, i := range positions {
+			buckets[i].BucketStart = start
+		}
+		series[eventID] = buckets
+	}
+	for _, row := range seriesRows {
+		i, ok := positions[row.BucketStart]
+		if !ok {
+			continue
+		}
+		bucket := &series[row.EventID][i]
+		switch row.Metric {
+		case "registrations":
+			bucket.Registrations += row.Count
+		case "payments":
+			bucket.Payments += row.Count
+			bucket.Revenue += row.Amount
+		case "cancellations":
+			bucket.Cancellations += row.Count
+		case "check_ins":
+			bucket.CheckIns += row.Count
+		}
+	}
+	return funnels, revenues, series, nil
+}
+
+func buildFunnel(row dto.AnalyticsFunnelRow) dto.AnalyticsFunnel {
+	return dto.AnalyticsFunnel{
+		Registrations:    row.Registrations,
+		Paid:             row.Paid,
+		Cancelled:        row.Cancelled,
+		CheckedIn:        row.CheckedIn,
+		ConversionRate:   ratio(row.Paid, row.Registrations),
+		CancellationRate: ratio(row.Cancelled, row.Registrations),
+		CheckInRate:      ratio(row.CheckedIn, row.Registrations-row.Cancelled),
+	}
+}
+
+// ratio dibulatkan ke 4 desimal; 0 jika penyebut 0
+func ratio(part, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
+
+func orderAgeGroups(demographics dto.AttendeeDemographics) dto.AttendeeDemographics {
+	counts := make(map[string]int64, len(demographics.AgeGroups))
+	for _, group := range demographics.AgeGroups {
+		counts[group.AgeGroup] = group.Count
+	}
+
+	ordered := make([]dto.AgeGroupCount, len(ageGroups))
+	for i, group := range ageGroups {
+		ordered[i] = dto.AgeGroupCount{AgeGroup: group, Count: counts[group]}
+	}
+	demographics.AgeGroups = ordered
+	return demographics
+}
+
+// parseAnalyticsPeriod memvalidasi query. Default: bucket harian, dari event paling awal dibuat sampai hari ini.
+func parseAnalyticsPeriod(query dto.AnalyticsQuery, events []models.Event) (analyticsPeriod, error) {
+	period := analyticsPeriod{bucket: query.Bucket, location: time.UTC}
+	if period.bucket == "" {
+		period.bucket = "day"
+	}
+	if period.bucket != "day" && period.bucket != "week" {
+		return period, errors.New("bucket must be day or week")
+	}
+
+	if query.TZ != "" {
+		location, err := time.LoadLocation(query.TZ)
+		if err != nil {
+			return period, fmt.Errorf("unknown time zone %q", query.TZ)
+		}
+		period.location = location
+	}
+
+	now := time.Now().In(period.location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, period.location)
+	if query.To != "" {
+		parsed, err := time.ParseInLocation(analyticsDateLayout, query.To, period.location)
+		if err != nil {
+			return period, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		to = parsed
+	}
+	period.to = to.AddDate(0, 0, 1)
+
+	if query.From != "" {
+		parsed, err := time.ParseInLocation(analyticsDateLayout, query.From, period.location)
+		if err != nil {
+			return period, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		period.from = parsed
+	} else {
+		period.from = to
+		for _, event := range events {
+			created := event.CreatedAt.In(period.location)
+			day := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, period.location)
+			if day.Before(period.from) {
+				period.from = day
+			}
+		}
+	}
+
+	if !period.from.Before(period.to) {
+		return period, errors.New("from must not be after to")
+	}
+	if len(period.bucketStarts()) > maxAnalyticsBuckets {
+		return period, fmt.Errorf("period too long: at most %d %s buckets", maxAnalyticsBuckets, period.bucket)
+	}
+	return period, nil
+}
+
+// bucketStarts mengembalikan awal setiap bucket dalam format yang sama dengan to_char di repository.
+// Minggu dimulai hari Senin, mengikuti date_trunc('week') Postgres.
+func (p analyticsPeriod) bucketStarts() []string {
+	start := p.from
+	step := 1
+	if p.bucket == "week" {
+		step = 7
+		offset := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -offset)
+	}
+
+	var starts []string
+	for day := start; day.Before(p.to); day = day.AddDate(0, 0, step) {
+		starts = append(starts, day.Format(analyticsDateLayout))
+		if len(starts) > maxAnalyticsBuckets {
+			break
+		}
+	}
+	return starts
+}
@@ -61,6 +61,7 @@ func (uc *authenticationUseCase) Login(ctx *gin.Context, request dto.LoginReques
 			Username: user.Username,
 			Email:    user.Email,
 			Role:     user.Role,
+			Verified: user.Verified,
 		},
 		Latitude:  geo.Latitude,
 		Longitude: geo.Longitude,
@@ -0,0 +1,253 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxDeliveryAttempts = 3
+	deliveryBatchSize   = 100
+	// deliveryStaleAfter: delivery "sending" lebih lama dari ini dianggap gagal di tengah jalan dan diulang
+	deliveryStaleAfter = 10 * time.Minute
+)
+
+type BroadcastUsecase interface {
+	CreateBroadcast(eventID, organizerID int, role string, request dto.CreateBroadcastRequest) (*dto.BroadcastResponse, error)
+	ListBroadcasts(eventID, organizerID int, role string) ([]dto.BroadcastResponse, error)
+	ListDeliveries(eventID, broadcastID, organizerID int, role string) ([]models.BroadcastDelivery, error)
+	DispatchPending() error
+}
+
+type broadcastUsecase struct {
+	repo      repositories.BroadcastRepository
+	eventRepo repositories.EventsRepository
+	channels  map[string]service.MessageChannel
+}
+
+func NewBroadcastUsecase(
+	repo repositories.BroadcastRepository,
+	eventRepo repositories.EventsRepository,
+	channels ...service.MessageChannel,
+) BroadcastUsecase {
+	registered := make(map[string]service.MessageChannel, len(channels))
+	for _, channel := range channels {
+		registered[channel.Name()] = channel
+	}
+	return &broadcastUsecase{
+		repo:      repo,
+		eventRepo: eventRepo,
+		channels:  registered,
+	}
+}
+
+func (uc *broadcastUsecase) findManagedEvent(eventID, organizerID int, role string) (*models.Event, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, organizerID, role); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// CreateBroadcast menyimpan pesan beserta satu delivery per penerima per channel,
+// lalu langsung memicu pengiriman di background.
+func (uc *broadcastUsecase) CreateBroadcast(eventID, organizerID int, role string, request dto.CreateBroadcastRequest) (*dto.BroadcastResponse, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+
+	channels := make([]string, 0, len(request.Channels))
+	seen := make(map[string]bool)
+	for _, name := range request.Channels {
+		if _, ok := uc.channels[name]; !ok {
+			return nil, fmt.Errorf("channel %s is not configured", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			channels = append(channels, name)
+		}
+	}
+
+	recipients, err := uc.repo.FindRecipients(eventID, request.Segment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve recipients: %w", err)
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("no attendees match the selected segment")
+	}
+
+	broadcast := &models.Broadcast{
+		EventID:        eventID,
+		SenderID:       organizerID,
+		Subject:        request.Subject,
+		Body:           request.Body,
+		Channels:       channels,
+		Segment:        request.Segment,
+		Status:         models.BroadcastStatusQueued,
+		RecipientCount: len(recipients),
+	}
+
+	deliveries := make([]models.BroadcastDelivery, 0, len(recipients)*len(channels))
+	for _, recipient := range recipients {
+		for _, channel := range channels {
+			delivery := models.BroadcastDelivery{
+				UserID:  recipient.UserID,
+				Channel: channel,
+				Status:  models.DeliveryStatusPending,
+			}
+			if channel == models.ChannelEmail {
+				delivery.Address = recipient.Email
+			}
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	if err := uc.repo.CreateBroadcast(broadcast, deliveries); err != nil {
+		return nil, fmt.Errorf("failed to save broadcast: %w", err)
+	}
+
+	go func() {
+		if err := uc.DispatchPending(); err != nil {
+			log.Printf("broadcast %d dispatch failed: %v", broadcast.ID, err)
+		}
+	}()
+
+	return &dto.BroadcastResponse{
+		Broadcast:  *broadcast,
+		Deliveries: dto.BroadcastDeliveryStats{Pending: len(deliveries)},
+	}, nil
+}
+
+func (uc *broadcastUsecase) ListBroadcasts(eventID, organizerID int, role string) ([]dto.BroadcastResponse, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+
+	broadcasts, err := uc.repo.ListByEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(broadcasts))
+	for _, broadcast := range broadcasts {
+		ids = append(ids, broadcast.ID)
+	}
+	stats, err := uc.repo.DeliveryStats(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.BroadcastResponse, 0, len(broadcasts))
+	for _, broadcast := range broadcasts {
+		response = append(response, dto.BroadcastResponse{
+			Broadcast:  broadcast,
+			Deliveries: stats[broadcast.ID],
+		})
+	}
+	return response, nil
+}
+
+func (uc *broadcastUsecase) ListDeliveries(eventID, broadcastID, organizerID int, role string) ([]models.BroadcastDelivery, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+	if _, err := uc.repo.FindByID(eventID, broadcastID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("broadcast not found")
+		}
+		return nil, err
+	}
+	return uc.repo.ListDeliveries(broadcastID)
+}
+
+// DispatchPending mengirim delivery yang masih pending secara bertahap. Delivery yang gagal
+// dikembalikan ke pending dan baru dicoba lagi pada putaran job berikutnya, maksimal maxDeliveryAttempts kali.
+func (uc *broadcastUsecase) DispatchPending() error {
+	runStart := time.Now()
+	if err := uc.repo.ReleaseStaleDeliveries(runStart.Add(-deliveryStaleAfter)); err != nil {
+		return err
+	}
+
+	for {
+		deliveries, err := uc.repo.ClaimPendingDeliveries(deliveryBatchSize, runStart)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		broadcastIDs := make([]int, 0)
+		seen := make(map[int]bool)
+		for _, delivery := range deliveries {
+			if !seen[delivery.BroadcastID] {
+				seen[delivery.BroadcastID] = true
+				broadcastIDs = append(broadcastIDs, delivery.BroadcastID)
+			}
+		}
+		broadcasts, err := uc.repo.FindByIDs(broadcastIDs)
+		if err != nil {
+			return err
+		}
+		byID := make(map[int]models.Broadcast, len(broadcasts))
+		for _, broadcast := range broadcasts {
+			byID[broadcast.ID] = broadcast
+		}
+
+		for i := range deliveries {
+			uc.deliver(&deliveries[i], byID[deliveries[i].BroadcastID])
+		}
+
+		if err := uc.repo.CompleteBroadcasts(broadcastIDs, time.Now()); err != nil {
+			return err
+		}
+	}
+}
+
+func (uc *broadcastUsecase) deliver(delivery *models.BroadcastDelivery, broadcast models.Broadcast) {
+	var err error
+	channel, ok := uc.channels[delivery.Channel]
+	if !ok {
+		err = fmt.Errorf("channel %s is not configured", delivery.Channel)
+	} else {
+		err = channel.Send(context.Background(), service.OutgoingMessage{
+			UserID:      delivery.UserID,
+			Address:     delivery.Address,
+			EventID:     broadcast.EventID,
+			BroadcastID: broadcast.ID,
+			Type:        models.InboxTypeBroadcast,
+			Subject:     broadcast.Subject,
+			Body:        broadcast.Body,
+		})
+	}
+
+	now := time.Now()
+	delivery.UpdatedAt = now
+	if err == nil {
+		delivery.Status = models.DeliveryStatusSent
+		delivery.SentAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = err.Error()
+		delivery.Status = models.DeliveryStatusPending
+		if delivery.Attempts >= maxDeliveryAttempts || !ok {
+			delivery.Status = models.DeliveryStatusFailed
+		}
+	}
+
+	if err := uc.repo.UpdateDelivery(delivery); err != nil {
+		log.Printf("failed to update delivery %d: %v", delivery.ID, err)
+	}
+}
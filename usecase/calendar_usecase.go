@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/repositories"
+	"gatherly-app/utils"
+
+	"gorm.io/gorm"
+)
+
+const calendarProductID = "-//Gatherly//Gatherly App//EN"
+
+type CalendarUsecase interface {
+	ExportEvent(eventID, userID int, accessCode string) ([]byte, error)
+	GetFeedToken(userID int) (string, error)
+	RotateFeedToken(userID int) (string, error)
+	GetFeed(ctx context.Context, token string) ([]byte, error)
+}
+
+type calendarUsecase struct {
+	feedRepo     repositories.CalendarFeedRepository
+	eventRepo    repositories.EventsRepository
+	attendeeUC   EventAttendeeUseCase
+	invitationUC InvitationUsecase
+}
+
+func NewCalendarUsecase(
+	feedRepo repositories.CalendarFeedRepository,
+	eventRepo repositories.EventsRepository,
+	attendeeUC EventAttendeeUseCase,
+	invitationUC InvitationUsecase,
+) CalendarUsecase {
+	return &calendarUsecase{
+		feedRepo:     feedRepo,
+		eventRepo:    eventRepo,
+		attendeeUC:   attendeeUC,
+		invitationUC: invitationUC,
+	}
+}
+
+func (uc *calendarUsecase) ExportEvent(eventID, userID int, accessCode string) ([]byte, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := uc.invitationUC.CanView(event, userID, accessCode)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("event tidak ditemukan")
+	}
+
+	calendar := utils.ICalCalendar{
+		ProductID: calendarProductID,
+		Events:    []utils.ICalEvent{toICalEvent(*event)},
+	}
+	return calendar.Render(), nil
+}
+
+// GetFeedToken mengembalikan token feed milik user, membuat token baru jika belum ada.
+func (uc *calendarUsecase) GetFeedToken(userID int) (string, error) {
+	feed, err := uc.feedRepo.FindByUserID(userID)
+	if err != nil {
+		return "", err
+	}
+	if feed != nil {
+		return feed.Token, nil
+	}
+	return uc.RotateFeedToken(userID)
+}
+
+// RotateFeedToken mengganti token feed sehingga URL langganan lama tidak berlaku lagi.
+func (uc *calendarUsecase) RotateFeedToken(userID int) (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate feed token: %w", err)
+	}
+
+	feed, err := uc.feedRepo.FindByUserID(userID)
+	if err != nil {
+		return "", err
+	}
+	if feed == nil {
+		feed = &models.CalendarFeed{UserID: userID}
+	}
+	feed.Token = token
+
+	if err := uc.feedRepo.Save(feed); err != nil {
+		return "", fmt.Errorf("failed to save feed token: %w", err)
+	}
+	return token, nil
+}
+
+func (uc *calendarUsecase) GetFeed(ctx context.Context, token string) ([]byte, error) {
+	feed, err := uc.feedRepo.FindByToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calendar feed not found")
+		}
+		return nil, err
+	}
+
+	registrations, err := uc.attendeeUC.ListUserRegistrations(ctx, feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var eventIDs []int
+	for _, registration := range registrations {
+		// Pendaftaran yang menyatakan tidak hadir tidak dimasukkan ke kalender
+		if registration.RSVPStatus == "not_attending" || registration.RSVPStatus == "not_going" {
+			continue
+		}
+		eventIDs = append(eventIDs, registration.EventID)
+	}
+
+	events, err := uc.eventRepo.FindEventsByIDs(eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := utils.ICalCalendar{
+		ProductID: calendarProductID,
+		Name:      "Gatherly - My Events",
+	}
+	for _, event := range events {
+		calendar.Events = append(calendar.Events, toICalEvent(event))
+	}
+	return calendar.Render(), nil
+}
+
+func toICalEvent(event models.Event) utils.ICalEvent {
+	return utils.ICalEvent{
+		UID:          fmt.Sprintf("event-%d@gatherly-app", event.ID),
+		Summary:      event.Name,
+		Description:  event.Description,
+		Categories:   event.Category,
+		Start:        event.StartDate,
+		End:          event.EndDate,
+		Latitude:     event.Latitude,
+		Longitude:    event.Longitude,
+		Sequence:     event.Sequence,
+		LastModified: event.UpdatedAt,
+		Cancelled:    event.Status == models.EventStatusCancelled,
+	}
+}
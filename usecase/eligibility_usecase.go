@@ -0,0 +1,455 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotEligible dikembalikan saat user tidak memenuhi syarat event atau ticket type; pesan berisi alasannya
+	ErrNotEligible = errors.New("you are not eligible for this ticket")
+	// ErrUserGroupNotFound dikembalikan saat grup tidak ada atau bukan milik user
+	ErrUserGroupNotFound = errors.New("user group not found")
+	// ErrUserGroupInUse dikembalikan saat grup yang masih dipakai rule eligibility akan dihapus
+	ErrUserGroupInUse = errors.New("user group is still used by an eligibility rule")
+)
+
+type EligibilityUsecase interface {
+	ListRules(eventID, organizerID int, role string) ([]models.EligibilityRule, error)
+	SaveRule(eventID, organizerID int, role string, input dto.SaveEligibilityRuleRequest) (*models.EligibilityRule, error)
+	DeleteRule(eventID, organizerID int, role string, ticketTypeID *int) error
+
+	// CheckEligibility dipanggil sebelum registrasi; error membungkus ErrNotEligible dengan semua alasan penolakan
+	CheckEligibility(eventID, ticketTypeID, userID int) error
+	// EligibleTickets menampilkan setiap ticket type event beserta kelayakan user
+	EligibleTickets(eventID, userID int) ([]dto.EligibleTicket, error)
+	// HasRules true jika pendaftaran ticket type dibatasi rule event atau rule ticket type
+	HasRules(eventID, ticketTypeID int) (bool, error)
+
+	CreateGroup(ownerID int, input dto.SaveUserGroupRequest) (*models.UserGroup, error)
+	RenameGroup(groupID, ownerID int, role string, input dto.SaveUserGroupRequest) (*models.UserGroup, error)
+	ListGroups(ownerID int) ([]models.UserGroup, error)
+	DeleteGroup(groupID, ownerID int, role string) error
+	ListMembers(groupID, ownerID int, role string) ([]dto.UserResponse, error)
+	AddMembers(groupID, ownerID int, role string, input dto.UserGroupMembersRequest) ([]dto.UserResponse, error)
+	RemoveMember(groupID, ownerID int, role string, userID int) error
+
+	// SetVerified menandai akun yang identitasnya sudah diperiksa (admin saja)
+	SetVerified(userID int, role string, input dto.SetUserVerifiedRequest) (*dto.UserResponse, error)
+}
+
+type eligibilityUsecase struct {
+	repo       repositories.EligibilityRepository
+	eventRepo  repositories.EventsRepository
+	ticketRepo repositories.TicketRepository
+	userRepo   repositories.UserRepository
+}
+
+func NewEligibilityUsecase(
+	repo repositories.EligibilityRepository,
+	eventRepo repositories.EventsRepository,
+	ticketRepo repositories.TicketRepository,
+	userRepo repositories.UserRepository,
+) EligibilityUsecase {
+	return &eligibilityUsecase{
+		repo:       repo,
+		eventRepo:  eventRepo,
+		ticketRepo: ticketRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (uc *eligibilityUsecase) findManagedEvent(eventID, organizerID int, role string) (*models.Event, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, organizerID, role); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (uc *eligibilityUsecase) ListRules(eventID, organizerID int, role string) ([]models.EligibilityRule, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+	return uc.repo.FindRules(eventID)
+}
+
+func (uc *eligibilityUsecase) SaveRule(eventID, organizerID int, role string, input dto.SaveEligibilityRuleRequest) (*models.EligibilityRule, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+
+	if input.TicketTypeID != nil {
+		ticket, err := uc.ticketRepo.FindTicketByID(*input.TicketTypeID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("ticket type with ID %d not found", *input.TicketTypeID)
+			}
+			return nil, err
+		}
+		if ticket.EventID != eventID {
+			return nil, fmt.Errorf("ticket type ID %d does not belong to event ID %d", ticket.Id, eventID)
+		}
+	}
+	if input.MaxAge > 0 && input.MaxAge < input.MinAge {
+		return nil, errors.New("max_age must not be lower than min_age")
+	}
+	domains, err := normalizeEmailDomains(input.EmailDomains)
+	if err != nil {
+		return nil, err
+	}
+	if input.UserGroupID != nil {
+		// Organizer hanya boleh memakai grup miliknya sendiri
+		if _, err := uc.findGroup(*input.UserGroupID, organizerID, role); err != nil {
+			return nil, err
+		}
+	}
+
+	rule, err := uc.repo.FindRule(eventID, input.TicketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		rule = &models.EligibilityRule{EventID: eventID, TicketTypeID: input.TicketTypeID}
+	}
+	rule.MinAge = input.MinAge
+	rule.MaxAge = input.MaxAge
+	rule.EmailDomains = domains
+	rule.UserGroupID = input.UserGroupID
+	// Alamat email akun tidak diverifikasi saat signup, jadi syarat domain baru berarti jika
+	// akunnya sudah diverifikasi admin
+	rule.RequireVerified = input.RequireVerified || len(domains) > 0
+
+	if err := uc.repo.SaveRule(rule); err != nil {
+		return nil, fmt.Errorf("failed to save eligibility rule: %w", err)
+	}
+	return rule, nil
+}
+
+// normalizeEmailDomains menerima "ui.ac.id" maupun "@ui.ac.id" dan membuang duplikat
+func normalizeEmailDomains(input []string) ([]string, error) {
+	domains := make([]string, 0, len(input))
+	seen := make(map[string]bool, len(input))
+	for _, raw := range input {
+		domain := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(raw), "@"))
+		if domain == "" {
+			continue
+		}
+		if !strings.Contains(domain, ".") || strings.ContainsAny(domain, "@ /") {
+			return nil, fmt.Errorf("invalid email domain %q", raw)
+		}
+		if !seen[domain] {
+			seen[domain] = true
+			domains = append(domains, domain)
+		}
+	}
+	return domains, nil
+}
+
+func (uc *eligibilityUsecase) DeleteRule(eventID, organizerID int, role string, ticketTypeID *int) error {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return err
+	}
+	if err := uc.repo.DeleteRule(eventID, ticketTypeID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("eligibility rule not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// applicableRules memilih rule event dan rule ticket type yang berlaku untuk satu ticket type
+func applicableRules(rules []models.EligibilityRule, ticketTypeID int) []models.EligibilityRule {
+	var applicable []models.EligibilityRule
+	for _, rule := range rules {
+		if rule.TicketTypeID == nil || *rule.TicketTypeID == ticketTypeID {
+			applicable = append(applicable, rule)
+		}
+	}
+	return applicable
+}
+
+func (uc *eligibilityUsecase) CheckEligibility(eventID, ticketTypeID, userID int) error {
+	rules, err := uc.repo.FindRules(eventID)
+	if err != nil {
+		return err
+	}
+	rules = applicableRules(rules, ticketTypeID)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	reasons, err := uc.evaluate(rules, user)
+	if err != nil {
+		return err
+	}
+	if len(reasons) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotEligible, strings.Join(reasons, "; "))
+	}
+	return nil
+}
+
+func (uc *eligibilityUsecase) EligibleTickets(eventID, userID int) ([]dto.EligibleTicket, error) {
+	if _, err := uc.eventRepo.FindEventByID(eventID); err != nil {
+		return nil, err
+	}
+	tickets, err := uc.ticketRepo.FindById(eventID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := uc.repo.FindRules(eventID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.EligibleTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		reasons, err := uc.evaluate(applicableRules(rules, ticket.Id), user)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dto.EligibleTicket{Ticket: ticket, Eligible: len(reasons) == 0, Reasons: reasons})
+	}
+	return result, nil
+}
+
+func (uc *eligibilityUsecase) HasRules(eventID, ticketTypeID int) (bool, error) {
+	rules, err := uc.repo.FindRules(eventID)
+	if err != nil {
+		return false, err
+	}
+	return len(applicableRules(rules, ticketTypeID)) > 0, nil
+}
+
+// evaluate mengumpulkan semua alasan penolakan agar user tahu syarat mana saja yang belum terpenuhi
+func (uc *eligibilityUsecase) evaluate(rules []models.EligibilityRule, user *models.User) ([]string, error) {
+	var reasons []string
+	add := func(reason string) {
+		for _, existing := range reasons {
+			if existing == reason {
+				return
+			}
+		}
+		reasons = append(reasons, reason)
+	}
+
+	for _, rule := range rules {
+		if rule.MinAge > 0 && user.Age < rule.MinAge {
+			add(fmt.Sprintf("you must be at least %d years old", rule.MinAge))
+		}
+		if rule.MaxAge > 0 && user.Age > rule.MaxAge {
+			add(fmt.Sprintf("you must be %d years old or younger", rule.MaxAge))
+		}
+		if len(rule.EmailDomains) > 0 && !emailDomainAllowed(user.Email, rule.EmailDomains) {
+			add("registration is limited to email addresses at " + strings.Join(rule.EmailDomains, ", "))
+		}
+		if (rule.RequireVerified || len(rule.EmailDomains) > 0) && !user.Verified {
+			add("a verified account is required")
+		}
+		if rule.UserGroupID != nil {
+			member, err := uc.repo.IsMember(*rule.UserGroupID, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if !member {
+				name := "the required group"
+				if group, err := uc.repo.FindGroup(*rule.UserGroupID); err == nil {
+					name = group.Name
+				}
+				add("registration is limited to members of " + name)
+			}
+		}
+	}
+	return reasons, nil
+}
+
+// emailDomainAllowed juga menerima subdomain, mis. mhs.ui.ac.id untuk domain ui.ac.id
+func emailDomainAllowed(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range domains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// findGroup mengembalikan grup milik ownerID; admin boleh mengakses grup siapa pun
+func (uc *eligibilityUsecase) findGroup(groupID, ownerID int, role string) (*models.UserGroup, error) {
+	group, err := uc.repo.FindGroup(groupID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserGroupNotFound
+		}
+		return nil, err
+	}
+	if role != "admin" && group.OwnerID != ownerID {
+		return nil, ErrUserGroupNotFound
+	}
+	return group, nil
+}
+
+func (uc *eligibilityUsecase) CreateGroup(ownerID int, input dto.SaveUserGroupRequest) (*models.UserGroup, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("group name is required")
+	}
+	group := &models.UserGroup{OwnerID: ownerID, Name: name}
+	if err := uc.repo.CreateGroup(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (uc *eligibilityUsecase) RenameGroup(groupID, ownerID int, role string, input dto.SaveUserGroupRequest) (*models.UserGroup, error) {
+	group, err := uc.findGroup(groupID, ownerID, role)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("group name is required")
+	}
+	group.Name = name
+	if err := uc.repo.SaveGroup(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (uc *eligibilityUsecase) ListGroups(ownerID int) ([]models.UserGroup, error) {
+	return uc.repo.ListGroups(ownerID)
+}
+
+func (uc *eligibilityUsecase) DeleteGroup(groupID, ownerID int, role string) error {
+	if _, err := uc.findGroup(groupID, ownerID, role); err != nil {
+		return err
+	}
+	inUse, err := uc.repo.GroupInUse(groupID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrUserGroupInUse
+	}
+	return uc.repo.DeleteGroup(groupID)
+}
+
+func (uc *eligibilityUsecase) ListMembers(groupID, ownerID int, role string) ([]dto.UserResponse, error) {
+	if _, err := uc.findGroup(groupID, ownerID, role); err != nil {
+		return nil, err
+	}
+	users, err := uc.repo.ListMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]dto.UserResponse, 0, len(users))
+	for _, user := range users {
+		members = append(members, memberResponse(&user))
+	}
+	return members, nil
+}
+
+func memberResponse(user *models.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:       user.ID,
+		Name:     user.Name,
+		Username: user.Username,
+		Email:    user.Email,
+	}
+}
+
+// AddMembers menolak seluruh permintaan jika ada ID atau email yang tidak terdaftar,
+// supaya organizer tahu akun mana yang perlu dicek
+func (uc *eligibilityUsecase) AddMembers(groupID, ownerID int, role string, input dto.UserGroupMembersRequest) ([]dto.UserResponse, error) {
+	if _, err := uc.findGroup(groupID, ownerID, role); err != nil {
+		return nil, err
+	}
+	if len(input.UserIDs) == 0 && len(input.Emails) == 0 {
+		return nil, errors.New("user_ids or emails is required")
+	}
+
+	userIDs := make([]int, 0, len(input.UserIDs)+len(input.Emails))
+	for _, id := range input.UserIDs {
+		if _, err := uc.userRepo.FindByID(id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("user %d not found", id)
+			}
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	for _, email := range input.Emails {
+		email = strings.TrimSpace(email)
+		user, err := uc.userRepo.FindByEmail(email)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("no account registered with %s", email)
+			}
+			return nil, err
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+
+	if err := uc.repo.AddMembers(groupID, userIDs); err != nil {
+		return nil, fmt.Errorf("failed to add group members: %w", err)
+	}
+	return uc.ListMembers(groupID, ownerID, role)
+}
+
+func (uc *eligibilityUsecase) RemoveMember(groupID, ownerID int, role string, userID int) error {
+	if _, err := uc.findGroup(groupID, ownerID, role); err != nil {
+		return err
+	}
+	if err := uc.repo.RemoveMember(groupID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("user %d is not a member of this group", userID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (uc *eligibilityUsecase) SetVerified(userID int, role string, input dto.SetUserVerifiedRequest) (*dto.UserResponse, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user %d not found", userID)
+		}
+		return nil, err
+	}
+	user.Verified = input.Verified
+	if err := uc.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	response := memberResponse(user)
+	response.Age = user.Age
+	response.Role = user.Role
+	response.Verified = user.Verified
+	return &response, nil
+}
@@ -7,6 +7,10 @@ import (
 	"gatherly-app/models"
 	"gatherly-app/models/dto"
 	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"gatherly-app/utils"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,13 +20,15 @@ import (
 // --- Interface Definition ---
 // Added ticketTypeID to Register signature
 type EventAttendeeUseCase interface {
-	Register(ctx context.Context, userID, eventID, ticketTypeID int, rsvpStatus string) (*models.EventAttendee, error)
+	Register(ctx context.Context, userID, eventID, ticketTypeID int, seatID *int, rsvpStatus, accessCode string, answers map[string]any, client dto.ClientInfo) (*models.EventAttendee, error)
 	CancelRegistration(ctx context.Context, userID, eventID int) error
 	GetRegistrationDetails(ctx context.Context, userID, eventID int) (*models.EventAttendee, error)
 	ListAttendeesForEvent(ctx context.Context, eventID int) ([]*models.EventAttendee, error)
 	ListUserRegistrations(ctx context.Context, userID int) ([]*models.EventAttendee, error)
-	ConfirmPayment(ctx context.Context, userID, eventID int) (*models.EventAttendee, error)
 	UpdateRSVPStatus(ctx context.Context, userID, eventID int, newStatus string) (*models.EventAttendee, error)
+	CheckIn(ctx context.Context, organizerID int, role string, request dto.AttendeeCheckInRequest) (*models.EventAttendee, error)
+	PrepareAttendeeExport(ctx context.Context, eventID, organizerID int, role string, columns []string) (*dto.AttendeeExportPlan, error)
+	WriteAttendeeExport(ctx context.Context, plan *dto.AttendeeExportPlan, w utils.TableWriter) error
 }
 
 // --- Struct Definition ---
@@ -32,6 +38,16 @@ type eventAttendeeUseCaseImpl struct {
 	eventRepo     repositories.EventsRepository // Added
 	ticketRepo    repositories.TicketRepository // Added
 	transactionUC TransactionUsecase          // Added
+	invitationUC  InvitationUsecase
+	formUC        RegistrationFormUsecase
+	notifyUC      NotificationUsecase
+	pubsub        service.PubSub
+	statsUC       EventStatsUsecase
+	webhookUC     WebhookUsecase
+	seatMapUC     SeatMapUsecase
+	guardUC       PurchaseGuardUsecase
+	queueUC       VirtualQueueUsecase
+	eligibilityUC EligibilityUsecase
 }
 
 // --- Constructor ---
@@ -41,12 +57,32 @@ func NewEventAttendeeUseCase(
 	eventRepo repositories.EventsRepository, // Added
 	ticketRepo repositories.TicketRepository, // Added
 	transactionUC TransactionUsecase, // Added
+	invitationUC InvitationUsecase,
+	formUC RegistrationFormUsecase,
+	notifyUC NotificationUsecase,
+	pubsub service.PubSub,
+	statsUC EventStatsUsecase,
+	webhookUC WebhookUsecase,
+	seatMapUC SeatMapUsecase,
+	guardUC PurchaseGuardUsecase,
+	queueUC VirtualQueueUsecase,
+	eligibilityUC EligibilityUsecase,
 ) EventAttendeeUseCase {
 	return &eventAttendeeUseCaseImpl{
 		attendeeRepo:  attendeeRepo,
 		eventRepo:     eventRepo,     // Initialized
 		ticketRepo:    ticketRepo,    // Initialized
 		transactionUC: transactionUC,
+		invitationUC:  invitationUC,
+		formUC:        formUC,
+		notifyUC:      notifyUC,
+		pubsub:        pubsub,
+		statsUC:       statsUC,
+		webhookUC:     webhookUC,
+		seatMapUC:     seatMapUC,
+		guardUC:       guardUC,
+		queueUC:       queueUC,
+		eligibilityUC: eligibilityUC,
 	}
 }
 
@@ -57,7 +93,13 @@ func generateTicketCode(eventID, userID int) string {
 
 // --- Register Method (Modified) ---
 // Updated Register method signature and logic
-func (uc *eventAttendeeUseCaseImpl) Register(ctx context.Context, userID, eventID, ticketTypeID int, rsvpStatus string) (*models.EventAttendee, error) {
+func (uc *eventAttendeeUseCaseImpl) Register(ctx context.Context, userID, eventID, ticketTypeID int, seatID *int, rsvpStatus, accessCode string, answers map[string]any, client dto.ClientInfo) (*models.EventAttendee, error) {
+
+	// --- Rate Limit Attempts ---
+	// Dihitung sebelum validasi lain agar percobaan yang gagal juga ikut terbatasi
+	if err := uc.guardUC.AllowAttempt(userID, client.IP); err != nil {
+		return nil, err
+	}
 
 	// --- Basic Input Validation ---
 	allowedRSVP := map[string]bool{"pending": true, "attending": true, "not_attending": true, "maybe": true}
@@ -112,6 +154,12 @@ func (uc *eventAttendeeUseCaseImpl) Register(ctx context.Context, userID, eventI
 		return nil, fmt.Errorf("ticket type '%s' is not currently available for purchase", ticketType.TicketType)
 	}
 
+	// --- Step 1b: Enforce Eligibility Rules ---
+	// Umur, domain email, grup dan verifikasi akun dicek untuk rule event dan rule ticket type
+	if err := uc.eligibilityUC.CheckEligibility(eventID, ticketTypeID, userID); err != nil {
+		return nil, fmt.Errorf("registration not allowed: %w", err)
+	}
+
 	// --- Step 2: Fetch Event Details ---
 	event, err := uc.eventRepo.FindEventByID(eventID)
 	if err != nil {
@@ -121,10 +169,49 @@ func (uc *eventAttendeeUseCaseImpl) Register(ctx context.Context, userID, eventI
 		return nil, fmt.Errorf("error fetching event details: %w", err)
 	}
 
+	// --- Step 2b: Enforce Visibility (unlisted / invite-only) ---
+	if err := uc.invitationUC.AuthorizeRegistration(event, userID, accessCode); err != nil {
+		return nil, fmt.Errorf("registration not allowed: %w", err)
+	}
+
+	// --- Step 2c: Validate Custom Form Answers ---
+	formAnswer, err := uc.formUC.ValidateAnswers(eventID, ticketTypeID, userID, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	// --- Step 2d: Claim Reserved Seat ---
+	// Event dengan seat map mewajibkan kursi yang sedang ditahan user, sesuai zona ticket type
+	seat, err := uc.seatMapUC.ClaimSeat(eventID, ticketTypeID, userID, seatID)
+	if err != nil {
+		return nil, fmt.Errorf("registration not allowed: %w", err)
+	}
+
+	// --- Step 2e: Waiting Queue, Bot Protection & Purchase Limits ---
+	if err := uc.queueUC.RequireAdmission(eventID, userID); err != nil {
+		return nil, fmt.Errorf("registration not allowed: %w", err)
+	}
+	if err := uc.guardUC.CheckPurchase(event, userID, 1, client); err != nil {
+		return nil, fmt.Errorf("registration not allowed: %w", err)
+	}
+
 	// --- Step 3: Determine Initial Payment Status ---
 	paymentStatus := "unpaid" // Default for free events
+	itemName := fmt.Sprintf("Ticket: %s (%s)", event.Name, ticketType.TicketType)
+	var quote *dto.OrderQuote
 	if event.IsPaid {
 		paymentStatus = "pending" // Requires payment
+		// Pajak dihitung dan dicek ke payment provider sebelum pendaftaran dibuat
+		quote, err = uc.transactionUC.QuoteOrder(event, []dto.OrderItem{{Description: itemName, Quantity: 1, UnitPrice: ticketType.Price}})
+		if err != nil {
+			return nil, fmt.Errorf("registration not allowed: %w", err)
+		}
+	}
+
+	// --- Step 3b: Reserve Per-IP Ticket Limit ---
+	// Dipesan sebelum pendaftaran dibuat dan dilepas lagi jika pendaftaran gagal atau dibatalkan
+	if err := uc.guardUC.ReservePurchase(event, 1, client.IP); err != nil {
+		return nil, fmt.Errorf("registration not allowed: %w", err)
 	}
 
 	// --- Step 4: Create EventAttendee Record ---
@@ -139,12 +226,43 @@ func (uc *eventAttendeeUseCaseImpl) Register(ctx context.Context, userID, eventI
 		PaymentStatus: paymentStatus,
 		TicketCode:    nil, // Ticket code generated upon successful payment confirmation
 	}
+	if seat != nil {
+		label := seat.Label()
+		newAttendee.SeatID = &seat.ID
+		newAttendee.SeatLabel = &label
+	}
+	if client.IP != "" {
+		newAttendee.PurchaseIP = &client.IP
+	}
 
 	err = uc.attendeeRepo.Create(ctx, newAttendee)
 	if err != nil {
 		// If using DB transaction, rollback here
+		uc.guardUC.ReleasePurchase(eventID, 1, client.IP)
+		if strings.Contains(err.Error(), "idx_event_attendees_seat") {
+			return nil, ErrSeatUnavailable
+		}
 		return nil, fmt.Errorf("failed to create registration record: %w", err)
 	}
+	if seat != nil {
+		// Kursi kini terikat ke pendaftaran; hold tidak diperlukan lagi
+		if err := uc.seatMapUC.ReleaseHold(eventID, userID); err != nil {
+			fmt.Printf("ERROR: failed to release seat hold for UserID %d, EventID %d: %v\n", userID, eventID, err)
+		}
+	}
+
+	// --- Step 4b: Store Form Answers ---
+	if formAnswer != nil {
+		if err := uc.formUC.SaveAnswer(formAnswer); err != nil {
+			// Batalkan pendaftaran agar user bisa mencoba lagi tanpa tercatat "already registered"
+			if delErr := uc.attendeeRepo.Delete(ctx, userID, eventID); delErr != nil {
+				fmt.Printf("ERROR: failed to roll back registration for UserID %d, EventID %d: %v\n", userID, eventID, delErr)
+			} else {
+				uc.guardUC.ReleasePurchase(eventID, 1, client.IP)
+			}
+			return nil, fmt.Errorf("failed to save form answers: %w", err)
+		}
+	}
 
 	// --- Step 5: Create Transaction if Event is Paid ---
 	if event.IsPaid {
@@ -152,20 +270,20 @@ func (uc *eventAttendeeUseCaseImpl) Register(ctx context.Context, userID, eventI
 			UserId:          userID,
 			EventId:         eventID,
 			TransactionDate: &now,
-			Amount:          float64(ticketType.Price),                                         // Use price from the specific ticket type
-			Items:           fmt.Sprintf("Ticket: %s (%s)", event.Name, ticketType.TicketType), // Descriptive item name
+			Quote:           quote, // Amount & Currency diisi dari quote (harga ticket type + pajak)
+			Items:           itemName,
 			Notes:           fmt.Sprintf("Auto-created for registration EventID: %d, TicketTypeID: %d", eventID, ticketTypeID),
 		}
 
 		// Prepare Midtrans request details (OrderID needs to be unique)
+		// GrossAmount diisi transactionUC dari Amount agar selalu sama dengan nominal transaksi
 		midtransOrderID := uuid.NewString() // Generate a unique ID for Midtrans
 		midtransInput := dto.MidtransSnapReq{
 			TransactionDetails: struct {
 				OrderID     string `json:"order_id"`
 				GrossAmount int    `json:"gross_amount"`
 			}{
-				OrderID:     midtransOrderID,
-				GrossAmount: ticketType.Price, // Use the integer price for Midtrans GrossAmount
+				OrderID: midtransOrderID,
 			},
 			// Add Customer and Item details as required by your MidtransService and DTO
 			Customer: fmt.Sprintf("User ID: %d", userID), // Example customer detail
@@ -175,42 +293,78 @@ func (uc *eventAttendeeUseCaseImpl) Register(ctx context.Context, userID, eventI
 		// Call the injected Transaction Use Case
 		_, txErr := uc.transactionUC.CreateTransaction(transactionInput, midtransInput) // Pass both DTOs
 		if txErr != nil {
-			// CRITICAL: Registration saved, payment failed. Requires robust handling.
-			// If using DB transaction, rollback attendeeRepo.Create before returning error.
-			// Log the detailed error for debugging.
-			fmt.Printf("ERROR: Registration for UserID %d, EventID %d saved, but failed to initiate transaction: %v\n", userID, eventID, txErr)
-			// Return the attendee record but indicate the payment initiation failure clearly.
-			// The user might need to retry payment later or contact support.
-			// Returning a specific error might be better for the controller to handle.
-			return newAttendee, fmt.Errorf("registration successful, but failed to start payment process: %w. Please try paying later or contact support", txErr)
+			// Pendaftaran pending tanpa transaksi tidak pernah kedaluwarsa dan akan menahan kursi serta
+			// batas tiket selamanya, jadi dihapus agar user bisa langsung mendaftar ulang
+			fmt.Printf("ERROR: failed to initiate transaction for UserID %d, EventID %d: %v\n", userID, eventID, txErr)
+			if delErr := uc.attendeeRepo.Delete(ctx, userID, eventID); delErr != nil {
+				fmt.Printf("ERROR: failed to roll back registration for UserID %d, EventID %d: %v\n", userID, eventID, delErr)
+			} else {
+				uc.guardUC.ReleasePurchase(eventID, 1, client.IP)
+			}
+			return nil, fmt.Errorf("failed to start payment process: %w", txErr)
 		}
 		// Transaction initiation successful (Midtrans link/token generated by transactionUC)
 		// The transaction record itself is created within transactionUC.CreateTransaction
 	}
 
+	// --- Step 6: Queue Registration Confirmation ---
+	// Kegagalan antre notifikasi tidak membatalkan pendaftaran
+	confirmation := map[string]any{
+		"event_id":         eventID,
+		"event_name":       event.Name,
+		"event_start":      event.StartDate,
+		"ticket_type":      ticketType.TicketType,
+		"payment_required": event.IsPaid,
+	}
+	// Pendaftaran tidak punya ID sendiri: satu pendaftaran dikenali dari event, user dan berapa kali
+	// user pernah membatalkan, sehingga daftar ulang setelah batal tetap mendapat konfirmasi baru.
+	// Notify menambahkan jenis notifikasi ke key.
+	cancellations, err := uc.attendeeRepo.CountCancellations(ctx, userID, eventID)
+	if err != nil {
+		fmt.Printf("ERROR: failed to count cancellations for UserID %d, EventID %d: %v\n", userID, eventID, err)
+	}
+	dedupKey := fmt.Sprintf("%d:%d:%d", eventID, userID, cancellations)
+	if err := uc.notifyUC.Notify([]int{userID}, models.NotificationRegistrationConfirmed, confirmation, dedupKey); err != nil {
+		fmt.Printf("ERROR: failed to queue registration confirmation for UserID %d, EventID %d: %v\n", userID, eventID, err)
+	}
+	uc.statsUC.Publish(ctx, eventID, service.StreamAttendeeRegistered, userID, &ticketTypeID)
+	uc.webhookUC.Emit(ctx, models.WebhookAttendeeRegistered, eventID, userID)
+
 	// If using DB transaction, commit here
 
+	// Slot antrean baru dilepas setelah pendaftaran (dan transaksinya) berhasil, sehingga user yang
+	// gagal di langkah mana pun masih bisa mencoba lagi dalam checkout window-nya
+	uc.queueUC.Complete(eventID, userID)
+
 	return newAttendee, nil // Registration successful (payment initiated if applicable)
 }
 
 // --- CancelRegistration Method (Unchanged) ---
 func (uc *eventAttendeeUseCaseImpl) CancelRegistration(ctx context.Context, userID, eventID int) error {
-	_, err := uc.attendeeRepo.FindByUserAndEvent(ctx, userID, eventID)
+	attendee, err := uc.attendeeRepo.FindByUserAndEvent(ctx, userID, eventID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return fmt.Errorf("registration not found for user %d, event %d", userID, eventID)
 		}
 		return fmt.Errorf("error checking registration before delete: %w", err)
 	}
+	if attendee == nil {
+		return fmt.Errorf("registration not found for user %d, event %d", userID, eventID)
+	}
 
 	// TODO: Add logic here to check if a transaction exists for this registration
 	// and potentially cancel it via transactionUC if it's still pending.
 
-	err = uc.attendeeRepo.Delete(ctx, userID, eventID)
+	err = uc.attendeeRepo.Cancel(ctx, attendee, time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to delete registration: %w", err)
 	}
+	if attendee.PurchaseIP != nil {
+		uc.guardUC.ReleasePurchase(eventID, 1, *attendee.PurchaseIP)
+	}
 
+	uc.statsUC.Publish(ctx, eventID, service.StreamAttendeeCancelled, userID, attendee.TicketTypeID)
+	uc.webhookUC.Emit(ctx, models.WebhookAttendeeCancelled, eventID, userID)
 	return nil
 }
 
@@ -247,107 +401,238 @@ func (uc *eventAttendeeUseCaseImpl) ListUserRegistrations(ctx context.Context, u
 	return attendees, nil
 }
 
-// --- ConfirmPayment Method ---
-// Needs modification to handle quota decrement
-func (uc *eventAttendeeUseCaseImpl) ConfirmPayment(ctx context.Context, userID, eventID int) (*models.EventAttendee, error) {
-	// --- Fetch Attendee Record ---
+// --- UpdateRSVPStatus Method (Unchanged for now) ---
+func (uc *eventAttendeeUseCaseImpl) UpdateRSVPStatus(ctx context.Context, userID, eventID int, newStatus string) (*models.EventAttendee, error) {
+	allowedRSVP := map[string]bool{"pending": true, "attending": true, "not_attending": true, "maybe": true, "going": true, "interested": true, "not_going": true} // Expanded allowed statuses
+	if !allowedRSVP[newStatus] {
+		return nil, fmt.Errorf("invalid RSVP status: %s", newStatus)
+	}
+
 	attendee, err := uc.attendeeRepo.FindByUserAndEvent(ctx, userID, eventID)
 	if err != nil {
-		// Handle not found and other errors...
-		return nil, fmt.Errorf("failed to find registration for payment confirmation: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cannot update status for non-existent registration")
+		}
+		return nil, fmt.Errorf("failed to find registration for status update: %w", err)
 	}
 	if attendee == nil {
-		return nil, errors.New("cannot confirm payment for non-existent registration")
+		return nil, errors.New("cannot update status for non-existent registration")
 	}
 
-	// --- Check if already paid ---
-	if attendee.PaymentStatus == "paid" && attendee.TicketCode != nil {
-		return attendee, nil // Already confirmed
-	}
+	attendee.RSVPStatus = newStatus
+	now := time.Now()
+	attendee.RSVPDate = &now
 
-	// --- Check if TicketTypeID is linked ---
-	if attendee.TicketTypeID == nil {
-		return nil, errors.New("cannot confirm payment: registration is not linked to a specific ticket type")
+	err = uc.attendeeRepo.Update(ctx, attendee)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update RSVP status: %w", err)
 	}
 
-	// --- Decrement Quota (CRITICAL: Use DB Transaction) ---
-	// It's highly recommended to wrap this section in a database transaction
-	// Start transaction here...
+	return attendee, nil
+}
 
-	// Fetch the specific ticket type with locking to prevent race conditions
-	ticketType, err := uc.ticketRepo.FindTicketByIDForUpdate(*attendee.TicketTypeID) // <<--- NEEDS A REPO METHOD WITH LOCKING (e.g., SELECT ... FOR UPDATE)
+// --- CheckIn Method ---
+// Organizer memindai ticket code (event berbayar) atau memilih user ID (event gratis) di pintu masuk.
+func (uc *eventAttendeeUseCaseImpl) CheckIn(ctx context.Context, organizerID int, role string, request dto.AttendeeCheckInRequest) (*models.EventAttendee, error) {
+	event, err := uc.eventRepo.FindEventByID(request.EventID)
 	if err != nil {
-		// Rollback transaction if started
-		return nil, fmt.Errorf("failed to lock ticket type for quota update: %w", err)
+		return nil, fmt.Errorf("event with ID %d not found", request.EventID)
 	}
-	if ticketType == nil {
-		// Rollback transaction if started
-		return nil, fmt.Errorf("ticket type %d not found during payment confirmation", *attendee.TicketTypeID)
+	if err := authorizeOrganizer(event, organizerID, role); err != nil {
+		return nil, err
 	}
 
-	// Double-check quota before decrementing
-	if ticketType.Quota <= 0 {
-		// Rollback transaction if started
-		// Payment might have succeeded via Midtrans, but quota ran out.
-		// This indicates a potential issue (overselling). Log this situation.
-		// Update attendee status to maybe "payment_received_no_quota"?
-		attendee.PaymentStatus = "failed_no_quota" // Example status
-		uc.attendeeRepo.Update(ctx, attendee)      // Update attendee status
-		// Commit transaction here (to save the 'failed_no_quota' status)
-		return nil, fmt.Errorf("payment confirmed, but ticket type '%s' is now sold out", ticketType.TicketType)
-	}
-
-	// Decrement the quota
-	err = uc.ticketRepo.DecrementQuota(*attendee.TicketTypeID) // <<--- NEEDS A REPO METHOD TO UPDATE quota = quota - 1
+	var attendee *models.EventAttendee
+	switch {
+	case request.TicketCode != "":
+		attendee, err = uc.attendeeRepo.FindByTicketCode(ctx, request.EventID, request.TicketCode)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Tiket group booking untuk peserta tanpa akun tidak punya baris event_attendees
+			return uc.checkInGroupSeat(ctx, request)
+		}
+	case request.UserID != 0:
+		attendee, err = uc.attendeeRepo.FindByUserAndEvent(ctx, request.UserID, request.EventID)
+		if err == nil && attendee == nil {
+			return nil, errors.New("registration not found")
+		}
+	default:
+		return nil, errors.New("ticketCode or userId is required")
+	}
 	if err != nil {
-		// Rollback transaction if started
-		return nil, fmt.Errorf("failed to decrement ticket quota: %w", err)
+		return nil, fmt.Errorf("failed to find registration: %w", err)
+	}
+
+	if event.IsPaid && attendee.PaymentStatus != "paid" {
+		return nil, fmt.Errorf("cannot check in: payment status is %s", attendee.PaymentStatus)
+	}
+	if attendee.CheckedInAt != nil {
+		return nil, fmt.Errorf("attendee already checked in at %s", attendee.CheckedInAt.Format(time.RFC3339))
 	}
 
-	// --- Update Attendee Record ---
-	attendee.PaymentStatus = "paid"
-	attendee.TicketCode = new(string)
-	*attendee.TicketCode = generateTicketCode(eventID, userID)
 	now := time.Now()
-	attendee.RSVPDate = &now // Update RSVP date to reflect payment confirmation time
+	if err := uc.attendeeRepo.MarkCheckedIn(ctx, attendee.EventID, attendee.UserID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("attendee already checked in")
+		}
+		return nil, fmt.Errorf("failed to check in attendee: %w", err)
+	}
+	attendee.CheckedInAt = &now
 
-	err = uc.attendeeRepo.Update(ctx, attendee)
+	uc.statsUC.Publish(ctx, attendee.EventID, service.StreamCheckInCreated, attendee.UserID, attendee.TicketTypeID)
+	uc.webhookUC.Emit(ctx, models.WebhookCheckInCreated, attendee.EventID, attendee.UserID)
+	return attendee, nil
+}
+
+// checkInGroupSeat mencatat check-in kursi group booking yang pesertanya tidak punya akun
+func (uc *eventAttendeeUseCaseImpl) checkInGroupSeat(ctx context.Context, request dto.AttendeeCheckInRequest) (*models.EventAttendee, error) {
+	attendee, err := uc.attendeeRepo.FindGroupSeatByTicketCode(ctx, request.EventID, request.TicketCode)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("ticket code not found for this event")
+	}
 	if err != nil {
-		// Rollback transaction if started
-		return nil, fmt.Errorf("failed to update registration after payment confirmation: %w", err)
+		return nil, fmt.Errorf("failed to find registration: %w", err)
+	}
+	if attendee.CheckedInAt != nil {
+		return nil, fmt.Errorf("attendee already checked in at %s", attendee.CheckedInAt.Format(time.RFC3339))
 	}
 
-	// Commit transaction here...
+	now := time.Now()
+	if err := uc.attendeeRepo.MarkGroupSeatCheckedIn(ctx, request.EventID, request.TicketCode, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("attendee already checked in")
+		}
+		return nil, fmt.Errorf("failed to check in attendee: %w", err)
+	}
+	attendee.CheckedInAt = &now
 
+	uc.statsUC.Publish(ctx, attendee.EventID, service.StreamCheckInCreated, *attendee.PurchaserID, attendee.TicketTypeID)
+	uc.webhookUC.EmitGroupSeat(ctx, models.WebhookCheckInCreated, attendee.EventID, request.TicketCode)
 	return attendee, nil
 }
 
-// --- UpdateRSVPStatus Method (Unchanged for now) ---
-func (uc *eventAttendeeUseCaseImpl) UpdateRSVPStatus(ctx context.Context, userID, eventID int, newStatus string) (*models.EventAttendee, error) {
-	allowedRSVP := map[string]bool{"pending": true, "attending": true, "not_attending": true, "maybe": true, "going": true, "interested": true, "not_going": true} // Expanded allowed statuses
-	if !allowedRSVP[newStatus] {
-		return nil, fmt.Errorf("invalid RSVP status: %s", newStatus)
+// attendeeExportColumns adalah kolom dasar manifest; "answers" diperluas menjadi "answer.<key>" per field form.
+var attendeeExportColumns = []string{
+	"user_id", "name", "email", "ticket_type", "ticket_price", "rsvp_status",
+	"rsvp_date", "payment_status", "ticket_code", "seat", "checked_in_at",
+}
+
+const answerColumnPrefix = "answer."
+
+// --- PrepareAttendeeExport Method ---
+// Dipisah dari WriteAttendeeExport agar error otorisasi/kolom bisa dikirim sebelum file mulai di-stream.
+func (uc *eventAttendeeUseCaseImpl) PrepareAttendeeExport(ctx context.Context, eventID, organizerID int, role string, columns []string) (*dto.AttendeeExportPlan, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("event with ID %d not found", eventID)
+	}
+	if err := authorizeOrganizer(event, organizerID, role); err != nil {
+		return nil, err
 	}
 
-	attendee, err := uc.attendeeRepo.FindByUserAndEvent(ctx, userID, eventID)
+	forms, err := uc.formUC.ListForms(eventID, organizerID, role)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("cannot update status for non-existent registration")
+		return nil, err
+	}
+	answerKeys := FormFieldKeys(forms)
+
+	if len(columns) == 0 {
+		columns = append(append([]string{}, attendeeExportColumns...), "answers")
+	}
+
+	plan := &dto.AttendeeExportPlan{EventID: eventID, EventName: event.Name}
+	seen := make(map[string]bool)
+	add := func(column string) {
+		if !seen[column] {
+			seen[column] = true
+			plan.Columns = append(plan.Columns, column)
 		}
-		return nil, fmt.Errorf("failed to find registration for status update: %w", err)
 	}
-	if attendee == nil {
-		return nil, errors.New("cannot update status for non-existent registration")
+
+	for _, column := range columns {
+		column = strings.TrimSpace(column)
+		switch {
+		case column == "":
+			continue
+		case column == "answers":
+			for _, key := range answerKeys {
+				add(answerColumnPrefix + key)
+			}
+		case strings.HasPrefix(column, answerColumnPrefix):
+			if !contains(answerKeys, strings.TrimPrefix(column, answerColumnPrefix)) {
+				return nil, fmt.Errorf("unknown form field in column %q", column)
+			}
+			add(column)
+		case contains(attendeeExportColumns, column):
+			add(column)
+		default:
+			return nil, fmt.Errorf("unknown column %q, allowed: %s, answers, answer.<field>", column, strings.Join(attendeeExportColumns, ", "))
+		}
 	}
+	if len(plan.Columns) == 0 {
+		return nil, errors.New("no columns selected")
+	}
+	return plan, nil
+}
 
-	attendee.RSVPStatus = newStatus
-	now := time.Now()
-	attendee.RSVPDate = &now
+// --- WriteAttendeeExport Method ---
+func (uc *eventAttendeeUseCaseImpl) WriteAttendeeExport(ctx context.Context, plan *dto.AttendeeExportPlan, w utils.TableWriter) error {
+	if err := w.WriteRow(plan.Columns); err != nil {
+		return err
+	}
 
-	err = uc.attendeeRepo.Update(ctx, attendee)
+	err := uc.attendeeRepo.StreamManifest(ctx, plan.EventID, func(row *dto.AttendeeManifestRow) error {
+		values := make([]string, len(plan.Columns))
+		for i, column := range plan.Columns {
+			values[i] = attendeeExportValue(row, column)
+		}
+		return w.WriteRow(values)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update RSVP status: %w", err)
+		return fmt.Errorf("failed to export attendees: %w", err)
 	}
+	return w.Close()
+}
 
-	return attendee, nil
+func attendeeExportValue(row *dto.AttendeeManifestRow, column string) string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	switch column {
+	case "user_id":
+		if row.UserID == nil {
+			return ""
+		}
+		return strconv.Itoa(*row.UserID)
+	case "name":
+		return row.Name
+	case "email":
+		return row.Email
+	case "ticket_type":
+		return row.TicketType
+	case "ticket_price":
+		return models.Money{Amount: row.TicketPrice, Currency: row.Currency}.Decimal()
+	case "rsvp_status":
+		return row.RSVPStatus
+	case "rsvp_date":
+		return formatTime(row.RSVPDate)
+	case "payment_status":
+		return row.PaymentStatus
+	case "ticket_code":
+		if row.TicketCode == nil {
+			return ""
+		}
+		return *row.TicketCode
+	case "seat":
+		if row.Seat == nil {
+			return ""
+		}
+		return *row.Seat
+	case "checked_in_at":
+		return formatTime(row.CheckedInAt)
+	}
+	return FormatAnswer(row.Answers[strings.TrimPrefix(column, answerColumnPrefix)])
 }
@@ -0,0 +1,587 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/utils"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+
+	maxImportEvents = 1000
+)
+
+// asyncImportAddresses: import yang perlu meng-geocode lebih dari ini alamat unik diproses di
+// background (LocationIQ dibatasi 2 request/detik, jadi 1000 alamat butuh sekitar 8 menit)
+const asyncImportAddresses = 20
+
+// importJobStaleAfter: job "running" yang tidak selesai selama ini dianggap mati dan diantrekan lagi
+const importJobStaleAfter = 30 * time.Minute
+
+var ErrImportJobNotFound = errors.New("import job not found")
+
+type EventImportUsecase interface {
+	Import(organizerID int, format string, data []byte, dryRun bool) (*dto.ImportResult, error)
+	// ImportNow sama dengan Import tetapi selalu langsung diproses; dipakai CLI admin
+	ImportNow(organizerID int, format string, data []byte, dryRun bool) (*dto.ImportResult, error)
+	GetJob(organizerID, jobID int) (*dto.ImportJobResponse, error)
+	// ProcessQueued menjalankan import yang diantrekan; dipanggil oleh background job
+	ProcessQueued() error
+}
+
+type eventImportUsecase struct {
+	eventRepo  repositories.EventsRepository
+	importRepo repositories.EventImportRepository
+}
+
+func NewEventImportUsecase(eventRepo repositories.EventsRepository, importRepo repositories.EventImportRepository) EventImportUsecase {
+	return &eventImportUsecase{eventRepo: eventRepo, importRepo: importRepo}
+}
+
+// Import memvalidasi seluruh baris terlebih dahulu. Jika ada satu saja error, tidak ada yang disimpan;
+// error dikembalikan di result (bukan sebagai error) agar caller bisa menampilkan laporan per baris.
+// Geocoding baru dilakukan setelah semua baris valid, dan file dengan banyak alamat diantrekan.
+func (uc *eventImportUsecase) Import(organizerID int, format string, data []byte, dryRun bool) (*dto.ImportResult, error) {
+	rows, result, err := parseAndValidateImport(format, data, dryRun)
+	if err != nil || len(result.Errors) > 0 {
+		return result, err
+	}
+
+	if len(importAddresses(rows)) <= asyncImportAddresses {
+		return uc.importRows(organizerID, rows, dryRun)
+	}
+
+	job := &models.EventImportJob{
+		OrganizerID: organizerID,
+		Format:      format,
+		DryRun:      dryRun,
+		Data:        data,
+		Status:      models.ImportJobQueued,
+	}
+	if err := uc.importRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to queue import: %w", err)
+	}
+	result.Queued = true
+	result.JobID = job.ID
+	return result, nil
+}
+
+func (uc *eventImportUsecase) ImportNow(organizerID int, format string, data []byte, dryRun bool) (*dto.ImportResult, error) {
+	rows, result, err := parseAndValidateImport(format, data, dryRun)
+	if err != nil || len(result.Errors) > 0 {
+		return result, err
+	}
+	return uc.importRows(organizerID, rows, dryRun)
+}
+
+func (uc *eventImportUsecase) GetJob(organizerID, jobID int) (*dto.ImportJobResponse, error) {
+	job, err := uc.importRepo.FindByID(jobID, organizerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, err
+	}
+
+	response := &dto.ImportJobResponse{
+		ID:         job.ID,
+		Status:     job.Status,
+		DryRun:     job.DryRun,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if len(job.Result) > 0 {
+		var result dto.ImportResult
+		if err := json.Unmarshal(job.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to read import result: %w", err)
+		}
+		response.Result = &result
+	}
+	return response, nil
+}
+
+func (uc *eventImportUsecase) ProcessQueued() error {
+	if err := uc.importRepo.ReleaseStale(time.Now().Add(-importJobStaleAfter)); err != nil {
+		return fmt.Errorf("failed to release stale import jobs: %w", err)
+	}
+
+	// Satu job per putaran: geocoding sudah dibatasi rate LocationIQ
+	jobs, err := uc.importRepo.ClaimQueued(1)
+	if err != nil {
+		return fmt.Errorf("failed to claim import jobs: %w", err)
+	}
+	for _, job := range jobs {
+		uc.runJob(&job)
+		if err := uc.importRepo.Finish(&job); err != nil {
+			return fmt.Errorf("failed to save import job %d: %w", job.ID, err)
+		}
+	}
+	return nil
+}
+
+func (uc *eventImportUsecase) runJob(job *models.EventImportJob) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = models.ImportJobFailed
+
+	rows, result, err := parseAndValidateImport(job.Format, job.Data, job.DryRun)
+	if err == nil && len(result.Errors) == 0 {
+		result, err = uc.importRows(job.OrganizerID, rows, job.DryRun)
+	}
+	if err != nil {
+		job.Error = err.Error()
+		return
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		job.Error = err.Error()
+		return
+	}
+	job.Result = encoded
+	job.Status = models.ImportJobDone
+}
+
+// parseAndValidateImport mem-parse file dan memvalidasi setiap baris tanpa geocoding
+func parseAndValidateImport(format string, data []byte, dryRun bool) ([]dto.ImportEventRow, *dto.ImportResult, error) {
+	var rows []dto.ImportEventRow
+	var problems []dto.ImportError
+
+	switch format {
+	case ImportFormatCSV:
+		rows, problems = parseEventImportCSV(data)
+	case ImportFormatJSON:
+		rows, problems = parseEventImportJSON(data)
+	default:
+		return nil, nil, fmt.Errorf("unsupported import format %q, use csv or json", format)
+	}
+
+	result := &dto.ImportResult{DryRun: dryRun, Events: []dto.ImportedEvent{}, Errors: []dto.ImportError{}}
+	if len(rows) > maxImportEvents {
+		return nil, nil, fmt.Errorf("too many events: %d (maximum %d per import)", len(rows), maxImportEvents)
+	}
+	if len(rows) == 0 && len(problems) == 0 {
+		return nil, nil, errors.New("import file contains no events")
+	}
+
+	for _, row := range rows {
+		problems = append(problems, validateImportEvent(row)...)
+	}
+	if len(problems) > 0 {
+		result.Errors = problems
+	}
+	return rows, result, nil
+}
+
+// importAddresses mengembalikan alamat unik dari baris yang belum punya koordinat
+func importAddresses(rows []dto.ImportEventRow) []string {
+	seen := make(map[string]bool)
+	var addresses []string
+	for _, row := range rows {
+		if !hasCoordinates(row) && row.Address != "" && !seen[row.Address] {
+			seen[row.Address] = true
+			addresses = append(addresses, row.Address)
+		}
+	}
+	return addresses
+}
+
+// importRows meng-geocode dan menyimpan baris yang sudah lolos validasi
+func (uc *eventImportUsecase) importRows(organizerID int, rows []dto.ImportEventRow, dryRun bool) (*dto.ImportResult, error) {
+	result := &dto.ImportResult{DryRun: dryRun, Events: []dto.ImportedEvent{}, Errors: []dto.ImportError{}}
+
+	// Geocoding dilakukan sekali per alamat unik untuk baris yang belum punya koordinat
+	geocodes := utils.GeocodeAddresses(importAddresses(rows), 2, 500*time.Millisecond)
+
+	var problems []dto.ImportError
+	events := make([]models.Event, 0, len(rows))
+	for _, row := range rows {
+		event, err := buildImportedEvent(organizerID, row, geocodes)
+		if err != nil {
+			problems = append(problems, dto.ImportError{Line: row.Line, Field: "address", Message: err.Error()})
+			continue
+		}
+		events = append(events, event)
+	}
+
+	if len(problems) > 0 {
+		result.Errors = problems
+		return result, nil
+	}
+
+	if !dryRun {
+		if err := uc.eventRepo.CreateEventsInTransaction(events); err != nil {
+			return nil, fmt.Errorf("import rolled back: %w", err)
+		}
+		result.Committed = true
+	}
+
+	for i, event := range events {
+		result.Events = append(result.Events, dto.ImportedEvent{
+			Line:    rows[i].Line,
+			ID:      event.ID,
+			Name:    event.Name,
+			Tickets: len(event.Tickets),
+		})
+	}
+	return result, nil
+}
+
+func hasCoordinates(row dto.ImportEventRow) bool {
+	return row.Latitude != nil && row.Longitude != nil
+}
+
+func buildImportedEvent(organizerID int, row dto.ImportEventRow, geocodes map[string]utils.GeocodeResult) (models.Event, error) {
+	var latitude, longitude float64
+	if hasCoordinates(row) {
+		latitude, longitude = *row.Latitude, *row.Longitude
+	} else {
+		geocode := geocodes[row.Address]
+		if geocode.Err != nil {
+			return models.Event{}, fmt.Errorf("gagal mendapatkan koordinat: %v", geocode.Err)
+		}
+		if geocode.Geocode == nil {
+			return models.Event{}, errors.New("lokasi tidak ditemukan")
+		}
+		latitude, longitude = geocode.Geocode.Latitude, geocode.Geocode.Longitude
+	}
+
+	// Tanggal sudah divalidasi di validateImportEvent
+	startDate, _ := time.Parse("2006-01-02", row.StartDate)
+	endDate, _ := time.Parse("2006-01-02", row.EndDate)
+
+	visibility := row.Visibility
+	if visibility == "" {
+		visibility = models.EventVisibilityPublic
+	}
+	accessKey, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return models.Event{}, fmt.Errorf("gagal membuat access key: %w", err)
+	}
+
+	// Mata uang dan harga sudah divalidasi di validateImportEvent
+	currency := importCurrency(row)
+
+	event := models.Event{
+		Name:        row.Name,
+		Category:    row.Category,
+		Description: row.Description,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IsPaid:      row.IsPaid,
+		Currency:    currency,
+		Capacity:    row.Capacity,
+		Latitude:    latitude,
+		Longitude:   longitude,
+		PosterURL:   row.PosterURL,
+		OrganizerID: organizerID,
+		Visibility:  visibility,
+		AccessKey:   accessKey,
+	}
+	for _, ticket := range row.Tickets {
+		status := ticket.Status
+		if status == "" {
+			status = "available"
+		}
+		price, _ := models.MoneyFromMajor(ticket.Price, currency)
+		event.Tickets = append(event.Tickets, models.Ticket{
+			TikcetUuid: GenerateUuid(),
+			TicketType: ticket.TicketType,
+			Price:      price.Amount,
+			Quota:      ticket.Quota,
+			Status:     status,
+		})
+	}
+	return event, nil
+}
+
+func importCurrency(row dto.ImportEventRow) string {
+	if row.Currency == "" {
+		return models.DefaultCurrency
+	}
+	return strings.ToUpper(row.Currency)
+}
+
+func validateImportEvent(row dto.ImportEventRow) []dto.ImportError {
+	var problems []dto.ImportError
+	add := func(line int, field, message string) {
+		problems = append(problems, dto.ImportError{Line: line, Field: field, Message: message})
+	}
+
+	if strings.TrimSpace(row.Name) == "" {
+		add(row.Line, "name", "is required")
+	}
+	if strings.TrimSpace(row.Category) == "" {
+		add(row.Line, "category", "is required")
+	}
+
+	startDate, startErr := time.Parse("2006-01-02", row.StartDate)
+	if startErr != nil {
+		add(row.Line, "start_date", "format harus YYYY-MM-DD")
+	}
+	endDate, endErr := time.Parse("2006-01-02", row.EndDate)
+	if endErr != nil {
+		add(row.Line, "end_date", "format harus YYYY-MM-DD")
+	}
+	if startErr == nil && endErr == nil && endDate.Before(startDate) {
+		add(row.Line, "end_date", "must not be before start_date")
+	}
+
+	if row.Capacity <= 0 {
+		add(row.Line, "capacity", "must be greater than 0")
+	}
+	if !hasCoordinates(row) {
+		if (row.Latitude == nil) != (row.Longitude == nil) {
+			add(row.Line, "latitude", "latitude and longitude must be set together")
+		} else if strings.TrimSpace(row.Address) == "" {
+			add(row.Line, "address", "address or latitude/longitude is required")
+		}
+	} else if *row.Latitude < -90 || *row.Latitude > 90 || *row.Longitude < -180 || *row.Longitude > 180 {
+		add(row.Line, "latitude", "coordinates out of range")
+	}
+
+	switch row.Visibility {
+	case "", models.EventVisibilityPublic, models.EventVisibilityUnlisted, models.EventVisibilityInviteOnly:
+	default:
+		add(row.Line, "visibility", "must be public, unlisted or invite_only")
+	}
+	currency, currencyErr := models.LookupCurrency(importCurrency(row))
+	if currencyErr != nil {
+		add(row.Line, "currency", currencyErr.Error())
+	}
+
+	if len(row.Tickets) == 0 {
+		add(row.Line, "tickets", "at least one ticket type is required")
+	}
+
+	totalQuota := 0
+	seen := make(map[string]bool)
+	for _, ticket := range row.Tickets {
+		name := strings.ToLower(strings.TrimSpace(ticket.TicketType))
+		switch {
+		case name == "":
+			add(ticket.Line, "ticket_type", "is required")
+		case seen[name]:
+			add(ticket.Line, "ticket_type", fmt.Sprintf("duplicate ticket type %q", ticket.TicketType))
+		}
+		seen[name] = true
+
+		if ticket.Price < 0 {
+			add(ticket.Line, "ticket_price", "must not be negative")
+		} else if !row.IsPaid && ticket.Price > 0 {
+			add(ticket.Line, "ticket_price", "must be 0 for a free event")
+		} else if row.IsPaid && ticket.Price == 0 {
+			add(ticket.Line, "ticket_price", "must be greater than 0 for a paid event")
+		}
+		if currencyErr == nil && ticket.Price >= 0 {
+			// Aturan yang sama dengan POST /ticket: tanpa pembulatan dan bisa ditagihkan
+			if _, err := ticketPrice(ticket.Price, currency); err != nil {
+				add(ticket.Line, "ticket_price", err.Error())
+			}
+		}
+		if ticket.Quota <= 0 {
+			add(ticket.Line, "ticket_quota", "must be greater than 0")
+		}
+		if ticket.Status != "" && ticket.Status != "available" && ticket.Status != "unavailable" {
+			add(ticket.Line, "ticket_status", "must be available or unavailable")
+		}
+		totalQuota += ticket.Quota
+	}
+	if row.Capacity > 0 && totalQuota > row.Capacity {
+		add(row.Line, "ticket_quota", fmt.Sprintf("total ticket quota %d exceeds capacity %d", totalQuota, row.Capacity))
+	}
+
+	return problems
+}
+
+// parseEventImportCSV membaca CSV satu baris per ticket type. Baris dengan event_ref yang sama
+// digabung menjadi satu event; kolom event cukup diisi di baris pertama grup.
+func parseEventImportCSV(data []byte) ([]dto.ImportEventRow, []dto.ImportError) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, []dto.ImportError{{Line: 1, Message: fmt.Sprintf("cannot read header: %v", err)}}
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	var problems []dto.ImportError
+	for _, required := range []string{"name", "category", "start_date", "end_date", "capacity", "ticket_type", "ticket_price", "ticket_quota"} {
+		if _, ok := columns[required]; !ok {
+			problems = append(problems, dto.ImportError{Line: 1, Field: required, Message: "missing column"})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	var rows []dto.ImportEventRow
+	groups := make(map[string]int) // event_ref -> index di rows
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// FieldPos tidak boleh dipanggil setelah Read gagal; nomor baris diambil dari ParseError
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				problems = append(problems, dto.ImportError{Message: err.Error()})
+				break
+			}
+			problems = append(problems, dto.ImportError{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		parseInt := func(column string) int {
+			value := get(column)
+			if value == "" {
+				return 0
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				problems = append(problems, dto.ImportError{Line: line, Field: column, Message: "must be an integer"})
+			}
+			return n
+		}
+		parseFloat := func(column string) *float64 {
+			value := get(column)
+			if value == "" {
+				return nil
+			}
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				problems = append(problems, dto.ImportError{Line: line, Field: column, Message: "must be a number"})
+				return nil
+			}
+			return &f
+		}
+		parseAmount := func(column string) float64 {
+			if f := parseFloat(column); f != nil {
+				return *f
+			}
+			return 0
+		}
+
+		ref := get("event_ref")
+		index, grouped := groups[ref]
+		if ref == "" || !grouped {
+			isPaid := false
+			if value := get("is_paid"); value != "" {
+				isPaid, err = strconv.ParseBool(value)
+				if err != nil {
+					problems = append(problems, dto.ImportError{Line: line, Field: "is_paid", Message: "must be true or false"})
+				}
+			}
+			rows = append(rows, dto.ImportEventRow{
+				Name:        get("name"),
+				Category:    get("category"),
+				Description: get("description"),
+				StartDate:   get("start_date"),
+				EndDate:     get("end_date"),
+				IsPaid:      isPaid,
+				Currency:    get("currency"),
+				Capacity:    parseInt("capacity"),
+				Address:     get("address"),
+				Latitude:    parseFloat("latitude"),
+				Longitude:   parseFloat("longitude"),
+				PosterURL:   get("poster_url"),
+				Visibility:  get("visibility"),
+				Line:        line,
+			})
+			index = len(rows) - 1
+			if ref != "" {
+				groups[ref] = index
+			}
+		}
+
+		if get("ticket_type") == "" && get("ticket_price") == "" && get("ticket_quota") == "" {
+			continue
+		}
+		rows[index].Tickets = append(rows[index].Tickets, dto.ImportTicketRow{
+			TicketType: get("ticket_type"),
+			Price:      parseAmount("ticket_price"),
+			Quota:      parseInt("ticket_quota"),
+			Status:     get("ticket_status"),
+			Line:       line,
+		})
+	}
+
+	return rows, problems
+}
+
+// parseEventImportJSON membaca array event dan mencatat nomor baris awal setiap elemen
+func parseEventImportJSON(data []byte) ([]dto.ImportEventRow, []dto.ImportError) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	lineAt := func(offset int64) int {
+		// Lewati spasi/koma agar nomor baris menunjuk ke awal objek
+		for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[offset])) {
+			offset++
+		}
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, []dto.ImportError{{Line: 1, Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, []dto.ImportError{{Line: 1, Message: "JSON import must be an array of events"}}
+	}
+
+	var rows []dto.ImportEventRow
+	var problems []dto.ImportError
+	for decoder.More() {
+		line := lineAt(decoder.InputOffset())
+
+		var row dto.ImportEventRow
+		if err := decoder.Decode(&row); err != nil {
+			// Salah tipe tidak menghentikan decoder, jadi elemen berikutnya tetap diperiksa
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				problems = append(problems, dto.ImportError{Line: line, Field: typeErr.Field, Message: fmt.Sprintf("must be %s", typeErr.Type)})
+				continue
+			}
+			return rows, append(problems, dto.ImportError{Line: line, Message: fmt.Sprintf("invalid JSON: %v", err)})
+		}
+
+		row.Line = line
+		for i := range row.Tickets {
+			row.Tickets[i].Line = line
+		}
+		rows = append(rows, row)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return rows, append(problems, dto.ImportError{Line: lineAt(decoder.InputOffset()), Message: fmt.Sprintf("invalid JSON: %v", err)})
+	}
+	return rows, problems
+}
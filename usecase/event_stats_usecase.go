@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"log"
+	"time"
+)
+
+type EventStatsUsecase interface {
+	// Snapshot mengembalikan total terkini; hanya untuk organizer event atau admin
+	Snapshot(ctx context.Context, eventID, organizerID int, role string) (*dto.EventCounters, error)
+	Subscribe(eventID int) (<-chan service.StreamEvent, func())
+	// Publish menghitung ulang total lalu menyebarkan perubahan ke dashboard yang terhubung
+	Publish(ctx context.Context, eventID int, kind string, userID int, ticketTypeID *int)
+}
+
+type eventStatsUsecase struct {
+	attendeeRepo repositories.EventAttendeeRepository
+	eventRepo    repositories.EventsRepository
+	pubsub       service.PubSub
+}
+
+func NewEventStatsUsecase(
+	attendeeRepo repositories.EventAttendeeRepository,
+	eventRepo repositories.EventsRepository,
+	pubsub service.PubSub,
+) EventStatsUsecase {
+	return &eventStatsUsecase{
+		attendeeRepo: attendeeRepo,
+		eventRepo:    eventRepo,
+		pubsub:       pubsub,
+	}
+}
+
+func (uc *eventStatsUsecase) Snapshot(ctx context.Context, eventID, organizerID int, role string) (*dto.EventCounters, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, organizerID, role); err != nil {
+		return nil, err
+	}
+	return uc.counters(ctx, eventID)
+}
+
+func (uc *eventStatsUsecase) Subscribe(eventID int) (<-chan service.StreamEvent, func()) {
+	return uc.pubsub.Subscribe(service.EventTopic(eventID))
+}
+
+// Publish tidak mengembalikan error: statistik live tidak boleh menggagalkan pendaftaran atau pembayaran.
+// Total hanya dihitung jika ada dashboard yang terhubung; dashboard baru mendapat snapshot saat subscribe.
+func (uc *eventStatsUsecase) Publish(ctx context.Context, eventID int, kind string, userID int, ticketTypeID *int) {
+	topic := service.EventTopic(eventID)
+	if !uc.pubsub.HasSubscribers(topic) {
+		return
+	}
+
+	totals, err := uc.counters(ctx, eventID)
+	if err != nil {
+		log.Printf("event stats: failed to count event %d: %v", eventID, err)
+		return
+	}
+
+	uc.pubsub.Publish(topic, service.StreamEvent{
+		Type: kind,
+		Data: dto.EventStatsDelta{
+			EventID:      eventID,
+			UserID:       userID,
+			TicketTypeID: ticketTypeID,
+			At:           time.Now(),
+			Totals:       *totals,
+		},
+	})
+}
+
+func (uc *eventStatsUsecase) counters(ctx context.Context, eventID int) (*dto.EventCounters, error) {
+	ticketTypes, err := uc.attendeeRepo.TicketTypeCounters(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := &dto.EventCounters{EventID: eventID, Currency: models.DefaultCurrency, TicketTypes: ticketTypes, UpdatedAt: time.Now()}
+	for i := range totals.TicketTypes {
+		counter := &totals.TicketTypes[i]
+		counter.Revenue = counter.Paid * counter.Price
+		totals.Currency = counter.Currency
+		totals.Registered += counter.Registered
+		totals.Paid += counter.Paid
+		totals.CheckedIn += counter.CheckedIn
+		totals.Revenue += counter.Revenue
+	}
+	return totals, nil
+}
@@ -1,42 +1,64 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"gatherly-app/models"
 	"gatherly-app/models/dto"
 	"gatherly-app/repositories"
+	"gatherly-app/service"
 	"gatherly-app/utils"
+	"math"
+	"sort"
+	"strings"
 
 	"time"
 )
 
+// ErrEventCurrencyLocked: harga dan transaksi yang sudah ada tercatat dalam mata uang lama
+var ErrEventCurrencyLocked = errors.New("currency cannot be changed after attendees have registered")
+
+// viewDedupeWindow: view dari viewer yang sama dalam rentang ini dihitung sekali
+const viewDedupeWindow = 30 * time.Minute
+
 type eventsUsecase struct {
 	repo         repositories.EventsRepository
 	attendeeRepo repositories.EventAttendeeRepository
+	invitationUC InvitationUsecase
+	notifyUC     NotificationUsecase
+	payment      service.MidtransService
 }
 
 type EventsUsecase interface {
-	CreateEvent(request dto.CreateEventRequestDTO) (*models.Event, error)
+	CreateEvent(organizerID int, request dto.CreateEventRequestDTO) (*models.Event, error)
 	GetAllEvent() ([]dto.EventResponseDTO, error)
-	GetEventByID(id int) (*dto.EventResponseDTO, error)
-	UpdateEvent(id int, request dto.UpdateEventRequestDTO) (*models.Event, error)
-	DeleteEvent(id int) error
+	GetEventByID(id, userID int, accessCode string) (*dto.EventResponseDTO, error)
+	UpdateEvent(id, userID int, role string, request dto.UpdateEventRequestDTO) (*models.Event, error)
+	DeleteEvent(id, userID int, role string) error
 	GetEventByDistance(latitude, longitude, radius float64) ([]dto.EventNearbyDistanceResponseDTO, error)
+	RecordView(eventID, userID int, ip string) error
+	GetRecommendations(ctx context.Context, userID int, latitude, longitude float64, limit int) ([]dto.EventRecommendationDTO, error)
 }
 
 // Update NewEventUsecase
 func NewEventUsecase(
 	repo repositories.EventsRepository,
 	attendeeRepo repositories.EventAttendeeRepository, // Sesuai dengan nama di server.go
+	invitationUC InvitationUsecase,
+	notifyUC NotificationUsecase,
+	payment service.MidtransService,
 ) EventsUsecase {
 	return &eventsUsecase{
 		repo:         repo,
 		attendeeRepo: attendeeRepo,
+		invitationUC: invitationUC,
+		notifyUC:     notifyUC,
+		payment:      payment,
 	}
 }
 
-func (uc *eventsUsecase) CreateEvent(request dto.CreateEventRequestDTO) (*models.Event, error) {
+func (uc *eventsUsecase) CreateEvent(organizerID int, request dto.CreateEventRequestDTO) (*models.Event, error) {
 	startDate, err := time.Parse("2006-01-02", request.StartDate)
 	if err != nil {
 		return nil, fmt.Errorf("format harus YYYY-MM-DD: %w", err)
@@ -47,11 +69,26 @@ func (uc *eventsUsecase) CreateEvent(request dto.CreateEventRequestDTO) (*models
 		return nil, fmt.Errorf("format harus YYYY-MM-DD: %w", err)
 	}
 
+	currency, err := uc.eventCurrency(request.Currency, request.IsPaid)
+	if err != nil {
+		return nil, err
+	}
+
 	coordinate, err := utils.GetCoordinatesFromAddress(request.Address)
 	if err != nil {
 		return nil, fmt.Errorf("gagal mendapatkan koordinat: %w", err)
 	}
 
+	visibility := request.Visibility
+	if visibility == "" {
+		visibility = models.EventVisibilityPublic
+	}
+
+	accessKey, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat access key: %w", err)
+	}
+
 	events := &models.Event{
 		Name: request.Name,
 		Category: request.Category,
@@ -59,11 +96,15 @@ func (uc *eventsUsecase) CreateEvent(request dto.CreateEventRequestDTO) (*models
 		StartDate: startDate,
 		EndDate: endDate,
 		IsPaid: request.IsPaid,
+		Currency: currency,
 		Capacity: request.Capacity,
 		Latitude: coordinate.Latitude,
 		Longitude: coordinate.Longitude,
 		PosterURL: request.PosterURL,
 		Status: request.Status,
+		OrganizerID: organizerID,
+		Visibility: visibility,
+		AccessKey: accessKey,
 	}
 
 	create, err := uc.repo.CreateEvent(events)
@@ -73,6 +114,22 @@ func (uc *eventsUsecase) CreateEvent(request dto.CreateEventRequestDTO) (*models
 	return create, nil
 }
 
+// eventCurrency memvalidasi mata uang event. Event berbayar hanya boleh memakai mata uang yang
+// bisa ditagihkan payment provider; event gratis boleh memakai mata uang apa pun untuk tampilan.
+func (uc *eventsUsecase) eventCurrency(code string, isPaid bool) (string, error) {
+	if code == "" {
+		code = models.DefaultCurrency
+	}
+	currency, err := models.LookupCurrency(code)
+	if err != nil {
+		return "", err
+	}
+	if isPaid && !uc.payment.SupportsCurrency(currency.Code) {
+		return "", fmt.Errorf("%w: %s", service.ErrCurrencyNotSupported, currency.Code)
+	}
+	return currency.Code, nil
+}
+
 func (uc *eventsUsecase) GetAllEvent() ([]dto.EventResponseDTO, error) {
 	events, err := uc.repo.FindEvent()
 	if err != nil {
@@ -105,7 +162,7 @@ func (uc *eventsUsecase) GetAllEvent() ([]dto.EventResponseDTO, error) {
 			ticketResponse = &dto.TicketResponseDTO{
 				ID: ticket.Id,
 				TicketType: ticket.TicketType,
-				Price: ticket.Price,
+				Price: dto.NewMoneyView(models.Money{Amount: ticket.Price, Currency: event.Currency}),
 				Quota: ticket.Quota,
 				Status: ticketStatus,
 			}
@@ -117,6 +174,7 @@ func (uc *eventsUsecase) GetAllEvent() ([]dto.EventResponseDTO, error) {
 			StartDate: event.StartDate.Format(time.RFC3339),
 			EndDate: event.EndDate.Format(time.RFC3339),
 			IsPaid:      event.IsPaid,
+			Currency:    event.Currency,
 			Ticket:      ticketResponse,
 			Capacity:    event.Capacity,
 			Latitude:    event.Latitude,
@@ -129,12 +187,21 @@ func (uc *eventsUsecase) GetAllEvent() ([]dto.EventResponseDTO, error) {
 	return response, nil
 }
 
-func (uc *eventsUsecase) GetEventByID(id int) (*dto.EventResponseDTO, error) {
+func (uc *eventsUsecase) GetEventByID(id, userID int, accessCode string) (*dto.EventResponseDTO, error) {
 	event, err := uc.repo.FindEventByID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	// Event unlisted/invite-only diperlakukan seperti tidak ada bagi user tanpa akses
+	allowed, err := uc.invitationUC.CanView(event, userID, accessCode)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("event tidak ditemukan")
+	}
+
 	now := time.Now()
 	status := "Unknown"
 	if event.StartDate.After(now) {
@@ -155,7 +222,7 @@ func (uc *eventsUsecase) GetEventByID(id int) (*dto.EventResponseDTO, error) {
 		ticketResponse = &dto.TicketResponseDTO{
 			ID:         ticket.Id,
 			TicketType: ticket.TicketType,
-			Price:      ticket.Price,
+			Price:      dto.NewMoneyView(models.Money{Amount: ticket.Price, Currency: event.Currency}),
 			Quota:      event.Capacity,
 			Status:     ticketStatus,
 		}
@@ -168,6 +235,7 @@ func (uc *eventsUsecase) GetEventByID(id int) (*dto.EventResponseDTO, error) {
 		StartDate:   event.StartDate.Format(time.RFC3339),
 		EndDate:     event.EndDate.Format(time.RFC3339),
 		IsPaid:      event.IsPaid,
+		Currency:    event.Currency,
 		Ticket:      ticketResponse,
 		Capacity:    event.Capacity,
 		Latitude:    event.Latitude,
@@ -178,7 +246,14 @@ func (uc *eventsUsecase) GetEventByID(id int) (*dto.EventResponseDTO, error) {
 	return response, nil
 }
 
-func (uc *eventsUsecase) UpdateEvent(id int, request dto.UpdateEventRequestDTO) (*models.Event, error) {
+// RecordView mencatat kunjungan ke halaman detail event sebagai sinyal trending; kunjungan ulang
+// dalam viewDedupeWindow tidak dihitung supaya refresh berulang tidak menaikkan skor
+func (uc *eventsUsecase) RecordView(eventID, userID int, ip string) error {
+	now := time.Now()
+	return uc.repo.RecordView(models.EventView{EventID: eventID, UserID: userID, IP: ip, ViewedAt: now}, now.Add(-viewDedupeWindow))
+}
+
+func (uc *eventsUsecase) UpdateEvent(id, userID int, role string, request dto.UpdateEventRequestDTO) (*models.Event, error) {
 	var startDate, endDate time.Time
 	var err error
 
@@ -200,6 +275,11 @@ func (uc *eventsUsecase) UpdateEvent(id int, request dto.UpdateEventRequestDTO)
 	if err != nil {
 		return nil, err
 	}
+	// Hanya organizer event (atau admin) yang boleh mengubah, termasuk membatalkan event
+	if err := authorizeOrganizer(isExist, userID, role); err != nil {
+		return nil, err
+	}
+	before := *isExist
 
 	if request.Name != nil {
 		isExist.Name = *request.Name
@@ -219,6 +299,26 @@ func (uc *eventsUsecase) UpdateEvent(id int, request dto.UpdateEventRequestDTO)
 	if request.IsPaid != nil {
 		isExist.IsPaid = *request.IsPaid
 	}
+	if request.Currency != nil || request.IsPaid != nil {
+		code := isExist.Currency
+		if request.Currency != nil {
+			code = *request.Currency
+		}
+		currency, err := uc.eventCurrency(code, isExist.IsPaid)
+		if err != nil {
+			return nil, err
+		}
+		if currency != before.Currency {
+			counts, err := uc.attendeeRepo.CountByEventIDs(context.Background(), []int{id})
+			if err != nil {
+				return nil, err
+			}
+			if counts[id] > 0 {
+				return nil, ErrEventCurrencyLocked
+			}
+		}
+		isExist.Currency = currency
+	}
 	if request.Capacity != nil {
 		isExist.Capacity = *request.Capacity
 	}
@@ -235,31 +335,90 @@ func (uc *eventsUsecase) UpdateEvent(id int, request dto.UpdateEventRequestDTO)
 		isExist.PosterURL = *request.PosterURL
 	}
 
+	if request.Visibility != nil {
+		isExist.Visibility = *request.Visibility
+	}
+
+	if request.Status != nil && strings.EqualFold(*request.Status, models.EventStatusCancelled) {
+		isExist.Status = models.EventStatusCancelled
+	}
+
 	now := time.Now()
 	startTime := isExist.StartDate
 	endTime := isExist.EndDate
 
-	if startTime.After(now) {
-		isExist.Status = "Upcoming"
-	} else if now.After(startTime) && now.Before(endTime) {
-		isExist.Status = "Ongoing"
-	} else if now.After(endTime) {
-		isExist.Status = "Ended"
+	// Event yang dibatalkan tidak dihitung ulang statusnya dari tanggal
+	if isExist.Status != models.EventStatusCancelled {
+		if startTime.After(now) {
+			isExist.Status = "Upcoming"
+		} else if now.After(startTime) && now.Before(endTime) {
+			isExist.Status = "Ongoing"
+		} else if now.After(endTime) {
+			isExist.Status = "Ended"
+		}
 	}
 
+	isExist.Sequence++
+
 	updatedEvent, err := uc.repo.UpdateEvent(id, isExist)
 	if err != nil {
 		return nil, err
 	}
+
+	uc.notifyEventChange(&before, updatedEvent)
 	return updatedEvent, nil
 }
 
-func (uc *eventsUsecase) DeleteEvent(id int) error {
-	_, err := uc.repo.FindEventByID(id)
+// notifyEventChange memberi tahu peserta jika event dibatalkan atau jadwal/lokasinya berubah.
+// Kegagalan hanya dicatat karena perubahan event sudah tersimpan.
+func (uc *eventsUsecase) notifyEventChange(before, after *models.Event) {
+	notificationType := ""
+	locationChanged := before.Latitude != after.Latitude || before.Longitude != after.Longitude
+	switch {
+	case after.Status == models.EventStatusCancelled && before.Status != models.EventStatusCancelled:
+		notificationType = models.NotificationEventCancelled
+	case after.Status == models.EventStatusCancelled:
+		return
+	case !before.StartDate.Equal(after.StartDate) || !before.EndDate.Equal(after.EndDate) || locationChanged:
+		notificationType = models.NotificationEventChanged
+	default:
+		return
+	}
+
+	attendees, err := uc.attendeeRepo.ListByEventID(context.Background(), after.ID)
+	if err != nil {
+		fmt.Printf("ERROR: failed to load attendees of event %d for notification: %v\n", after.ID, err)
+		return
+	}
+	userIDs := make([]int, 0, len(attendees))
+	for _, attendee := range attendees {
+		if attendee.RSVPStatus != "not_attending" {
+			userIDs = append(userIDs, attendee.UserID)
+		}
+	}
+
+	payload := map[string]any{
+		"event_id":         after.ID,
+		"event_name":       after.Name,
+		"event_start":      after.StartDate,
+		"event_end":        after.EndDate,
+		"location_changed": locationChanged,
+	}
+	dedupKey := fmt.Sprintf("%d:%d", after.ID, after.Sequence)
+	if err := uc.notifyUC.Notify(userIDs, notificationType, payload, dedupKey); err != nil {
+		fmt.Printf("ERROR: failed to queue %s notification for event %d: %v\n", notificationType, after.ID, err)
+	}
+}
+
+func (uc *eventsUsecase) DeleteEvent(id, userID int, role string) error {
+	event, err := uc.repo.FindEventByID(id)
 	if err != nil {
 
 		return errors.New("event tidak ditemukan atau terjadi kesalahan saat mencari: " + err.Error())
 	}
+	if err := authorizeOrganizer(event, userID, role); err != nil {
+		return err
+	}
 
 	err = uc.repo.DeleteEvent(id)
 	if err != nil {
@@ -311,6 +470,7 @@ func (uc *eventsUsecase) GetEventByDistance(latitude, longitude, radius float64)
 			StartDate:   event.StartDate,
 			EndDate:     event.EndDate,
 			IsPaid:      event.IsPaid,
+			Currency:    event.Currency,
 			Capacity:    event.Capacity,
 			Latitude:    event.Latitude,
 			Longitude:   event.Longitude,
@@ -322,3 +482,147 @@ func (uc *eventsUsecase) GetEventByDistance(latitude, longitude, radius float64)
 	}
 	return results, nil
 }
+
+// Bobot tiap sinyal pada skor rekomendasi (total 1.0)
+const (
+	recommendationCategoryWeight   = 0.35
+	recommendationDistanceWeight   = 0.30
+	recommendationPopularityWeight = 0.20
+	recommendationRecencyWeight    = 0.15
+
+	recommendationDistanceScaleKm = 50.0 // skor jarak turun ke 0 pada jarak ini
+	recommendationRecencyDays     = 30.0 // skor waktu turun ke 0 untuk event sejauh ini ke depan
+	recommendationFavoriteLimit   = 3
+)
+
+func (uc *eventsUsecase) GetRecommendations(ctx context.Context, userID int, latitude, longitude float64, limit int) ([]dto.EventRecommendationDTO, error) {
+	now := time.Now()
+
+	events, err := uc.repo.FindUpcomingEvents(now)
+	if err != nil {
+		return nil, err
+	}
+
+	registrations, err := uc.attendeeRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	joined := make(map[int]bool, len(registrations))
+	for _, registration := range registrations {
+		joined[registration.EventID] = true
+	}
+
+	favorites, err := uc.attendeeRepo.GetFavoriteCategories(userID, recommendationFavoriteLimit)
+	if err != nil {
+		return nil, err
+	}
+	// Kategori favorit pertama bernilai 1, berikutnya berkurang bertahap
+	categoryAffinity := make(map[string]float64, len(favorites))
+	for i, category := range favorites {
+		categoryAffinity[strings.ToLower(category)] = 1 - float64(i)/float64(len(favorites))
+	}
+
+	var candidates []models.Event
+	var candidateIDs []int
+	for _, event := range events {
+		if joined[event.ID] {
+			continue
+		}
+		candidates = append(candidates, event)
+		candidateIDs = append(candidateIDs, event.ID)
+	}
+
+	registrationCounts, err := uc.attendeeRepo.CountByEventIDs(ctx, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	hasLocation := latitude != 0 || longitude != 0
+	results := make([]dto.EventRecommendationDTO, 0, len(candidates))
+
+	for _, event := range candidates {
+		var score float64
+		var reasons []string
+
+		if affinity, ok := categoryAffinity[strings.ToLower(event.Category)]; ok {
+			score += recommendationCategoryWeight * affinity
+			reasons = append(reasons, fmt.Sprintf("You often attend %s events", event.Category))
+		}
+
+		var distance *float64
+		if hasLocation && (event.Latitude != 0 || event.Longitude != 0) {
+			km := utils.DistanceKm(latitude, longitude, event.Latitude, event.Longitude)
+			distance = &km
+			if km < recommendationDistanceScaleKm {
+				score += recommendationDistanceWeight * (1 - km/recommendationDistanceScaleKm)
+				reasons = append(reasons, fmt.Sprintf("Only %.1f km away from you", km))
+			}
+		}
+
+		if event.Capacity > 0 {
+			fillRate := math.Min(float64(registrationCounts[event.ID])/float64(event.Capacity), 1)
+			score += recommendationPopularityWeight * fillRate
+			if fillRate >= 0.5 {
+				reasons = append(reasons, fmt.Sprintf("Popular: %d%% of spots taken", int(fillRate*100)))
+			}
+		}
+
+		daysUntil := event.StartDate.Sub(now).Hours() / 24
+		if daysUntil < recommendationRecencyDays {
+			score += recommendationRecencyWeight * (1 - daysUntil/recommendationRecencyDays)
+			if daysUntil < 7 {
+				reasons = append(reasons, "Happening this week")
+			}
+		}
+
+		if len(reasons) == 0 {
+			reasons = append(reasons, "Upcoming event you haven't joined yet")
+		}
+
+		results = append(results, dto.EventRecommendationDTO{
+			ID:          event.ID,
+			Name:        event.Name,
+			Category:    event.Category,
+			Description: event.Description,
+			StartDate:   event.StartDate.Format(time.RFC3339),
+			EndDate:     event.EndDate.Format(time.RFC3339),
+			IsPaid:      event.IsPaid,
+			Ticket:      firstTicketResponse(event),
+			Capacity:    event.Capacity,
+			Latitude:    event.Latitude,
+			Longitude:   event.Longitude,
+			Distance:    distance,
+			PosterURL:   event.PosterURL,
+			Score:       math.Round(score*1000) / 1000,
+			Reasons:     reasons,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func firstTicketResponse(event models.Event) *dto.TicketResponseDTO {
+	if len(event.Tickets) == 0 {
+		return nil
+	}
+
+	ticket := event.Tickets[0]
+	ticketStatus := "Available"
+	if ticket.Quota <= 0 {
+		ticketStatus = "Not Available"
+	}
+	return &dto.TicketResponseDTO{
+		ID:         ticket.Id,
+		TicketType: ticket.TicketType,
+		Price:      dto.NewMoneyView(models.Money{Amount: ticket.Price, Currency: event.Currency}),
+		Quota:      ticket.Quota,
+		Status:     ticketStatus,
+	}
+}
@@ -0,0 +1,361 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrGroupBookingNotFound dikembalikan saat booking tidak ada atau tidak boleh dilihat user.
+	ErrGroupBookingNotFound = errors.New("group booking not found")
+	// ErrGroupBookingClosed dikembalikan saat booking yang sudah dibayar/ditutup diubah lagi.
+	ErrGroupBookingClosed = errors.New("group booking is no longer awaiting payment")
+)
+
+const (
+	groupBookingListLimit   = 200
+	groupBookingExpiryBatch = 100
+)
+
+type GroupBookingUsecase interface {
+	// Create menahan kuota tiket dan menerbitkan invoice dengan jatuh tempo
+	Create(purchaserID, eventID int, input dto.CreateGroupBookingRequest, client dto.ClientInfo) (*models.GroupBooking, error)
+	Get(id, userID int, role string) (*models.GroupBooking, error)
+	ListMine(purchaserID int) ([]models.GroupBooking, error)
+	ListForEvent(eventID, userID int, role, status string) ([]models.GroupBooking, error)
+	AssignSeats(id, purchaserID int, input dto.AssignSeatsRequest) (*models.GroupBooking, error)
+	// MarkPaid dipanggil organizer setelah transfer bank diterima
+	MarkPaid(id, userID int, role string, input dto.MarkGroupBookingPaidRequest) (*models.GroupBooking, error)
+	Cancel(id, userID int, role string) (*models.GroupBooking, error)
+	// ExpireOverdue menutup booking yang lewat jatuh tempo dan mengembalikan kuotanya
+	ExpireOverdue() error
+}
+
+type groupBookingUsecase struct {
+	repo          repositories.GroupBookingRepository
+	eventRepo     repositories.EventsRepository
+	ticketRepo    repositories.TicketRepository
+	userRepo      repositories.UserRepository
+	invitationUC  InvitationUsecase
+	invoiceUC     InvoiceUsecase
+	transactionUC TransactionUsecase
+	seatMapUC     SeatMapUsecase
+	guardUC       PurchaseGuardUsecase
+	queueUC       VirtualQueueUsecase
+	eligibilityUC EligibilityUsecase
+	minTickets    int
+	maxTickets    int
+	paymentTerm   time.Duration
+}
+
+func NewGroupBookingUsecase(
+	repo repositories.GroupBookingRepository,
+	eventRepo repositories.EventsRepository,
+	ticketRepo repositories.TicketRepository,
+	userRepo repositories.UserRepository,
+	invitationUC InvitationUsecase,
+	invoiceUC InvoiceUsecase,
+	transactionUC TransactionUsecase,
+	seatMapUC SeatMapUsecase,
+	guardUC PurchaseGuardUsecase,
+	queueUC VirtualQueueUsecase,
+	eligibilityUC EligibilityUsecase,
+	minTickets, maxTickets int,
+	paymentTerm time.Duration,
+) GroupBookingUsecase {
+	return &groupBookingUsecase{
+		repo:          repo,
+		eventRepo:     eventRepo,
+		ticketRepo:    ticketRepo,
+		userRepo:      userRepo,
+		invitationUC:  invitationUC,
+		invoiceUC:     invoiceUC,
+		transactionUC: transactionUC,
+		seatMapUC:     seatMapUC,
+		guardUC:       guardUC,
+		queueUC:       queueUC,
+		eligibilityUC: eligibilityUC,
+		minTickets:    minTickets,
+		maxTickets:    maxTickets,
+		paymentTerm:   paymentTerm,
+	}
+}
+
+// closedError menerjemahkan error repository agar controller bisa membalas 409
+func closedError(err error) error {
+	if errors.Is(err, repositories.ErrGroupBookingNotReserved) {
+		return ErrGroupBookingClosed
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrGroupBookingNotFound
+	}
+	return err
+}
+
+func (uc *groupBookingUsecase) Create(purchaserID, eventID int, input dto.CreateGroupBookingRequest, client dto.ClientInfo) (*models.GroupBooking, error) {
+	if err := uc.guardUC.AllowAttempt(purchaserID, client.IP); err != nil {
+		return nil, err
+	}
+	if input.Quantity < uc.minTickets || input.Quantity > uc.maxTickets {
+		return nil, fmt.Errorf("quantity must be between %d and %d", uc.minTickets, uc.maxTickets)
+	}
+
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.IsPaid {
+		return nil, errors.New("group bookings are only available for paid events")
+	}
+	if event.Status == models.EventStatusCancelled {
+		return nil, errors.New("event has been cancelled")
+	}
+	now := time.Now()
+	if !event.StartDate.After(now) {
+		return nil, errors.New("event has already started")
+	}
+	if err := uc.invitationUC.AuthorizeRegistration(event, purchaserID, input.AccessCode); err != nil {
+		return nil, fmt.Errorf("booking not allowed: %w", err)
+	}
+	// Kursi di event bernomor dipilih per orang saat registrasi, jadi tidak bisa dipesan borongan
+	if seated, err := uc.seatMapUC.HasSeatMap(eventID); err != nil {
+		return nil, err
+	} else if seated {
+		return nil, errors.New("group bookings are not available for events with reserved seating")
+	}
+	if err := uc.queueUC.RequireAdmission(eventID, purchaserID); err != nil {
+		return nil, fmt.Errorf("booking not allowed: %w", err)
+	}
+	if err := uc.guardUC.CheckPurchase(event, purchaserID, input.Quantity, client); err != nil {
+		return nil, fmt.Errorf("booking not allowed: %w", err)
+	}
+
+	ticketType, err := uc.ticketRepo.FindTicketByID(input.TicketTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("ticket type with ID %d not found", input.TicketTypeID)
+	}
+	if ticketType.EventID != eventID {
+		return nil, fmt.Errorf("ticket type ID %d does not belong to event ID %d", input.TicketTypeID, eventID)
+	}
+	if ticketType.Status != "available" {
+		return nil, fmt.Errorf("ticket type '%s' is not currently available for purchase", ticketType.TicketType)
+	}
+	// Syarat peserta dicek per orang saat registrasi, sedangkan peserta group booking belum diketahui
+	if restricted, err := uc.eligibilityUC.HasRules(eventID, ticketType.Id); err != nil {
+		return nil, err
+	} else if restricted {
+		return nil, errors.New("group bookings are not available for ticket types with eligibility rules")
+	}
+
+	description := fmt.Sprintf("Ticket: %s (%s)", event.Name, ticketType.TicketType)
+	quote, err := uc.invoiceUC.Quote(event, []dto.OrderItem{{Description: description, Quantity: input.Quantity, UnitPrice: ticketType.Price}})
+	if err != nil {
+		return nil, err
+	}
+
+	// Jatuh tempo tidak boleh melewati awal event
+	dueDate := now.Add(uc.paymentTerm)
+	if event.StartDate.Before(dueDate) {
+		dueDate = event.StartDate
+	}
+	orderID := "GB-" + uuid.NewString()
+
+	transaction := &models.Transactions{
+		UserId:                      purchaserID,
+		EventId:                     eventID,
+		Amount:                      quote.Total,
+		Currency:                    quote.Currency,
+		TransactionDate:             now,
+		Status:                      "pending",
+		PaymentMethod:               "bank_transfer",
+		PaymentGatewayTransactionId: orderID,
+		Items:                       fmt.Sprintf("%d x %s", input.Quantity, description),
+		Notes:                       fmt.Sprintf("Group booking, due %s", dueDate.Format(time.RFC3339)),
+		Gateway:                     models.PaymentGatewayInvoice,
+	}
+	booking := &models.GroupBooking{
+		EventID:      eventID,
+		TicketTypeID: ticketType.Id,
+		PurchaserID:  purchaserID,
+		Quantity:     input.Quantity,
+		Company:      strings.TrimSpace(input.Buyer.Company),
+		Status:       models.GroupBookingReserved,
+		OrderID:      orderID,
+		Amount:       quote.Total,
+		Currency:     quote.Currency,
+		DueDate:      dueDate,
+		PurchaseIP:   client.IP,
+	}
+	if err := uc.guardUC.ReservePurchase(event, input.Quantity, client.IP); err != nil {
+		return nil, fmt.Errorf("booking not allowed: %w", err)
+	}
+	if err := uc.repo.Reserve(booking, transaction); err != nil {
+		uc.guardUC.ReleasePurchase(eventID, input.Quantity, client.IP)
+		return nil, err
+	}
+	uc.queueUC.Complete(eventID, purchaserID)
+
+	// Booking tetap sah tanpa invoice; kegagalan dicatat agar invoice bisa diterbitkan ulang manual
+	invoice, err := uc.invoiceUC.Issue(*transaction, *quote)
+	if err != nil {
+		log.Printf("failed to issue invoice for group booking %d: %v", booking.ID, err)
+	} else {
+		if input.Buyer != (dto.InvoiceBuyerRequest{}) {
+			if _, err := uc.invoiceUC.UpdateBuyer(invoice.ID, purchaserID, input.Buyer); err != nil {
+				log.Printf("failed to set buyer details on invoice %d: %v", invoice.ID, err)
+			}
+		}
+		if err := uc.repo.SetInvoice(booking.ID, invoice.ID); err != nil {
+			log.Printf("failed to link invoice %d to group booking %d: %v", invoice.ID, booking.ID, err)
+		}
+	}
+
+	return uc.repo.FindByID(booking.ID)
+}
+
+// find mengembalikan booking yang boleh dilihat user; organizer true jika user organizer event atau admin
+func (uc *groupBookingUsecase) find(id, userID int, role string) (*models.GroupBooking, bool, error) {
+	booking, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, false, closedError(err)
+	}
+	event, err := uc.eventRepo.FindEventByID(booking.EventID)
+	if err != nil {
+		return nil, false, err
+	}
+	organizer := authorizeOrganizer(event, userID, role) == nil
+	if !organizer && booking.PurchaserID != userID {
+		return nil, false, ErrGroupBookingNotFound
+	}
+	return booking, organizer, nil
+}
+
+func (uc *groupBookingUsecase) Get(id, userID int, role string) (*models.GroupBooking, error) {
+	booking, _, err := uc.find(id, userID, role)
+	return booking, err
+}
+
+func (uc *groupBookingUsecase) ListMine(purchaserID int) ([]models.GroupBooking, error) {
+	return uc.repo.ListByPurchaser(purchaserID, groupBookingListLimit)
+}
+
+func (uc *groupBookingUsecase) ListForEvent(eventID, userID int, role, status string) ([]models.GroupBooking, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, userID, role); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListByEvent(eventID, status, groupBookingListLimit)
+}
+
+func (uc *groupBookingUsecase) AssignSeats(id, purchaserID int, input dto.AssignSeatsRequest) (*models.GroupBooking, error) {
+	booking, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, closedError(err)
+	}
+	if booking.PurchaserID != purchaserID {
+		return nil, ErrGroupBookingNotFound
+	}
+
+	// Satu email hanya boleh memegang satu kursi dalam booking yang sama
+	emails := make(map[int]string, len(booking.Seats))
+	for _, seat := range booking.Seats {
+		emails[seat.ID] = strings.ToLower(seat.AttendeeEmail)
+	}
+	for _, assignment := range input.Seats {
+		if _, ok := emails[assignment.SeatID]; !ok {
+			return nil, fmt.Errorf("seat %d does not belong to this booking", assignment.SeatID)
+		}
+		emails[assignment.SeatID] = strings.ToLower(strings.TrimSpace(assignment.Email))
+	}
+	seen := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		if email == "" {
+			continue
+		}
+		if seen[email] {
+			return nil, fmt.Errorf("%s is assigned to more than one seat", email)
+		}
+		seen[email] = true
+	}
+
+	now := time.Now()
+	for _, assignment := range input.Seats {
+		email := strings.TrimSpace(assignment.Email)
+		// Peserta yang punya akun mendapat pendaftaran atas nama pembeli
+		var attendee *models.EventAttendee
+		if user, err := uc.userRepo.FindByEmail(email); err == nil && user != nil {
+			attendee = &models.EventAttendee{UserID: user.ID, RSVPStatus: "attending"}
+		}
+		if _, err := uc.repo.AssignSeat(id, assignment.SeatID, strings.TrimSpace(assignment.Name), email, attendee, now); err != nil {
+			return nil, fmt.Errorf("seat %d: %w", assignment.SeatID, closedError(err))
+		}
+	}
+	return uc.repo.FindByID(id)
+}
+
+func (uc *groupBookingUsecase) MarkPaid(id, userID int, role string, input dto.MarkGroupBookingPaidRequest) (*models.GroupBooking, error) {
+	booking, organizer, err := uc.find(id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !organizer {
+		return nil, ErrNotEventOrganizer
+	}
+
+	if err := uc.repo.MarkPaid(id, userID, strings.TrimSpace(input.PaymentReference), time.Now(), uuid.NewString); err != nil {
+		return nil, closedError(err)
+	}
+
+	// Sama seperti notifikasi settlement Midtrans: status transaksi, notifikasi pembeli, ledger dan fee
+	err = uc.transactionUC.ApplyGatewayStatus(dto.MidtransStatusResp{
+		StatusCode:        "200",
+		TransactionStatus: "settlement",
+		OrderID:           booking.OrderID,
+		PaymentType:       "bank_transfer",
+	})
+	if err != nil {
+		log.Printf("failed to settle transaction %s of group booking %d: %v", booking.OrderID, id, err)
+	}
+	return uc.repo.FindByID(id)
+}
+
+func (uc *groupBookingUsecase) Cancel(id, userID int, role string) (*models.GroupBooking, error) {
+	booking, _, err := uc.find(id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Close(id, models.GroupBookingCancelled, "cancel", time.Now()); err != nil {
+		return nil, closedError(err)
+	}
+	uc.guardUC.ReleasePurchase(booking.EventID, booking.Quantity, booking.PurchaseIP)
+	return uc.repo.FindByID(id)
+}
+
+func (uc *groupBookingUsecase) ExpireOverdue() error {
+	now := time.Now()
+	bookings, err := uc.repo.FindOverdue(now, groupBookingExpiryBatch)
+	if err != nil {
+		return err
+	}
+	for _, booking := range bookings {
+		err := uc.repo.Close(booking.ID, models.GroupBookingExpired, "expire", now)
+		if err == nil {
+			uc.guardUC.ReleasePurchase(booking.EventID, booking.Quantity, booking.PurchaseIP)
+		} else if !errors.Is(err, repositories.ErrGroupBookingNotReserved) {
+			log.Printf("failed to expire group booking %d: %v", booking.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"errors"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InboxUsecase interface {
+	ListMessages(userID, beforeID, limit int, unreadOnly bool) (*dto.InboxPage, error)
+	MarkRead(userID, messageID int) (*dto.InboxReadResponse, error)
+	MarkAllRead(userID int) (*dto.InboxReadResponse, error)
+	UnreadCount(userID int) (int64, error)
+	Subscribe(userID int) (<-chan service.StreamEvent, func())
+}
+
+type inboxUsecase struct {
+	repo   repositories.InboxRepository
+	pubsub service.PubSub
+}
+
+func NewInboxUsecase(repo repositories.InboxRepository, pubsub service.PubSub) InboxUsecase {
+	return &inboxUsecase{repo: repo, pubsub: pubsub}
+}
+
+func (uc *inboxUsecase) ListMessages(userID, beforeID, limit int, unreadOnly bool) (*dto.InboxPage, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	// Ambil satu pesan ekstra untuk mengetahui apakah masih ada halaman berikutnya
+	messages, err := uc.repo.ListByUserID(userID, beforeID, limit+1, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := uc.repo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &dto.InboxPage{Messages: messages, UnreadCount: unread}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		next := page.Messages[limit-1].ID
+		page.NextBefore = &next
+	}
+	return page, nil
+}
+
+func (uc *inboxUsecase) MarkRead(userID, messageID int) (*dto.InboxReadResponse, error) {
+	if err := uc.repo.MarkRead(userID, messageID, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("message not found")
+		}
+		return nil, err
+	}
+	return uc.readResponse(userID, 1)
+}
+
+func (uc *inboxUsecase) MarkAllRead(userID int) (*dto.InboxReadResponse, error) {
+	updated, err := uc.repo.MarkAllRead(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return uc.readResponse(userID, updated)
+}
+
+// readResponse menghitung ulang jumlah belum dibaca dan memberitahu tab/perangkat lain milik user
+func (uc *inboxUsecase) readResponse(userID int, updated int64) (*dto.InboxReadResponse, error) {
+	unread, err := uc.repo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	response := &dto.InboxReadResponse{Updated: updated, UnreadCount: unread}
+	uc.pubsub.Publish(service.UserTopic(userID), service.StreamEvent{Type: service.StreamInboxRead, Data: response})
+	return response, nil
+}
+
+func (uc *inboxUsecase) UnreadCount(userID int) (int64, error) {
+	return uc.repo.CountUnread(userID)
+}
+
+func (uc *inboxUsecase) Subscribe(userID int) (<-chan service.StreamEvent, func()) {
+	return uc.pubsub.Subscribe(service.UserTopic(userID))
+}
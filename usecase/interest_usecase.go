@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InterestUsecase interface {
+	AddBookmark(userID, eventID int, accessCode string) error
+	RemoveBookmark(userID, eventID int) error
+	ListBookmarks(userID int) ([]models.EventBookmark, error)
+	FollowOrganizer(userID, organizerID int) error
+	UnfollowOrganizer(userID, organizerID int) error
+	FollowCategory(userID int, category string) error
+	UnfollowCategory(userID int, category string) error
+	ListFollows(userID int) (*dto.FollowListResponse, error)
+	GetFeed(userID int, since time.Time, limit int) ([]models.Event, error)
+	GetOrganizerStats(organizerID int) (*dto.OrganizerStatsResponse, error)
+}
+
+type interestUsecase struct {
+	bookmarkRepo repositories.BookmarkRepository
+	followRepo   repositories.FollowRepository
+	eventRepo    repositories.EventsRepository
+	userRepo     repositories.UserRepository
+	invitationUC InvitationUsecase
+}
+
+func NewInterestUsecase(
+	bookmarkRepo repositories.BookmarkRepository,
+	followRepo repositories.FollowRepository,
+	eventRepo repositories.EventsRepository,
+	userRepo repositories.UserRepository,
+	invitationUC InvitationUsecase,
+) InterestUsecase {
+	return &interestUsecase{
+		bookmarkRepo: bookmarkRepo,
+		followRepo:   followRepo,
+		eventRepo:    eventRepo,
+		userRepo:     userRepo,
+		invitationUC: invitationUC,
+	}
+}
+
+func (uc *interestUsecase) AddBookmark(userID, eventID int, accessCode string) error {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return err
+	}
+
+	allowed, err := uc.invitationUC.CanView(event, userID, accessCode)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("event tidak ditemukan")
+	}
+
+	return uc.bookmarkRepo.Create(&models.EventBookmark{
+		UserID:  userID,
+		EventID: eventID,
+	})
+}
+
+func (uc *interestUsecase) RemoveBookmark(userID, eventID int) error {
+	err := uc.bookmarkRepo.Delete(userID, eventID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("bookmark not found")
+	}
+	return err
+}
+
+func (uc *interestUsecase) ListBookmarks(userID int) ([]models.EventBookmark, error) {
+	return uc.bookmarkRepo.ListByUserID(userID)
+}
+
+func (uc *interestUsecase) FollowOrganizer(userID, organizerID int) error {
+	if userID == organizerID {
+		return errors.New("cannot follow yourself")
+	}
+	if _, err := uc.userRepo.FindByID(organizerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("organizer %d not found", organizerID)
+		}
+		return err
+	}
+
+	return uc.followRepo.Create(&models.Follow{
+		UserID:      userID,
+		TargetType:  models.FollowTargetOrganizer,
+		TargetValue: strconv.Itoa(organizerID),
+	})
+}
+
+func (uc *interestUsecase) UnfollowOrganizer(userID, organizerID int) error {
+	err := uc.followRepo.Delete(userID, models.FollowTargetOrganizer, strconv.Itoa(organizerID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("you are not following this organizer")
+	}
+	return err
+}
+
+func (uc *interestUsecase) FollowCategory(userID int, category string) error {
+	category = normalizeCategory(category)
+	if category == "" {
+		return errors.New("category is required")
+	}
+
+	return uc.followRepo.Create(&models.Follow{
+		UserID:      userID,
+		TargetType:  models.FollowTargetCategory,
+		TargetValue: category,
+	})
+}
+
+func (uc *interestUsecase) UnfollowCategory(userID int, category string) error {
+	err := uc.followRepo.Delete(userID, models.FollowTargetCategory, normalizeCategory(category))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("you are not following this category")
+	}
+	return err
+}
+
+func (uc *interestUsecase) ListFollows(userID int) (*dto.FollowListResponse, error) {
+	follows, err := uc.followRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.FollowListResponse{Organizers: []int{}, Categories: []string{}}
+	for _, follow := range follows {
+		switch follow.TargetType {
+		case models.FollowTargetOrganizer:
+			organizerID, err := strconv.Atoi(follow.TargetValue)
+			if err == nil {
+				response.Organizers = append(response.Organizers, organizerID)
+			}
+		case models.FollowTargetCategory:
+			response.Categories = append(response.Categories, follow.TargetValue)
+		}
+	}
+	return response, nil
+}
+
+func (uc *interestUsecase) GetFeed(userID int, since time.Time, limit int) ([]models.Event, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	return uc.followRepo.FindFeedEvents(userID, since, limit)
+}
+
+func (uc *interestUsecase) GetOrganizerStats(organizerID int) (*dto.OrganizerStatsResponse, error) {
+	followers, err := uc.followRepo.CountFollowers(models.FollowTargetOrganizer, strconv.Itoa(organizerID))
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := uc.bookmarkRepo.CountByOrganizer(organizerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.OrganizerStatsResponse{
+		OrganizerID: organizerID,
+		Followers:   int(followers),
+		Events:      events,
+	}
+	for _, event := range events {
+		response.Bookmarks += event.Bookmarks
+	}
+	return response, nil
+}
+
+// Kategori disimpan lower-case agar "Music" dan "music" dianggap sama
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
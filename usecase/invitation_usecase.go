@@ -0,0 +1,359 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotEventOrganizer dikembalikan saat user yang bukan organizer (atau admin) mencoba mengelola event.
+var ErrNotEventOrganizer = errors.New("only the event organizer can manage this event")
+
+// ErrEventAccessDenied dikembalikan saat user tidak punya akses ke event unlisted/invite-only.
+var ErrEventAccessDenied = errors.New("you do not have access to this event")
+
+type InvitationUsecase interface {
+	InviteUsers(eventID, organizerID int, role string, request dto.CreateInvitationsRequest) ([]models.EventInvitation, error)
+	ListInvitations(eventID, organizerID int, role string) ([]models.EventInvitation, error)
+	RevokeInvitation(eventID, invitationID, organizerID int, role string) error
+	ListMyInvitations(userID int) ([]models.EventInvitation, error)
+
+	CreateInviteLink(eventID, organizerID int, role string, request dto.CreateInviteLinkRequest) (*dto.InviteLinkResponse, error)
+	ListInviteLinks(eventID, organizerID int, role string) ([]dto.InviteLinkResponse, error)
+	RevokeInviteLink(eventID, linkID, organizerID int, role string) error
+	GetEventAccess(eventID, organizerID int, role string) (*dto.EventAccessResponse, error)
+
+	CanView(event *models.Event, userID int, accessCode string) (bool, error)
+	AuthorizeRegistration(event *models.Event, userID int, accessCode string) error
+}
+
+type invitationUsecase struct {
+	repo      repositories.InvitationRepository
+	eventRepo repositories.EventsRepository
+	userRepo  repositories.UserRepository
+}
+
+func NewInvitationUsecase(
+	repo repositories.InvitationRepository,
+	eventRepo repositories.EventsRepository,
+	userRepo repositories.UserRepository,
+) InvitationUsecase {
+	return &invitationUsecase{
+		repo:      repo,
+		eventRepo: eventRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// authorizeOrganizer memastikan user adalah pembuat event atau admin
+func authorizeOrganizer(event *models.Event, userID int, role string) error {
+	if role == "admin" || event.OrganizerID == userID {
+		return nil
+	}
+	return ErrNotEventOrganizer
+}
+
+func (uc *invitationUsecase) findManagedEvent(eventID, organizerID int, role string) (*models.Event, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, organizerID, role); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (uc *invitationUsecase) InviteUsers(eventID, organizerID int, role string, request dto.CreateInvitationsRequest) ([]models.EventInvitation, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+	if len(request.Emails) == 0 && len(request.Usernames) == 0 {
+		return nil, errors.New("at least one email or username is required")
+	}
+
+	var invitations []models.EventInvitation
+	seen := make(map[string]bool)
+
+	addInvitation := func(userID *int, email string) error {
+		key := strings.ToLower(email)
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+
+		existing, err := uc.repo.FindActiveInvitation(eventID, intValue(userID), email)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return nil
+		}
+
+		invitations = append(invitations, models.EventInvitation{
+			EventID:   eventID,
+			UserID:    userID,
+			Email:     email,
+			Status:    models.InvitationStatusPending,
+			InvitedBy: organizerID,
+		})
+		return nil
+	}
+
+	for _, username := range request.Usernames {
+		user, err := uc.userRepo.FindByUsername(strings.TrimSpace(username))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("user %q not found", username)
+			}
+			return nil, err
+		}
+		userID := user.ID
+		if err := addInvitation(&userID, user.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, email := range request.Emails {
+		email = strings.TrimSpace(email)
+		// Hubungkan langsung ke akun jika email sudah terdaftar
+		var userID *int
+		if user, err := uc.userRepo.FindByEmail(email); err == nil {
+			userID = &user.ID
+		}
+		if err := addInvitation(userID, email); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.repo.CreateInvitations(invitations); err != nil {
+		return nil, fmt.Errorf("failed to save invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+func (uc *invitationUsecase) ListInvitations(eventID, organizerID int, role string) ([]models.EventInvitation, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListInvitationsByEvent(eventID)
+}
+
+func (uc *invitationUsecase) RevokeInvitation(eventID, invitationID, organizerID int, role string) error {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return err
+	}
+
+	err := uc.repo.RevokeInvitation(eventID, invitationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("invitation not found")
+	}
+	return err
+}
+
+func (uc *invitationUsecase) ListMyInvitations(userID int) ([]models.EventInvitation, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.repo.ListInvitationsForUser(userID, user.Email)
+}
+
+func (uc *invitationUsecase) CreateInviteLink(eventID, organizerID int, role string, request dto.CreateInviteLinkRequest) (*dto.InviteLinkResponse, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+
+	code, err := utils.GenerateSecureToken(12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite code: %w", err)
+	}
+
+	link := &models.EventInviteLink{
+		EventID:   eventID,
+		Code:      code,
+		MaxUses:   request.MaxUses,
+		CreatedBy: organizerID,
+	}
+	if request.ExpiresInHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(request.ExpiresInHours) * time.Hour)
+		link.ExpiresAt = &expiresAt
+	}
+
+	if err := uc.repo.CreateLink(link); err != nil {
+		return nil, fmt.Errorf("failed to save invite link: %w", err)
+	}
+
+	response := toInviteLinkResponse(*link)
+	return &response, nil
+}
+
+func (uc *invitationUsecase) ListInviteLinks(eventID, organizerID int, role string) ([]dto.InviteLinkResponse, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+
+	links, err := uc.repo.ListLinksByEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.InviteLinkResponse, 0, len(links))
+	for _, link := range links {
+		response = append(response, toInviteLinkResponse(link))
+	}
+	return response, nil
+}
+
+func (uc *invitationUsecase) RevokeInviteLink(eventID, linkID, organizerID int, role string) error {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return err
+	}
+
+	err := uc.repo.RevokeLink(eventID, linkID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("invite link not found")
+	}
+	return err
+}
+
+// GetEventAccess mengembalikan secret key untuk link share event unlisted
+func (uc *invitationUsecase) GetEventAccess(eventID, organizerID int, role string) (*dto.EventAccessResponse, error) {
+	event, err := uc.findManagedEvent(eventID, organizerID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.EventAccessResponse{
+		EventID:    event.ID,
+		Visibility: event.Visibility,
+		AccessKey:  event.AccessKey,
+	}, nil
+}
+
+// CanView memeriksa akses baca tanpa memakai kuota invite link
+func (uc *invitationUsecase) CanView(event *models.Event, userID int, accessCode string) (bool, error) {
+	if event.Visibility == "" || event.Visibility == models.EventVisibilityPublic || event.OrganizerID == userID {
+		return true, nil
+	}
+	if event.Visibility == models.EventVisibilityUnlisted && accessCode != "" && accessCode == event.AccessKey {
+		return true, nil
+	}
+
+	invited, err := uc.isInvited(event.ID, userID)
+	if err != nil || invited {
+		return invited, err
+	}
+
+	if accessCode != "" {
+		link, err := uc.repo.FindLinkByCode(event.ID, accessCode)
+		if err != nil {
+			return false, err
+		}
+		return link != nil && link.IsUsable(time.Now()), nil
+	}
+	return false, nil
+}
+
+// AuthorizeRegistration dipanggil oleh Register. Kode dari invite link ditukar menjadi undangan
+// yang diterima, sehingga pemakaian kuota link hanya terjadi sekali per user.
+func (uc *invitationUsecase) AuthorizeRegistration(event *models.Event, userID int, accessCode string) error {
+	if event.Visibility == "" || event.Visibility == models.EventVisibilityPublic || event.OrganizerID == userID {
+		return nil
+	}
+	if event.Visibility == models.EventVisibilityUnlisted && accessCode != "" && accessCode == event.AccessKey {
+		return nil
+	}
+
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := uc.repo.FindActiveInvitation(event.ID, userID, user.Email)
+	if err != nil {
+		return err
+	}
+	if invitation != nil {
+		if invitation.Status != models.InvitationStatusAccepted {
+			now := time.Now()
+			invitation.Status = models.InvitationStatusAccepted
+			invitation.UserID = &userID
+			invitation.AcceptedAt = &now
+			if err := uc.repo.UpdateInvitation(invitation); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if accessCode == "" {
+		return ErrEventAccessDenied
+	}
+
+	link, err := uc.repo.FindLinkByCode(event.ID, accessCode)
+	if err != nil {
+		return err
+	}
+	if link == nil {
+		return errors.New("invalid access code")
+	}
+
+	now := time.Now()
+	if err := uc.repo.RedeemLink(link.ID, now); err != nil {
+		return err
+	}
+
+	return uc.repo.CreateInvitations([]models.EventInvitation{{
+		EventID:    event.ID,
+		UserID:     &userID,
+		Email:      user.Email,
+		Status:     models.InvitationStatusAccepted,
+		InvitedBy:  link.CreatedBy,
+		AcceptedAt: &now,
+	}})
+}
+
+func (uc *invitationUsecase) isInvited(eventID, userID int) (bool, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	invitation, err := uc.repo.FindActiveInvitation(eventID, userID, user.Email)
+	if err != nil {
+		return false, err
+	}
+	return invitation != nil, nil
+}
+
+func toInviteLinkResponse(link models.EventInviteLink) dto.InviteLinkResponse {
+	response := dto.InviteLinkResponse{
+		ID:        link.ID,
+		Code:      link.Code,
+		MaxUses:   link.MaxUses,
+		UsedCount: link.UsedCount,
+		Revoked:   link.RevokedAt != nil,
+	}
+	if link.ExpiresAt != nil {
+		response.ExpiresAt = link.ExpiresAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+func intValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvoiceNotFound dikembalikan saat invoice tidak ada atau tidak boleh dilihat user.
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+const invoiceListLimit = 200
+
+type InvoiceUsecase interface {
+	// Quote menghitung pajak per baris pesanan dengan setting pajak event
+	Quote(event *models.Event, items []dto.OrderItem) (*dto.OrderQuote, error)
+	// Issue membuat invoice untuk transaksi yang baru dibuat dari hasil Quote
+	Issue(transaction models.Transactions, quote dto.OrderQuote) (*models.Invoice, error)
+
+	GetEventTax(eventID, userID int, role string) (*models.EventTaxSetting, error)
+	SetEventTax(eventID, userID int, role string, input dto.SetEventTaxRequest) (*models.EventTaxSetting, error)
+	DeleteEventTax(eventID, userID int, role string) error
+
+	GetLegalProfile(organizerID int) (*models.OrganizerLegalProfile, error)
+	SetLegalProfile(organizerID int, input dto.SetLegalProfileRequest) (*models.OrganizerLegalProfile, error)
+
+	GetInvoice(id, userID int, role string) (*models.Invoice, error)
+	ListMyInvoices(userID int) ([]models.Invoice, error)
+	ListEventInvoices(eventID, userID int, role string) ([]models.Invoice, error)
+	// UpdateBuyer melengkapi data perusahaan pembeli selama transaksi belum dibayar
+	UpdateBuyer(id, userID int, input dto.InvoiceBuyerRequest) (*models.Invoice, error)
+}
+
+type invoiceUsecase struct {
+	repo      repositories.InvoiceRepository
+	eventRepo repositories.EventsRepository
+	userRepo  repositories.UserRepository
+}
+
+func NewInvoiceUsecase(repo repositories.InvoiceRepository, eventRepo repositories.EventsRepository, userRepo repositories.UserRepository) InvoiceUsecase {
+	return &invoiceUsecase{
+		repo:      repo,
+		eventRepo: eventRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// roundTo membulatkan num/den ke kelipatan unit terdekat (half up); semua nilai positif
+func roundTo(num, den, unit int64) int64 {
+	d := den * unit
+	return (2*num + d) / (2 * d) * unit
+}
+
+// lineTax menghitung net dan pajak satu baris. Pajak dibulatkan per baris ke nominal terkecil
+// mata uang, sehingga jumlah baris selalu sama persis dengan total yang ditagihkan.
+func lineTax(amount int64, mode string, rateBps int, rounding int64) (net, tax int64) {
+	bps := int64(rateBps)
+	switch {
+	case bps == 0:
+		return amount, 0
+	case mode == models.TaxInclusive:
+		tax = roundTo(amount*bps, feeBpsScale+bps, rounding)
+		return amount - tax, tax
+	default:
+		return amount, roundTo(amount*bps, feeBpsScale, rounding)
+	}
+}
+
+func (uc *invoiceUsecase) Quote(event *models.Event, items []dto.OrderItem) (*dto.OrderQuote, error) {
+	currency, err := models.LookupCurrency(event.Currency)
+	if err != nil {
+		return nil, err
+	}
+	setting, err := uc.repo.FindTaxSetting(event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tax setting: %w", err)
+	}
+
+	quote := &dto.OrderQuote{Currency: currency.Code}
+	if setting != nil {
+		quote.TaxMode = setting.Mode
+		quote.TaxName = setting.TaxName
+		quote.TaxRateBps = setting.RateBps
+		quote.TaxID = setting.TaxID
+	}
+
+	for _, item := range items {
+		if item.Quantity <= 0 || item.UnitPrice < 0 {
+			return nil, fmt.Errorf("invalid order item %q", item.Description)
+		}
+		net, tax := lineTax(item.UnitPrice*int64(item.Quantity), quote.TaxMode, quote.TaxRateBps, currency.Rounding)
+		quote.Lines = append(quote.Lines, models.InvoiceLine{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			NetAmount:   net,
+			TaxAmount:   tax,
+			TotalAmount: net + tax,
+		})
+		quote.Subtotal += net
+		quote.TaxTotal += tax
+	}
+	quote.Total = quote.Subtotal + quote.TaxTotal
+	return quote, nil
+}
+
+func (uc *invoiceUsecase) Issue(transaction models.Transactions, quote dto.OrderQuote) (*models.Invoice, error) {
+	event, err := uc.eventRepo.FindEventByID(transaction.EventId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find event %d: %w", transaction.EventId, err)
+	}
+	if transaction.Amount != quote.Total {
+		return nil, fmt.Errorf("transaction amount %d does not match quoted total %d", transaction.Amount, quote.Total)
+	}
+
+	issuedAt := time.Now()
+	invoice := &models.Invoice{
+		Number:        fmt.Sprintf("INV-%s-%06d", issuedAt.Format("20060102"), transaction.ID),
+		TransactionID: transaction.ID,
+		OrderID:       transaction.PaymentGatewayTransactionId,
+		EventID:       event.ID,
+		OrganizerID:   event.OrganizerID,
+		UserID:        transaction.UserId,
+		Currency:      quote.Currency,
+		TaxMode:       quote.TaxMode,
+		TaxName:       quote.TaxName,
+		TaxRateBps:    quote.TaxRateBps,
+		Subtotal:      quote.Subtotal,
+		TaxTotal:      quote.TaxTotal,
+		Total:         quote.Total,
+		SellerTaxID:   quote.TaxID,
+		IssuedAt:      issuedAt,
+		Lines:         quote.Lines,
+	}
+
+	profile, err := uc.repo.FindLegalProfile(event.OrganizerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organizer legal profile: %w", err)
+	}
+	if profile != nil {
+		invoice.SellerName = profile.LegalName
+		invoice.SellerAddress = profile.Address
+		if invoice.SellerTaxID == "" {
+			invoice.SellerTaxID = profile.TaxID
+		}
+	}
+	if buyer, err := uc.userRepo.FindByID(transaction.UserId); err == nil {
+		invoice.BuyerName = buyer.Name
+		invoice.BuyerEmail = buyer.Email
+	}
+
+	if err := uc.repo.Create(invoice); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (uc *invoiceUsecase) findManagedEvent(eventID, userID int, role string) (*models.Event, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, userID, role); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (uc *invoiceUsecase) GetEventTax(eventID, userID int, role string) (*models.EventTaxSetting, error) {
+	if _, err := uc.findManagedEvent(eventID, userID, role); err != nil {
+		return nil, err
+	}
+	setting, err := uc.repo.FindTaxSetting(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil {
+		return nil, errors.New("event has no tax setting")
+	}
+	return setting, nil
+}
+
+func (uc *invoiceUsecase) SetEventTax(eventID, userID int, role string, input dto.SetEventTaxRequest) (*models.EventTaxSetting, error) {
+	if _, err := uc.findManagedEvent(eventID, userID, role); err != nil {
+		return nil, err
+	}
+	taxName := strings.TrimSpace(input.TaxName)
+	if taxName == "" {
+		taxName = "PPN"
+	}
+	setting := &models.EventTaxSetting{
+		EventID: eventID,
+		Mode:    input.Mode,
+		RateBps: int(math.Round(input.Rate * 100)),
+		TaxName: taxName,
+		TaxID:   strings.TrimSpace(input.TaxID),
+	}
+	if err := uc.repo.SaveTaxSetting(setting); err != nil {
+		return nil, err
+	}
+	return uc.repo.FindTaxSetting(eventID)
+}
+
+func (uc *invoiceUsecase) DeleteEventTax(eventID, userID int, role string) error {
+	if _, err := uc.findManagedEvent(eventID, userID, role); err != nil {
+		return err
+	}
+	deleted, err := uc.repo.DeleteTaxSetting(eventID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("event has no tax setting")
+	}
+	return nil
+}
+
+func (uc *invoiceUsecase) GetLegalProfile(organizerID int) (*models.OrganizerLegalProfile, error) {
+	profile, err := uc.repo.FindLegalProfile(organizerID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, errors.New("legal profile not set")
+	}
+	return profile, nil
+}
+
+func (uc *invoiceUsecase) SetLegalProfile(organizerID int, input dto.SetLegalProfileRequest) (*models.OrganizerLegalProfile, error) {
+	profile := &models.OrganizerLegalProfile{
+		OrganizerID: organizerID,
+		LegalName:   strings.TrimSpace(input.LegalName),
+		TaxID:       strings.TrimSpace(input.TaxID),
+		Address:     strings.TrimSpace(input.Address),
+		Email:       strings.TrimSpace(input.Email),
+		Phone:       strings.TrimSpace(input.Phone),
+	}
+	if err := uc.repo.SaveLegalProfile(profile); err != nil {
+		return nil, err
+	}
+	return uc.repo.FindLegalProfile(organizerID)
+}
+
+func (uc *invoiceUsecase) GetInvoice(id, userID int, role string) (*models.Invoice, error) {
+	invoice, err := uc.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Invoice hanya terlihat oleh pembeli, organizer event dan admin
+	if role != "admin" && invoice.UserID != userID && invoice.OrganizerID != userID {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (uc *invoiceUsecase) ListMyInvoices(userID int) ([]models.Invoice, error) {
+	return uc.repo.ListByUser(userID, invoiceListLimit)
+}
+
+func (uc *invoiceUsecase) ListEventInvoices(eventID, userID int, role string) ([]models.Invoice, error) {
+	if _, err := uc.findManagedEvent(eventID, userID, role); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListByEvent(eventID, invoiceListLimit)
+}
+
+func (uc *invoiceUsecase) UpdateBuyer(id, userID int, input dto.InvoiceBuyerRequest) (*models.Invoice, error) {
+	updated, err := uc.repo.UpdateBuyer(id, userID, dto.InvoiceBuyerRequest{
+		Company: strings.TrimSpace(input.Company),
+		TaxID:   strings.TrimSpace(input.TaxID),
+		Address: strings.TrimSpace(input.Address),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		invoice, err := uc.repo.FindByID(id)
+		if err != nil || invoice.UserID != userID {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, errors.New("buyer details can only be changed while the transaction is pending")
+	}
+	return uc.repo.FindByID(id)
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// taxSettingRepo hanya mengimplementasikan FindTaxSetting; method lain tidak dipakai Quote
+type taxSettingRepo struct {
+	repositories.InvoiceRepository
+	setting *models.EventTaxSetting
+}
+
+func (r taxSettingRepo) FindTaxSetting(eventID int) (*models.EventTaxSetting, error) {
+	return r.setting, nil
+}
+
+func TestLineTax(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		mode     string
+		rateBps  int
+		rounding int64
+		wantNet  int64
+		wantTax  int64
+	}{
+		{"no tax", 15000000, models.TaxExclusive, 0, 100, 15000000, 0},
+		{"no mode is exclusive", 15000000, "", 1100, 100, 15000000, 1650000},
+		{"exclusive IDR exact", 15000000, models.TaxExclusive, 1100, 100, 15000000, 1650000},
+		{"exclusive IDR rounds to whole rupiah", 9999900, models.TaxExclusive, 1100, 100, 9999900, 1100000},
+		{"exclusive IDR half rupiah rounds up", 500, models.TaxExclusive, 1000, 100, 500, 100},
+		{"exclusive IDR below half rounds down", 400, models.TaxExclusive, 1000, 100, 400, 0},
+		{"exclusive USD rounds to cent", 999, models.TaxExclusive, 825, 1, 999, 82},
+		{"exclusive USD half cent rounds up", 1000, models.TaxExclusive, 825, 1, 1000, 83},
+		{"inclusive IDR exact", 11100000, models.TaxInclusive, 1100, 100, 10000000, 1100000},
+		{"inclusive IDR rounds to whole rupiah", 10000000, models.TaxInclusive, 1100, 100, 9009000, 991000},
+		{"inclusive USD rounds to cent", 1999, models.TaxInclusive, 1000, 1, 1817, 182},
+		{"zero amount", 0, models.TaxInclusive, 1100, 100, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, tax := lineTax(tt.amount, tt.mode, tt.rateBps, tt.rounding)
+			if net != tt.wantNet || tax != tt.wantTax {
+				t.Fatalf("lineTax(%d) = (%d, %d), want (%d, %d)", tt.amount, net, tax, tt.wantNet, tt.wantTax)
+			}
+			if tt.mode == models.TaxInclusive && net+tax != tt.amount {
+				t.Fatalf("inclusive net+tax = %d, want %d", net+tax, tt.amount)
+			}
+			if tax%tt.rounding != 0 {
+				t.Fatalf("tax %d is not a multiple of %d", tax, tt.rounding)
+			}
+		})
+	}
+}
+
+func TestQuoteRoundsPerLine(t *testing.T) {
+	// Tiga tiket Rp6 dengan PPN 10%: pajak per baris (Rp0,6 exclusive, Rp0,55 inclusive) dibulatkan
+	// menjadi Rp1, sehingga total pajak Rp3, sedangkan pembulatan di level invoice menghasilkan Rp2.
+	// Quote membulatkan per baris agar jumlah baris selalu sama dengan total yang ditagihkan.
+	items := []dto.OrderItem{
+		{Description: "A", Quantity: 1, UnitPrice: 600},
+		{Description: "B", Quantity: 1, UnitPrice: 600},
+		{Description: "C", Quantity: 1, UnitPrice: 600},
+	}
+	tests := []struct {
+		name         string
+		mode         string
+		wantSubtotal int64
+		wantTax      int64
+		wantTotal    int64
+	}{
+		{"exclusive", models.TaxExclusive, 1800, 300, 2100},
+		{"inclusive", models.TaxInclusive, 1500, 300, 1800},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &invoiceUsecase{repo: taxSettingRepo{setting: &models.EventTaxSetting{Mode: tt.mode, RateBps: 1000, TaxName: "PPN"}}}
+			quote, err := uc.Quote(&models.Event{ID: 1, Currency: "IDR"}, items)
+			if err != nil {
+				t.Fatalf("Quote: %v", err)
+			}
+			if quote.Subtotal != tt.wantSubtotal || quote.TaxTotal != tt.wantTax || quote.Total != tt.wantTotal {
+				t.Fatalf("quote = (%d, %d, %d), want (%d, %d, %d)", quote.Subtotal, quote.TaxTotal, quote.Total, tt.wantSubtotal, tt.wantTax, tt.wantTotal)
+			}
+			var sum int64
+			for _, line := range quote.Lines {
+				if line.NetAmount+line.TaxAmount != line.TotalAmount {
+					t.Fatalf("line %q net+tax = %d, want %d", line.Description, line.NetAmount+line.TaxAmount, line.TotalAmount)
+				}
+				sum += line.TotalAmount
+			}
+			if sum != quote.Total {
+				t.Fatalf("sum of lines = %d, want total %d", sum, quote.Total)
+			}
+			if _, invoiceLevel := lineTax(1800, tt.mode, 1000, 100); invoiceLevel != 200 {
+				t.Fatalf("invoice-level tax = %d, want 200", invoiceLevel)
+			} else if invoiceLevel == quote.TaxTotal {
+				t.Fatalf("expected invoice-level rounding (%d) to differ from line-level %d", invoiceLevel, quote.TaxTotal)
+			}
+		})
+	}
+}
+
+func TestMidtransItemsMatchGrossAmount(t *testing.T) {
+	items := []dto.OrderItem{
+		{Description: "Regular", Quantity: 2, UnitPrice: 15000000},
+		{Description: "VIP", Quantity: 1, UnitPrice: 9999900},
+		{Description: "Early bird", Quantity: 3, UnitPrice: 500},
+	}
+	transaction := &transactionUsecase{midtransService: service.NewMidtransService(resty.New(), "test-key")}
+	tests := []struct {
+		name    string
+		setting *models.EventTaxSetting
+	}{
+		{"no tax", nil},
+		{"exclusive", &models.EventTaxSetting{Mode: models.TaxExclusive, RateBps: 1100, TaxName: "PPN"}},
+		{"inclusive", &models.EventTaxSetting{Mode: models.TaxInclusive, RateBps: 1100, TaxName: "PPN"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := &invoiceUsecase{repo: taxSettingRepo{setting: tt.setting}}
+			quote, err := invoice.Quote(&models.Event{ID: 1, Currency: "IDR"}, items)
+			if err != nil {
+				t.Fatalf("Quote: %v", err)
+			}
+			details, err := transaction.midtransItems(*quote)
+			if err != nil {
+				t.Fatalf("midtransItems: %v", err)
+			}
+			var sum int
+			for _, item := range details {
+				sum += item.Price * item.Quantity
+			}
+			if want := int(quote.Total / 100); sum != want {
+				t.Fatalf("item sum = %d, want gross amount %d", sum, want)
+			}
+		})
+	}
+}
+
+func TestMidtransItemsRejectsMismatch(t *testing.T) {
+	transaction := &transactionUsecase{midtransService: service.NewMidtransService(resty.New(), "test-key")}
+	tests := []struct {
+		name  string
+		quote dto.OrderQuote
+	}{
+		{"inclusive line total differs from unit price", dto.OrderQuote{
+			Currency: "IDR", TaxMode: models.TaxInclusive, TaxRateBps: 1100,
+			Lines: []models.InvoiceLine{{Description: "A", Quantity: 1, UnitPrice: 10000, NetAmount: 9000, TaxAmount: 900, TotalAmount: 9900}},
+			Total: 9900,
+		}},
+		{"total differs from item sum", dto.OrderQuote{
+			Currency: "IDR",
+			Lines:    []models.InvoiceLine{{Description: "A", Quantity: 2, UnitPrice: 10000, NetAmount: 20000, TotalAmount: 20000}},
+			Subtotal: 20000, Total: 30000,
+		}},
+		{"tax not in whole rupiah", dto.OrderQuote{
+			Currency: "IDR", TaxMode: models.TaxExclusive, TaxRateBps: 1100,
+			Lines:    []models.InvoiceLine{{Description: "A", Quantity: 1, UnitPrice: 10000, NetAmount: 10000, TaxAmount: 1050, TotalAmount: 11050}},
+			Subtotal: 10000, TaxTotal: 1050, Total: 11050,
+		}},
+		{"unsupported currency", dto.OrderQuote{
+			Currency: "USD",
+			Lines:    []models.InvoiceLine{{Description: "A", Quantity: 1, UnitPrice: 999, NetAmount: 999, TotalAmount: 999}},
+			Subtotal: 999, Total: 999,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := transaction.midtransItems(tt.quote); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"sync"
+	"time"
+)
+
+// ErrUnbalancedEntry dikembalikan saat journal entry yang akan dicatat tidak berjumlah nol.
+var ErrUnbalancedEntry = errors.New("journal entry is not balanced")
+
+// ErrLedgerCurrency dikembalikan untuk transaksi yang mata uangnya berbeda dari mata uang ledger.
+var ErrLedgerCurrency = errors.New("transaction currency does not match the ledger currency")
+
+const ledgerCheckLimit = 100
+
+type LedgerUsecase interface {
+	// RecordSettlement: Dr gateway_clearing / Cr organizer. Idempoten per order id.
+	RecordSettlement(transaction models.Transactions) error
+	// RecordPlatformFee: Dr organizer / Cr platform_revenue untuk fee (minor unit) atas satu order.
+	RecordPlatformFee(transaction models.Transactions, fee int64) error
+	// RecordRefund membebankan refund ke organizer lalu mencatat pembayarannya oleh gateway:
+	// Dr organizer / Cr refunds, kemudian Dr refunds / Cr gateway_clearing.
+	RecordRefund(transaction models.Transactions, amount int64, reference string) error
+	// RecordPayout: Dr organizer / Cr gateway_clearing saat dana dikirim ke rekening organizer.
+	RecordPayout(organizerID int, amount int64, reference string) error
+	OrganizerBalance(organizerID int) (*dto.LedgerBalance, error)
+	AccountBalances(role string) ([]dto.LedgerBalance, error)
+	Check(role string) (*dto.LedgerCheckResult, error)
+	// CheckInvariants dipakai job periodik; mengembalikan error jika ledger tidak konsisten
+	CheckInvariants() error
+}
+
+type ledgerUsecase struct {
+	repo      repositories.LedgerRepository
+	eventRepo repositories.EventsRepository
+	// cache id akun per kode; akun tidak pernah dihapus
+	accounts sync.Map
+}
+
+func NewLedgerUsecase(repo repositories.LedgerRepository, eventRepo repositories.EventsRepository) LedgerUsecase {
+	return &ledgerUsecase{repo: repo, eventRepo: eventRepo}
+}
+
+// ledgerAmount mengembalikan nominal transaksi dalam minor unit ledger. Transaksi lama tanpa
+// mata uang dianggap IDR.
+func ledgerAmount(transaction models.Transactions) (int64, error) {
+	if transaction.Currency != "" && transaction.Currency != models.LedgerCurrency {
+		return 0, fmt.Errorf("%w: %s", ErrLedgerCurrency, transaction.Currency)
+	}
+	return transaction.Amount, nil
+}
+
+// ledgerLine adalah satu baris entry sebelum akunnya di-resolve; amount debit positif, kredit negatif
+type ledgerLine struct {
+	account models.LedgerAccount
+	amount  int64
+}
+
+func (uc *ledgerUsecase) account(code, accountType string, organizerID *int) (models.LedgerAccount, error) {
+	if cached, ok := uc.accounts.Load(code); ok {
+		return cached.(models.LedgerAccount), nil
+	}
+
+	account := models.LedgerAccount{
+		Code:        code,
+		Type:        accountType,
+		OrganizerID: organizerID,
+		Currency:    models.LedgerCurrency,
+	}
+	if err := uc.repo.EnsureAccount(&account); err != nil {
+		return models.LedgerAccount{}, fmt.Errorf("failed to open ledger account %s: %w", code, err)
+	}
+	uc.accounts.Store(code, account)
+	return account, nil
+}
+
+func (uc *ledgerUsecase) organizerAccount(organizerID int) (models.LedgerAccount, error) {
+	return uc.account(models.OrganizerAccountCode(organizerID), models.LedgerLiability, &organizerID)
+}
+
+func (uc *ledgerUsecase) post(kind, reference string, eventID *int, description string, lines ...ledgerLine) error {
+	var total int64
+	entry := &models.JournalEntry{
+		Kind:        kind,
+		Reference:   reference,
+		EventID:     eventID,
+		Description: description,
+	}
+	for _, line := range lines {
+		if line.amount == 0 {
+			continue
+		}
+		total += line.amount
+		entry.Postings = append(entry.Postings, models.LedgerPosting{AccountID: line.account.ID, Amount: line.amount})
+	}
+	if total != 0 || len(entry.Postings) < 2 {
+		return fmt.Errorf("%w: %s %s", ErrUnbalancedEntry, kind, reference)
+	}
+
+	// Entry duplikat (kind + reference sama) diabaikan agar pemanggil boleh mengulang
+	_, err := uc.repo.CreateEntry(entry)
+	return err
+}
+
+// transfer adalah entry dua baris: debit satu akun, kredit akun lain sebesar amount
+func (uc *ledgerUsecase) transfer(kind, reference string, eventID *int, description string, debit, credit models.LedgerAccount, amount int64) error {
+	if amount <= 0 {
+		return fmt.Errorf("%s %s: amount must be positive", kind, reference)
+	}
+	return uc.post(kind, reference, eventID, description,
+		ledgerLine{account: debit, amount: amount},
+		ledgerLine{account: credit, amount: -amount},
+	)
+}
+
+func (uc *ledgerUsecase) eventOrganizer(eventID int) (int, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find event %d: %w", eventID, err)
+	}
+	return event.OrganizerID, nil
+}
+
+func (uc *ledgerUsecase) RecordSettlement(transaction models.Transactions) error {
+	amount, err := ledgerAmount(transaction)
+	if err != nil {
+		return err
+	}
+	organizerID, err := uc.eventOrganizer(transaction.EventId)
+	if err != nil {
+		return err
+	}
+	clearing, err := uc.account(models.LedgerGatewayClearing, models.LedgerAsset, nil)
+	if err != nil {
+		return err
+	}
+	organizer, err := uc.organizerAccount(organizerID)
+	if err != nil {
+		return err
+	}
+
+	eventID := transaction.EventId
+	return uc.transfer(models.JournalSettlement, transaction.PaymentGatewayTransactionId, &eventID,
+		transaction.Items, clearing, organizer, amount)
+}
+
+func (uc *ledgerUsecase) RecordPlatformFee(transaction models.Transactions, fee int64) error {
+	if fee == 0 {
+		return nil
+	}
+	organizerID, err := uc.eventOrganizer(transaction.EventId)
+	if err != nil {
+		return err
+	}
+	organizer, err := uc.organizerAccount(organizerID)
+	if err != nil {
+		return err
+	}
+	revenue, err := uc.account(models.LedgerPlatformRevenue, models.LedgerRevenue, nil)
+	if err != nil {
+		return err
+	}
+
+	eventID := transaction.EventId
+	return uc.transfer(models.JournalPlatformFee, transaction.PaymentGatewayTransactionId, &eventID,
+		"Platform fee", organizer, revenue, fee)
+}
+
+func (uc *ledgerUsecase) RecordRefund(transaction models.Transactions, amount int64, reference string) error {
+	if _, err := ledgerAmount(transaction); err != nil {
+		return err
+	}
+	organizerID, err := uc.eventOrganizer(transaction.EventId)
+	if err != nil {
+		return err
+	}
+	organizer, err := uc.organizerAccount(organizerID)
+	if err != nil {
+		return err
+	}
+	refunds, err := uc.account(models.LedgerRefunds, models.LedgerLiability, nil)
+	if err != nil {
+		return err
+	}
+	clearing, err := uc.account(models.LedgerGatewayClearing, models.LedgerAsset, nil)
+	if err != nil {
+		return err
+	}
+
+	eventID := transaction.EventId
+	description := "Refund " + transaction.PaymentGatewayTransactionId
+	if err := uc.transfer(models.JournalRefund, reference, &eventID, description, organizer, refunds, amount); err != nil {
+		return err
+	}
+	return uc.transfer(models.JournalRefundPaid, reference, &eventID, description, refunds, clearing, amount)
+}
+
+func (uc *ledgerUsecase) RecordPayout(organizerID int, amount int64, reference string) error {
+	organizer, err := uc.organizerAccount(organizerID)
+	if err != nil {
+		return err
+	}
+	clearing, err := uc.account(models.LedgerGatewayClearing, models.LedgerAsset, nil)
+	if err != nil {
+		return err
+	}
+	return uc.transfer(models.JournalPayout, reference, nil,
+		fmt.Sprintf("Payout to organizer %d", organizerID), organizer, clearing, amount)
+}
+
+func (uc *ledgerUsecase) OrganizerBalance(organizerID int) (*dto.LedgerBalance, error) {
+	balances, err := uc.repo.Balances(&organizerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(balances) == 0 {
+		// Belum ada settlement: saldo nol tanpa membuat akun
+		return &dto.LedgerBalance{
+			Code:        models.OrganizerAccountCode(organizerID),
+			Type:        models.LedgerLiability,
+			OrganizerID: &organizerID,
+			Currency:    models.LedgerCurrency,
+		}, nil
+	}
+	balance := withNormalBalance(balances[0])
+	return &balance, nil
+}
+
+func (uc *ledgerUsecase) AccountBalances(role string) ([]dto.LedgerBalance, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	balances, err := uc.repo.Balances(nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range balances {
+		balances[i] = withNormalBalance(balances[i])
+	}
+	return balances, nil
+}
+
+func withNormalBalance(balance dto.LedgerBalance) dto.LedgerBalance {
+	switch balance.Type {
+	case models.LedgerAsset, models.LedgerExpense:
+		balance.Balance = balance.Debits - balance.Credits
+	default:
+		balance.Balance = balance.Credits - balance.Debits
+	}
+	return balance
+}
+
+func (uc *ledgerUsecase) Check(role string) (*dto.LedgerCheckResult, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	return uc.check()
+}
+
+func (uc *ledgerUsecase) check() (*dto.LedgerCheckResult, error) {
+	trialBalance, err := uc.repo.TrialBalance()
+	if err != nil {
+		return nil, err
+	}
+	unbalanced, err := uc.repo.UnbalancedEntries(ledgerCheckLimit)
+	if err != nil {
+		return nil, err
+	}
+	mismatches, err := uc.repo.UnrecordedSettlements(ledgerCheckLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.LedgerCheckResult{
+		OK:                   trialBalance == 0 && len(unbalanced) == 0 && len(mismatches) == 0,
+		CheckedAt:            time.Now(),
+		TrialBalance:         trialBalance,
+		UnbalancedEntries:    unbalanced,
+		SettlementMismatches: mismatches,
+	}, nil
+}
+
+func (uc *ledgerUsecase) CheckInvariants() error {
+	result, err := uc.check()
+	if err != nil {
+		return err
+	}
+	if !result.OK {
+		return fmt.Errorf("ledger invariant violated: trial balance %d, %d unbalanced entr(ies), %d settlement mismatch(es)",
+			result.TrialBalance, len(result.UnbalancedEntries), len(result.SettlementMismatches))
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"fmt"
+	"gatherly-app/models"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// notificationText adalah template subject dan body untuk satu tipe notifikasi.
+// Data template adalah payload outbox (map[string]any).
+type notificationText struct {
+	Subject string
+	Body    string
+}
+
+var notificationTexts = map[string]map[string]notificationText{
+	models.LocaleIndonesian: {
+		models.NotificationRegistrationConfirmed: {
+			Subject: "Pendaftaran {{.event_name}} berhasil",
+			Body: `Halo {{.user_name}},
+
+Pendaftaran kamu untuk {{.event_name}} ({{.ticket_type}}) sudah kami terima.
+Event dimulai {{date .event_start}}.
+{{- if .payment_required}}
+
+Selesaikan pembayaran agar tiket kamu aktif.{{end}}`,
+		},
+		models.NotificationPaymentReceived: {
+			Subject: "Pembayaran {{.order_id}} diterima",
+			Body: `Halo {{.user_name}},
+
+Pembayaran sebesar {{money .amount .currency}} untuk {{.items}} sudah kami terima. Terima kasih!`,
+		},
+		models.NotificationPaymentExpiring: {
+			Subject: "Pembayaran {{.order_id}} segera kedaluwarsa",
+			Body: `Halo {{.user_name}},
+
+Pembayaran sebesar {{money .amount .currency}} untuk {{.items}} akan kedaluwarsa pada {{date .expires_at}}.
+{{- if .payment_url}}
+Bayar sekarang: {{.payment_url}}{{end}}`,
+		},
+		models.NotificationPaymentExpired: {
+			Subject: "Pembayaran {{.order_id}} kedaluwarsa",
+			Body: `Halo {{.user_name}},
+
+Pembayaran sebesar {{money .amount .currency}} untuk {{.items}} tidak kami terima sampai batas waktu dan telah dibatalkan.
+{{- if .registration_released}}
+Pendaftaranmu untuk {{.event_name}} ikut dibatalkan. Silakan daftar ulang jika masih ingin hadir.{{end}}`,
+		},
+		models.NotificationEventReminder24h: {
+			Subject: "Besok: {{.event_name}}",
+			Body: `Halo {{.user_name}},
+
+Jangan lupa, {{.event_name}} dimulai {{date .event_start}}. Sampai jumpa!`,
+		},
+		models.NotificationEventReminder1h: {
+			Subject: "1 jam lagi: {{.event_name}}",
+			Body: `Halo {{.user_name}},
+
+{{.event_name}} dimulai {{date .event_start}}. Siapkan tiket kamu untuk check-in.`,
+		},
+		models.NotificationEventChanged: {
+			Subject: "Perubahan jadwal {{.event_name}}",
+			Body: `Halo {{.user_name}},
+
+Penyelenggara mengubah detail {{.event_name}}.
+Mulai: {{date .event_start}}
+Selesai: {{date .event_end}}
+{{- if .location_changed}}
+Lokasi event juga berubah, cek detail event untuk lokasi terbaru.{{end}}`,
+		},
+		models.NotificationEventCancelled: {
+			Subject: "{{.event_name}} dibatalkan",
+			Body: `Halo {{.user_name}},
+
+Mohon maaf, {{.event_name}} yang dijadwalkan {{date .event_start}} dibatalkan oleh penyelenggara.`,
+		},
+	},
+	models.LocaleEnglish: {
+		models.NotificationRegistrationConfirmed: {
+			Subject: "You're registered for {{.event_name}}",
+			Body: `Hi {{.user_name}},
+
+We have received your registration for {{.event_name}} ({{.ticket_type}}).
+The event starts {{date .event_start}}.
+{{- if .payment_required}}
+
+Please complete your payment to activate your ticket.{{end}}`,
+		},
+		models.NotificationPaymentReceived: {
+			Subject: "Payment {{.order_id}} received",
+			Body: `Hi {{.user_name}},
+
+We have received your payment of {{money .amount .currency}} for {{.items}}. Thank you!`,
+		},
+		models.NotificationPaymentExpiring: {
+			Subject: "Payment {{.order_id}} is about to expire",
+			Body: `Hi {{.user_name}},
+
+Your payment of {{money .amount .currency}} for {{.items}} expires on {{date .expires_at}}.
+{{- if .payment_url}}
+Pay now: {{.payment_url}}{{end}}`,
+		},
+		models.NotificationPaymentExpired: {
+			Subject: "Payment {{.order_id}} has expired",
+			Body: `Hi {{.user_name}},
+
+We did not receive your payment of {{money .amount .currency}} for {{.items}} in time, so it has been cancelled.
+{{- if .registration_released}}
+Your registration for {{.event_name}} was released as well. Feel free to register again if you still want to attend.{{end}}`,
+		},
+		models.NotificationEventReminder24h: {
+			Subject: "Tomorrow: {{.event_name}}",
+			Body: `Hi {{.user_name}},
+
+A reminder that {{.event_name}} starts {{date .event_start}}. See you there!`,
+		},
+		models.NotificationEventReminder1h: {
+			Subject: "Starting in 1 hour: {{.event_name}}",
+			Body: `Hi {{.user_name}},
+
+{{.event_name}} starts {{date .event_start}}. Have your ticket ready for check-in.`,
+		},
+		models.NotificationEventChanged: {
+			Subject: "{{.event_name}} has been updated",
+			Body: `Hi {{.user_name}},
+
+The organizer changed the details of {{.event_name}}.
+Starts: {{date .event_start}}
+Ends: {{date .event_end}}
+{{- if .location_changed}}
+The location has changed too, check the event page for the new venue.{{end}}`,
+		},
+		models.NotificationEventCancelled: {
+			Subject: "{{.event_name}} has been cancelled",
+			Body: `Hi {{.user_name}},
+
+We're sorry, {{.event_name}} scheduled for {{date .event_start}} has been cancelled by the organizer.`,
+		},
+	},
+}
+
+type compiledNotification struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// notificationTemplates di-parse sekali saat package dimuat; template yang salah langsung panic saat start
+var notificationTemplates = compileNotificationTemplates()
+
+func compileNotificationTemplates() map[string]map[string]compiledNotification {
+	compiled := make(map[string]map[string]compiledNotification, len(notificationTexts))
+	for locale, texts := range notificationTexts {
+		funcs := notificationFuncs(locale)
+		compiled[locale] = make(map[string]compiledNotification, len(texts))
+		for notificationType, text := range texts {
+			name := locale + "/" + notificationType
+			compiled[locale][notificationType] = compiledNotification{
+				subject: template.Must(template.New(name + "/subject").Funcs(funcs).Parse(text.Subject)),
+				body:    template.Must(template.New(name + "/body").Funcs(funcs).Parse(text.Body)),
+			}
+		}
+	}
+	return compiled
+}
+
+// renderNotification menghasilkan subject dan body sesuai bahasa user; bahasa yang tidak dikenal memakai Indonesia
+func renderNotification(locale, notificationType string, payload map[string]any) (string, string, error) {
+	templates, ok := notificationTemplates[locale]
+	if !ok {
+		templates = notificationTemplates[models.LocaleIndonesian]
+	}
+	compiled, ok := templates[notificationType]
+	if !ok {
+		return "", "", fmt.Errorf("no template for notification type %s", notificationType)
+	}
+
+	var subject, body strings.Builder
+	if err := compiled.subject.Execute(&subject, payload); err != nil {
+		return "", "", err
+	}
+	if err := compiled.body.Execute(&body, payload); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
+
+var indonesianMonths = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+func notificationFuncs(locale string) template.FuncMap {
+	return template.FuncMap{
+		// date menerima waktu RFC3339 (hasil serialisasi payload) dan memformatnya sesuai bahasa
+		"date": func(value any) string {
+			t, ok := value.(time.Time)
+			if !ok {
+				s, _ := value.(string)
+				parsed, err := time.Parse(time.RFC3339, s)
+				if err != nil {
+					return s
+				}
+				t = parsed
+			}
+			if locale == models.LocaleEnglish {
+				return t.Format("Monday, January 2, 2006 15:04 MST")
+			}
+			return fmt.Sprintf("%d %s %d pukul %s", t.Day(), indonesianMonths[t.Month()-1], t.Year(), t.Format("15:04 MST"))
+		},
+		// money memformat nominal minor unit: "Rp150.000" (id) atau "IDR 150,000" (en).
+		// Payload yang diantrekan sebelum multi-currency tidak punya currency dan berisi rupiah desimal.
+		"money": func(value, currency any) string {
+			var amount float64
+			switch v := value.(type) {
+			case float64:
+				amount = v
+			case int:
+				amount = float64(v)
+			case int64:
+				amount = float64(v)
+			}
+			code, _ := currency.(string)
+			if code == "" {
+				legacy, _ := models.MoneyFromMajor(amount, models.DefaultCurrency)
+				return legacy.Format(locale)
+			}
+			return models.Money{Amount: int64(math.Round(amount)), Currency: code}.Format(locale)
+		},
+	}
+}
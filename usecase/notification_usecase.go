@@ -0,0 +1,329 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"log"
+	"sort"
+	"time"
+)
+
+const (
+	maxNotificationAttempts = 5
+	notificationBatchSize   = 100
+	notificationStaleAfter  = 10 * time.Minute
+	// paymentExpiringNotice: pengingat pembayaran dikirim sejak satu jam sebelum transaksi kedaluwarsa
+	paymentExpiringNotice = time.Hour
+)
+
+// notificationChannels adalah channel yang bisa diatur user, terlepas dari channel yang aktif di server
+var notificationChannels = []string{models.ChannelEmail, models.ChannelInbox}
+
+type NotificationUsecase interface {
+	Notify(userIDs []int, notificationType string, payload map[string]any, dedupKey string) error
+	DispatchOutbox() error
+	ScheduleReminders() error
+	GetPreferences(userID int) (*dto.NotificationPreferencesResponse, error)
+	UpdatePreferences(userID int, request dto.UpdateNotificationPreferencesRequest) (*dto.NotificationPreferencesResponse, error)
+}
+
+type notificationUsecase struct {
+	repo          repositories.NotificationRepository
+	userRepo      repositories.UserRepository
+	paymentExpiry time.Duration
+	channels      map[string]service.MessageChannel
+}
+
+func NewNotificationUsecase(
+	repo repositories.NotificationRepository,
+	userRepo repositories.UserRepository,
+	paymentExpiry time.Duration,
+	channels ...service.MessageChannel,
+) NotificationUsecase {
+	registered := make(map[string]service.MessageChannel, len(channels))
+	for _, channel := range channels {
+		registered[channel.Name()] = channel
+	}
+	return &notificationUsecase{
+		repo:          repo,
+		userRepo:      userRepo,
+		paymentExpiry: paymentExpiry,
+		channels:      registered,
+	}
+}
+
+// Notify menaruh notifikasi ke outbox untuk setiap user pada setiap channel aktif yang tidak dimatikan user.
+// dedupKey mengidentifikasi kejadian sumbernya (mis. order id) sehingga pemanggilan ulang tidak mengirim dua kali.
+func (uc *notificationUsecase) Notify(userIDs []int, notificationType string, payload map[string]any, dedupKey string) error {
+	if _, ok := notificationTemplates[models.LocaleIndonesian][notificationType]; !ok {
+		return fmt.Errorf("unknown notification type %s", notificationType)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	disabled, err := uc.repo.DisabledChannels(userIDs, notificationType)
+	if err != nil {
+		return fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+
+	channels := make([]string, 0, len(uc.channels))
+	for name := range uc.channels {
+		channels = append(channels, name)
+	}
+	sort.Strings(channels)
+
+	now := time.Now()
+	messages := make([]models.NotificationOutbox, 0, len(userIDs)*len(channels))
+	for _, userID := range userIDs {
+		for _, channel := range channels {
+			if disabled[userID][channel] {
+				continue
+			}
+			messages = append(messages, models.NotificationOutbox{
+				UserID:      userID,
+				Type:        notificationType,
+				Channel:     channel,
+				Payload:     payload,
+				DedupKey:    fmt.Sprintf("%s:%s:%d:%s", notificationType, dedupKey, userID, channel),
+				Status:      models.OutboxStatusPending,
+				AvailableAt: now,
+			})
+		}
+	}
+	return uc.repo.Enqueue(messages)
+}
+
+// DispatchOutbox merender dan mengirim pesan outbox yang sudah waktunya. Pesan yang gagal
+// dijadwalkan ulang dengan backoff dan dianggap gagal permanen setelah maxNotificationAttempts.
+func (uc *notificationUsecase) DispatchOutbox() error {
+	if err := uc.repo.ReleaseStaleOutbox(time.Now().Add(-notificationStaleAfter)); err != nil {
+		return err
+	}
+
+	runStart := time.Now()
+	for {
+		messages, err := uc.repo.ClaimOutbox(notificationBatchSize, runStart)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		users := make(map[int]*models.User)
+		for i := range messages {
+			uc.send(&messages[i], users)
+		}
+	}
+}
+
+func (uc *notificationUsecase) send(message *models.NotificationOutbox, users map[int]*models.User) {
+	err := uc.deliver(message, users)
+
+	now := time.Now()
+	message.UpdatedAt = now
+	if err == nil {
+		message.Status = models.OutboxStatusSent
+		message.SentAt = &now
+		message.LastError = ""
+	} else {
+		message.LastError = err.Error()
+		message.Status = models.OutboxStatusPending
+		// Backoff kuadratik: 1, 4, 9, 16 menit
+		message.AvailableAt = now.Add(time.Duration(message.Attempts*message.Attempts) * time.Minute)
+		if message.Attempts >= maxNotificationAttempts {
+			message.Status = models.OutboxStatusFailed
+		}
+	}
+
+	if err := uc.repo.UpdateOutbox(message); err != nil {
+		log.Printf("failed to update notification %d: %v", message.ID, err)
+	}
+}
+
+func (uc *notificationUsecase) deliver(message *models.NotificationOutbox, users map[int]*models.User) error {
+	channel, ok := uc.channels[message.Channel]
+	if !ok {
+		return fmt.Errorf("channel %s is not configured", message.Channel)
+	}
+
+	user, ok := users[message.UserID]
+	if !ok {
+		found, err := uc.userRepo.FindByID(message.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to load user %d: %w", message.UserID, err)
+		}
+		user = found
+		users[message.UserID] = user
+	}
+
+	payload := make(map[string]any, len(message.Payload)+1)
+	for key, value := range message.Payload {
+		payload[key] = value
+	}
+	payload["user_name"] = user.Name
+
+	subject, body, err := renderNotification(user.Locale, message.Type, payload)
+	if err != nil {
+		return err
+	}
+
+	outgoing := service.OutgoingMessage{
+		UserID:  user.ID,
+		Type:    message.Type,
+		Subject: subject,
+		Body:    body,
+	}
+	if eventID, ok := payload["event_id"].(float64); ok {
+		outgoing.EventID = int(eventID)
+	}
+	if message.Channel == models.ChannelEmail {
+		outgoing.Address = user.Email
+	}
+	return channel.Send(context.Background(), outgoing)
+}
+
+// ScheduleReminders mengantrekan pengingat event (24 jam dan 1 jam sebelum mulai) dan
+// pengingat pembayaran yang akan kedaluwarsa. Aman dijalankan berulang karena dedup key.
+func (uc *notificationUsecase) ScheduleReminders() error {
+	now := time.Now()
+
+	reminders := []struct {
+		notificationType string
+		from, to         time.Time
+	}{
+		{models.NotificationEventReminder24h, now.Add(time.Hour), now.Add(24 * time.Hour)},
+		{models.NotificationEventReminder1h, now, now.Add(time.Hour)},
+	}
+	for _, reminder := range reminders {
+		targets, err := uc.repo.ReminderTargets(reminder.from, reminder.to)
+		if err != nil {
+			return fmt.Errorf("failed to load %s targets: %w", reminder.notificationType, err)
+		}
+
+		byEvent := make(map[int][]dto.NotificationTarget)
+		for _, target := range targets {
+			byEvent[target.EventID] = append(byEvent[target.EventID], target)
+		}
+		for eventID, eventTargets := range byEvent {
+			userIDs := make([]int, 0, len(eventTargets))
+			for _, target := range eventTargets {
+				userIDs = append(userIDs, target.UserID)
+			}
+			first := eventTargets[0]
+			payload := map[string]any{
+				"event_id":    eventID,
+				"event_name":  first.EventName,
+				"event_start": first.StartDate,
+			}
+			// Tanggal mulai ikut di dedup key agar event yang dijadwal ulang mendapat pengingat baru
+			dedupKey := fmt.Sprintf("%d:%d", eventID, first.StartDate.Unix())
+			if err := uc.Notify(userIDs, reminder.notificationType, payload, dedupKey); err != nil {
+				return err
+			}
+		}
+	}
+
+	if uc.paymentExpiry <= 0 {
+		return nil
+	}
+	createdFrom := now.Add(-uc.paymentExpiry)
+	transactions, err := uc.repo.ExpiringTransactions(createdFrom, createdFrom.Add(paymentExpiringNotice))
+	if err != nil {
+		return fmt.Errorf("failed to load expiring transactions: %w", err)
+	}
+	for _, transaction := range transactions {
+		payload := map[string]any{
+			"event_id":    transaction.EventId,
+			"order_id":    transaction.PaymentGatewayTransactionId,
+			"amount":      transaction.Amount,
+			"currency":    transaction.Money().Currency,
+			"items":       transaction.Items,
+			"payment_url": transaction.Url,
+			"expires_at":  transaction.TransactionDate.Add(uc.paymentExpiry),
+		}
+		err := uc.Notify([]int{transaction.UserId}, models.NotificationPaymentExpiring, payload, transaction.PaymentGatewayTransactionId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *notificationUsecase) GetPreferences(userID int) (*dto.NotificationPreferencesResponse, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := uc.repo.ListPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	disabled := make(map[string]bool)
+	for _, preference := range preferences {
+		if !preference.Enabled {
+			disabled[preference.Type+"/"+preference.Channel] = true
+		}
+	}
+
+	items := make([]dto.NotificationPreferenceItem, 0, len(models.NotificationTypes)*len(notificationChannels))
+	for _, notificationType := range models.NotificationTypes {
+		for _, channel := range notificationChannels {
+			items = append(items, dto.NotificationPreferenceItem{
+				Type:    notificationType,
+				Channel: channel,
+				Enabled: !disabled[notificationType+"/"+channel],
+			})
+		}
+	}
+
+	locale := user.Locale
+	if locale == "" {
+		locale = models.LocaleIndonesian
+	}
+	return &dto.NotificationPreferencesResponse{Locale: locale, Preferences: items}, nil
+}
+
+func (uc *notificationUsecase) UpdatePreferences(userID int, request dto.UpdateNotificationPreferencesRequest) (*dto.NotificationPreferencesResponse, error) {
+	now := time.Now()
+	preferences := make([]models.NotificationPreference, 0, len(request.Preferences))
+	for _, item := range request.Preferences {
+		if !contains(models.NotificationTypes, item.Type) {
+			return nil, fmt.Errorf("unknown notification type %s", item.Type)
+		}
+		if !contains(notificationChannels, item.Channel) {
+			return nil, fmt.Errorf("unknown notification channel %s", item.Channel)
+		}
+		preferences = append(preferences, models.NotificationPreference{
+			UserID:    userID,
+			Type:      item.Type,
+			Channel:   item.Channel,
+			Enabled:   item.Enabled,
+			UpdatedAt: now,
+		})
+	}
+
+	if request.Locale != "" {
+		user, err := uc.userRepo.FindByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if user.Locale != request.Locale {
+			user.Locale = request.Locale
+			if err := uc.userRepo.Update(user); err != nil {
+				return nil, fmt.Errorf("failed to update locale: %w", err)
+			}
+		}
+	}
+
+	if err := uc.repo.SavePreferences(preferences); err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return uc.GetPreferences(userID)
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"log"
+	"time"
+)
+
+const paymentExpiryBatchSize = 100
+
+type PaymentExpiryUsecase interface {
+	// ExpireStale membatalkan transaksi pending yang melewati batas waktu pembayaran.
+	// Hanya satu instance yang memproses dalam satu waktu (Postgres advisory lock).
+	ExpireStale() error
+}
+
+type paymentExpiryUsecase struct {
+	transactionRepo repositories.TransactionRepository
+	locker          repositories.AdvisoryLocker
+	midtransService service.MidtransService
+	transactionUC   TransactionUsecase
+	notifyUC        NotificationUsecase
+	statsUC         EventStatsUsecase
+	webhookUC       WebhookUsecase
+	guardUC         PurchaseGuardUsecase
+	pubsub          service.PubSub
+	window          time.Duration
+}
+
+func NewPaymentExpiryUsecase(
+	transactionRepo repositories.TransactionRepository,
+	locker repositories.AdvisoryLocker,
+	midtransService service.MidtransService,
+	transactionUC TransactionUsecase,
+	notifyUC NotificationUsecase,
+	statsUC EventStatsUsecase,
+	webhookUC WebhookUsecase,
+	guardUC PurchaseGuardUsecase,
+	pubsub service.PubSub,
+	window time.Duration,
+) PaymentExpiryUsecase {
+	return &paymentExpiryUsecase{
+		transactionRepo: transactionRepo,
+		locker:          locker,
+		midtransService: midtransService,
+		transactionUC:   transactionUC,
+		notifyUC:        notifyUC,
+		statsUC:         statsUC,
+		webhookUC:       webhookUC,
+		guardUC:         guardUC,
+		pubsub:          pubsub,
+		window:          window,
+	}
+}
+
+func (uc *paymentExpiryUsecase) ExpireStale() error {
+	acquired, err := uc.locker.TryWithLock(context.Background(), repositories.PaymentExpiryLockKey, uc.expireAll)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		log.Println("payment expiry: another instance holds the lock, skipping")
+	}
+	return nil
+}
+
+func (uc *paymentExpiryUsecase) expireAll() error {
+	cutoff := time.Now().Add(-uc.window)
+	// Transaksi yang dilewati (gateway error, sudah lunas) tetap pending; skipped mencegah batch berikutnya
+	// mengambil baris yang sama terus-menerus di putaran ini.
+	skipped := make(map[uint]bool)
+	expired := 0
+
+	for {
+		transactions, err := uc.transactionRepo.FindExpiredPending(cutoff, paymentExpiryBatchSize+len(skipped))
+		if err != nil {
+			return err
+		}
+
+		progressed := false
+		for _, transaction := range transactions {
+			if skipped[transaction.ID] {
+				continue
+			}
+			progressed = true
+			ok, err := uc.expire(transaction)
+			if err != nil {
+				log.Printf("payment expiry: order %s: %v", transaction.PaymentGatewayTransactionId, err)
+			}
+			if ok {
+				expired++
+			} else {
+				skipped[transaction.ID] = true
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	if expired > 0 {
+		log.Printf("payment expiry: expired %d transaction(s)", expired)
+	}
+	return nil
+}
+
+// expire memeriksa status terakhir di gateway sebelum membatalkan, supaya pembayaran yang
+// notifikasinya hilang tidak ikut dibatalkan.
+func (uc *paymentExpiryUsecase) expire(transaction models.Transactions) (bool, error) {
+	orderID := transaction.PaymentGatewayTransactionId
+	finalStatus := "expire"
+
+	if orderID != "" {
+		status, err := uc.midtransService.GetStatus(orderID)
+		if err != nil {
+			return false, fmt.Errorf("failed to check gateway status: %w", err)
+		}
+
+		switch {
+		case status.StatusCode == "404":
+			// Pembeli tidak pernah membuka halaman pembayaran; tidak ada yang perlu dibatalkan di gateway
+		case isSettledStatus(status.TransactionStatus):
+			return false, uc.transactionUC.ApplyGatewayStatus(status)
+		case status.TransactionStatus == "pending":
+			if err := uc.midtransService.CancelTransaction(orderID); err != nil {
+				return false, fmt.Errorf("failed to cancel at gateway: %w", err)
+			}
+		default:
+			// expire, cancel, deny: pakai status dari gateway
+			finalStatus = status.TransactionStatus
+		}
+	}
+
+	released, expired, err := uc.transactionRepo.ExpirePending(transaction, finalStatus, time.Now())
+	if err != nil || !expired {
+		return false, err
+	}
+
+	uc.pubsub.Publish(service.UserTopic(transaction.UserId), service.StreamEvent{
+		Type: service.StreamPaymentStatus,
+		Data: map[string]any{
+			"order_id": orderID,
+			"event_id": transaction.EventId,
+			"status":   finalStatus,
+		},
+	})
+
+	payload := map[string]any{
+		"event_id":              transaction.EventId,
+		"event_name":            transaction.Event.Name,
+		"order_id":              orderID,
+		"amount":                transaction.Amount,
+		"currency":              transaction.Money().Currency,
+		"items":                 transaction.Items,
+		"registration_released": released != nil,
+	}
+	dedupKey := fmt.Sprintf("%d", transaction.ID)
+	if err := uc.notifyUC.Notify([]int{transaction.UserId}, models.NotificationPaymentExpired, payload, dedupKey); err != nil {
+		log.Printf("payment expiry: failed to queue notification for order %s: %v", orderID, err)
+	}
+
+	if released != nil {
+		if released.PurchaseIP != nil {
+			uc.guardUC.ReleasePurchase(transaction.EventId, 1, *released.PurchaseIP)
+		}
+		ctx := context.Background()
+		uc.statsUC.Publish(ctx, transaction.EventId, service.StreamAttendeeCancelled, transaction.UserId, released.TicketTypeID)
+		uc.webhookUC.Emit(ctx, models.WebhookAttendeeCancelled, transaction.EventId, transaction.UserId)
+	}
+	return true, nil
+}
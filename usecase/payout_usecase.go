@@ -0,0 +1,576 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPayoutNotFound dikembalikan saat payout tidak ada atau bukan milik organizer.
+	ErrPayoutNotFound = errors.New("payout not found")
+	// ErrPayoutBatchNotFound dikembalikan saat batch payout tidak ditemukan.
+	ErrPayoutBatchNotFound = errors.New("payout batch not found")
+	// ErrPayoutBatchClosed dikembalikan saat batch yang sudah disetujui/ditolak diubah lagi.
+	ErrPayoutBatchClosed = errors.New("payout batch is no longer pending")
+	// ErrInsufficientBalance dikembalikan saat saldo tersedia organizer kurang dari nominal payout.
+	ErrInsufficientBalance = errors.New("insufficient available balance")
+)
+
+const (
+	payoutListLimit = 200
+	// fee dalam basis point: 10000 = 100%
+	feeBpsScale = 10000
+)
+
+type PayoutUsecase interface {
+	// ApplyPlatformFee mencatat fee platform untuk transaksi lunas; idempoten per order id
+	ApplyPlatformFee(transaction models.Transactions) error
+	ListFeeRules(role string) (*dto.FeeRuleList, error)
+	SetFeeRule(adminID int, role string, input dto.SetFeeRuleRequest) (*models.FeeRule, error)
+	DeleteFeeRule(role string, id int) error
+
+	Statement(organizerID int, from, to string) (*dto.OrganizerStatement, error)
+	RequestPayout(organizerID int, input dto.CreatePayoutRequest) (*models.Payout, error)
+	ListPayouts(organizerID int) ([]models.Payout, error)
+	CancelPayout(organizerID, payoutID int) error
+
+	ListAllPayouts(role, status string) ([]models.Payout, error)
+	CreateBatch(adminID int, role string, input dto.CreatePayoutBatchRequest) (*models.PayoutBatch, error)
+	ListBatches(role string) ([]models.PayoutBatch, error)
+	GetBatch(role string, batchID int) (*models.PayoutBatch, error)
+	ApproveBatch(adminID int, role string, batchID int, note string) (*models.PayoutBatch, error)
+	RejectBatch(adminID int, role string, batchID int, note string) (*models.PayoutBatch, error)
+	// ExportBatch menulis file transfer bank untuk batch yang sudah disetujui
+	ExportBatch(role string, batchID int, w io.Writer) error
+}
+
+type payoutUsecase struct {
+	repo       repositories.PayoutRepository
+	ledgerRepo repositories.LedgerRepository
+	ledgerUC   LedgerUsecase
+	eventRepo  repositories.EventsRepository
+	defaultFee models.FeeRule
+	minAmount  int64
+	bankFormat dto.BankFileFormat
+}
+
+func NewPayoutUsecase(
+	repo repositories.PayoutRepository,
+	ledgerRepo repositories.LedgerRepository,
+	ledgerUC LedgerUsecase,
+	eventRepo repositories.EventsRepository,
+	defaultFee models.FeeRule,
+	minAmount int64,
+	bankFormat dto.BankFileFormat,
+) PayoutUsecase {
+	return &payoutUsecase{
+		repo:       repo,
+		ledgerRepo: ledgerRepo,
+		ledgerUC:   ledgerUC,
+		eventRepo:  eventRepo,
+		defaultFee: defaultFee,
+		minAmount:  minAmount,
+		bankFormat: bankFormat,
+	}
+}
+
+// platformFee menghitung fee dari amount (minor unit); tidak pernah melebihi amount
+func platformFee(amount int64, percentBps int, fixed int64) int64 {
+	fee := (amount*int64(percentBps)+feeBpsScale/2)/feeBpsScale + fixed
+	if fee > amount {
+		return amount
+	}
+	return fee
+}
+
+func (uc *payoutUsecase) ApplyPlatformFee(transaction models.Transactions) error {
+	event, err := uc.eventRepo.FindEventByID(transaction.EventId)
+	if err != nil {
+		return fmt.Errorf("failed to find event %d: %w", transaction.EventId, err)
+	}
+
+	rule, err := uc.repo.FindFeeRule(event.ID, event.OrganizerID)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		rule = &uc.defaultFee
+	}
+
+	amount, err := ledgerAmount(transaction)
+	if err != nil {
+		return err
+	}
+	fee := platformFee(amount, rule.PercentBps, rule.FixedAmount)
+	return uc.ledgerUC.RecordPlatformFee(transaction, fee)
+}
+
+func (uc *payoutUsecase) ListFeeRules(role string) (*dto.FeeRuleList, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	rules, err := uc.repo.ListFeeRules()
+	if err != nil {
+		return nil, err
+	}
+	return &dto.FeeRuleList{
+		Default: dto.PlatformFee{
+			PercentBps:  uc.defaultFee.PercentBps,
+			FixedAmount: uc.defaultFee.FixedAmount,
+		},
+		Rules: rules,
+	}, nil
+}
+
+func (uc *payoutUsecase) SetFeeRule(adminID int, role string, input dto.SetFeeRuleRequest) (*models.FeeRule, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	if (input.EventID == nil) == (input.OrganizerID == nil) {
+		return nil, errors.New("exactly one of event_id or organizer_id is required")
+	}
+	if input.EventID != nil {
+		if _, err := uc.eventRepo.FindEventByID(*input.EventID); err != nil {
+			return nil, err
+		}
+	}
+
+	rule := &models.FeeRule{
+		EventID:     input.EventID,
+		OrganizerID: input.OrganizerID,
+		PercentBps:  int(math.Round(input.Percent * 100)),
+		FixedAmount: input.FixedAmount,
+		CreatedBy:   adminID,
+	}
+	if err := uc.repo.SaveFeeRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (uc *payoutUsecase) DeleteFeeRule(role string, id int) error {
+	if role != "admin" {
+		return ErrAdminOnly
+	}
+	deleted, err := uc.repo.DeleteFeeRule(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("fee rule not found")
+	}
+	return nil
+}
+
+func (uc *payoutUsecase) Statement(organizerID int, from, to string) (*dto.OrganizerStatement, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	end := today
+	if to != "" {
+		parsed, err := time.Parse(analyticsDateLayout, to)
+		if err != nil {
+			return nil, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		end = parsed
+	}
+	// Default: awal bulan dari tanggal akhir
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if from != "" {
+		parsed, err := time.Parse(analyticsDateLayout, from)
+		if err != nil {
+			return nil, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		start = parsed
+	}
+	if start.After(end) {
+		return nil, errors.New("from must not be after to")
+	}
+
+	code := models.OrganizerAccountCode(organizerID)
+	opening, err := uc.ledgerRepo.CreditBalanceBefore(code, start)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := uc.ledgerRepo.AccountActivity(code, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	current, err := uc.ledgerUC.OrganizerBalance(organizerID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := uc.repo.PendingPayoutTotal(organizerID)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &dto.OrganizerStatement{
+		OrganizerID:    organizerID,
+		Currency:       models.LedgerCurrency,
+		From:           start.Format(analyticsDateLayout),
+		To:             end.Format(analyticsDateLayout),
+		OpeningBalance: opening,
+		PendingPayouts: pending,
+		Available:      current.Balance - pending,
+		Lines:          lines,
+	}
+	balance := opening
+	for i := range statement.Lines {
+		line := &statement.Lines[i]
+		balance += line.Amount
+		line.Balance = balance
+
+		switch line.Kind {
+		case models.JournalSettlement:
+			statement.Settlements += line.Amount
+		case models.JournalPlatformFee:
+			statement.PlatformFees -= line.Amount
+		case models.JournalRefund:
+			statement.Refunds -= line.Amount
+		case models.JournalPayout:
+			statement.Payouts -= line.Amount
+		}
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
+
+func (uc *payoutUsecase) RequestPayout(organizerID int, input dto.CreatePayoutRequest) (*models.Payout, error) {
+	if input.Amount < uc.minAmount {
+		return nil, fmt.Errorf("minimum payout is %d", uc.minAmount)
+	}
+
+	payout := &models.Payout{
+		OrganizerID:   organizerID,
+		Amount:        input.Amount,
+		Currency:      models.LedgerCurrency,
+		Status:        models.PayoutRequested,
+		BankCode:      input.BankCode,
+		BankName:      input.BankName,
+		AccountNumber: input.AccountNumber,
+		AccountHolder: input.AccountHolder,
+		Note:          input.Note,
+		RequestedAt:   time.Now(),
+	}
+	available, created, err := uc.repo.CreatePayout(payout)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, fmt.Errorf("%w: %d available", ErrInsufficientBalance, available)
+	}
+	return payout, nil
+}
+
+func (uc *payoutUsecase) ListPayouts(organizerID int) ([]models.Payout, error) {
+	return uc.repo.ListPayouts(&organizerID, "", payoutListLimit)
+}
+
+func (uc *payoutUsecase) CancelPayout(organizerID, payoutID int) error {
+	cancelled, err := uc.repo.CancelPayout(payoutID, organizerID, time.Now())
+	if err != nil {
+		return err
+	}
+	if cancelled {
+		return nil
+	}
+
+	payout, err := uc.repo.FindPayout(payoutID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && payout.OrganizerID != organizerID) {
+		return ErrPayoutNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("payout is %s and can no longer be cancelled", payout.Status)
+}
+
+func (uc *payoutUsecase) ListAllPayouts(role, status string) ([]models.Payout, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	return uc.repo.ListPayouts(nil, status, payoutListLimit)
+}
+
+func (uc *payoutUsecase) CreateBatch(adminID int, role string, input dto.CreatePayoutBatchRequest) (*models.PayoutBatch, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+
+	batch, err := uc.repo.CreateBatch(&models.PayoutBatch{
+		Status:    models.PayoutBatchPending,
+		Currency:  models.LedgerCurrency,
+		CreatedBy: adminID,
+	}, input.PayoutIDs)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, errors.New("no requested payouts to batch")
+	}
+	return uc.repo.FindBatch(batch.ID)
+}
+
+func (uc *payoutUsecase) ListBatches(role string) ([]models.PayoutBatch, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	return uc.repo.ListBatches(payoutListLimit)
+}
+
+func (uc *payoutUsecase) GetBatch(role string, batchID int) (*models.PayoutBatch, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	return uc.findBatch(batchID)
+}
+
+func (uc *payoutUsecase) findBatch(batchID int) (*models.PayoutBatch, error) {
+	batch, err := uc.repo.FindBatch(batchID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPayoutBatchNotFound
+	}
+	return batch, err
+}
+
+// ApproveBatch mencatat payout di ledger setelah batch ditutup. Memanggil ulang untuk batch yang
+// sudah approved aman: entry ledger unik per payout, jadi hanya yang gagal tercatat yang diposting.
+func (uc *payoutUsecase) ApproveBatch(adminID int, role string, batchID int, note string) (*models.PayoutBatch, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	batch, err := uc.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if batch.Status == models.PayoutBatchPending {
+		// Refund setelah payout diminta bisa membuat saldo ledger organizer turun di bawah payout-nya
+		if err := uc.checkFunded(batch); err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		batch.Status = models.PayoutBatchApproved
+		batch.ReviewedBy = &adminID
+		batch.ReviewedAt = &now
+		batch.Note = note
+		closed, err := uc.repo.CloseBatch(batch, models.PayoutApproved)
+		if err != nil {
+			return nil, err
+		}
+		if !closed {
+			return nil, ErrPayoutBatchClosed
+		}
+	}
+	if batch.Status != models.PayoutBatchApproved {
+		return nil, ErrPayoutBatchClosed
+	}
+
+	for _, payout := range batch.Payouts {
+		reference := fmt.Sprintf("payout:%d", payout.ID)
+		if err := uc.ledgerUC.RecordPayout(payout.OrganizerID, payout.Amount, reference); err != nil {
+			log.Printf("payout batch %d: failed to record payout %d in ledger: %v", batch.ID, payout.ID, err)
+			return nil, fmt.Errorf("batch approved but payout %d was not recorded in the ledger, approve again to retry: %w", payout.ID, err)
+		}
+	}
+	return uc.repo.FindBatch(batch.ID)
+}
+
+func (uc *payoutUsecase) checkFunded(batch *models.PayoutBatch) error {
+	totals := make(map[int]int64)
+	for _, payout := range batch.Payouts {
+		totals[payout.OrganizerID] += payout.Amount
+	}
+	for organizerID, total := range totals {
+		balance, err := uc.ledgerUC.OrganizerBalance(organizerID)
+		if err != nil {
+			return err
+		}
+		if balance.Balance < total {
+			return fmt.Errorf("%w: organizer %d has %d, batch pays %d", ErrInsufficientBalance, organizerID, balance.Balance, total)
+		}
+	}
+	return nil
+}
+
+func (uc *payoutUsecase) RejectBatch(adminID int, role string, batchID int, note string) (*models.PayoutBatch, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	batch, err := uc.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	batch.Status = models.PayoutBatchRejected
+	batch.ReviewedBy = &adminID
+	batch.ReviewedAt = &now
+	batch.Note = note
+	closed, err := uc.repo.CloseBatch(batch, models.PayoutRejected)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, ErrPayoutBatchClosed
+	}
+	return uc.repo.FindBatch(batch.ID)
+}
+
+func (uc *payoutUsecase) ExportBatch(role string, batchID int, w io.Writer) error {
+	if role != "admin" {
+		return ErrAdminOnly
+	}
+	batch, err := uc.findBatch(batchID)
+	if err != nil {
+		return err
+	}
+	if batch.Status != models.PayoutBatchApproved {
+		return errors.New("only approved batches can be exported")
+	}
+	return uc.writeBankFile(batch, w)
+}
+
+func (uc *payoutUsecase) writeBankFile(batch *models.PayoutBatch, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Comma, _ = utf8.DecodeRuneInString(uc.bankFormat.Delimiter)
+	writer.UseCRLF = uc.bankFormat.CRLF
+
+	if uc.bankFormat.Header {
+		header := make([]string, len(uc.bankFormat.Columns))
+		for i, column := range uc.bankFormat.Columns {
+			header[i] = column.Header
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+	}
+	for _, payout := range batch.Payouts {
+		row := make([]string, len(uc.bankFormat.Columns))
+		for i, column := range uc.bankFormat.Columns {
+			row[i] = bankFileValue(column, batch, payout)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// bankFileFields adalah field payout yang bisa dipakai kolom file transfer bank
+var bankFileFields = map[string]bool{
+	"reference": true, "payout_id": true, "batch_id": true, "organizer_id": true,
+	"bank_code": true, "bank_name": true, "account_number": true, "account_holder": true,
+	"amount": true, "amount_whole": true, "amount_minor": true, "currency": true,
+	"note": true, "date": true,
+}
+
+func bankFileValue(column dto.BankFileColumn, batch *models.PayoutBatch, payout models.Payout) string {
+	switch column.Field {
+	case "":
+		return column.Value
+	case "reference":
+		return fmt.Sprintf("PAYOUT-%d", payout.ID)
+	case "payout_id":
+		return strconv.Itoa(payout.ID)
+	case "batch_id":
+		return strconv.Itoa(batch.ID)
+	case "organizer_id":
+		return strconv.Itoa(payout.OrganizerID)
+	case "bank_code":
+		return payout.BankCode
+	case "bank_name":
+		return payout.BankName
+	case "account_number":
+		return payout.AccountNumber
+	case "account_holder":
+		return payout.AccountHolder
+	case "amount":
+		return fmt.Sprintf("%d.%02d", payout.Amount/100, payout.Amount%100)
+	case "amount_whole":
+		return strconv.FormatInt(payout.Amount/100, 10)
+	case "amount_minor":
+		return strconv.FormatInt(payout.Amount, 10)
+	case "currency":
+		return payout.Currency
+	case "note":
+		return payout.Note
+	case "date":
+		layout := column.Layout
+		if layout == "" {
+			layout = analyticsDateLayout
+		}
+		if batch.ReviewedAt != nil {
+			return batch.ReviewedAt.Format(layout)
+		}
+		return time.Now().Format(layout)
+	}
+	return ""
+}
+
+// DefaultBankFileFormat adalah CSV generik yang dipakai jika PAYOUT_BANK_FORMAT tidak diisi
+func DefaultBankFileFormat() dto.BankFileFormat {
+	return dto.BankFileFormat{
+		Name:      "default",
+		Delimiter: ",",
+		Header:    true,
+		Columns: []dto.BankFileColumn{
+			{Header: "Reference", Field: "reference"},
+			{Header: "Bank Code", Field: "bank_code"},
+			{Header: "Bank Name", Field: "bank_name"},
+			{Header: "Account Number", Field: "account_number"},
+			{Header: "Account Holder", Field: "account_holder"},
+			{Header: "Amount", Field: "amount"},
+			{Header: "Currency", Field: "currency"},
+			{Header: "Note", Field: "note"},
+		},
+	}
+}
+
+// LoadBankFileFormat membaca format file transfer bank dari file JSON; path kosong berarti format default
+func LoadBankFileFormat(path string) (dto.BankFileFormat, error) {
+	if path == "" {
+		return DefaultBankFileFormat(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return dto.BankFileFormat{}, err
+	}
+	var format dto.BankFileFormat
+	if err := json.Unmarshal(data, &format); err != nil {
+		return dto.BankFileFormat{}, fmt.Errorf("invalid bank file format %s: %w", path, err)
+	}
+
+	if format.Delimiter == "" {
+		format.Delimiter = ","
+	}
+	if delimiter, _ := utf8.DecodeRuneInString(format.Delimiter); utf8.RuneCountInString(format.Delimiter) != 1 ||
+		delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+		return dto.BankFileFormat{}, fmt.Errorf("bank file format %s: delimiter must be a single character", path)
+	}
+	if len(format.Columns) == 0 {
+		return dto.BankFileFormat{}, fmt.Errorf("bank file format %s: no columns", path)
+	}
+	for _, column := range format.Columns {
+		if column.Field != "" && !bankFileFields[column.Field] {
+			return dto.BankFileFormat{}, fmt.Errorf("bank file format %s: unknown field %q", path, column.Field)
+		}
+	}
+	return format, nil
+}
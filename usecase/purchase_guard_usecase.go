@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"log"
+	"time"
+)
+
+var (
+	// ErrRateLimited dikembalikan saat percobaan registrasi/signup melewati batas window
+	ErrRateLimited = errors.New("too many attempts")
+	// ErrChallengeRequired dikembalikan saat challenge anti-bot tidak ada, salah atau sudah dipakai
+	ErrChallengeRequired = errors.New("a valid challenge answer is required")
+	// ErrPurchaseLimit dikembalikan saat batas tiket per akun atau per IP event tercapai
+	ErrPurchaseLimit = errors.New("purchase limit reached")
+)
+
+const (
+	defaultQueueBatchSize = 50
+	defaultCheckoutWindow = 10 // menit
+)
+
+// AttemptLimit membatasi Max percobaan per Window; Max 0 berarti tidak dibatasi
+type AttemptLimit struct {
+	Max    int
+	Window time.Duration
+}
+
+// Counter tanpa window (mis. tiket per IP) memakai window_start tetap ini
+var lifetimeWindow = time.Unix(0, 0).UTC()
+
+type PurchaseGuardUsecase interface {
+	GetPolicy(eventID, userID int, role string) (*models.EventPurchasePolicy, error)
+	SetPolicy(eventID, userID int, role string, input dto.SetPurchasePolicyRequest) (*models.EventPurchasePolicy, error)
+
+	IssueChallenge(eventID, userID int) (*service.Challenge, error)
+	IssueSignupChallenge(ip string) (*service.Challenge, error)
+	// CheckSignup membatasi pembuatan akun per IP dan memeriksa challenge jika diwajibkan
+	CheckSignup(ip string, answer *dto.ChallengeAnswer) error
+
+	// AllowAttempt menghitung satu percobaan registrasi/pembelian per user dan per IP
+	AllowAttempt(userID int, ip string) error
+	// CheckPurchase memeriksa challenge dan batas tiket per akun sebelum tiket dibuat
+	CheckPurchase(event *models.Event, userID, quantity int, client dto.ClientInfo) error
+	// ReservePurchase memesan kuota batas tiket per IP sebelum tiket dibuat. Pemesanan harus
+	// dilepas dengan ReleasePurchase jika pembuatan tiket gagal, dibatalkan atau kedaluwarsa.
+	ReservePurchase(event *models.Event, quantity int, ip string) error
+	ReleasePurchase(eventID, quantity int, ip string)
+	CleanupCounters() error
+}
+
+type purchaseGuardUsecase struct {
+	repo            repositories.PurchaseGuardRepository
+	eventRepo       repositories.EventsRepository
+	challenge       service.ChallengeProvider
+	challengeTTL    time.Duration
+	registerPerUser AttemptLimit
+	registerPerIP   AttemptLimit
+	signupPerIP     AttemptLimit
+	signupChallenge bool
+}
+
+func NewPurchaseGuardUsecase(
+	repo repositories.PurchaseGuardRepository,
+	eventRepo repositories.EventsRepository,
+	challenge service.ChallengeProvider,
+	challengeTTL time.Duration,
+	registerPerUser, registerPerIP, signupPerIP AttemptLimit,
+	signupChallenge bool,
+) PurchaseGuardUsecase {
+	return &purchaseGuardUsecase{
+		repo:            repo,
+		eventRepo:       eventRepo,
+		challenge:       challenge,
+		challengeTTL:    challengeTTL,
+		registerPerUser: registerPerUser,
+		registerPerIP:   registerPerIP,
+		signupPerIP:     signupPerIP,
+		signupChallenge: signupChallenge,
+	}
+}
+
+func (uc *purchaseGuardUsecase) GetPolicy(eventID, userID int, role string) (*models.EventPurchasePolicy, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, userID, role); err != nil {
+		return nil, err
+	}
+	policy, err := uc.repo.FindPolicy(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		// Belum diatur: tampilkan default tanpa batas
+		policy = &models.EventPurchasePolicy{EventID: eventID, QueueBatchSize: defaultQueueBatchSize, CheckoutWindow: defaultCheckoutWindow}
+	}
+	return policy, nil
+}
+
+func (uc *purchaseGuardUsecase) SetPolicy(eventID, userID int, role string, input dto.SetPurchasePolicyRequest) (*models.EventPurchasePolicy, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, userID, role); err != nil {
+		return nil, err
+	}
+	batchSize := input.QueueBatchSize
+	if batchSize == 0 {
+		batchSize = defaultQueueBatchSize
+	}
+	checkoutWindow := input.CheckoutWindow
+	if checkoutWindow == 0 {
+		checkoutWindow = defaultCheckoutWindow
+	}
+	policy := &models.EventPurchasePolicy{
+		EventID:           eventID,
+		MaxTicketsPerUser: input.MaxTicketsPerUser,
+		MaxTicketsPerIP:   input.MaxTicketsPerIP,
+		RequireChallenge:  input.RequireChallenge,
+		QueueEnabled:      input.QueueEnabled,
+		QueueBatchSize:    batchSize,
+		QueueAdmitRate:    input.QueueAdmitRate,
+		CheckoutWindow:    checkoutWindow,
+	}
+	if err := uc.repo.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+	return uc.repo.FindPolicy(eventID)
+}
+
+func eventChallengeSubject(eventID, userID int) string {
+	return fmt.Sprintf("event:%d:user:%d", eventID, userID)
+}
+
+func (uc *purchaseGuardUsecase) IssueChallenge(eventID, userID int) (*service.Challenge, error) {
+	if _, err := uc.eventRepo.FindEventByID(eventID); err != nil {
+		return nil, err
+	}
+	return uc.challenge.Issue(eventChallengeSubject(eventID, userID))
+}
+
+func (uc *purchaseGuardUsecase) IssueSignupChallenge(ip string) (*service.Challenge, error) {
+	return uc.challenge.Issue("signup:" + ip)
+}
+
+func (uc *purchaseGuardUsecase) CheckSignup(ip string, answer *dto.ChallengeAnswer) error {
+	if err := uc.hit("signup:ip:"+ip, uc.signupPerIP, time.Now()); err != nil {
+		return err
+	}
+	if uc.signupChallenge {
+		return uc.verifyChallenge("signup:"+ip, answer)
+	}
+	return nil
+}
+
+func (uc *purchaseGuardUsecase) AllowAttempt(userID int, ip string) error {
+	now := time.Now()
+	if err := uc.hit(fmt.Sprintf("register:user:%d", userID), uc.registerPerUser, now); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return uc.hit("register:ip:"+ip, uc.registerPerIP, now)
+}
+
+// hit menaikkan counter fixed-window. Jika counter gagal ditulis, request tetap dilayani:
+// rate limit tidak boleh menjadi penyebab registrasi gagal.
+func (uc *purchaseGuardUsecase) hit(key string, limit AttemptLimit, now time.Time) error {
+	if limit.Max <= 0 || limit.Window <= 0 {
+		return nil
+	}
+	windowStart := now.Truncate(limit.Window)
+	windowEnd := windowStart.Add(limit.Window)
+	count, err := uc.repo.Hit(key, windowStart, 1, windowEnd)
+	if err != nil {
+		log.Printf("rate limit %s: failed to count attempt: %v", key, err)
+		return nil
+	}
+	if count > limit.Max {
+		return fmt.Errorf("%w, try again in %s", ErrRateLimited, windowEnd.Sub(now).Round(time.Second))
+	}
+	return nil
+}
+
+func (uc *purchaseGuardUsecase) verifyChallenge(subject string, answer *dto.ChallengeAnswer) error {
+	var token, solution string
+	if answer != nil {
+		token, solution = answer.Token, answer.Solution
+	}
+	nonce, err := uc.challenge.Verify(subject, token, solution)
+	if err != nil {
+		return ErrChallengeRequired
+	}
+	if nonce == "" {
+		return nil
+	}
+
+	// Satu challenge hanya berlaku untuk satu percobaan
+	used, err := uc.repo.Hit("challenge:"+nonce, lifetimeWindow, 1, time.Now().Add(uc.challengeTTL))
+	if err != nil {
+		return err
+	}
+	if used > 1 {
+		return fmt.Errorf("%w: challenge has already been used", ErrChallengeRequired)
+	}
+	return nil
+}
+
+func ipTicketKey(eventID int, ip string) string {
+	return fmt.Sprintf("tickets:event:%d:ip:%s", eventID, ip)
+}
+
+func (uc *purchaseGuardUsecase) CheckPurchase(event *models.Event, userID, quantity int, client dto.ClientInfo) error {
+	policy, err := uc.repo.FindPolicy(event.ID)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return nil
+	}
+
+	if policy.RequireChallenge {
+		if err := uc.verifyChallenge(eventChallengeSubject(event.ID, userID), client.Challenge); err != nil {
+			return err
+		}
+	}
+	if policy.MaxTicketsPerUser > 0 {
+		owned, err := uc.repo.CountUserTickets(event.ID, userID)
+		if err != nil {
+			return err
+		}
+		if owned+quantity > policy.MaxTicketsPerUser {
+			return fmt.Errorf("%w: at most %d tickets per account for this event, you already have %d",
+				ErrPurchaseLimit, policy.MaxTicketsPerUser, owned)
+		}
+	}
+	return nil
+}
+
+func (uc *purchaseGuardUsecase) ReservePurchase(event *models.Event, quantity int, ip string) error {
+	if ip == "" {
+		return nil
+	}
+	policy, err := uc.repo.FindPolicy(event.ID)
+	if err != nil {
+		return err
+	}
+	// Counter disimpan sampai sehari setelah event selesai. Tanpa batas per IP counter tetap dihitung
+	// agar batas yang diaktifkan belakangan memperhitungkan tiket yang sudah terjual.
+	key, expiresAt := ipTicketKey(event.ID, ip), event.EndDate.Add(24*time.Hour)
+	if policy == nil || policy.MaxTicketsPerIP <= 0 {
+		if _, err := uc.repo.Hit(key, lifetimeWindow, quantity, expiresAt); err != nil {
+			log.Printf("purchase guard: failed to record %d tickets for event %d: %v", quantity, event.ID, err)
+		}
+		return nil
+	}
+	ok, err := uc.repo.Reserve(key, lifetimeWindow, quantity, policy.MaxTicketsPerIP, expiresAt)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: ticket limit for your network has been reached", ErrPurchaseLimit)
+	}
+	return nil
+}
+
+func (uc *purchaseGuardUsecase) ReleasePurchase(eventID, quantity int, ip string) {
+	if ip == "" {
+		return
+	}
+	if err := uc.repo.Release(ipTicketKey(eventID, ip), lifetimeWindow, quantity); err != nil {
+		log.Printf("purchase guard: failed to release %d tickets for event %d: %v", quantity, eventID, err)
+	}
+}
+
+func (uc *purchaseGuardUsecase) CleanupCounters() error {
+	deleted, err := uc.repo.DeleteExpiredCounters(time.Now())
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("purchase guard: removed %d expired counters", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,392 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"gatherly-app/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAdminOnly dikembalikan saat endpoint khusus admin dipanggil user biasa.
+var ErrAdminOnly = errors.New("admin access required")
+
+// ErrReconciliationRunning dikembalikan saat run baru diminta padahal run lain masih berjalan.
+var ErrReconciliationRunning = errors.New("a reconciliation run is already in progress")
+
+// ErrReconciliationNotFound dikembalikan saat run rekonsiliasi tidak ditemukan.
+var ErrReconciliationNotFound = errors.New("reconciliation run not found")
+
+const (
+	reconcileBatchSize = 100
+	// Run yang masih running lebih lama dari ini dianggap mati
+	reconcileStaleAfter = 2 * time.Hour
+	// Jeda antar panggilan status API agar tidak terkena rate limit gateway
+	reconcileRequestGap = 100 * time.Millisecond
+)
+
+type ReconciliationUsecase interface {
+	// Run menjalankan rekonsiliasi secara sinkron (job terjadwal dan CLI)
+	Run(triggeredBy *int) (*models.ReconciliationRun, error)
+	// RunScheduled dipakai runPeriodically; run yang bentrok dengan instance lain dilewati
+	RunScheduled() error
+	// Start membuat run lalu memprosesnya di background; dipanggil admin lewat API
+	Start(adminID int, role string) (*models.ReconciliationRun, error)
+	ListRuns(role string) ([]models.ReconciliationRun, error)
+	GetReport(role string, runID int) (*dto.ReconciliationReport, error)
+	// WriteReport menulis daftar selisih sebagai tabel (CSV/XLSX); writer ditutup di sini
+	WriteReport(role string, runID int, writer utils.TableWriter) error
+	TransactionReport(role string, from, to string) (*dto.TransactionReport, error)
+}
+
+type reconciliationUsecase struct {
+	repo            repositories.ReconciliationRepository
+	midtransService service.MidtransService
+	transactionUC   TransactionUsecase
+	paymentExpiry   time.Duration
+	closedLookback  time.Duration
+}
+
+// NewReconciliationUsecase: transaksi non-pending yang dibuat dalam closedLookback ikut dicek
+// untuk menemukan selisih nominal dan perubahan status setelah pelunasan (refund, chargeback).
+func NewReconciliationUsecase(
+	repo repositories.ReconciliationRepository,
+	midtransService service.MidtransService,
+	transactionUC TransactionUsecase,
+	paymentExpiry time.Duration,
+	closedLookback time.Duration,
+) ReconciliationUsecase {
+	return &reconciliationUsecase{
+		repo:            repo,
+		midtransService: midtransService,
+		transactionUC:   transactionUC,
+		paymentExpiry:   paymentExpiry,
+		closedLookback:  closedLookback,
+	}
+}
+
+func (uc *reconciliationUsecase) Run(triggeredBy *int) (*models.ReconciliationRun, error) {
+	run, err := uc.begin(triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+	uc.process(run)
+	if run.Status == models.ReconciliationFailed {
+		return run, errors.New(run.Error)
+	}
+	return run, nil
+}
+
+func (uc *reconciliationUsecase) RunScheduled() error {
+	_, err := uc.Run(nil)
+	if errors.Is(err, ErrReconciliationRunning) {
+		log.Println("reconciliation: another run is in progress, skipping")
+		return nil
+	}
+	return err
+}
+
+func (uc *reconciliationUsecase) Start(adminID int, role string) (*models.ReconciliationRun, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	run, err := uc.begin(&adminID)
+	if err != nil {
+		return nil, err
+	}
+	started := *run
+	go uc.process(run)
+	return &started, nil
+}
+
+func (uc *reconciliationUsecase) begin(triggeredBy *int) (*models.ReconciliationRun, error) {
+	if err := uc.repo.FailStaleRuns(time.Now().Add(-reconcileStaleAfter)); err != nil {
+		return nil, err
+	}
+
+	run := &models.ReconciliationRun{
+		Status:      models.ReconciliationRunning,
+		TriggeredBy: triggeredBy,
+		StartedAt:   time.Now(),
+	}
+	if err := uc.repo.CreateRun(run); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "idx_reconciliation_running") {
+			return nil, ErrReconciliationRunning
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+// process memeriksa transaksi per batch dan menyimpan selisihnya; status run selalu ditutup
+func (uc *reconciliationUsecase) process(run *models.ReconciliationRun) {
+	closedSince := time.Now().Add(-uc.closedLookback)
+	var afterID uint
+	var runErr error
+
+	for runErr == nil {
+		transactions, err := uc.repo.TransactionsToCheck(closedSince, afterID, reconcileBatchSize)
+		if err != nil {
+			runErr = fmt.Errorf("failed to load transactions: %w", err)
+			break
+		}
+		if len(transactions) == 0 {
+			break
+		}
+
+		var discrepancies []models.ReconciliationDiscrepancy
+		for _, transaction := range transactions {
+			afterID = transaction.ID
+			found := uc.reconcile(run.ID, transaction)
+			for _, discrepancy := range found {
+				if discrepancy.Repaired {
+					run.Repaired++
+				}
+			}
+			discrepancies = append(discrepancies, found...)
+			run.Checked++
+			time.Sleep(reconcileRequestGap)
+		}
+
+		if err := uc.repo.CreateDiscrepancies(discrepancies); err != nil {
+			runErr = fmt.Errorf("failed to save discrepancies: %w", err)
+		}
+		run.Discrepancies += len(discrepancies)
+	}
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = models.ReconciliationCompleted
+	if runErr != nil {
+		run.Status = models.ReconciliationFailed
+		run.Error = runErr.Error()
+	}
+	if err := uc.repo.UpdateRun(run); err != nil {
+		log.Printf("reconciliation: failed to close run %d: %v", run.ID, err)
+	}
+	log.Printf("reconciliation run %d %s: checked %d, discrepancies %d, repaired %d",
+		run.ID, run.Status, run.Checked, run.Discrepancies, run.Repaired)
+}
+
+// reconcile membandingkan satu transaksi dengan gateway. Status diperbaiki lewat ApplyGatewayStatus
+// agar efek sampingnya (status peserta, notifikasi, webhook) sama dengan notifikasi Midtrans biasa.
+// Selisih nominal hanya dilaporkan, tidak diperbaiki.
+func (uc *reconciliationUsecase) reconcile(runID int, transaction models.Transactions) []models.ReconciliationDiscrepancy {
+	base := models.ReconciliationDiscrepancy{
+		RunID:         runID,
+		TransactionID: transaction.ID,
+		OrderID:       transaction.PaymentGatewayTransactionId,
+		EventID:       transaction.EventId,
+		UserID:        transaction.UserId,
+		LocalStatus:   transaction.Status,
+		LocalAmount:   transaction.Money().Major(),
+	}
+	overdue := time.Since(transaction.TransactionDate) > uc.paymentExpiry
+
+	status, err := uc.midtransService.GetStatus(transaction.PaymentGatewayTransactionId)
+	if err != nil {
+		base.Kind = models.DiscrepancyGatewayError
+		base.Note = err.Error()
+		return []models.ReconciliationDiscrepancy{base}
+	}
+	if status.StatusCode == "404" {
+		// Order baru terdaftar di gateway setelah pembeli membuka halaman pembayaran
+		if transaction.Status == "pending" && !overdue {
+			return nil
+		}
+		base.Kind = models.DiscrepancyMissingAtGateway
+		base.Note = status.StatusMessage
+		return []models.ReconciliationDiscrepancy{base}
+	}
+
+	base.GatewayStatus = status.TransactionStatus
+	var found []models.ReconciliationDiscrepancy
+	amountMismatch := false
+
+	// Dibandingkan dalam minor unit agar tidak terpengaruh pembulatan float
+	if amount, err := strconv.ParseFloat(status.GrossAmount, 64); err == nil {
+		base.GatewayAmount = &amount
+		gateway, err := models.MoneyFromMajor(amount, transaction.Money().Currency)
+		if err != nil || gateway.Amount != transaction.Amount {
+			amountMismatch = true
+			mismatch := base
+			mismatch.Kind = models.DiscrepancyAmountMismatch
+			mismatch.Note = fmt.Sprintf("local %s, gateway %s", transaction.Money(), status.GrossAmount)
+			found = append(found, mismatch)
+		}
+	}
+
+	// capture dengan fraud_status challenge belum lunas sampai di-approve di dashboard Midtrans
+	challenged := status.TransactionStatus == "capture" && status.FraudStatus == "challenge"
+
+	switch {
+	case sameTransactionStatus(transaction.Status, status.TransactionStatus) || challenged:
+		if transaction.Status == "pending" && overdue {
+			stuck := base
+			stuck.Kind = models.DiscrepancyStuckPending
+			stuck.Note = fmt.Sprintf("pending since %s", transaction.TransactionDate.Format(time.RFC3339))
+			if challenged {
+				stuck.Note += " (fraud challenge)"
+			}
+			found = append(found, stuck)
+		}
+	default:
+		mismatch := base
+		mismatch.Kind = models.DiscrepancyStatusMismatch
+		if isSettledStatus(status.TransactionStatus) {
+			mismatch.Kind = models.DiscrepancyUnrecordedSettlement
+		}
+
+		// Nominal yang berbeda tidak boleh melunasi order atau menerbitkan tiket; diperiksa manual
+		if amountMismatch {
+			mismatch.Note = "not repaired: gateway amount differs from local amount"
+			found = append(found, mismatch)
+			break
+		}
+
+		err := uc.transactionUC.ApplyGatewayStatus(status)
+		if err != nil {
+			mismatch.Note = "repair failed: " + err.Error()
+		} else {
+			mismatch.Repaired = true
+			mismatch.Note = fmt.Sprintf("status updated from %s to %s", transaction.Status, status.TransactionStatus)
+		}
+		found = append(found, mismatch)
+	}
+	return found
+}
+
+func isSettledStatus(status string) bool {
+	return status == "settlement" || status == "capture"
+}
+
+// sameTransactionStatus menganggap settlement dan capture setara (keduanya sudah lunas)
+func sameTransactionStatus(local, gateway string) bool {
+	return local == gateway || (isSettledStatus(local) && isSettledStatus(gateway))
+}
+
+func (uc *reconciliationUsecase) ListRuns(role string) ([]models.ReconciliationRun, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+	return uc.repo.ListRuns(50)
+}
+
+func (uc *reconciliationUsecase) GetReport(role string, runID int) (*dto.ReconciliationReport, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+
+	run, err := uc.repo.FindRun(runID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReconciliationNotFound
+		}
+		return nil, err
+	}
+	discrepancies, err := uc.repo.ListDiscrepancies(runID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReconciliationReport{Run: *run, Discrepancies: discrepancies}, nil
+}
+
+func (uc *reconciliationUsecase) WriteReport(role string, runID int, writer utils.TableWriter) error {
+	report, err := uc.GetReport(role, runID)
+	if err != nil {
+		return err
+	}
+
+	err = writer.WriteRow([]string{
+		"run_id", "transaction_id", "order_id", "event_id", "user_id", "kind",
+		"local_status", "gateway_status", "local_amount", "gateway_amount", "repaired", "note", "detected_at",
+	})
+	if err != nil {
+		return err
+	}
+	for _, d := range report.Discrepancies {
+		gatewayAmount := ""
+		if d.GatewayAmount != nil {
+			gatewayAmount = strconv.FormatFloat(*d.GatewayAmount, 'f', 2, 64)
+		}
+		err = writer.WriteRow([]string{
+			strconv.Itoa(d.RunID),
+			strconv.FormatUint(uint64(d.TransactionID), 10),
+			d.OrderID,
+			strconv.Itoa(d.EventID),
+			strconv.Itoa(d.UserID),
+			d.Kind,
+			d.LocalStatus,
+			d.GatewayStatus,
+			strconv.FormatFloat(d.LocalAmount, 'f', 2, 64),
+			gatewayAmount,
+			strconv.FormatBool(d.Repaired),
+			d.Note,
+			d.CreatedAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// TransactionReport merangkum transaksi per status; from/to berupa tanggal YYYY-MM-DD (inklusif, UTC).
+// Default 30 hari terakhir.
+func (uc *reconciliationUsecase) TransactionReport(role string, from, to string) (*dto.TransactionReport, error) {
+	if role != "admin" {
+		return nil, ErrAdminOnly
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	end := today
+	if to != "" {
+		parsed, err := time.Parse(analyticsDateLayout, to)
+		if err != nil {
+			return nil, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		end = parsed
+	}
+	start := end.AddDate(0, 0, -29)
+	if from != "" {
+		parsed, err := time.Parse(analyticsDateLayout, from)
+		if err != nil {
+			return nil, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		start = parsed
+	}
+	if start.After(end) {
+		return nil, errors.New("from must not be after to")
+	}
+
+	statuses, err := uc.repo.TransactionSummary(start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.TransactionReport{
+		From:          start.Format(analyticsDateLayout),
+		To:            end.Format(analyticsDateLayout),
+		Statuses:      statuses,
+		SettledAmount: make(map[string]int64),
+		PendingAmount: make(map[string]int64),
+	}
+	for _, status := range statuses {
+		report.TotalCount += status.Count
+		switch {
+		case isSettledStatus(status.Status):
+			report.SettledAmount[status.Currency] += status.Amount
+		case status.Status == "pending":
+			report.PendingAmount[status.Currency] += status.Amount
+		}
+	}
+	return report, nil
+}
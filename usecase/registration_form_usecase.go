@@ -0,0 +1,575 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/utils"
+	"io"
+	"math"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var formFieldKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+type RegistrationFormUsecase interface {
+	SaveForm(eventID, organizerID int, role string, request dto.SaveRegistrationFormRequest) (*models.RegistrationForm, error)
+	GetForm(eventID, userID int, ticketTypeID *int, accessCode string) (*models.RegistrationForm, error)
+	ListForms(eventID, organizerID int, role string) ([]models.RegistrationForm, error)
+	DeleteForm(eventID, organizerID int, role string, ticketTypeID *int) error
+	UploadFile(eventID, userID int, fieldKey, accessCode string, file *multipart.FileHeader) (*dto.FormUploadResponse, error)
+
+	ValidateAnswers(eventID, ticketTypeID, userID int, answers map[string]any) (*models.RegistrationAnswer, error)
+	SaveAnswer(answer *models.RegistrationAnswer) error
+	GetMyAnswers(eventID, userID int) (*models.RegistrationAnswer, error)
+	ListAnswers(eventID, organizerID int, role string) ([]models.RegistrationAnswer, error)
+	ExportAnswersCSV(eventID, organizerID int, role string, w io.Writer) error
+}
+
+type registrationFormUsecase struct {
+	repo          repositories.RegistrationFormRepository
+	eventRepo     repositories.EventsRepository
+	ticketRepo    repositories.TicketRepository
+	invitationUC  InvitationUsecase
+	uploadDir     string
+	maxUploadSize int64
+}
+
+func NewRegistrationFormUsecase(
+	repo repositories.RegistrationFormRepository,
+	eventRepo repositories.EventsRepository,
+	ticketRepo repositories.TicketRepository,
+	invitationUC InvitationUsecase,
+	uploadDir string,
+	maxUploadSize int64,
+) RegistrationFormUsecase {
+	return &registrationFormUsecase{
+		repo:          repo,
+		eventRepo:     eventRepo,
+		ticketRepo:    ticketRepo,
+		invitationUC:  invitationUC,
+		uploadDir:     uploadDir,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+func (uc *registrationFormUsecase) findManagedEvent(eventID, organizerID int, role string) (*models.Event, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, organizerID, role); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (uc *registrationFormUsecase) SaveForm(eventID, organizerID int, role string, request dto.SaveRegistrationFormRequest) (*models.RegistrationForm, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+
+	if request.TicketTypeID != nil {
+		ticket, err := uc.ticketRepo.FindTicketByID(*request.TicketTypeID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("ticket type with ID %d not found", *request.TicketTypeID)
+			}
+			return nil, err
+		}
+		if ticket.EventID != eventID {
+			return nil, fmt.Errorf("ticket type ID %d does not belong to event ID %d", ticket.Id, eventID)
+		}
+	}
+
+	if err := validateFormSchema(request.Fields); err != nil {
+		return nil, err
+	}
+
+	form, err := uc.repo.FindForm(eventID, request.TicketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if form == nil {
+		form = &models.RegistrationForm{EventID: eventID, TicketTypeID: request.TicketTypeID}
+	}
+	form.Fields = request.Fields
+
+	if err := uc.repo.SaveForm(form); err != nil {
+		return nil, fmt.Errorf("failed to save registration form: %w", err)
+	}
+	return form, nil
+}
+
+// GetForm mengembalikan form yang berlaku: form khusus ticket type jika ada, selain itu form event.
+func (uc *registrationFormUsecase) GetForm(eventID, userID int, ticketTypeID *int, accessCode string) (*models.RegistrationForm, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := uc.invitationUC.CanView(event, userID, accessCode)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("event tidak ditemukan")
+	}
+
+	form, err := uc.effectiveForm(eventID, intValue(ticketTypeID))
+	if err != nil {
+		return nil, err
+	}
+	if form == nil {
+		return nil, errors.New("registration form not found")
+	}
+	return form, nil
+}
+
+func (uc *registrationFormUsecase) effectiveForm(eventID, ticketTypeID int) (*models.RegistrationForm, error) {
+	if ticketTypeID != 0 {
+		form, err := uc.repo.FindForm(eventID, &ticketTypeID)
+		if err != nil || form != nil {
+			return form, err
+		}
+	}
+	return uc.repo.FindForm(eventID, nil)
+}
+
+func (uc *registrationFormUsecase) ListForms(eventID, organizerID int, role string) ([]models.RegistrationForm, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListFormsByEvent(eventID)
+}
+
+func (uc *registrationFormUsecase) DeleteForm(eventID, organizerID int, role string, ticketTypeID *int) error {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return err
+	}
+
+	err := uc.repo.DeleteForm(eventID, ticketTypeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("registration form not found")
+	}
+	return err
+}
+
+// UploadFile menyimpan file untuk field bertipe "file". Token yang dikembalikan dipakai sebagai jawaban saat Register.
+func (uc *registrationFormUsecase) UploadFile(eventID, userID int, fieldKey, accessCode string, file *multipart.FileHeader) (*dto.FormUploadResponse, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := uc.invitationUC.CanView(event, userID, accessCode)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("event tidak ditemukan")
+	}
+
+	forms, err := uc.repo.ListFormsByEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	field := findFileField(forms, fieldKey)
+	if field == nil {
+		return nil, fmt.Errorf("field %q is not a file field of this event", fieldKey)
+	}
+
+	if file.Size > uc.maxUploadSize {
+		return nil, fmt.Errorf("file exceeds maximum size of %d bytes", uc.maxUploadSize)
+	}
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if len(field.Options) > 0 && !containsFold(field.Options, ext) {
+		return nil, fmt.Errorf("file type %q is not allowed, allowed: %s", ext, strings.Join(field.Options, ", "))
+	}
+
+	token, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate upload token: %w", err)
+	}
+
+	dir := filepath.Join(uc.uploadDir, "forms", strconv.Itoa(eventID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to prepare upload directory: %w", err)
+	}
+	path := filepath.Join(dir, token+ext)
+	if err := saveMultipartFile(file, path); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	upload := &models.FormUpload{
+		Token:       token,
+		EventID:     eventID,
+		UserID:      userID,
+		FieldKey:    fieldKey,
+		FileName:    filepath.Base(file.Filename),
+		ContentType: file.Header.Get("Content-Type"),
+		Size:        file.Size,
+		Path:        path,
+	}
+	if err := uc.repo.CreateUpload(upload); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to save upload: %w", err)
+	}
+
+	return &dto.FormUploadResponse{
+		Token:    upload.Token,
+		FieldKey: upload.FieldKey,
+		FileName: upload.FileName,
+		Size:     upload.Size,
+	}, nil
+}
+
+// ValidateAnswers memvalidasi jawaban terhadap form yang berlaku untuk ticket type.
+// Mengembalikan nil jika event tidak memiliki form.
+func (uc *registrationFormUsecase) ValidateAnswers(eventID, ticketTypeID, userID int, answers map[string]any) (*models.RegistrationAnswer, error) {
+	form, err := uc.effectiveForm(eventID, ticketTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching registration form: %w", err)
+	}
+	if form == nil {
+		return nil, nil
+	}
+
+	known := make(map[string]bool, len(form.Fields))
+	for _, field := range form.Fields {
+		known[field.Key] = true
+	}
+
+	var problems []string
+	for key := range answers {
+		if !known[key] {
+			problems = append(problems, fmt.Sprintf("%s: unknown field", key))
+		}
+	}
+
+	// Field diproses berurutan; kondisi show_if hanya boleh merujuk field sebelumnya,
+	// sehingga field yang tersembunyi otomatis menyembunyikan turunannya juga.
+	cleaned := make(map[string]any)
+	for _, field := range form.Fields {
+		if field.ShowIf != nil && !conditionMet(cleaned[field.ShowIf.Field], field.ShowIf.Equals) {
+			continue
+		}
+
+		value, present := answers[field.Key]
+		if !present || isEmptyAnswer(value) {
+			if field.Required {
+				problems = append(problems, fmt.Sprintf("%s: is required", field.Key))
+			}
+			continue
+		}
+
+		normalized, err := uc.validateFieldAnswer(field, value, eventID, userID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", field.Key, err.Error()))
+			continue
+		}
+		cleaned[field.Key] = normalized
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid form answers: %s", strings.Join(problems, "; "))
+	}
+
+	return &models.RegistrationAnswer{
+		EventID: eventID,
+		UserID:  userID,
+		FormID:  form.ID,
+		Answers: cleaned,
+	}, nil
+}
+
+func (uc *registrationFormUsecase) validateFieldAnswer(field models.FormField, value any, eventID, userID int) (any, error) {
+	switch field.Type {
+	case models.FormFieldText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, errors.New("must be a string")
+		}
+		text = strings.TrimSpace(text)
+		if field.MaxLength > 0 && len([]rune(text)) > field.MaxLength {
+			return nil, fmt.Errorf("must be at most %d characters", field.MaxLength)
+		}
+		return text, nil
+
+	case models.FormFieldSelect:
+		choice, ok := value.(string)
+		if !ok || !contains(field.Options, choice) {
+			return nil, fmt.Errorf("must be one of: %s", strings.Join(field.Options, ", "))
+		}
+		return choice, nil
+
+	case models.FormFieldCheckbox:
+		// Checkbox tunggal (mis. persetujuan) berupa boolean; dengan options berupa daftar pilihan.
+		if len(field.Options) == 0 {
+			checked, ok := value.(bool)
+			if !ok {
+				return nil, errors.New("must be true or false")
+			}
+			if field.Required && !checked {
+				return nil, errors.New("must be checked")
+			}
+			return checked, nil
+		}
+		items, ok := value.([]any)
+		if !ok {
+			return nil, errors.New("must be a list of options")
+		}
+		selected := make([]string, 0, len(items))
+		for _, item := range items {
+			choice, ok := item.(string)
+			if !ok || !contains(field.Options, choice) {
+				return nil, fmt.Errorf("options must be within: %s", strings.Join(field.Options, ", "))
+			}
+			selected = append(selected, choice)
+		}
+		return selected, nil
+
+	case models.FormFieldNumber:
+		number, ok := value.(float64)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, errors.New("must be a number")
+		}
+		if field.Min != nil && number < *field.Min {
+			return nil, fmt.Errorf("must be at least %v", *field.Min)
+		}
+		if field.Max != nil && number > *field.Max {
+			return nil, fmt.Errorf("must be at most %v", *field.Max)
+		}
+		return number, nil
+
+	case models.FormFieldDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, errors.New("must be a date (YYYY-MM-DD)")
+		}
+		if _, err := time.Parse("2006-01-02", text); err != nil {
+			return nil, errors.New("must be a date (YYYY-MM-DD)")
+		}
+		return text, nil
+
+	case models.FormFieldFile:
+		token, ok := value.(string)
+		if !ok {
+			return nil, errors.New("must be an upload token")
+		}
+		upload, err := uc.repo.FindUpload(token)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("upload not found")
+			}
+			return nil, err
+		}
+		if upload.EventID != eventID || upload.UserID != userID || upload.FieldKey != field.Key {
+			return nil, errors.New("upload not found")
+		}
+		return token, nil
+	}
+
+	return nil, fmt.Errorf("unsupported field type %q", field.Type)
+}
+
+func (uc *registrationFormUsecase) SaveAnswer(answer *models.RegistrationAnswer) error {
+	return uc.repo.SaveAnswer(answer)
+}
+
+func (uc *registrationFormUsecase) GetMyAnswers(eventID, userID int) (*models.RegistrationAnswer, error) {
+	answer, err := uc.repo.FindAnswer(eventID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("form answers not found")
+	}
+	return answer, err
+}
+
+func (uc *registrationFormUsecase) ListAnswers(eventID, organizerID int, role string) ([]models.RegistrationAnswer, error) {
+	if _, err := uc.findManagedEvent(eventID, organizerID, role); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListAnswersByEvent(eventID)
+}
+
+// ExportAnswersCSV menulis satu baris per attendee dengan satu kolom per field (gabungan seluruh form event).
+func (uc *registrationFormUsecase) ExportAnswersCSV(eventID, organizerID int, role string, w io.Writer) error {
+	answers, err := uc.ListAnswers(eventID, organizerID, role)
+	if err != nil {
+		return err
+	}
+	forms, err := uc.repo.ListFormsByEvent(eventID)
+	if err != nil {
+		return err
+	}
+
+	keys := FormFieldKeys(forms)
+	// Jawaban diisi bebas oleh peserta, jadi ditulis lewat TableWriter yang meng-escape formula
+	writer := utils.NewCSVTableWriter(w)
+	if err := writer.WriteRow(append([]string{"user_id", "submitted_at"}, keys...)); err != nil {
+		return err
+	}
+	for _, answer := range answers {
+		row := []string{strconv.Itoa(answer.UserID), answer.UpdatedAt.Format(time.RFC3339)}
+		for _, key := range keys {
+			row = append(row, FormatAnswer(answer.Answers[key]))
+		}
+		if err := writer.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// FormFieldKeys mengumpulkan key field unik dari beberapa form, sesuai urutan kemunculan.
+func FormFieldKeys(forms []models.RegistrationForm) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, form := range forms {
+		for _, field := range form.Fields {
+			if !seen[field.Key] {
+				seen[field.Key] = true
+				keys = append(keys, field.Key)
+			}
+		}
+	}
+	return keys
+}
+
+// FormatAnswer mengubah jawaban menjadi teks untuk ekspor; pilihan checkbox digabung dengan "; ".
+func FormatAnswer(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, FormatAnswer(item))
+		}
+		return strings.Join(parts, "; ")
+	case []string:
+		return strings.Join(v, "; ")
+	}
+	return fmt.Sprint(value)
+}
+
+func validateFormSchema(fields []models.FormField) error {
+	if len(fields) == 0 {
+		return errors.New("form must have at least one field")
+	}
+
+	seen := make(map[string]bool, len(fields))
+	for i, field := range fields {
+		if !formFieldKeyPattern.MatchString(field.Key) {
+			return fmt.Errorf("field %d: key must be lowercase letters, digits or underscore", i+1)
+		}
+		if seen[field.Key] {
+			return fmt.Errorf("field %q: duplicate key", field.Key)
+		}
+		if strings.TrimSpace(field.Label) == "" {
+			return fmt.Errorf("field %q: label is required", field.Key)
+		}
+
+		switch field.Type {
+		case models.FormFieldText, models.FormFieldCheckbox, models.FormFieldDate, models.FormFieldFile:
+		case models.FormFieldSelect:
+			if len(field.Options) == 0 {
+				return fmt.Errorf("field %q: select requires options", field.Key)
+			}
+		case models.FormFieldNumber:
+			if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+				return fmt.Errorf("field %q: min must not exceed max", field.Key)
+			}
+		default:
+			return fmt.Errorf("field %q: unsupported type %q", field.Key, field.Type)
+		}
+
+		if field.ShowIf != nil && !seen[field.ShowIf.Field] {
+			return fmt.Errorf("field %q: show_if must reference an earlier field", field.Key)
+		}
+		seen[field.Key] = true
+	}
+	return nil
+}
+
+func findFileField(forms []models.RegistrationForm, key string) *models.FormField {
+	for _, form := range forms {
+		for i := range form.Fields {
+			if form.Fields[i].Key == key && form.Fields[i].Type == models.FormFieldFile {
+				return &form.Fields[i]
+			}
+		}
+	}
+	return nil
+}
+
+func conditionMet(answer, expected any) bool {
+	if answer == nil {
+		return false
+	}
+	if selected, ok := answer.([]string); ok {
+		return contains(selected, fmt.Sprint(expected))
+	}
+	return FormatAnswer(answer) == FormatAnswer(expected)
+}
+
+func isEmptyAnswer(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func saveMultipartFile(file *multipart.FileHeader, path string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
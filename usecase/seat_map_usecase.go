@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSeatMapNotFound dikembalikan saat event tidak punya seat map.
+	ErrSeatMapNotFound = errors.New("event has no seat map")
+	// ErrSeatUnavailable dikembalikan saat kursi diblokir, sudah terdaftar atau ditahan user lain.
+	ErrSeatUnavailable = errors.New("seat is not available")
+	// ErrSeatMapInUse dikembalikan saat denah diganti setelah ada kursi yang terdaftar.
+	ErrSeatMapInUse = errors.New("seat map already has registered seats")
+)
+
+type SeatMapUsecase interface {
+	GetSeatMap(eventID, userID int) (*dto.SeatMapView, error)
+	SetSeatMap(eventID, userID int, role string, input dto.SetSeatMapRequest) (*dto.SeatMapView, error)
+	HasSeatMap(eventID int) (bool, error)
+
+	// HoldSeat menahan kursi sementara selama user menyelesaikan registrasi
+	HoldSeat(eventID, userID int, input dto.HoldSeatRequest) (*dto.SeatHold, error)
+	ReleaseHold(eventID, userID int) error
+	// ClaimSeat dipanggil Register: mengembalikan kursi yang ditahan user untuk ticket type tersebut,
+	// atau nil jika event tidak memakai seat map
+	ClaimSeat(eventID, ticketTypeID, userID int, seatID *int) (*models.Seat, error)
+}
+
+type seatMapUsecase struct {
+	repo       repositories.SeatMapRepository
+	eventRepo  repositories.EventsRepository
+	ticketRepo repositories.TicketRepository
+	holdFor    time.Duration
+}
+
+func NewSeatMapUsecase(repo repositories.SeatMapRepository, eventRepo repositories.EventsRepository, ticketRepo repositories.TicketRepository, holdFor time.Duration) SeatMapUsecase {
+	return &seatMapUsecase{
+		repo:       repo,
+		eventRepo:  eventRepo,
+		ticketRepo: ticketRepo,
+		holdFor:    holdFor,
+	}
+}
+
+func (uc *seatMapUsecase) GetSeatMap(eventID, userID int) (*dto.SeatMapView, error) {
+	seatMap, err := uc.repo.FindByEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if seatMap == nil {
+		return nil, ErrSeatMapNotFound
+	}
+
+	zones, err := uc.repo.ZoneViews(seatMap.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range zones {
+		zones[i].Price = dto.NewMoneyView(models.Money{Amount: zones[i].Amount, Currency: zones[i].Currency})
+	}
+	seats, err := uc.repo.SeatViews(seatMap.ID, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SeatMapView{
+		ID:      seatMap.ID,
+		EventID: seatMap.EventID,
+		Name:    seatMap.Name,
+		Zones:   zones,
+		Seats:   seats,
+	}, nil
+}
+
+func (uc *seatMapUsecase) findManagedEvent(eventID, userID int, role string) (*models.Event, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganizer(event, userID, role); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (uc *seatMapUsecase) SetSeatMap(eventID, userID int, role string, input dto.SetSeatMapRequest) (*dto.SeatMapView, error) {
+	if _, err := uc.findManagedEvent(eventID, userID, role); err != nil {
+		return nil, err
+	}
+
+	seatMap := &models.SeatMap{EventID: eventID, Name: strings.TrimSpace(input.Name)}
+	zoneIndex := make(map[string]int, len(input.Zones))
+	for _, zone := range input.Zones {
+		name := strings.TrimSpace(zone.Name)
+		if _, ok := zoneIndex[name]; ok {
+			return nil, fmt.Errorf("duplicate zone %q", name)
+		}
+		ticketType, err := uc.ticketRepo.FindTicketByID(zone.TicketTypeID)
+		if err != nil || ticketType.EventID != eventID {
+			return nil, fmt.Errorf("zone %q: ticket type %d does not belong to this event", name, zone.TicketTypeID)
+		}
+		zoneIndex[name] = len(seatMap.Zones)
+		seatMap.Zones = append(seatMap.Zones, models.SeatZone{Name: name, TicketTypeID: zone.TicketTypeID})
+	}
+
+	seats := input.Seats
+	if input.CopyFromEventID != nil {
+		copied, err := uc.copySeats(*input.CopyFromEventID, userID, role)
+		if err != nil {
+			return nil, err
+		}
+		seats = copied
+	}
+	if len(seats) == 0 {
+		return nil, errors.New("seat map must have at least one seat")
+	}
+
+	positions := make(map[string]bool, len(seats))
+	for _, seat := range seats {
+		section, row, number := strings.TrimSpace(seat.Section), strings.TrimSpace(seat.Row), strings.TrimSpace(seat.Number)
+		index, ok := zoneIndex[strings.TrimSpace(seat.Zone)]
+		if !ok {
+			return nil, fmt.Errorf("seat %s/%s/%s: unknown zone %q", section, row, number, seat.Zone)
+		}
+		position := section + "\x00" + row + "\x00" + number
+		if positions[position] {
+			return nil, fmt.Errorf("duplicate seat %s/%s/%s", section, row, number)
+		}
+		positions[position] = true
+		seatMap.Seats = append(seatMap.Seats, models.Seat{
+			ZoneID:     index,
+			Section:    section,
+			Row:        row,
+			Number:     number,
+			Accessible: seat.Accessible,
+			Blocked:    seat.Blocked,
+		})
+	}
+
+	if err := uc.repo.Replace(seatMap); err != nil {
+		if errors.Is(err, repositories.ErrSeatMapInUse) {
+			return nil, ErrSeatMapInUse
+		}
+		return nil, err
+	}
+	return uc.GetSeatMap(eventID, userID)
+}
+
+// copySeats mengambil tata letak kursi event lain milik organizer yang sama (venue yang sama)
+func (uc *seatMapUsecase) copySeats(sourceEventID, userID int, role string) ([]dto.SeatInput, error) {
+	if _, err := uc.findManagedEvent(sourceEventID, userID, role); err != nil {
+		return nil, err
+	}
+	source, err := uc.repo.FindByEvent(sourceEventID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("event %d has no seat map to copy", sourceEventID)
+	}
+	seats, zones, err := uc.repo.ListSeats(source.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	zoneNames := make(map[int]string, len(zones))
+	for _, zone := range zones {
+		zoneNames[zone.ID] = zone.Name
+	}
+	inputs := make([]dto.SeatInput, 0, len(seats))
+	for _, seat := range seats {
+		inputs = append(inputs, dto.SeatInput{
+			Section:    seat.Section,
+			Row:        seat.Row,
+			Number:     seat.Number,
+			Zone:       zoneNames[seat.ZoneID],
+			Accessible: seat.Accessible,
+			Blocked:    seat.Blocked,
+		})
+	}
+	return inputs, nil
+}
+
+func (uc *seatMapUsecase) HasSeatMap(eventID int) (bool, error) {
+	return uc.repo.HasSeatMap(eventID)
+}
+
+func (uc *seatMapUsecase) HoldSeat(eventID, userID int, input dto.HoldSeatRequest) (*dto.SeatHold, error) {
+	now := time.Now()
+	until := now.Add(uc.holdFor)
+	seat, err := uc.repo.Hold(eventID, input.SeatID, userID, until, now)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSeatUnavailable) {
+			return nil, ErrSeatUnavailable
+		}
+		return nil, err
+	}
+	return &dto.SeatHold{SeatID: seat.ID, Seat: seat.Label(), HeldUntil: until}, nil
+}
+
+func (uc *seatMapUsecase) ReleaseHold(eventID, userID int) error {
+	return uc.repo.ReleaseHolds(eventID, userID)
+}
+
+func (uc *seatMapUsecase) ClaimSeat(eventID, ticketTypeID, userID int, seatID *int) (*models.Seat, error) {
+	hasSeatMap, err := uc.repo.HasSeatMap(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasSeatMap {
+		if seatID != nil {
+			return nil, errors.New("event does not use reserved seating")
+		}
+		return nil, nil
+	}
+	if seatID == nil {
+		return nil, errors.New("a seat must be selected for this event")
+	}
+
+	seat, seatTicketTypeID, err := uc.repo.FindHeldSeat(eventID, *seatID, userID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("seat is not held by you or the hold has expired")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if seatTicketTypeID != ticketTypeID {
+		return nil, fmt.Errorf("seat %s is priced as ticket type %d", seat.Label(), seatTicketTypeID)
+	}
+	return seat, nil
+}
@@ -1,27 +1,53 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
 	"gatherly-app/models"
+	"gatherly-app/models/dto"
 	"gatherly-app/repositories"
 
 	"github.com/google/uuid"
 )
 
+// ErrInvalidTicket dikembalikan untuk input ticket type yang tidak valid, mis. harga tidak bisa ditagihkan
+var ErrInvalidTicket = errors.New("invalid ticket")
+
 type TicketUseCase interface {
-	CreateTicket(input []models.Ticket) ([]models.Ticket, error)
+	CreateTicket(input []dto.CreateTicketRequest) ([]models.Ticket, error)
 	DeleteTicketById(id []int) (models.Ticket, error)
 }
 
 type ticketUseCaseImpl struct {
 	ticketRepository repositories.TicketRepository
+	eventRepository  repositories.EventsRepository
 }
 
-func NewTicketUseCase(ticketRepository repositories.TicketRepository) TicketUseCase {
-	return &ticketUseCaseImpl{ticketRepository: ticketRepository}
+func NewTicketUseCase(ticketRepository repositories.TicketRepository, eventRepository repositories.EventsRepository) TicketUseCase {
+	return &ticketUseCaseImpl{ticketRepository: ticketRepository, eventRepository: eventRepository}
 }
 
-func (tc *ticketUseCaseImpl) CreateTicket(input []models.Ticket) ([]models.Ticket, error) {
+// ticketPrice mengubah harga satuan utama ke minor unit. Harga harus bisa disimpan tanpa
+// pembulatan dan bisa ditagihkan (IDR tanpa sen), sama seperti jalur import.
+func ticketPrice(value float64, currency models.Currency) (models.Money, error) {
+	if value < 0 {
+		return models.Money{}, errors.New("price must not be negative")
+	}
+	price, err := models.MoneyFromMajor(value, currency.Code)
+	if err != nil {
+		return models.Money{}, err
+	}
+	if price.Major() != value {
+		return models.Money{}, fmt.Errorf("%s allows at most %d decimal place(s)", currency.Code, currency.Exponent)
+	}
+	if !price.Payable() {
+		step := models.Money{Amount: currency.Rounding, Currency: currency.Code}
+		return models.Money{}, fmt.Errorf("%s prices must be a multiple of %s", currency.Code, step.Format(models.LocaleEnglish))
+	}
+	return price, nil
+}
+
+func (tc *ticketUseCaseImpl) CreateTicket(input []dto.CreateTicketRequest) ([]models.Ticket, error) {
 	var errs error
 	ticket := []models.Ticket{}
 
@@ -30,11 +56,28 @@ func (tc *ticketUseCaseImpl) CreateTicket(input []models.Ticket) ([]models.Ticke
 		return []models.Ticket{}, errs
 	}
 
-	for _, t := range input {
+	currencies := make(map[int]models.Currency)
+	for i, t := range input {
+		currency, ok := currencies[t.EventID]
+		if !ok {
+			event, err := tc.eventRepository.FindEventByID(t.EventID)
+			if err != nil {
+				return nil, fmt.Errorf("%w: ticket %d: event %d not found", ErrInvalidTicket, i+1, t.EventID)
+			}
+			if currency, err = models.LookupCurrency(event.Currency); err != nil {
+				return nil, err
+			}
+			currencies[t.EventID] = currency
+		}
+		price, err := ticketPrice(t.Price, currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: ticket %d: %v", ErrInvalidTicket, i+1, err)
+		}
+
 		newTicket := models.Ticket{
 			TikcetUuid: GenerateUuid(),
 			TicketType: t.TicketType,
-			Price:      t.Price,
+			Price:      price.Amount,
 			Quota:      t.Quota,
 			Status:     t.Status,
 			EventID:    t.EventID,
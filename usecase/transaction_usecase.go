@@ -1,17 +1,22 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"gatherly-app/models"
 	"gatherly-app/models/dto"
 	"gatherly-app/repositories"
 	"gatherly-app/service"
-
+	"log"
+	"strconv"
 	"time"
 )
 
 type TransactionUsecase interface {
 	CreateTransaction(input dto.CreateTransaction, midtransInput dto.MidtransSnapReq) (models.Transactions, error)
+	// QuoteOrder menghitung pajak pesanan dan memastikan hasilnya bisa ditagihkan lewat payment provider
+	QuoteOrder(event *models.Event, items []dto.OrderItem) (*dto.OrderQuote, error)
 	GetAllTransactions(userId int) ([]models.Transactions, error)
 	FindTransactionById(id uint, userId int) (models.Transactions, error)
 	FindTransactionByEventId(eventId uint, userId int) ([]models.Transactions, error)
@@ -21,22 +26,69 @@ type TransactionUsecase interface {
 	FindTransactionByDateRange(input dto.GetTransactionsByDate, userId int) ([]models.Transactions, error)
 	FindTransactionByTicket(ticket string, userId int) ([]models.Transactions, error)
 	DeleteTransactionById(id uint, userId int) error
+	// HandleNotification menerapkan notifikasi Midtrans setelah signature dan nominalnya diverifikasi
 	HandleNotification(notification dto.MidtransNotification) error
+	// ApplyGatewayStatus menerapkan status yang dibaca langsung dari gateway (GetStatus) atau yang
+	// ditetapkan sistem sendiri, mis. invoice group booking yang ditandai lunas
+	ApplyGatewayStatus(status dto.MidtransStatusResp) error
 }
 
+var (
+	ErrInvalidSignature = errors.New("payment notification signature is invalid")
+	ErrAmountMismatch   = errors.New("payment amount does not match the transaction")
+	// ErrInvalidStatusTransition dikembalikan untuk status gateway yang tidak boleh diterapkan pada
+	// status transaksi saat ini, mis. settlement untuk transaksi yang sudah expire
+	ErrInvalidStatusTransition = errors.New("transaction status transition is not allowed")
+)
+
 type transactionUsecase struct {
 	transactionRepository repositories.TransactionRepository
 	midtransService       service.MidtransService
+	notificationUsecase   NotificationUsecase
+	pubsub                service.PubSub
+	eventStatsUsecase     EventStatsUsecase
+	webhookUsecase        WebhookUsecase
+	ledgerUsecase         LedgerUsecase
+	payoutUsecase         PayoutUsecase
+	invoiceUsecase        InvoiceUsecase
 }
 
-func NewTransactionUsecase(transactionRepository repositories.TransactionRepository, midtransService service.MidtransService) TransactionUsecase {
+func NewTransactionUsecase(transactionRepository repositories.TransactionRepository, midtransService service.MidtransService, notificationUsecase NotificationUsecase, pubsub service.PubSub, eventStatsUsecase EventStatsUsecase, webhookUsecase WebhookUsecase, ledgerUsecase LedgerUsecase, payoutUsecase PayoutUsecase, invoiceUsecase InvoiceUsecase) TransactionUsecase {
 	return &transactionUsecase{
 		transactionRepository: transactionRepository,
 		midtransService:       midtransService,
+		notificationUsecase:   notificationUsecase,
+		pubsub:                pubsub,
+		eventStatsUsecase:     eventStatsUsecase,
+		webhookUsecase:        webhookUsecase,
+		ledgerUsecase:         ledgerUsecase,
+		payoutUsecase:         payoutUsecase,
+		invoiceUsecase:        invoiceUsecase,
 	}
 }
 
 func (t *transactionUsecase) CreateTransaction(input dto.CreateTransaction, midtransInput dto.MidtransSnapReq) (models.Transactions, error) {
+	if input.Quote != nil {
+		input.Amount = input.Quote.Total
+		input.Currency = input.Quote.Currency
+		items, err := t.midtransItems(*input.Quote)
+		if err != nil {
+			return models.Transactions{}, err
+		}
+		midtransInput.ItemDetails = items
+	}
+
+	currency := input.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	// gross_amount selalu diturunkan dari nominal yang disimpan agar keduanya tidak bisa berbeda
+	grossAmount, err := t.midtransService.GrossAmount(models.Money{Amount: input.Amount, Currency: currency})
+	if err != nil {
+		return models.Transactions{}, err
+	}
+	midtransInput.TransactionDetails.GrossAmount = grossAmount
+
 	resp, err := t.midtransService.Pay(midtransInput)
 	if err != nil {
 		return models.Transactions{}, err
@@ -46,6 +98,7 @@ func (t *transactionUsecase) CreateTransaction(input dto.CreateTransaction, midt
 		UserId:                      input.UserId,
 		EventId:                     input.EventId,
 		Amount:                      input.Amount,
+		Currency:                    currency,
 		TransactionDate:             time.Now(),
 		Status:                      "pending",
 		PaymentMethod:               "",
@@ -67,9 +120,84 @@ func (t *transactionUsecase) CreateTransaction(input dto.CreateTransaction, midt
 		return models.Transactions{}, err
 	}
 
+	if input.Quote != nil {
+		// Transaksi tetap sah tanpa invoice; invoice yang gagal dibuat bisa diterbitkan ulang manual
+		if _, err := t.invoiceUsecase.Issue(result, *input.Quote); err != nil {
+			log.Printf("failed to issue invoice for %s: %v", result.PaymentGatewayTransactionId, err)
+		}
+	}
+
 	return result, nil
 }
 
+// QuoteOrder dipanggil sebelum pendaftaran dibuat, agar user tidak tertinggal dengan registrasi
+// "pending" yang tidak bisa dibayar
+func (t *transactionUsecase) QuoteOrder(event *models.Event, items []dto.OrderItem) (*dto.OrderQuote, error) {
+	quote, err := t.invoiceUsecase.Quote(event, items)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := t.midtransItems(*quote); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// midtransItems menyusun item_details dari baris quote. Pada mode exclusive harga item adalah
+// harga net dan pajak dikirim sebagai item tersendiri; selain itu harga item sudah termasuk pajak.
+// Jumlahnya harus sama persis dengan gross_amount, jika tidak Midtrans menolak request.
+func (t *transactionUsecase) midtransItems(quote dto.OrderQuote) ([]dto.MidtransItemDetail, error) {
+	var items []dto.MidtransItemDetail
+	var sum int
+	for i, line := range quote.Lines {
+		price, err := t.midtransService.GrossAmount(models.Money{Amount: line.UnitPrice, Currency: quote.Currency})
+		if err != nil {
+			return nil, err
+		}
+		if quote.TaxMode == models.TaxInclusive && line.TotalAmount != line.UnitPrice*int64(line.Quantity) {
+			return nil, fmt.Errorf("line %q total does not match its unit price", line.Description)
+		}
+		items = append(items, dto.MidtransItemDetail{
+			ID:       fmt.Sprintf("line-%d", i+1),
+			Price:    price,
+			Quantity: line.Quantity,
+			Name:     midtransItemName(line.Description),
+		})
+		sum += price * line.Quantity
+	}
+	if quote.TaxMode == models.TaxExclusive && quote.TaxTotal > 0 {
+		tax, err := t.midtransService.GrossAmount(models.Money{Amount: quote.TaxTotal, Currency: quote.Currency})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, dto.MidtransItemDetail{
+			ID:       "tax",
+			Price:    tax,
+			Quantity: 1,
+			Name:     midtransItemName(fmt.Sprintf("%s %s%%", quote.TaxName, strconv.FormatFloat(float64(quote.TaxRateBps)/100, 'f', -1, 64))),
+		})
+		sum += tax
+	}
+
+	total, err := t.midtransService.GrossAmount(models.Money{Amount: quote.Total, Currency: quote.Currency})
+	if err != nil {
+		return nil, err
+	}
+	if sum != total {
+		return nil, fmt.Errorf("item details sum %d does not match gross amount %d", sum, total)
+	}
+	return items, nil
+}
+
+// midtransItemName memotong nama item ke 50 karakter, batas Midtrans
+func midtransItemName(name string) string {
+	runes := []rune(name)
+	if len(runes) > 50 {
+		return string(runes[:50])
+	}
+	return name
+}
+
 func (t *transactionUsecase) GetAllTransactions(userId int) ([]models.Transactions, error) {
 	result, err := t.transactionRepository.GetAll(userId)
 	if err != nil {
@@ -119,7 +247,20 @@ func (t *transactionUsecase) FindTransactionByStatus(status string, userId int)
 }
 
 func (t *transactionUsecase) FindTransactionByAmountRange(input dto.GetTransactionsByAmount, userId int) ([]models.Transactions, error) {
-	result, err := t.transactionRepository.FindByAmountRange(input, userId)
+	currency := input.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	minAmount, err := models.MoneyFromMajor(input.MinAmount, currency)
+	if err != nil {
+		return nil, err
+	}
+	maxAmount, err := models.MoneyFromMajor(input.MaxAmount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := t.transactionRepository.FindByAmountRange(minAmount, maxAmount, userId)
 
 	if err != nil {
 		return nil, err
@@ -169,12 +310,157 @@ func (t *transactionUsecase) DeleteTransactionById(id uint, userId int) error {
 }
 
 func (t *transactionUsecase) HandleNotification(notification dto.MidtransNotification) error {
-	_, err := t.transactionRepository.FindByTransactionIdNoUser(notification.OrderID)
+	if !t.midtransService.VerifySignature(notification) {
+		return ErrInvalidSignature
+	}
+	transaction, err := t.transactionRepository.FindByTransactionIdNoUser(notification.OrderID)
+	if err != nil {
+		return err
+	}
+	if !grossAmountMatches(transaction, notification.GrossAmount) {
+		return fmt.Errorf("%w: local %s, gateway %s", ErrAmountMismatch, transaction.Money(), notification.GrossAmount)
+	}
+	return t.applyStatus(transaction, notification.TransactionStatus, notification.FraudStatus, notification.PaymentType)
+}
 
+func (t *transactionUsecase) ApplyGatewayStatus(status dto.MidtransStatusResp) error {
+	transaction, err := t.transactionRepository.FindByTransactionIdNoUser(status.OrderID)
 	if err != nil {
 		return err
 	}
+	if status.GrossAmount != "" && !grossAmountMatches(transaction, status.GrossAmount) {
+		return fmt.Errorf("%w: local %s, gateway %s", ErrAmountMismatch, transaction.Money(), status.GrossAmount)
+	}
+	return t.applyStatus(transaction, status.TransactionStatus, status.FraudStatus, status.PaymentType)
+}
 
-	t.transactionRepository.UpdateStatus(notification)
+// grossAmountMatches membandingkan gross_amount gateway (mis. "150000.00") dengan nominal transaksi dalam minor unit
+func grossAmountMatches(transaction models.Transactions, grossAmount string) bool {
+	amount, err := strconv.ParseFloat(grossAmount, 64)
+	if err != nil {
+		return false
+	}
+	gateway, err := models.MoneyFromMajor(amount, transaction.Money().Currency)
+	return err == nil && gateway.Amount == transaction.Amount
+}
+
+// statusTransitionFrom mengembalikan status transaksi yang boleh berpindah ke status gateway;
+// nil untuk status yang tidak mengubah apa pun (pending, authorize)
+func statusTransitionFrom(status string) []string {
+	switch status {
+	case "settlement", "capture", "expire", "cancel", "deny", "failure":
+		return []string{"pending"}
+	case "refund", "partial_refund":
+		return []string{"settlement", "capture", "partial_refund"}
+	}
 	return nil
 }
+
+// applyStatus menerapkan status gateway hanya pada transisi yang valid, sehingga efek samping dan
+// entry ledger tidak tercatat ulang oleh notifikasi yang dikirim ulang atau datang terlambat
+func (t *transactionUsecase) applyStatus(transaction models.Transactions, status, fraudStatus, paymentType string) error {
+	orderID := transaction.PaymentGatewayTransactionId
+
+	// capture dengan fraud_status challenge belum lunas sampai di-approve di dashboard Midtrans
+	if status == "capture" && fraudStatus == "challenge" {
+		log.Printf("payment %s is held for fraud review", orderID)
+		return nil
+	}
+	from := statusTransitionFrom(status)
+	if from == nil {
+		return nil
+	}
+
+	ticketCode := ""
+	if isSettledStatus(status) {
+		ticketCode = generateTicketCode(transaction.EventId, transaction.UserId)
+	}
+	transition, err := t.transactionRepository.TransitionStatus(orderID, from, status, paymentType, ticketCode)
+	if err != nil {
+		return err
+	}
+	previous := transition.Previous
+	if !transition.Changed {
+		if sameTransactionStatus(previous, status) {
+			return nil
+		}
+		log.Printf("ignoring %s for %s: transaction is %s", status, orderID, previous)
+		return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, previous, status)
+	}
+
+	t.pubsub.Publish(service.UserTopic(transaction.UserId), service.StreamEvent{
+		Type: service.StreamPaymentStatus,
+		Data: map[string]any{
+			"order_id": orderID,
+			"event_id": transaction.EventId,
+			"status":   status,
+		},
+	})
+
+	if issued := transition.Issued; issued != nil {
+		if issued.TicketCode != nil {
+			ticket := map[string]any{
+				"event_id":    transaction.EventId,
+				"ticket_code": *issued.TicketCode,
+			}
+			if issued.SeatLabel != nil {
+				ticket["seat"] = *issued.SeatLabel
+			}
+			t.pubsub.Publish(service.UserTopic(transaction.UserId), service.StreamEvent{
+				Type: service.StreamTicketIssued,
+				Data: ticket,
+			})
+		} else {
+			log.Printf("payment %s settled but ticket type %d is sold out; refund required", orderID, *issued.TicketTypeID)
+		}
+	}
+
+	if isSettledStatus(status) {
+		payload := map[string]any{
+			"event_id": transaction.EventId,
+			"order_id": orderID,
+			"amount":   transaction.Amount,
+			"currency": transaction.Money().Currency,
+			"items":    transaction.Items,
+		}
+		err = t.notificationUsecase.Notify([]int{transaction.UserId}, models.NotificationPaymentReceived, payload, orderID)
+		if err != nil {
+			log.Printf("failed to queue payment notification for %s: %v", orderID, err)
+		}
+		// Dipublish sekali per settlement: notifikasi ulang tidak lolos TransitionStatus, dan tidak ada
+		// jalur konfirmasi pembayaran lain yang ikut mempublish
+		var ticketTypeID *int
+		if transition.Issued != nil {
+			ticketTypeID = transition.Issued.TicketTypeID
+		}
+		t.eventStatsUsecase.Publish(context.Background(), transaction.EventId, service.StreamPaymentSettled, transaction.UserId, ticketTypeID)
+		t.webhookUsecase.EmitOnce(context.Background(), models.WebhookPaymentSettled, transaction.EventId, transaction.UserId, orderID)
+
+		t.postSettlement(transaction)
+	}
+
+	switch {
+	case status == "refund" && isSettledStatus(previous):
+		if err := t.ledgerUsecase.RecordRefund(transaction, transaction.Amount, orderID); err != nil {
+			log.Printf("failed to record refund for %s in ledger: %v", orderID, err)
+		}
+	case status == "refund", status == "partial_refund":
+		// Nominal refund sebagian tidak dikirim gateway; dicatat manual setelah dicek di dashboard Midtrans
+		log.Printf("%s for %s must be recorded in the ledger manually", status, orderID)
+	}
+	return nil
+}
+
+// postSettlement mencatat settlement dan fee platform yang menjadi saldo payout organizer. Hanya
+// dipanggil pada transisi pending ke lunas dari notifikasi terverifikasi atau status dari gateway,
+// dan fee hanya dicatat jika settlement-nya tercatat.
+func (t *transactionUsecase) postSettlement(transaction models.Transactions) {
+	orderID := transaction.PaymentGatewayTransactionId
+	if err := t.ledgerUsecase.RecordSettlement(transaction); err != nil {
+		log.Printf("failed to record settlement for %s in ledger: %v", orderID, err)
+		return
+	}
+	if err := t.payoutUsecase.ApplyPlatformFee(transaction); err != nil {
+		log.Printf("failed to record platform fee for %s in ledger: %v", orderID, err)
+	}
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"fmt"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/utils"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultCityRadiusKm = 25.0
+
+// Koordinat kota di-cache agar filter kota tidak memanggil LocationIQ di setiap request.
+// Jumlah entri dibatasi karena nama kota berasal dari input user.
+const (
+	cityCoordinateTTL        = 24 * time.Hour
+	maxCachedCityCoordinates = 1000
+)
+
+type TrendingUsecase interface {
+	RefreshScores() error
+	GetTrending(query dto.TrendingEventQuery) ([]dto.TrendingEventDTO, error)
+}
+
+type trendingUsecase struct {
+	repo   repositories.TrendingRepository
+	window time.Duration
+
+	cityMu sync.Mutex
+	cities map[string]cachedCoordinate
+}
+
+type cachedCoordinate struct {
+	geocode   utils.Geocode
+	expiresAt time.Time
+}
+
+// NewTrendingUsecase membuat usecase trending; window adalah rentang waktu sinyal yang dihitung.
+func NewTrendingUsecase(repo repositories.TrendingRepository, window time.Duration) TrendingUsecase {
+	return &trendingUsecase{repo: repo, window: window, cities: make(map[string]cachedCoordinate)}
+}
+
+func (uc *trendingUsecase) RefreshScores() error {
+	if err := uc.repo.RefreshScores(uc.window); err != nil {
+		return fmt.Errorf("gagal menghitung skor trending: %w", err)
+	}
+	return nil
+}
+
+func (uc *trendingUsecase) GetTrending(query dto.TrendingEventQuery) ([]dto.TrendingEventDTO, error) {
+	// Filter kota diterjemahkan menjadi pusat koordinat + radius
+	if query.City != "" {
+		coordinate, err := uc.cityCoordinate(query.City)
+		if err != nil {
+			return nil, fmt.Errorf("gagal mendapatkan koordinat kota: %w", err)
+		}
+		query.Latitude = coordinate.Latitude
+		query.Longitude = coordinate.Longitude
+		if query.Radius <= 0 {
+			query.Radius = defaultCityRadiusKm
+		}
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	return uc.repo.FindTrending(query)
+}
+
+// cityCoordinate mengambil koordinat kota dari cache, atau dari LocationIQ jika belum ada/kedaluwarsa
+func (uc *trendingUsecase) cityCoordinate(city string) (*utils.Geocode, error) {
+	key := strings.ToLower(strings.TrimSpace(city))
+	now := time.Now()
+
+	uc.cityMu.Lock()
+	cached, ok := uc.cities[key]
+	uc.cityMu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return &cached.geocode, nil
+	}
+
+	coordinate, err := utils.GetCoordinatesFromAddress(city)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.cityMu.Lock()
+	defer uc.cityMu.Unlock()
+	if len(uc.cities) >= maxCachedCityCoordinates {
+		for name, entry := range uc.cities {
+			if !now.Before(entry.expiresAt) {
+				delete(uc.cities, name)
+			}
+		}
+	}
+	if len(uc.cities) < maxCachedCityCoordinates {
+		uc.cities[key] = cachedCoordinate{geocode: *coordinate, expiresAt: now.Add(cityCoordinateTTL)}
+	}
+	return coordinate, nil
+}
@@ -13,10 +13,17 @@ type UserUsecase interface {
 	CreateUser(input dto.CreateUserRequest) (*dto.UserResponse, error)
 	GetUserByID(id int) (*dto.UserResponse, error)
 	GetAllUsers() ([]dto.UserResponse, error)
-	UpdateUser(id int, input dto.UpdateUserRequest) (*dto.UserResponse, error)
-	DeleteUser(id int) error
+	UpdateUser(id, callerID int, callerRole string, input dto.UpdateUserRequest) (*dto.UserResponse, error)
+	DeleteUser(id, callerID int, callerRole string) error
 }
 
+// ErrUserAccessDenied: user biasa hanya boleh mengubah atau menghapus akunnya sendiri
+var ErrUserAccessDenied = errors.New("you can only modify your own account")
+
+// ErrUserFieldAdminOnly: role, umur dan email dipakai untuk hak akses admin dan syarat eligibility,
+// jadi hanya admin yang boleh mengubahnya
+var ErrUserFieldAdminOnly = errors.New("role, age and email can only be changed by an admin")
+
 type userUsecase struct {
 	repo repositories.UserRepository
 }
@@ -44,11 +51,8 @@ func (uc *userUsecase) CreateUser(input dto.CreateUserRequest) (*dto.UserRespons
 		Username: input.Username,
 		Email:    input.Email,
 		Password: string(hashedPassword),
-		Role:     input.Role,
-	}
-
-	if user.Role == "" {
-		user.Role = "user"
+		// Pendaftaran publik selalu role user; admin hanya bisa diberikan lewat UpdateUser oleh admin
+		Role: "user",
 	}
 
 	err = uc.repo.Create(user)
@@ -63,6 +67,7 @@ func (uc *userUsecase) CreateUser(input dto.CreateUserRequest) (*dto.UserRespons
 		Username: user.Username,
 		Email:    user.Email,
 		Role:     user.Role,
+		Verified: user.Verified,
 	}, nil
 }
 
@@ -79,6 +84,7 @@ func (uc *userUsecase) GetUserByID(id int) (*dto.UserResponse, error) {
 		Username: user.Username,
 		Email:    user.Email,
 		Role:     user.Role,
+		Verified: user.Verified,
 	}, nil
 }
 
@@ -97,12 +103,20 @@ func (uc *userUsecase) GetAllUsers() ([]dto.UserResponse, error) {
 			Username: u.Username,
 			Email:    u.Email,
 			Role:     u.Role,
+			Verified: u.Verified,
 		})
 	}
 	return res, nil
 }
 
-func (uc *userUsecase) UpdateUser(id int, input dto.UpdateUserRequest) (*dto.UserResponse, error) {
+func (uc *userUsecase) UpdateUser(id, callerID int, callerRole string, input dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	if err := authorizeAccountOwner(id, callerID, callerRole); err != nil {
+		return nil, err
+	}
+	if callerRole != "admin" && (input.Role != "" || input.Age != 0 || input.Email != "") {
+		return nil, ErrUserFieldAdminOnly
+	}
+
 	user, err := uc.repo.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -148,9 +162,21 @@ func (uc *userUsecase) UpdateUser(id int, input dto.UpdateUserRequest) (*dto.Use
 		Username: user.Username,
 		Email:    user.Email,
 		Role:     user.Role,
+		Verified: user.Verified,
 	}, nil
 }
 
-func (uc *userUsecase) DeleteUser(id int) error {
+func (uc *userUsecase) DeleteUser(id, callerID int, callerRole string) error {
+	if err := authorizeAccountOwner(id, callerID, callerRole); err != nil {
+		return err
+	}
 	return uc.repo.Delete(id)
 }
+
+// authorizeAccountOwner mengizinkan pemilik akun atau admin
+func authorizeAccountOwner(id, callerID int, callerRole string) error {
+	if callerRole == "admin" || id == callerID {
+		return nil
+	}
+	return ErrUserAccessDenied
+}
@@ -0,0 +1,291 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"log"
+	"time"
+)
+
+var (
+	// ErrNotAdmitted dikembalikan saat event memakai antrean dan user belum mendapat giliran checkout
+	ErrNotAdmitted = errors.New("this event uses a waiting queue; join the queue and wait for your turn")
+	// ErrQueueEntryNotFound dikembalikan saat user belum masuk antrean event
+	ErrQueueEntryNotFound = errors.New("you are not in the queue for this event")
+	// ErrCheckoutExpired dikembalikan saat jendela checkout user sudah lewat
+	ErrCheckoutExpired = errors.New("your checkout window has expired; join the queue again")
+)
+
+type VirtualQueueUsecase interface {
+	Join(eventID, userID int) (*dto.QueueStatus, error)
+	Status(eventID, userID int) (*dto.QueueStatus, error)
+	// StatusByToken memeriksa status memakai token antrean, tanpa login
+	StatusByToken(eventID int, token string) (*dto.QueueStatus, error)
+	// Subscribe memberi sinyal setiap antrean event bergerak, untuk stream status
+	Subscribe(eventID int) (<-chan service.StreamEvent, func())
+	// RequireAdmission dipanggil sebelum checkout; event tanpa mode antrean selalu lolos
+	RequireAdmission(eventID, userID int) error
+	// Complete menandai user yang sudah mendapatkan tiket agar tidak lagi memakai jatah inventory
+	Complete(eventID, userID int)
+	// AdmitBatches menutup checkout yang kedaluwarsa lalu memasukkan batch berikutnya,
+	// tidak melebihi sisa inventory dan admission rate event
+	AdmitBatches() error
+}
+
+type virtualQueueUsecase struct {
+	repo       repositories.VirtualQueueRepository
+	policyRepo repositories.PurchaseGuardRepository
+	eventRepo  repositories.EventsRepository
+	locker     repositories.AdvisoryLocker
+	tokens     *service.QueueTokenSigner
+	pubsub     service.PubSub
+	// admitInterval adalah jarak antar putaran admission, untuk membagi admission rate per menit
+	admitInterval time.Duration
+}
+
+func NewVirtualQueueUsecase(
+	repo repositories.VirtualQueueRepository,
+	policyRepo repositories.PurchaseGuardRepository,
+	eventRepo repositories.EventsRepository,
+	locker repositories.AdvisoryLocker,
+	tokens *service.QueueTokenSigner,
+	pubsub service.PubSub,
+	admitInterval time.Duration,
+) VirtualQueueUsecase {
+	return &virtualQueueUsecase{
+		repo:          repo,
+		policyRepo:    policyRepo,
+		eventRepo:     eventRepo,
+		locker:        locker,
+		tokens:        tokens,
+		pubsub:        pubsub,
+		admitInterval: admitInterval,
+	}
+}
+
+func (uc *virtualQueueUsecase) queueEnabled(eventID int) (bool, error) {
+	policy, err := uc.policyRepo.FindPolicy(eventID)
+	if err != nil {
+		return false, err
+	}
+	return policy != nil && policy.QueueEnabled, nil
+}
+
+func (uc *virtualQueueUsecase) Join(eventID, userID int) (*dto.QueueStatus, error) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == models.EventStatusCancelled {
+		return nil, errors.New("event has been cancelled")
+	}
+	enabled, err := uc.queueEnabled(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, errors.New("event does not use a waiting queue")
+	}
+
+	now := time.Now()
+	entry, err := uc.repo.Join(eventID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status == models.QueueExpired {
+		// Checkout sebelumnya lewat: masuk lagi dari belakang antrean
+		requeued, err := uc.repo.Requeue(entry.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		if requeued != nil {
+			entry = requeued
+		} else if entry, err = uc.repo.FindEntry(eventID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	status, err := uc.status(entry)
+	if err != nil {
+		return nil, err
+	}
+	if status.Token, err = uc.tokens.Sign(entry.EventID, entry.UserID, entry.ID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (uc *virtualQueueUsecase) Status(eventID, userID int) (*dto.QueueStatus, error) {
+	entry, err := uc.repo.FindEntry(eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrQueueEntryNotFound
+	}
+	return uc.status(entry)
+}
+
+// StatusByToken hanya menerima token milik entry yang masih sama; token lama dari sebelum
+// masuk ulang ke antrean dianggap tidak berlaku.
+func (uc *virtualQueueUsecase) StatusByToken(eventID int, token string) (*dto.QueueStatus, error) {
+	claims, err := uc.tokens.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.EventID != eventID {
+		return nil, service.ErrQueueTokenInvalid
+	}
+	entry, err := uc.repo.FindEntryByID(claims.EntryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || entry.EventID != claims.EventID || entry.UserID != claims.UserID {
+		return nil, ErrQueueEntryNotFound
+	}
+	return uc.status(entry)
+}
+
+func (uc *virtualQueueUsecase) Subscribe(eventID int) (<-chan service.StreamEvent, func()) {
+	return uc.pubsub.Subscribe(service.QueueTopic(eventID))
+}
+
+func (uc *virtualQueueUsecase) status(entry *models.QueueEntry) (*dto.QueueStatus, error) {
+	status := &dto.QueueStatus{
+		EventID:    entry.EventID,
+		Status:     entry.Status,
+		JoinedAt:   entry.JoinedAt,
+		AdmittedAt: entry.AdmittedAt,
+	}
+	switch entry.Status {
+	case models.QueueWaiting:
+		position, err := uc.repo.Position(entry)
+		if err != nil {
+			return nil, err
+		}
+		status.Position = position
+
+		policy, err := uc.policyRepo.FindPolicy(entry.EventID)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil && policy.QueueAdmitRate > 0 {
+			status.EstimatedWait = position * 60 / int64(policy.QueueAdmitRate)
+		}
+	case models.QueueAdmitted:
+		status.ExpiresAt = entry.ExpiresAt
+	}
+	return status, nil
+}
+
+func (uc *virtualQueueUsecase) RequireAdmission(eventID, userID int) error {
+	enabled, err := uc.queueEnabled(eventID)
+	if err != nil || !enabled {
+		return err
+	}
+	entry, err := uc.repo.FindEntry(eventID, userID)
+	if err != nil {
+		return err
+	}
+	if entry != nil && entry.Status == models.QueueExpired {
+		return ErrCheckoutExpired
+	}
+	if entry == nil || entry.Status != models.QueueAdmitted {
+		return ErrNotAdmitted
+	}
+	// Job expiry bisa tertinggal satu putaran, jadi batas waktu tetap diperiksa di sini
+	if entry.ExpiresAt != nil && !time.Now().Before(*entry.ExpiresAt) {
+		return ErrCheckoutExpired
+	}
+	return nil
+}
+
+func (uc *virtualQueueUsecase) Complete(eventID, userID int) {
+	if err := uc.repo.Complete(eventID, userID); err != nil {
+		log.Printf("virtual queue: failed to complete entry of user %d for event %d: %v", userID, eventID, err)
+	}
+}
+
+func (uc *virtualQueueUsecase) AdmitBatches() error {
+	acquired, err := uc.locker.TryWithLock(context.Background(), repositories.VirtualQueueLockKey, uc.admitAll)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		log.Println("virtual queue: another instance holds the lock, skipping")
+	}
+	return nil
+}
+
+func (uc *virtualQueueUsecase) admitAll() error {
+	now := time.Now()
+	expired, err := uc.repo.ExpireCheckouts(now)
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		log.Printf("virtual queue: closed %d expired checkout windows", expired)
+	}
+
+	policies, err := uc.repo.QueuedEvents()
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		available, err := uc.repo.AvailableInventory(policy.EventID)
+		if err != nil {
+			log.Printf("virtual queue: failed to load inventory of event %d: %v", policy.EventID, err)
+			continue
+		}
+		// User yang masuk checkout tidak boleh melebihi tiket yang tersisa
+		limit := min(policy.QueueBatchSize, available)
+		if policy.QueueAdmitRate > 0 {
+			if limit, err = uc.rateLimit(policy, limit, now); err != nil {
+				log.Printf("virtual queue: failed to load admission rate of event %d: %v", policy.EventID, err)
+				continue
+			}
+		}
+		if limit <= 0 {
+			continue
+		}
+
+		checkoutWindow := policy.CheckoutWindow
+		if checkoutWindow <= 0 {
+			checkoutWindow = defaultCheckoutWindow
+		}
+		expiresAt := now.Add(time.Duration(checkoutWindow) * time.Minute)
+		admitted, err := uc.repo.AdmitNext(policy.EventID, limit, now, expiresAt)
+		if err != nil {
+			log.Printf("virtual queue: failed to admit users for event %d: %v", policy.EventID, err)
+			continue
+		}
+		if admitted == 0 {
+			continue
+		}
+		log.Printf("virtual queue: admitted %d users to checkout for event %d", admitted, policy.EventID)
+		uc.pubsub.Publish(service.QueueTopic(policy.EventID), service.StreamEvent{
+			Type: service.StreamQueueAdvanced,
+			Data: map[string]any{"event_id": policy.EventID, "admitted": admitted},
+		})
+	}
+	return nil
+}
+
+// rateLimit membagi admission rate per menit ke tiap putaran job, dan tidak melebihi
+// jumlah user yang sudah masuk dalam satu menit terakhir.
+func (uc *virtualQueueUsecase) rateLimit(policy models.EventPurchasePolicy, limit int, now time.Time) (int, error) {
+	perRound := policy.QueueAdmitRate
+	if uc.admitInterval > 0 && uc.admitInterval < time.Minute {
+		perRound = int((int64(policy.QueueAdmitRate)*int64(uc.admitInterval) + int64(time.Minute) - 1) / int64(time.Minute))
+	}
+	recent, err := uc.repo.AdmittedSince(policy.EventID, now.Add(-time.Minute))
+	if err != nil {
+		return 0, err
+	}
+	return min(limit, perRound, policy.QueueAdmitRate-recent), nil
+}
@@ -0,0 +1,455 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gatherly-app/models"
+	"gatherly-app/models/dto"
+	"gatherly-app/repositories"
+	"gatherly-app/service"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxWebhookAttempts   = 8
+	webhookBatchSize     = 50
+	webhookBaseBackoff   = 30 * time.Second
+	webhookMaxBackoff    = 6 * time.Hour
+	webhookStaleAfter    = 10 * time.Minute
+	webhookDeliveryLimit = 100
+	webhookSendTimeout   = 15 * time.Second
+)
+
+// ErrWebhookNotFound dikembalikan saat endpoint tidak ada atau bukan milik organizer yang meminta.
+var ErrWebhookNotFound = errors.New("webhook endpoint not found")
+
+var ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+type WebhookUsecase interface {
+	CreateEndpoint(organizerID int, request dto.CreateWebhookRequest) (*dto.WebhookEndpointCreatedResponse, error)
+	ListEndpoints(organizerID int) ([]models.WebhookEndpoint, error)
+	UpdateEndpoint(organizerID, endpointID int, request dto.UpdateWebhookRequest) (*models.WebhookEndpoint, error)
+	DeleteEndpoint(organizerID, endpointID int) error
+	ListDeliveries(organizerID, endpointID int) ([]models.WebhookDelivery, error)
+	ListAttempts(organizerID, endpointID, deliveryID int) ([]models.WebhookAttempt, error)
+	Redeliver(ctx context.Context, organizerID, endpointID, deliveryID int) (*dto.WebhookRedeliveryResponse, error)
+	// Emit mengantrekan webhook untuk semua endpoint organizer event yang berlangganan eventType
+	Emit(ctx context.Context, eventType string, eventID, userID int)
+	// EmitOnce seperti Emit, tetapi id payload diturunkan dari key (mis. order id) sehingga emit
+	// ulang untuk key yang sama tidak mengirim webhook kedua
+	EmitOnce(ctx context.Context, eventType string, eventID, userID int, key string)
+	// EmitGroupSeat seperti Emit untuk kursi group booking yang tamunya tidak punya akun
+	EmitGroupSeat(ctx context.Context, eventType string, eventID int, ticketCode string)
+	DispatchDue() error
+}
+
+type webhookUsecase struct {
+	repo         repositories.WebhookRepository
+	eventRepo    repositories.EventsRepository
+	attendeeRepo repositories.EventAttendeeRepository
+	userRepo     repositories.UserRepository
+	sender       service.WebhookSender
+}
+
+func NewWebhookUsecase(
+	repo repositories.WebhookRepository,
+	eventRepo repositories.EventsRepository,
+	attendeeRepo repositories.EventAttendeeRepository,
+	userRepo repositories.UserRepository,
+	sender service.WebhookSender,
+) WebhookUsecase {
+	return &webhookUsecase{
+		repo:         repo,
+		eventRepo:    eventRepo,
+		attendeeRepo: attendeeRepo,
+		userRepo:     userRepo,
+		sender:       sender,
+	}
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+func (uc *webhookUsecase) CreateEndpoint(organizerID int, request dto.CreateWebhookRequest) (*dto.WebhookEndpointCreatedResponse, error) {
+	if request.EventID != nil {
+		event, err := uc.eventRepo.FindEventByID(*request.EventID)
+		if err != nil {
+			return nil, fmt.Errorf("event with ID %d not found", *request.EventID)
+		}
+		// Webhook selalu milik organizer event; admin pun tidak bisa mendaftarkan endpoint untuk event orang lain
+		if event.OrganizerID != organizerID {
+			return nil, ErrNotEventOrganizer
+		}
+	}
+
+	if err := service.ValidateWebhookURL(request.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		OrganizerID: organizerID,
+		EventID:     request.EventID,
+		URL:         request.URL,
+		Secret:      secret,
+		EventTypes:  uniqueStrings(request.EventTypes),
+		Description: request.Description,
+		Active:      true,
+	}
+	if err := uc.repo.CreateEndpoint(endpoint); err != nil {
+		return nil, fmt.Errorf("failed to save webhook endpoint: %w", err)
+	}
+	return &dto.WebhookEndpointCreatedResponse{WebhookEndpoint: *endpoint, Secret: secret}, nil
+}
+
+func (uc *webhookUsecase) ListEndpoints(organizerID int) ([]models.WebhookEndpoint, error) {
+	return uc.repo.ListEndpoints(organizerID)
+}
+
+func (uc *webhookUsecase) findEndpoint(organizerID, endpointID int) (*models.WebhookEndpoint, error) {
+	endpoint, err := uc.repo.FindEndpoint(organizerID, endpointID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (uc *webhookUsecase) UpdateEndpoint(organizerID, endpointID int, request dto.UpdateWebhookRequest) (*models.WebhookEndpoint, error) {
+	endpoint, err := uc.findEndpoint(organizerID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.URL != nil {
+		if err := service.ValidateWebhookURL(*request.URL); err != nil {
+			return nil, err
+		}
+		endpoint.URL = *request.URL
+	}
+	if request.EventTypes != nil {
+		endpoint.EventTypes = uniqueStrings(request.EventTypes)
+	}
+	if request.Description != nil {
+		endpoint.Description = *request.Description
+	}
+	if request.Active != nil {
+		endpoint.Active = *request.Active
+	}
+
+	if err := uc.repo.UpdateEndpoint(endpoint); err != nil {
+		return nil, fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	return endpoint, nil
+}
+
+func (uc *webhookUsecase) DeleteEndpoint(organizerID, endpointID int) error {
+	err := uc.repo.DeleteEndpoint(organizerID, endpointID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+func (uc *webhookUsecase) ListDeliveries(organizerID, endpointID int) ([]models.WebhookDelivery, error) {
+	if _, err := uc.findEndpoint(organizerID, endpointID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListDeliveries(endpointID, webhookDeliveryLimit)
+}
+
+func (uc *webhookUsecase) ListAttempts(organizerID, endpointID, deliveryID int) ([]models.WebhookAttempt, error) {
+	if _, err := uc.findEndpoint(organizerID, endpointID); err != nil {
+		return nil, err
+	}
+	if _, err := uc.repo.FindDelivery(endpointID, deliveryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return uc.repo.ListAttempts(deliveryID)
+}
+
+// Redeliver langsung mengirim ulang delivery, termasuk yang sudah berhasil atau gagal permanen.
+// Kegagalan tidak mengubah status maupun jadwal retry otomatis.
+func (uc *webhookUsecase) Redeliver(ctx context.Context, organizerID, endpointID, deliveryID int) (*dto.WebhookRedeliveryResponse, error) {
+	endpoint, err := uc.findEndpoint(organizerID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := uc.repo.FindDelivery(endpointID, deliveryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	if delivery.Status == models.WebhookDeliverySending {
+		return nil, errors.New("delivery is currently being sent, try again later")
+	}
+
+	delivery.Attempts++
+	attempt, sendErr := uc.attempt(ctx, endpoint, delivery, true)
+
+	now := time.Now()
+	delivery.UpdatedAt = now
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+	if sendErr == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	}
+	if err := uc.repo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return &dto.WebhookRedeliveryResponse{Delivery: *delivery, Attempt: *attempt}, nil
+}
+
+// Emit tidak mengembalikan error: webhook tidak boleh menggagalkan pendaftaran atau pembayaran
+func (uc *webhookUsecase) Emit(ctx context.Context, eventType string, eventID, userID int) {
+	uc.emit(ctx, eventType, eventID, uuid.NewString(), uc.attendeeData(ctx, eventID, userID))
+}
+
+func (uc *webhookUsecase) EmitOnce(ctx context.Context, eventType string, eventID, userID int, key string) {
+	uc.emit(ctx, eventType, eventID, eventType+":"+key, uc.attendeeData(ctx, eventID, userID))
+}
+
+func (uc *webhookUsecase) EmitGroupSeat(ctx context.Context, eventType string, eventID int, ticketCode string) {
+	uc.emit(ctx, eventType, eventID, uuid.NewString(), func(data *dto.WebhookAttendeeData) {
+		if seat, err := uc.attendeeRepo.FindGroupSeat(ctx, eventID, ticketCode); err == nil {
+			data.Name = seat.AttendeeName
+			data.Email = seat.AttendeeEmail
+		}
+		if attendee, err := uc.attendeeRepo.FindGroupSeatByTicketCode(ctx, eventID, ticketCode); err == nil {
+			data.GroupBookingID = attendee.GroupBookingID
+			data.TicketTypeID = attendee.TicketTypeID
+			data.RSVPStatus = attendee.RSVPStatus
+			data.PaymentStatus = attendee.PaymentStatus
+			data.TicketCode = attendee.TicketCode
+			data.CheckedInAt = attendee.CheckedInAt
+		}
+	})
+}
+
+// attendeeData mengisi payload dari akun user dan registrasinya
+func (uc *webhookUsecase) attendeeData(ctx context.Context, eventID, userID int) func(data *dto.WebhookAttendeeData) {
+	return func(data *dto.WebhookAttendeeData) {
+		data.UserID = userID
+		if user, err := uc.userRepo.FindByID(userID); err == nil {
+			data.Name = user.Name
+			data.Email = user.Email
+		}
+		// Registrasi yang dibatalkan sudah terhapus, sehingga hanya data user yang dikirim
+		if attendee, err := uc.attendeeRepo.FindByUserAndEvent(ctx, userID, eventID); err == nil && attendee != nil {
+			data.TicketTypeID = attendee.TicketTypeID
+			data.RSVPStatus = attendee.RSVPStatus
+			data.PaymentStatus = attendee.PaymentStatus
+			data.TicketCode = attendee.TicketCode
+			data.Seat = attendee.SeatLabel
+			data.CheckedInAt = attendee.CheckedInAt
+		}
+	}
+}
+
+// emit memuat data peserta lewat fill hanya jika ada endpoint yang berlangganan
+func (uc *webhookUsecase) emit(ctx context.Context, eventType string, eventID int, payloadID string, fill func(data *dto.WebhookAttendeeData)) {
+	event, err := uc.eventRepo.FindEventByID(eventID)
+	if err != nil {
+		log.Printf("webhook %s: failed to load event %d: %v", eventType, eventID, err)
+		return
+	}
+	endpoints, err := uc.repo.FindSubscribedEndpoints(event.OrganizerID, eventID, eventType)
+	if err != nil {
+		log.Printf("webhook %s: failed to load endpoints for event %d: %v", eventType, eventID, err)
+		return
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+
+	data := dto.WebhookAttendeeData{EventID: eventID, EventName: event.Name}
+	fill(&data)
+
+	now := time.Now()
+	body, err := json.Marshal(dto.WebhookPayload{
+		ID:        payloadID,
+		Type:      eventType,
+		CreatedAt: now,
+		Data:      data,
+	})
+	if err != nil {
+		log.Printf("webhook %s: failed to encode payload: %v", eventType, err)
+		return
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			PayloadID:     payloadID,
+			EventType:     eventType,
+			EventID:       eventID,
+			Body:          string(body),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if err := uc.repo.CreateDeliveries(deliveries); err != nil {
+		log.Printf("webhook %s: failed to queue deliveries for event %d: %v", eventType, eventID, err)
+		return
+	}
+
+	go func() {
+		if err := uc.DispatchDue(); err != nil {
+			log.Printf("webhook dispatch failed: %v", err)
+		}
+	}()
+}
+
+// DispatchDue mengirim delivery yang sudah jatuh tempo. Kegagalan dijadwalkan ulang dengan
+// exponential backoff (30 detik, 1 menit, 2 menit, ...) hingga maxWebhookAttempts.
+func (uc *webhookUsecase) DispatchDue() error {
+	if err := uc.repo.ReleaseStaleDeliveries(time.Now().Add(-webhookStaleAfter)); err != nil {
+		return err
+	}
+
+	runStart := time.Now()
+	for {
+		deliveries, err := uc.repo.ClaimDueDeliveries(webhookBatchSize, runStart)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		endpointIDs := make([]int, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			endpointIDs = append(endpointIDs, delivery.EndpointID)
+		}
+		endpoints, err := uc.repo.FindEndpointsByIDs(uniqueInts(endpointIDs))
+		if err != nil {
+			return err
+		}
+		byID := make(map[int]*models.WebhookEndpoint, len(endpoints))
+		for i := range endpoints {
+			byID[endpoints[i].ID] = &endpoints[i]
+		}
+
+		for i := range deliveries {
+			uc.deliver(&deliveries[i], byID[deliveries[i].EndpointID])
+		}
+	}
+}
+
+func (uc *webhookUsecase) deliver(delivery *models.WebhookDelivery, endpoint *models.WebhookEndpoint) {
+	now := time.Now()
+	delivery.UpdatedAt = now
+
+	if endpoint == nil || !endpoint.Active {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = "endpoint is disabled"
+	} else {
+		attempt, err := uc.attempt(context.Background(), endpoint, delivery, false)
+		delivery.LastStatusCode = attempt.StatusCode
+		delivery.LastError = attempt.Error
+		switch {
+		case err == nil:
+			delivery.Status = models.WebhookDeliverySucceeded
+			delivery.DeliveredAt = &now
+		case delivery.Attempts >= maxWebhookAttempts:
+			delivery.Status = models.WebhookDeliveryFailed
+		default:
+			delivery.Status = models.WebhookDeliveryPending
+			delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+		}
+	}
+
+	if err := uc.repo.UpdateDelivery(delivery); err != nil {
+		log.Printf("failed to update webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// attempt mengirim delivery satu kali dan mencatat hasilnya; delivery.Attempts harus sudah dinaikkan
+func (uc *webhookUsecase) attempt(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery, manual bool) (*models.WebhookAttempt, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookSendTimeout)
+	defer cancel()
+
+	result, err := uc.sender.Send(ctx, service.WebhookRequest{
+		URL:        endpoint.URL,
+		Secret:     endpoint.Secret,
+		DeliveryID: delivery.ID,
+		EventType:  delivery.EventType,
+		Body:       delivery.Body,
+	})
+
+	attempt := &models.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		Manual:     manual,
+		StatusCode: result.StatusCode,
+		DurationMs: result.Duration.Milliseconds(),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	if logErr := uc.repo.CreateAttempt(attempt); logErr != nil {
+		log.Printf("failed to log webhook attempt for delivery %d: %v", delivery.ID, logErr)
+	}
+	return attempt, err
+}
+
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookBaseBackoff
+	for i := 1; i < attempts && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxBackoff {
+		delay = webhookMaxBackoff
+	}
+	return delay
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	result := make([]int, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
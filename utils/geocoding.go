@@ -3,9 +3,12 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
+	"time"
 )
 
 type Geocode struct {
@@ -100,3 +103,65 @@ func GetCoordinatesFromAddress(address string) (*Geocode, error) {
 		Longitude: longitude,
 	}, nil
 }
+
+// DistanceKm menghitung jarak great-circle (haversine) dalam kilometer
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// GeocodeResult adalah hasil geocoding satu alamat dalam batch
+type GeocodeResult struct {
+	Geocode *Geocode
+	Err     error
+}
+
+// GeocodeAddresses meng-geocode alamat unik secara paralel dengan batas rate agar tidak
+// melewati limit LocationIQ (default free tier: 2 request/detik).
+func GeocodeAddresses(addresses []string, workers int, interval time.Duration) map[string]GeocodeResult {
+	results := make(map[string]GeocodeResult)
+	unique := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if _, ok := results[address]; !ok && address != "" {
+			results[address] = GeocodeResult{}
+			unique = append(unique, address)
+		}
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for address := range jobs {
+				geocode, err := GetCoordinatesFromAddress(address)
+				mu.Lock()
+				results[address] = GeocodeResult{Geocode: geocode, Err: err}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, address := range unique {
+		<-ticker.C
+		jobs <- address
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
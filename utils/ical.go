@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	icalDateTimeLayout = "20060102T150405Z"
+	icalDateLayout     = "20060102"
+	icalLineLimit      = 75
+)
+
+// ICalEvent is a single VEVENT entry of an iCalendar document.
+type ICalEvent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Categories   string
+	Start        time.Time
+	End          time.Time
+	Latitude     float64
+	Longitude    float64
+	Sequence     int
+	LastModified time.Time
+	Cancelled    bool
+}
+
+// ICalCalendar is a VCALENDAR document that can be rendered to RFC 5545 text.
+type ICalCalendar struct {
+	ProductID string
+	Name      string
+	Events    []ICalEvent
+}
+
+// Render menghasilkan isi file .ics (CRLF, baris dilipat pada 75 oktet).
+func (c ICalCalendar) Render() []byte {
+	var b strings.Builder
+	now := time.Now().UTC()
+
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:"+escapeICalText(c.ProductID))
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+e.UID)
+		writeICalLine(&b, "DTSTAMP:"+now.Format(icalDateTimeLayout))
+		writeICalLine(&b, formatICalStart(e.Start, e.End))
+		writeICalLine(&b, formatICalEnd(e.Start, e.End))
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(e.Summary))
+		if e.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(e.Description))
+		}
+		if e.Categories != "" {
+			writeICalLine(&b, "CATEGORIES:"+escapeICalText(e.Categories))
+		}
+		if e.Latitude != 0 || e.Longitude != 0 {
+			writeICalLine(&b, fmt.Sprintf("GEO:%.6f;%.6f", e.Latitude, e.Longitude))
+			writeICalLine(&b, "LOCATION:"+escapeICalText(fmt.Sprintf("%.6f, %.6f", e.Latitude, e.Longitude)))
+		}
+		writeICalLine(&b, fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		if !e.LastModified.IsZero() {
+			writeICalLine(&b, "LAST-MODIFIED:"+e.LastModified.UTC().Format(icalDateTimeLayout))
+		}
+		if e.Cancelled {
+			writeICalLine(&b, "STATUS:CANCELLED")
+		} else {
+			writeICalLine(&b, "STATUS:CONFIRMED")
+		}
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// Event yang disimpan hanya dengan tanggal (jam 00:00) ditulis sebagai all-day event.
+func isICalAllDay(start, end time.Time) bool {
+	isMidnight := func(t time.Time) bool {
+		return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+	}
+	return isMidnight(start) && isMidnight(end)
+}
+
+func formatICalStart(start, end time.Time) string {
+	if isICalAllDay(start, end) {
+		return "DTSTART;VALUE=DATE:" + start.Format(icalDateLayout)
+	}
+	return "DTSTART:" + start.UTC().Format(icalDateTimeLayout)
+}
+
+func formatICalEnd(start, end time.Time) string {
+	if isICalAllDay(start, end) {
+		// DTEND untuk all-day event bersifat eksklusif
+		return "DTEND;VALUE=DATE:" + end.AddDate(0, 0, 1).Format(icalDateLayout)
+	}
+	return "DTEND:" + end.UTC().Format(icalDateTimeLayout)
+}
+
+func escapeICalText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(s)
+}
+
+// writeICalLine menulis satu content line dan melipatnya sesuai RFC 5545 bagian 3.1.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		// jangan memotong di tengah karakter UTF-8
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// baris lanjutan diawali satu spasi
+		limit = icalLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}